@@ -6,13 +6,42 @@ import (
 
 const (
 	WorkflowActionNamelabel = "conure.io/workflow-action-name"
+	// BuildTypeLabel marks the jobs building the image of a component with their build type
+	BuildTypeLabel = "conure.io/build-type"
+	// BuildReusedLabel marks the build jobs reporting an image already in the registry instead of building it
+	BuildReusedLabel = "conure.io/build-reused"
+	// BuildCacheLabel marks the volumes caching the builds of a component, with the cache key
+	BuildCacheLabel = "conure.io/build-cache"
+	// BuildCacheLastUsedAnnotation is the time of the last build using a cache volume
+	BuildCacheLastUsedAnnotation = "conure.io/build-cache-last-used"
 )
 
+type BuildType string
+
+const (
+	// DockerfileBuild builds the Dockerfile of the source with Kaniko
+	DockerfileBuild BuildType = "dockerfile"
+	// NixpacksBuild generates a Dockerfile with Nixpacks and builds it with Kaniko
+	NixpacksBuild BuildType = "nixpacks"
+	// BuildpacksBuild builds the source with the Cloud Native Buildpacks of a builder
+	BuildpacksBuild BuildType = "buildpacks"
+)
+
+// BuildDefinition makes the action build the image of the component of the run, in a job created by the controller
+type BuildDefinition struct {
+	// Type is the build of the action, picked from the source of the component when it's empty
+	Type BuildType `json:"type,omitempty"`
+	// Image replaces the image of the builder: Kaniko, Nixpacks or the buildpacks builder
+	Image string `json:"image,omitempty"`
+}
+
 // ActionDefinitionSpec defines the desired state of ActionDefinition
 type ActionDefinitionSpec struct {
-	OCIRepository string `json:"ociRepository"`
-	OCITag        string `json:"ociTag"`
+	OCIRepository string `json:"ociRepository,omitempty"`
+	OCITag        string `json:"ociTag,omitempty"`
 	ConfigDocs    string `json:"configDocs"`
+	// Build is set by the built-in build actions, which have no module
+	Build *BuildDefinition `json:"build,omitempty"`
 }
 
 // ActionDefinitionStatus defines the observed state of ActionDefinition
@@ -53,6 +53,20 @@ type ComponentSpec struct {
 
 type ComponentStatus struct {
 	Conditions []metav1.Condition `json:"conditions"`
+	// Build is the image built by the last build action of the component
+	Build *BuildStatus `json:"build,omitempty"`
+}
+
+type BuildStatus struct {
+	OCIRepository string `json:"ociRepository"`
+	Tag           string `json:"tag"`
+	Digest        string `json:"digest"`
+	// Revision is the git commit built, empty when the head of the branch was built
+	Revision    string      `json:"revision,omitempty"`
+	WorkflowRun string      `json:"workflowRun"`
+	BuiltAt     metav1.Time `json:"builtAt"`
+	// Reused is set when the image of the commit was already in the registry and wasn't built again
+	Reused bool `json:"reused,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -98,6 +112,9 @@ type Values struct {
 	Network   Network               `json:"network"`
 	Source    Source                `json:"source"`
 	Storage   []Storage             `json:"storage"`
+	Health    *Health               `json:"health,omitempty"`
+	Lifecycle *Lifecycle            `json:"lifecycle,omitempty"`
+	Rollout   *Rollout              `json:"rollout,omitempty"`
 	Advanced  *runtime.RawExtension `json:"advanced,omitempty"`
 }
 
@@ -105,6 +122,73 @@ type Resources struct {
 	Replicas int    `json:"replicas"`
 	CPU      string `json:"cpu"`
 	Memory   string `json:"memory"`
+	// Autoscaling replaces the fixed replicas by a HorizontalPodAutoscaler
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+}
+
+// Autoscaling are the bounds of the replicas of the component and the usage they're scaled on
+type Autoscaling struct {
+	MinReplicas int `json:"minReplicas"`
+	MaxReplicas int `json:"maxReplicas"`
+	// TargetCPU and TargetMemory are the average utilization of the pods, in percent of their requests
+	TargetCPU    int `json:"targetCPU,omitempty"`
+	TargetMemory int `json:"targetMemory,omitempty"`
+	// ScaleToZero removes every replica while the service receives no requests, scaling on its requests per second
+	// over TargetRequests per replica
+	ScaleToZero    bool `json:"scaleToZero,omitempty"`
+	TargetRequests int  `json:"targetRequests,omitempty"`
+}
+
+type ProbeType string
+
+const (
+	HTTPProbe ProbeType = "http"
+	TCPProbe  ProbeType = "tcp"
+	ExecProbe ProbeType = "exec"
+)
+
+// Probe checks the container with an HTTP GET of Path on Port, a TCP connection to Port or by running Command, the
+// timings left at zero take the defaults of Kubernetes
+type Probe struct {
+	Type                ProbeType `json:"type"`
+	Path                string    `json:"path,omitempty"`
+	Port                int       `json:"port,omitempty"`
+	Command             []string  `json:"command,omitempty"`
+	InitialDelaySeconds int       `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int       `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      int       `json:"timeoutSeconds,omitempty"`
+	FailureThreshold    int       `json:"failureThreshold,omitempty"`
+}
+
+// Health are the probes of the container of the component, the startup probe holds the other two back until it
+// succeeds
+type Health struct {
+	Liveness  *Probe `json:"liveness,omitempty"`
+	Readiness *Probe `json:"readiness,omitempty"`
+	Startup   *Probe `json:"startup,omitempty"`
+}
+
+// Lifecycle is how the container of the component is stopped
+type Lifecycle struct {
+	// PreStopCommand runs in the container before it's sent SIGTERM
+	PreStopCommand []string `json:"preStopCommand,omitempty"`
+	// TerminationGracePeriodSeconds is how long the container has to stop before it's killed
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+}
+
+type RolloutStrategy string
+
+const (
+	RollingUpdateRollout RolloutStrategy = "rolling"
+	RecreateRollout      RolloutStrategy = "recreate"
+)
+
+// Rollout is how a new version of the component replaces the running one. MaxSurge and MaxUnavailable bound a
+// rolling update, as a number of pods or a percentage of the replicas.
+type Rollout struct {
+	Strategy       RolloutStrategy `json:"strategy,omitempty"`
+	MaxSurge       string          `json:"maxSurge,omitempty"`
+	MaxUnavailable string          `json:"maxUnavailable,omitempty"`
 }
 
 type AccessType string
@@ -145,6 +229,32 @@ type Source struct {
 	Command              []string `json:"command"`
 	WorkingDir           string   `json:"workingDir"`
 	ImagePullSecretsName string   `json:"imagePullSecretsName"`
+	// Cache configures the cache of the builds of the source
+	Cache *BuildCache `json:"cache,omitempty"`
+}
+
+type BuildCacheType string
+
+const (
+	// RegistryBuildCache keeps the cached layers in a repository of the registry
+	RegistryBuildCache BuildCacheType = "registry"
+	// VolumeBuildCache keeps the cached layers in a persistent volume of the namespace
+	VolumeBuildCache BuildCacheType = "volume"
+)
+
+// BuildCache configures the cache of the builds of a component, each branch has its own cache
+type BuildCache struct {
+	// Type is the storage of the cached layers, the layers aren't cached when it's empty
+	Type BuildCacheType `json:"type,omitempty"`
+	// Repository keeps the layers of a registry cache, the image repository suffixed with -cache by default
+	Repository string `json:"repository,omitempty"`
+	// Size is the size of the volume of a volume cache, 10Gi by default
+	Size string `json:"size,omitempty"`
+	// TTL is how long the cached layers are reused, the volumes of the branches which weren't built for as long are
+	// deleted. 336h by default.
+	TTL string `json:"ttl,omitempty"`
+	// SkipExisting reuses the image of the built commit when the registry already has it
+	SkipExisting bool `json:"skipExisting,omitempty"`
 }
 
 type Storage struct {
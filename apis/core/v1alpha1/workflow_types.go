@@ -77,6 +77,8 @@ type WorkflowRunSpec struct {
 	WorkflowName    string `json:"workflowName"`
 	ApplicationName string `json:"applicationName"`
 	ComponentName   string `json:"componentName"`
+	// Revision is the git commit to build, the actions build the head of their branch when it's empty
+	Revision string `json:"revision,omitempty"`
 }
 
 type WorkflowRunStatus struct {
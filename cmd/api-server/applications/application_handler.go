@@ -1,9 +1,12 @@
 package applications
 
 import (
+	"context"
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/database"
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"log"
@@ -57,14 +60,22 @@ func (ah *ApplicationHandler) GetApplicationByID(appID string) error {
 	return nil
 }
 
-func (ah *ApplicationHandler) Status(environment *models.Environment) (ProviderStatus, error) {
-	status, err := NewProviderStatus(ah.Model, environment)
+func (ah *ApplicationHandler) Status(config *apiConfig.Config, cluster *providers.Cluster, environment *models.Environment) (ProviderStatus, error) {
+	status, err := NewProviderStatus(config, cluster, ah.Model, environment)
 	if err != nil {
 		return nil, err
 	}
 	return status, nil
 }
 
+func (ah *ApplicationHandler) Watch(ctx context.Context, config *apiConfig.Config, cluster *providers.Cluster, environment *models.Environment) (<-chan providers.StatusEvent, error) {
+	watcher, err := NewProviderWatcher(config, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return watcher.Watch(ctx, ah.Model.ID.Hex(), environment.GetNamespace())
+}
+
 func getHandlerFromRoute(c *gin.Context, db *database.MongoDB) (*ApplicationHandler, error) {
 	// Escape the organizationID
 	if _, err := primitive.ObjectIDFromHex(c.Param("organizationID")); err != nil {
@@ -9,6 +9,7 @@ import (
 )
 
 func TestListOrganizationApplications(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	client, err := models.SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -40,6 +41,7 @@ func TestListOrganizationApplications(t *testing.T) {
 }
 
 func TestListOrganizationApplications_NotFound(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	client, err := models.SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -1,16 +1,25 @@
 package applications
 
 import (
+	"context"
 	"errors"
 	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sigs.k8s.io/yaml"
 
+	"github.com/coffeenights/conure/cmd/api-server/audit"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/middlewares"
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
+	"github.com/coffeenights/conure/cmd/api-server/settings"
 )
 
 func (a *ApiHandler) ListApplications(c *gin.Context) {
@@ -21,7 +30,7 @@ func (a *ApiHandler) ListApplications(c *gin.Context) {
 		return
 	}
 	org := models.Organization{}
-	_, err := org.GetById(a.MongoDB, c.Param("organizationID"))
+	_, err := org.GetById(a.db(c), c.Param("organizationID"))
 	if errors.Is(err, conureerrors.ErrObjectNotFound) {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -35,7 +44,12 @@ func (a *ApiHandler) ListApplications(c *gin.Context) {
 		conureerrors.AbortWithError(c, conureerrors.ErrNotAllowed)
 		return
 	}
-	handlers, err := ListOrganizationApplications(c.Param("organizationID"), a.MongoDB)
+	opts, err := pagination.ParseListOptions(c, models.ApplicationListSpec)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	page, err := models.ApplicationPage(a.db(c), org.ID, opts)
 	if err != nil {
 		log.Printf("Error getting applications list: %v\n", err)
 		conureerrors.AbortWithError(c, err)
@@ -46,21 +60,23 @@ func (a *ApiHandler) ListApplications(c *gin.Context) {
 	response.Organization = OrganizationResponse{
 		Organization: &org,
 	}
-	applicationResponses := make([]ApplicationResponse, len(handlers))
-	for i, handler := range handlers {
-		totalComponents, err := handler.Model.CountComponents(a.MongoDB)
+	applicationResponses := make([]ApplicationResponse, len(page.Items))
+	for i := range page.Items {
+		application := &page.Items[i]
+		totalComponents, err := application.CountComponents(a.db(c))
 		if err != nil {
 			log.Printf("Error counting components: %v\n", err)
 			conureerrors.AbortWithError(c, err)
 			return
 		}
 		r := ApplicationResponse{
-			Application:     handler.Model,
+			Application:     application,
 			TotalComponents: totalComponents,
 		}
 		applicationResponses[i] = r
 	}
 	response.Applications = applicationResponses
+	response.Metadata = pagination.SetNextCursor(c, opts, page.NextCursor)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -78,7 +94,7 @@ func (a *ApiHandler) DetailApplication(c *gin.Context) {
 		return
 	}
 
-	handler, err := NewApplicationHandler(a.MongoDB)
+	handler, err := NewApplicationHandler(a.db(c))
 	if err != nil {
 		log.Printf("Error creating application handler: %v\n", err)
 		conureerrors.AbortWithError(c, err)
@@ -109,7 +125,7 @@ func (a *ApiHandler) CreateApplication(c *gin.Context) {
 		return
 	}
 	org := models.Organization{}
-	_, err := org.GetById(a.MongoDB, c.Param("organizationID"))
+	_, err := org.GetById(a.db(c), c.Param("organizationID"))
 	if err != nil {
 		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
 		return
@@ -127,7 +143,7 @@ func (a *ApiHandler) CreateApplication(c *gin.Context) {
 	}
 	application := models.NewApplication(c.Param("organizationID"), request.Name, uID.Hex())
 	application.Description = request.Description
-	_, err = application.Create(a.MongoDB)
+	_, err = application.Create(a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -136,34 +152,65 @@ func (a *ApiHandler) CreateApplication(c *gin.Context) {
 }
 
 func (a *ApiHandler) DeployApplication(c *gin.Context) {
-	handler, err := getHandlerFromRoute(c, a.MongoDB)
+	handler, err := getHandlerFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
+	org := models.Organization{}
+	if _, err = org.GetById(a.db(c), handler.Model.OrganizationID.Hex()); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	if org.Status == models.OrgDisabled {
+		conureerrors.AbortWithError(c, conureerrors.ErrOrganizationDisabled)
+		return
+	}
 
-	env, err := handler.Model.GetEnvironmentByName(a.MongoDB, c.Param("environment"))
+	env, err := handler.Model.GetEnvironmentByName(a.db(c), c.Param("environment"))
 	if err != nil {
 		log.Printf("Error getting environment: %v\n", err)
 		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
 		return
 	}
-	manifest, err := BuildApplicationManifest(handler.Model, env, a.MongoDB)
+	routing, err := NewRouting(a.Config)
+	if err != nil {
+		log.Printf("Error reading the routing of the cluster: %v\n", err)
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	manifest, err := BuildApplicationManifest(handler.Model, env, a.db(c), routing)
 	if err != nil {
 		log.Printf("Error building application manifest: %v\n", err)
 		conureerrors.AbortWithError(c, err)
 		return
 	}
-	provider, err := NewProviderDispatcher(handler.Model, env)
+	dockerConfig, err := settings.RegistryCredentials(a.db(c), a.KeyStorage, handler.Model.OrganizationID)
+	if err != nil {
+		log.Printf("Error building the registry credentials: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	if dockerConfig != nil {
+		addImagePullSecrets(manifest)
+	}
+	provider, err := NewProviderDispatcher(a.Config, a.Cluster, handler.Model, env)
 	if err != nil {
 		log.Printf("Error creating provider dispatcher: %v\n", err)
 		conureerrors.AbortWithError(c, err)
 		return
 	}
-	err = provider.DeployApplication(manifest)
+	// like the writes to the database, the deploy isn't cancelled half done when the client goes away
+	ctx := context.WithoutCancel(c.Request.Context())
+	if err = provider.SyncRegistryCredentials(ctx, dockerConfig); err != nil {
+		log.Printf("Error syncing the registry credentials: %v\n", err)
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	err = provider.DeployApplication(ctx, manifest)
 	if errors.Is(err, conureerrors.ErrApplicationExists) {
 		log.Println("Application exists, updating instead")
-		err = provider.UpdateApplication(manifest)
+		err = provider.UpdateApplication(ctx, manifest)
 		if err != nil {
 			conureerrors.AbortWithError(c, err)
 			return
@@ -174,25 +221,36 @@ func (a *ApiHandler) DeployApplication(c *gin.Context) {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditApplicationDeployed,
+		OrganizationID: handler.Model.OrganizationID,
+		TargetType:     "application",
+		TargetID:       handler.Model.ID.Hex(),
+		After: map[string]interface{}{
+			"name":           handler.Model.Name,
+			"environment":    env.Name,
+			"environment_id": env.ID,
+		},
+	})
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Application deployed",
 	})
 }
 
 func (a *ApiHandler) StatusApplication(c *gin.Context) {
-	handler, err := getHandlerFromRoute(c, a.MongoDB)
+	handler, err := getHandlerFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
 
-	env, err := handler.Model.GetEnvironmentByName(a.MongoDB, c.Param("environment"))
+	env, err := handler.Model.GetEnvironmentByName(a.db(c), c.Param("environment"))
 	if err != nil {
 		log.Printf("Error getting environment: %v\n", err)
 		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
 		return
 	}
-	status, err := handler.Status(env)
+	status, err := handler.Status(a.Config, a.Cluster, env)
 	if errors.Is(err, k8sUtils.ErrApplicationNotFound) {
 		conureerrors.AbortWithError(c, conureerrors.ErrApplicationNotDeployed)
 		return
@@ -212,3 +270,216 @@ func (a *ApiHandler) StatusApplication(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": appStatus})
 
 }
+
+// WatchStatus streams the readiness of the components, the pod phases and the workflow progress of the environment
+// as server-sent events, starting with the current state
+func (a *ApiHandler) WatchStatus(c *gin.Context) {
+	handler, err := getHandlerFromRoute(c, a.db(c))
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+
+	env, err := handler.Model.GetEnvironmentByName(a.db(c), c.Param("environment"))
+	if err != nil {
+		log.Printf("Error getting environment: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	events, err := handler.Watch(c.Request.Context(), a.Config, a.Cluster, env)
+	if err != nil {
+		log.Printf("Error watching status: %v\n", err)
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	shuttingDown := middlewares.ShuttingDown(c)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event.Data)
+			return true
+		case <-shuttingDown:
+			return false
+		}
+	})
+}
+
+// isDryRun reports whether the request only wants to know what would be removed
+func isDryRun(c *gin.Context) (bool, error) {
+	value := c.Query("dry_run")
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func (a *ApiHandler) UpdateApplication(c *gin.Context) {
+	handler, err := getHandlerFromRoute(c, a.db(c))
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	request := UpdateApplicationRequest{}
+	if err = c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	before := map[string]interface{}{"name": handler.Model.Name, "description": handler.Model.Description}
+	name := handler.Model.Name
+	if request.Name != nil {
+		name = strings.TrimSpace(*request.Name)
+	}
+	if name == "" {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	description := handler.Model.Description
+	if request.Description != nil {
+		description = *request.Description
+	}
+	if err = handler.Model.Rename(a.db(c), name, description); err != nil {
+		log.Printf("Error updating application: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditApplicationUpdated,
+		OrganizationID: handler.Model.OrganizationID,
+		TargetType:     "application",
+		TargetID:       handler.Model.ID.Hex(),
+		Before:         before,
+		After:          map[string]interface{}{"name": handler.Model.Name, "description": handler.Model.Description},
+	})
+	c.JSON(http.StatusOK, ApplicationResponse{Application: handler.Model})
+}
+
+// DeleteApplication removes the application with its components, variables and environments. With dry_run=true only
+// the list of what would be removed is returned.
+func (a *ApiHandler) DeleteApplication(c *gin.Context) {
+	handler, err := getHandlerFromRoute(c, a.db(c))
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	plan, err := PlanApplicationDeletion(a.db(c), handler.Model)
+	if err != nil {
+		log.Printf("Error planning application deletion: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, plan)
+		return
+	}
+	if err = DeleteApplicationCascade(a.db(c), a.Config, a.Cluster, handler.Model); err != nil {
+		log.Printf("Error deleting application: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditApplicationDeleted,
+		OrganizationID: handler.Model.OrganizationID,
+		TargetType:     "application",
+		TargetID:       handler.Model.ID.Hex(),
+		Before: map[string]interface{}{
+			"name":         plan.Application,
+			"components":   plan.Components,
+			"environments": plan.Environments,
+			"variables":    plan.Variables,
+		},
+	})
+	c.Status(http.StatusNoContent)
+}
+
+// planManifest reads the manifest of the request, as JSON or YAML, and compares it with the application of the same
+// name in the organization
+func (a *ApiHandler) planManifest(c *gin.Context) (*ManifestPlan, error) {
+	org, err := a.getOwnedOrganization(c)
+	if err != nil {
+		return nil, err
+	}
+	if org.Status == models.OrgDisabled {
+		return nil, conureerrors.ErrOrganizationDisabled
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		return nil, conureerrors.ErrInvalidRequest
+	}
+	manifest := &Manifest{}
+	if err = yaml.UnmarshalStrict(body, manifest); err != nil {
+		return nil, &ManifestError{Problems: []string{err.Error()}}
+	}
+	currentUser := c.MustGet("currentUser").(models.User)
+	state, err := LoadManifestState(a.db(c), org, currentUser.ID, manifest.Application.Name)
+	if err != nil {
+		log.Printf("Error loading the application of the manifest: %v\n", err)
+		return nil, conureerrors.ErrDatabaseError
+	}
+	if state.Application != nil && state.Application.AccountID != currentUser.ID {
+		return nil, conureerrors.ErrNotAllowed
+	}
+	return PlanManifest(manifest, state)
+}
+
+func abortWithManifestError(c *gin.Context, err error) {
+	var manifestErr *ManifestError
+	if errors.As(err, &manifestErr) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"code":   conureerrors.ErrInvalidManifest.Code,
+			"error":  conureerrors.ErrInvalidManifest.Message,
+			"fields": strings.Join(manifestErr.Problems, "; "),
+		})
+		return
+	}
+	conureerrors.AbortWithError(c, err)
+}
+
+// DiffManifest returns the changes applying the manifest would make
+func (a *ApiHandler) DiffManifest(c *gin.Context) {
+	plan, err := a.planManifest(c)
+	if err != nil {
+		abortWithManifestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plan.Diff)
+}
+
+// ApplyManifest creates, updates and deletes the application, its environments, components and variables to match
+// the manifest. Nothing is changed if any of the changes fails.
+func (a *ApiHandler) ApplyManifest(c *gin.Context) {
+	plan, err := a.planManifest(c)
+	if err != nil {
+		abortWithManifestError(c, err)
+		return
+	}
+	application, err := plan.Apply(a.db(c))
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	if len(plan.Diff.Changes) > 0 {
+		audit.Record(c, a.db(c), audit.Entry{
+			Action:         models.AuditManifestApplied,
+			OrganizationID: application.OrganizationID,
+			TargetType:     "application",
+			TargetID:       application.ID.Hex(),
+			After: map[string]interface{}{
+				"name":    application.Name,
+				"changes": plan.Diff.Changes,
+			},
+		})
+	}
+	c.JSON(http.StatusOK, ManifestApplyResponse{Application: application, Diff: plan.Diff})
+}
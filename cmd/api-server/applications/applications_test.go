@@ -13,6 +13,7 @@ import (
 )
 
 func TestListApplications(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -57,6 +58,7 @@ func TestListApplications(t *testing.T) {
 }
 
 func TestListApplications_Empty(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -81,6 +83,7 @@ func TestListApplications_Empty(t *testing.T) {
 }
 
 func TestDetailApplication(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -117,6 +120,7 @@ func TestDetailApplication(t *testing.T) {
 }
 
 func TestDetailApplication_NotFound(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -142,6 +146,7 @@ func TestDetailApplication_NotFound(t *testing.T) {
 }
 
 func TestCreateApplication(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -175,6 +180,7 @@ func TestCreateApplication(t *testing.T) {
 }
 
 func TestCreateApplication_Empty(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -197,3 +203,90 @@ func TestCreateApplication_Empty(t *testing.T) {
 	}
 	_ = org.Delete(testConf.app.MongoDB)
 }
+
+func TestUpdateApplication(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	org := models.Organization{
+		Status:    models.OrgActive,
+		AccountID: testConf.authUser.ID,
+		Name:      "Test Organization for UpdateApplication",
+	}
+	oID, err := org.Create(testConf.app.MongoDB) // lint:ignore
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := models.NewApplication(oID, "TestUpdateApplication", testConf.authUser.ID.Hex()).Create(testConf.app.MongoDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "/organizations/" + oID + "/a/" + app.ID.Hex()
+	bodyBytes, _ := json.Marshal(map[string]interface{}{"name": "TestUpdateApplicationRenamed"})
+	req, _ := http.NewRequest("PATCH", url, bytes.NewBuffer(bodyBytes))
+	req.AddCookie(testConf.generateCookie())
+	resp := httptest.NewRecorder()
+	testConf.router.ServeHTTP(resp, req)
+
+	// Assert
+	if resp.Code != http.StatusOK {
+		t.Errorf("Expected response code 200, got: %v", resp.Code)
+	}
+	got := models.Application{}
+	_ = got.GetByID(testConf.app.MongoDB, app.ID.Hex())
+	if got.Name != "TestUpdateApplicationRenamed" {
+		t.Errorf("Expected name TestUpdateApplicationRenamed, got: %v", got.Name)
+	}
+	_ = app.Delete(testConf.app.MongoDB)
+	_ = org.Delete(testConf.app.MongoDB)
+}
+
+func TestDeleteApplication(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	org := models.Organization{
+		Status:    models.OrgActive,
+		AccountID: testConf.authUser.ID,
+		Name:      "Test Organization for DeleteApplication",
+	}
+	oID, err := org.Create(testConf.app.MongoDB) // lint:ignore
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := models.NewApplication(oID, "TestDeleteApplication", testConf.authUser.ID.Hex()).Create(testConf.app.MongoDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	component := models.Component{Name: "test-component", Type: "service", ApplicationID: app.ID}
+	if err = component.Create(testConf.app.MongoDB); err != nil {
+		t.Fatal(err)
+	}
+	url := "/organizations/" + oID + "/a/" + app.ID.Hex()
+
+	// a dry run only lists what would be removed
+	req, _ := http.NewRequest("DELETE", url+"?dry_run=true", nil)
+	req.AddCookie(testConf.generateCookie())
+	resp := httptest.NewRecorder()
+	testConf.router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Errorf("Expected response code 200, got: %v", resp.Code)
+	}
+	plan := DeletionPlan{}
+	_ = json.Unmarshal(resp.Body.Bytes(), &plan)
+	if len(plan.Components) != 1 || plan.Components[0] != "test-component" {
+		t.Errorf("Expected components [test-component], got: %v", plan.Components)
+	}
+	if count, _ := app.CountComponents(testConf.app.MongoDB); count != 1 {
+		t.Errorf("Expected the component to be kept, got %v components", count)
+	}
+
+	req, _ = http.NewRequest("DELETE", url, nil)
+	req.AddCookie(testConf.generateCookie())
+	resp = httptest.NewRecorder()
+	testConf.router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNoContent {
+		t.Errorf("Expected response code 204, got: %v", resp.Code)
+	}
+	if count, _ := app.CountComponents(testConf.app.MongoDB); count != 0 {
+		t.Errorf("Expected the components to be deleted, got %v components", count)
+	}
+	_ = app.Delete(testConf.app.MongoDB)
+	_ = org.Delete(testConf.app.MongoDB)
+}
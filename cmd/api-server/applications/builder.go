@@ -2,10 +2,13 @@ package applications
 
 import (
 	"fmt"
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/database"
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
 	k8sUtils "github.com/coffeenights/conure/internal/k8s"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"strconv"
 	"strings"
 )
 
@@ -15,38 +18,200 @@ var serviceType = map[string]string{
 }
 
 func buildExposeTrait(component *models.Component) map[string]interface{} {
-	if component.Settings.NetworkSettings.Exposed == false {
+	network := component.Settings.NetworkSettings
+	if network.Exposed == false && len(network.Routes) == 0 {
 		return map[string]interface{}{}
 	}
 	trait := map[string]interface{}{
 		"type":       "expose",
 		"properties": map[string]interface{}{},
 	}
-	// Set the service type
-	exposeType := string(component.Settings.NetworkSettings.Type)
+	// Set the service type, the routes of a component not exposed only need a cluster service
+	exposeType := string(models.Private)
+	if network.Exposed {
+		exposeType = string(network.Type)
+	}
 	trait["properties"].(map[string]interface{})["type"] = serviceType[exposeType]
 
 	type Port map[string]interface{}
 	var ports []Port
+	exposed := map[int]bool{}
 	// Set the ports
-	for _, settingsPort := range component.Settings.NetworkSettings.Ports {
-		traitPort := Port{
-			"port":     settingsPort.HostPort,
-			"protocol": strings.ToUpper(string(settingsPort.Protocol)),
+	if network.Exposed {
+		for _, settingsPort := range network.Ports {
+			traitPort := Port{
+				"port":     settingsPort.HostPort,
+				"protocol": strings.ToUpper(string(settingsPort.Protocol)),
+			}
+			ports = append(ports, traitPort)
+			exposed[settingsPort.HostPort] = true
+		}
+	}
+	// the routes reach the component through its service
+	for _, route := range network.Routes {
+		if !exposed[route.TargetPort] {
+			ports = append(ports, Port{"port": route.TargetPort, "protocol": "TCP"})
+			exposed[route.TargetPort] = true
 		}
-		ports = append(ports, traitPort)
 	}
 	trait["properties"].(map[string]interface{})["ports"] = ports
 	return trait
 }
 
-func buildScalerTrait(component *models.Component) map[string]interface{} {
+const (
+	// RequestsMetric is the metric of the requests per second of a service, served by the custom metrics adapter
+	// of the cluster, the components scaling to zero are scaled on it
+	RequestsMetric        = "requests_per_second"
+	defaultTargetCPU      = 80
+	defaultTargetRequests = 10
+)
+
+func buildScalerTrait(application *models.Application, environment *models.Environment, component *models.Component) (map[string]interface{}, error) {
+	autoscaling := component.Settings.ResourcesSettings.Autoscaling
+	if autoscaling != nil {
+		return buildAutoscalerTrait(application, environment, component)
+	}
 	trait := map[string]interface{}{
 		"type":       "scaler",
 		"properties": map[string]interface{}{},
 	}
 	trait["properties"].(map[string]interface{})["replicas"] = component.Settings.ResourcesSettings.Replicas
-	return trait
+	return trait, nil
+}
+
+// buildAutoscalerTrait returns the trait of the HorizontalPodAutoscaler of the component, which owns its replicas
+// in place of the scaler
+func buildAutoscalerTrait(application *models.Application, environment *models.Environment, component *models.Component) (map[string]interface{}, error) {
+	autoscaling := component.Settings.ResourcesSettings.Autoscaling
+	if err := autoscaling.Validate(); err != nil {
+		return nil, err
+	}
+	properties := map[string]interface{}{
+		"min":    autoscaling.MinReplicas,
+		"max":    autoscaling.MaxReplicas,
+		"labels": conureLabels(application, environment, component),
+	}
+	if autoscaling.TargetCPU > 0 {
+		properties["cpu"] = autoscaling.TargetCPU
+	}
+	if autoscaling.TargetMemory > 0 {
+		properties["memory"] = autoscaling.TargetMemory
+	}
+	if autoscaling.ScaleToZero {
+		network := component.Settings.NetworkSettings
+		if !network.Exposed && len(network.Routes) == 0 {
+			return nil, fmt.Errorf("%w: %s has no service to count the requests of", conureerrors.ErrInvalidAutoscaling, component.Name)
+		}
+		target := autoscaling.TargetRequests
+		if target == 0 {
+			target = defaultTargetRequests
+		}
+		properties["min"] = 0
+		properties["requests"] = map[string]interface{}{"metric": RequestsMetric, "target": target}
+	} else if autoscaling.TargetCPU == 0 && autoscaling.TargetMemory == 0 {
+		properties["cpu"] = defaultTargetCPU
+	}
+	return map[string]interface{}{
+		"type":       providers.AutoscalerTraitType,
+		"properties": properties,
+	}, nil
+}
+
+// buildWorkloadTrait returns the trait patching the probes, the lifecycle and the rollout strategy into the workload
+// of the component, empty when it sets none of them
+func buildWorkloadTrait(component *models.Component) map[string]interface{} {
+	settings := component.Settings
+	properties := map[string]interface{}{}
+	for name, probe := range map[string]*models.ProbeSettings{
+		"livenessProbe":  settings.HealthSettings.Liveness,
+		"readinessProbe": settings.HealthSettings.Readiness,
+		"startupProbe":   settings.HealthSettings.Startup,
+	} {
+		if probe != nil {
+			properties[name] = buildProbe(probe)
+		}
+	}
+	if len(settings.LifecycleSettings.PreStopCommand) > 0 {
+		properties["preStop"] = settings.LifecycleSettings.PreStopCommand
+	}
+	if settings.LifecycleSettings.TerminationGracePeriodSeconds != nil {
+		properties["terminationGracePeriodSeconds"] = *settings.LifecycleSettings.TerminationGracePeriodSeconds
+	}
+	rollout := settings.RolloutSettings
+	if rollout.Strategy == models.Recreate {
+		properties["strategy"] = map[string]interface{}{"type": "Recreate"}
+	} else if rollout.MaxSurge != "" || rollout.MaxUnavailable != "" {
+		rollingUpdate := map[string]interface{}{}
+		if rollout.MaxSurge != "" {
+			rollingUpdate["maxSurge"] = intOrPercent(rollout.MaxSurge)
+		}
+		if rollout.MaxUnavailable != "" {
+			rollingUpdate["maxUnavailable"] = intOrPercent(rollout.MaxUnavailable)
+		}
+		properties["strategy"] = map[string]interface{}{"type": "RollingUpdate", "rollingUpdate": rollingUpdate}
+	}
+	if len(properties) == 0 {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"type":       providers.WorkloadTraitType,
+		"properties": properties,
+	}
+}
+
+// buildProbe returns the probe as the probe of a Kubernetes container
+func buildProbe(settings *models.ProbeSettings) map[string]interface{} {
+	probe := map[string]interface{}{}
+	switch settings.Type {
+	case models.HTTPProbe:
+		path := settings.Path
+		if path == "" {
+			path = "/"
+		}
+		probe["httpGet"] = map[string]interface{}{"path": path, "port": settings.Port}
+	case models.TCPProbe:
+		probe["tcpSocket"] = map[string]interface{}{"port": settings.Port}
+	case models.ExecProbe:
+		probe["exec"] = map[string]interface{}{"command": settings.Command}
+	}
+	for name, value := range map[string]int{
+		"initialDelaySeconds": settings.InitialDelaySeconds,
+		"periodSeconds":       settings.PeriodSeconds,
+		"timeoutSeconds":      settings.TimeoutSeconds,
+		"failureThreshold":    settings.FailureThreshold,
+	} {
+		if value > 0 {
+			probe[name] = value
+		}
+	}
+	return probe
+}
+
+// intOrPercent returns the bound of a rolling update as a number of pods, or the percentage as it is
+func intOrPercent(bound string) interface{} {
+	if pods, err := strconv.Atoi(bound); err == nil {
+		return pods
+	}
+	return bound
+}
+
+// buildLabelsTrait labels the workloads and their pods with the Conure ids, the API server caches only the objects
+// carrying them
+func buildLabelsTrait(application *models.Application, environment *models.Environment, component *models.Component) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "labels",
+		"properties": conureLabels(application, environment, component),
+	}
+}
+
+func conureLabels(application *models.Application, environment *models.Environment, component *models.Component) map[string]interface{} {
+	return map[string]interface{}{
+		k8sUtils.ApplicationIDLabel:  application.ID.Hex(),
+		k8sUtils.OrganizationIDLabel: application.OrganizationID.Hex(),
+		k8sUtils.EnvironmentLabel:    environment.Name,
+		k8sUtils.ComponentIDLabel:    component.ID.Hex(),
+		k8sUtils.ComponentNameLabel:  component.Name,
+	}
 }
 
 func buildComponentProperties(component *models.Component) map[string]interface{} {
@@ -61,6 +226,14 @@ func buildComponentProperties(component *models.Component) map[string]interface{
 	return properties
 }
 
+// addImagePullSecrets makes the workloads of the components pull their images with the registry credentials
+func addImagePullSecrets(manifest map[string]interface{}) {
+	components, _ := manifest["spec"].(map[string]interface{})["components"].([]map[string]interface{})
+	for _, component := range components {
+		component["properties"].(map[string]interface{})["imagePullSecrets"] = []string{k8sUtils.RegistryCredentialsSecret}
+	}
+}
+
 func buildStorageTrait(component *models.Component) map[string]interface{} {
 	trait := map[string]interface{}{
 		"type": "storage",
@@ -88,7 +261,7 @@ func buildStorageTrait(component *models.Component) map[string]interface{} {
 	return trait
 }
 
-func BuildApplicationManifest(application *models.Application, environment *models.Environment, db *database.MongoDB) (map[string]interface{}, error) {
+func BuildApplicationManifest(application *models.Application, environment *models.Environment, db *database.MongoDB, routing Routing) (map[string]interface{}, error) {
 	object := map[string]interface{}{
 		"apiVersion": "core.oam.dev/v1beta1",
 		"kind":       "Application",
@@ -115,6 +288,7 @@ func BuildApplicationManifest(application *models.Application, environment *mode
 		return nil, err
 	}
 	for _, component := range components {
+		component.Settings = component.SettingsFor(environment)
 		componentManifest := map[string]interface{}{
 			"name": component.Name,
 			"type": component.Type,
@@ -125,12 +299,30 @@ func BuildApplicationManifest(application *models.Application, environment *mode
 		if len(exposeTrait) > 0 {
 			traits = append(traits, exposeTrait)
 		}
-		scalerTrait := buildScalerTrait(&component)
+		scalerTrait, err := buildScalerTrait(application, environment, &component)
+		if err != nil {
+			return nil, err
+		}
 		traits = append(traits, scalerTrait)
 
 		storageTrait := buildStorageTrait(&component)
 		traits = append(traits, storageTrait)
 
+		traits = append(traits, buildLabelsTrait(application, environment, &component))
+
+		workloadTrait := buildWorkloadTrait(&component)
+		if len(workloadTrait) > 0 {
+			traits = append(traits, workloadTrait)
+		}
+
+		routeTrait, err := buildRouteTrait(application, environment, &component, routing)
+		if err != nil {
+			return nil, err
+		}
+		if len(routeTrait) > 0 {
+			traits = append(traits, routeTrait)
+		}
+
 		componentManifest["traits"] = traits
 
 		// Add properties
@@ -0,0 +1,100 @@
+package applications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+)
+
+func TestBuildScalerTrait(t *testing.T) {
+	application := &models.Application{ID: primitive.NewObjectID(), Name: "shop"}
+	environment := &models.Environment{Name: "staging"}
+	component := routedComponent()
+	component.Settings.ResourcesSettings.Replicas = 2
+
+	trait, err := buildScalerTrait(application, environment, component)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"type": "scaler", "properties": map[string]interface{}{"replicas": 2}}, trait)
+
+	component.Settings.ResourcesSettings.Autoscaling = &models.AutoscalingSettings{MinReplicas: 2, MaxReplicas: 6}
+	trait, err = buildScalerTrait(application, environment, component)
+	require.NoError(t, err)
+	assert.Equal(t, providers.AutoscalerTraitType, trait["type"])
+	properties := trait["properties"].(map[string]interface{})
+	assert.EqualValues(t, 2, properties["min"])
+	assert.EqualValues(t, 6, properties["max"])
+	assert.EqualValues(t, defaultTargetCPU, properties["cpu"])
+	assert.NotContains(t, properties, "requests")
+
+	component.Settings.ResourcesSettings.Autoscaling = &models.AutoscalingSettings{MinReplicas: 4, MaxReplicas: 2}
+	_, err = buildScalerTrait(application, environment, component)
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidAutoscaling)
+}
+
+func TestBuildScalerTrait_ScaleToZero(t *testing.T) {
+	application := &models.Application{ID: primitive.NewObjectID(), Name: "shop"}
+	environment := &models.Environment{Name: "staging"}
+	autoscaling := &models.AutoscalingSettings{MaxReplicas: 3, TargetMemory: 70, ScaleToZero: true}
+
+	component := routedComponent()
+	component.Settings.ResourcesSettings.Autoscaling = autoscaling
+	_, err := buildScalerTrait(application, environment, component)
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidAutoscaling)
+
+	component = routedComponent(models.RouteSettings{TargetPort: 8080})
+	component.Settings.ResourcesSettings.Autoscaling = autoscaling
+	trait, err := buildScalerTrait(application, environment, component)
+	require.NoError(t, err)
+	properties := trait["properties"].(map[string]interface{})
+	assert.EqualValues(t, 0, properties["min"])
+	assert.EqualValues(t, 70, properties["memory"])
+	assert.NotContains(t, properties, "cpu")
+	assert.Equal(t, map[string]interface{}{"metric": RequestsMetric, "target": defaultTargetRequests}, properties["requests"])
+}
+
+func TestBuildWorkloadTrait(t *testing.T) {
+	component := routedComponent()
+	assert.Empty(t, buildWorkloadTrait(component))
+
+	grace := 45
+	component.Settings.HealthSettings = models.HealthSettings{
+		Liveness:  &models.ProbeSettings{Type: models.HTTPProbe, Port: 8080, PeriodSeconds: 10},
+		Readiness: &models.ProbeSettings{Type: models.TCPProbe, Port: 8080},
+		Startup:   &models.ProbeSettings{Type: models.ExecProbe, Command: []string{"cat", "/tmp/ready"}, FailureThreshold: 30},
+	}
+	component.Settings.LifecycleSettings = models.LifecycleSettings{
+		PreStopCommand:                []string{"sleep", "5"},
+		TerminationGracePeriodSeconds: &grace,
+	}
+	component.Settings.RolloutSettings = models.RolloutSettings{MaxSurge: "1", MaxUnavailable: "25%"}
+	trait := buildWorkloadTrait(component)
+	assert.Equal(t, providers.WorkloadTraitType, trait["type"])
+	assert.Equal(t, map[string]interface{}{
+		"livenessProbe": map[string]interface{}{
+			"httpGet":       map[string]interface{}{"path": "/", "port": 8080},
+			"periodSeconds": 10,
+		},
+		"readinessProbe": map[string]interface{}{"tcpSocket": map[string]interface{}{"port": 8080}},
+		"startupProbe": map[string]interface{}{
+			"exec":             map[string]interface{}{"command": []string{"cat", "/tmp/ready"}},
+			"failureThreshold": 30,
+		},
+		"preStop":                       []string{"sleep", "5"},
+		"terminationGracePeriodSeconds": 45,
+		"strategy": map[string]interface{}{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]interface{}{"maxSurge": 1, "maxUnavailable": "25%"},
+		},
+	}, trait["properties"])
+
+	component = routedComponent()
+	component.Settings.RolloutSettings = models.RolloutSettings{Strategy: models.Recreate}
+	trait = buildWorkloadTrait(component)
+	assert.Equal(t, map[string]interface{}{"strategy": map[string]interface{}{"type": "Recreate"}}, trait["properties"])
+}
@@ -2,9 +2,12 @@ package applications
 
 import (
 	"errors"
+	"github.com/coffeenights/conure/cmd/api-server/audit"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/middlewares"
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
 	"github.com/coffeenights/conure/cmd/api-server/providers"
 	k8sUtils "github.com/coffeenights/conure/internal/k8s"
 	"github.com/gin-gonic/gin"
@@ -31,7 +34,7 @@ func getComponentFromRoute(c *gin.Context, db *database.MongoDB) (*models.Compon
 
 func (a *ApiHandler) ListComponents(c *gin.Context) {
 	application := &models.Application{}
-	err := application.GetByID(a.MongoDB, c.Param("applicationID"))
+	err := application.GetByID(a.db(c), c.Param("applicationID"))
 	if errors.Is(err, conureerrors.ErrObjectNotFound) {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -44,29 +47,35 @@ func (a *ApiHandler) ListComponents(c *gin.Context) {
 		conureerrors.AbortWithError(c, conureerrors.ErrNotAllowed)
 		return
 	}
-	components, err := application.ListComponents(a.MongoDB)
+	opts, err := pagination.ParseListOptions(c, models.ComponentListSpec)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	page, err := application.ComponentPage(a.db(c), opts)
 	if err != nil {
 		log.Printf("Error getting components: %v\n", err)
 		conureerrors.AbortWithError(c, err)
 		return
 	}
 	var response ComponentListResponse
-	response.Components = make([]ComponentResponse, len(components))
-	for i, _ := range components {
+	response.Components = make([]ComponentResponse, len(page.Items))
+	for i := range page.Items {
 		response.Components[i] = ComponentResponse{
-			Component: &components[i],
+			Component: &page.Items[i],
 		}
 	}
+	response.Metadata = pagination.SetNextCursor(c, opts, page.NextCursor)
 	c.JSON(http.StatusOK, response)
 }
 
 func (a *ApiHandler) DetailComponent(c *gin.Context) {
-	_, err := getHandlerFromRoute(c, a.MongoDB)
+	_, err := getHandlerFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
-	component, err := getComponentFromRoute(c, a.MongoDB)
+	component, err := getComponentFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -77,12 +86,12 @@ func (a *ApiHandler) DetailComponent(c *gin.Context) {
 }
 
 func (a *ApiHandler) statusLoad(c *gin.Context, component *models.Component) (ProviderStatus, error) {
-	handler, err := getHandlerFromRoute(c, a.MongoDB)
+	handler, err := getHandlerFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return nil, err
 	}
-	componentFound, err := getComponentFromRoute(c, a.MongoDB)
+	componentFound, err := getComponentFromRoute(c, a.db(c))
 	if err != nil {
 		log.Printf("Error getting components: %v\n", err)
 		return nil, err
@@ -90,12 +99,12 @@ func (a *ApiHandler) statusLoad(c *gin.Context, component *models.Component) (Pr
 	*component = *componentFound
 
 	// Get environment
-	env, err := handler.Model.GetEnvironmentByName(a.MongoDB, c.Param("environment"))
+	env, err := handler.Model.GetEnvironmentByName(a.db(c), c.Param("environment"))
 	if err != nil {
 		return nil, err
 	}
 
-	status, err := handler.Status(env)
+	status, err := handler.Status(a.Config, a.Cluster, env)
 	if errors.Is(err, k8sUtils.ErrApplicationNotFound) {
 		return nil, conureerrors.ErrApplicationNotDeployed
 	} else if err != nil {
@@ -172,7 +181,7 @@ func (a *ApiHandler) StatusComponent(c *gin.Context) {
 }
 
 func (a *ApiHandler) CreateComponent(c *gin.Context) {
-	handler, err := getHandlerFromRoute(c, a.MongoDB)
+	handler, err := getHandlerFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -184,6 +193,10 @@ func (a *ApiHandler) CreateComponent(c *gin.Context) {
 		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
 		return
 	}
+	if err = request.Settings.Validate(); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
 	component := models.Component{
 		Name:          request.Name,
 		Type:          request.Type,
@@ -191,7 +204,7 @@ func (a *ApiHandler) CreateComponent(c *gin.Context) {
 		ApplicationID: handler.Model.ID,
 		Settings:      request.Settings,
 	}
-	err = component.Create(a.MongoDB)
+	err = component.Create(a.db(c))
 	if errors.Is(err, conureerrors.ErrObjectAlreadyExists) {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -204,13 +217,13 @@ func (a *ApiHandler) CreateComponent(c *gin.Context) {
 }
 
 func (a *ApiHandler) UpdateComponent(c *gin.Context) {
-	handler, err := getHandlerFromRoute(c, a.MongoDB)
+	handler, err := getHandlerFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
 
-	component, err := getComponentFromRoute(c, a.MongoDB)
+	component, err := getComponentFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -223,6 +236,10 @@ func (a *ApiHandler) UpdateComponent(c *gin.Context) {
 		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
 		return
 	}
+	if err = request.Settings.Validate(); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
 
 	component.Name = request.Name
 	component.Type = request.Type
@@ -230,7 +247,7 @@ func (a *ApiHandler) UpdateComponent(c *gin.Context) {
 	component.ApplicationID = handler.Model.ID
 	component.Settings = request.Settings
 
-	err = component.Update(a.MongoDB)
+	err = component.Update(a.db(c))
 	if errors.Is(err, conureerrors.ErrObjectAlreadyExists) {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -243,25 +260,58 @@ func (a *ApiHandler) UpdateComponent(c *gin.Context) {
 
 }
 
+// DeleteComponent removes the component from every environment along with its variables. With dry_run=true only the
+// list of what would be removed is returned.
 func (a *ApiHandler) DeleteComponent(c *gin.Context) {
-	_, err := getHandlerFromRoute(c, a.MongoDB)
+	handler, err := getHandlerFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
 
-	component, err := getComponentFromRoute(c, a.MongoDB)
+	component, err := getComponentFromRoute(c, a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
+	if component.ApplicationID != handler.Model.ID {
+		conureerrors.AbortWithError(c, conureerrors.ErrComponentNotFound)
+		return
+	}
 
-	err = component.Delete(a.MongoDB)
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	plan, err := PlanComponentDeletion(a.db(c), handler.Model, component)
+	if err != nil {
+		log.Printf("Error planning component deletion: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, plan)
+		return
+	}
+
+	err = DeleteComponentCascade(a.db(c), a.Config, a.Cluster, handler.Model, component)
 	if err != nil {
 		log.Printf("Error deleting component: %v\n", err)
 		conureerrors.AbortWithError(c, err)
 		return
 	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditComponentDeleted,
+		OrganizationID: handler.Model.OrganizationID,
+		TargetType:     "component",
+		TargetID:       component.ID.Hex(),
+		Before: map[string]interface{}{
+			"name":      component.Name,
+			"variables": plan.Variables,
+			"workloads": plan.Workloads,
+		},
+	})
 	c.Status(http.StatusNoContent)
 }
 
@@ -310,12 +360,15 @@ func (a *ApiHandler) StreamLogs(c *gin.Context) {
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
+	shuttingDown := middlewares.ShuttingDown(c)
 	c.Stream(func(w io.Writer) bool {
 		// Stream message to client from message channel
 		select {
 		case msg := <-logStream.Stream:
 			c.SSEvent("message", msg)
 			return true
+		case <-shuttingDown:
+			return false
 		case err := <-logStream.Error:
 			conureerrors.AbortWithError(c, err)
 			c.SSEvent("error", err.Error())
@@ -13,6 +13,7 @@ import (
 )
 
 func TestListComponents(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -81,6 +82,7 @@ func TestListComponents(t *testing.T) {
 }
 
 func TestListComponents_NotExist(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 
 	// Create test organization
 	org := models.Organization{
@@ -107,6 +109,7 @@ func TestListComponents_NotExist(t *testing.T) {
 }
 
 func TestListComponents_Empty(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 
 	// Create test organization
 	org := models.Organization{
@@ -153,6 +156,7 @@ func TestListComponents_Empty(t *testing.T) {
 }
 
 func TestCreateComponent(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -213,6 +217,7 @@ func TestCreateComponent(t *testing.T) {
 }
 
 func TestDetailComponent(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -269,6 +274,7 @@ func TestDetailComponent(t *testing.T) {
 }
 
 func TestDetailComponent_NotFound(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -306,6 +312,7 @@ func TestDetailComponent_NotFound(t *testing.T) {
 }
 
 func TestUpdateComponent(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -359,6 +366,7 @@ func TestUpdateComponent(t *testing.T) {
 }
 
 func TestDeleteComponent(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -0,0 +1,131 @@
+package applications
+
+import (
+	"log"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+)
+
+// DeletionPlan lists everything removed by a cascading delete, it's the response of a dry run
+type DeletionPlan struct {
+	Application  string   `json:"application,omitempty"`
+	Components   []string `json:"components"`
+	Environments []string `json:"environments"`
+	Namespaces   []string `json:"namespaces"`
+	Variables    []string `json:"variables"`
+	// Workloads are the live components removed from the deployed environments, as namespace/component
+	Workloads []string `json:"workloads"`
+}
+
+func newDeletionPlan() *DeletionPlan {
+	return &DeletionPlan{
+		Components:   []string{},
+		Environments: []string{},
+		Namespaces:   []string{},
+		Variables:    []string{},
+		Workloads:    []string{},
+	}
+}
+
+func addVariables(plan *DeletionPlan, variables []models.Variable) {
+	for _, variable := range variables {
+		plan.Variables = append(plan.Variables, variable.Name)
+	}
+}
+
+// PlanApplicationDeletion lists the components, variables and environments of the application, the namespaces of the
+// environments are deleted with all their workloads
+func PlanApplicationDeletion(db *database.MongoDB, application *models.Application) (*DeletionPlan, error) {
+	plan := newDeletionPlan()
+	plan.Application = application.Name
+	components, err := application.ListComponents(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range components {
+		plan.Components = append(plan.Components, component.Name)
+	}
+	for _, environment := range application.Environments {
+		plan.Environments = append(plan.Environments, environment.Name)
+		plan.Namespaces = append(plan.Namespaces, environment.GetNamespace())
+	}
+	variable := models.Variable{}
+	variables, err := variable.ListByApplication(db, application.ID)
+	if err != nil {
+		return nil, err
+	}
+	addVariables(plan, variables)
+	return plan, nil
+}
+
+// PlanComponentDeletion lists the variables of the component and its workloads in every environment
+func PlanComponentDeletion(db *database.MongoDB, application *models.Application, component *models.Component) (*DeletionPlan, error) {
+	plan := newDeletionPlan()
+	plan.Components = append(plan.Components, component.Name)
+	for _, environment := range application.Environments {
+		plan.Workloads = append(plan.Workloads, environment.GetNamespace()+"/"+component.Name)
+	}
+	variable := models.Variable{}
+	variables, err := variable.ListByComponent(db, component.ID)
+	if err != nil {
+		return nil, err
+	}
+	addVariables(plan, variables)
+	return plan, nil
+}
+
+// DeleteApplicationCascade tears down the namespaces of every environment before removing the components, variables
+// and environments, the application is soft deleted last so a failed teardown can be retried. The documents are
+// removed in a single transaction.
+func DeleteApplicationCascade(db *database.MongoDB, config *apiConfig.Config, cluster *providers.Cluster, application *models.Application) error {
+	for i := range application.Environments {
+		provider, err := NewProviderDispatcher(config, cluster, application, &application.Environments[i])
+		if err != nil {
+			return err
+		}
+		if err = provider.DeleteEnvironment(db.Context()); err != nil {
+			return err
+		}
+	}
+	return models.RunInTransaction(db, func(tx *database.MongoDB) error {
+		if _, err := application.DeleteComponents(tx); err != nil {
+			return err
+		}
+		variable := models.Variable{}
+		deleted, err := variable.DeleteByApplication(tx, application.ID)
+		if err != nil {
+			return err
+		}
+		log.Printf("Deleted %v variables of application %s\n", deleted, application.ID.Hex())
+		if err = application.ClearEnvironments(tx); err != nil {
+			return err
+		}
+		return application.SoftDelete(tx)
+	})
+}
+
+// DeleteComponentCascade removes the component from every deployed environment before removing its variables and
+// the component itself
+func DeleteComponentCascade(db *database.MongoDB, config *apiConfig.Config, cluster *providers.Cluster, application *models.Application, component *models.Component) error {
+	for i := range application.Environments {
+		provider, err := NewProviderDispatcher(config, cluster, application, &application.Environments[i])
+		if err != nil {
+			return err
+		}
+		if err = provider.DeleteComponent(db.Context(), component.Name); err != nil {
+			return err
+		}
+	}
+	return models.RunInTransaction(db, func(tx *database.MongoDB) error {
+		variable := models.Variable{}
+		deleted, err := variable.DeleteByComponent(tx, component.ID)
+		if err != nil {
+			return err
+		}
+		log.Printf("Deleted %v variables of component %s\n", deleted, component.ID.Hex())
+		return component.Delete(tx)
+	})
+}
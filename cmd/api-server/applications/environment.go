@@ -6,7 +6,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/coffeenights/conure/cmd/api-server/audit"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
 	"github.com/coffeenights/conure/cmd/api-server/models"
 )
 
@@ -18,7 +20,7 @@ func (a *ApiHandler) CreateEnvironment(c *gin.Context) {
 		return
 	}
 
-	appHandler, err := NewApplicationHandler(a.MongoDB)
+	appHandler, err := NewApplicationHandler(a.db(c))
 	if err != nil {
 		log.Printf("Error creating application handler: %v\n", err)
 		conureerrors.AbortWithError(c, err)
@@ -33,16 +35,79 @@ func (a *ApiHandler) CreateEnvironment(c *gin.Context) {
 		return
 	}
 
-	if _, err = appHandler.Model.CreateEnvironment(a.MongoDB, request.Name); err != nil {
+	if err = ValidateDomainTemplate(request.DomainTemplate); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+
+	environment, err := appHandler.Model.CreateEnvironment(a.db(c), request.Name)
+	if err != nil {
 		log.Printf("Error creating environment: %v\n", err)
 		conureerrors.AbortWithError(c, err)
 		return
 	}
+	if request.DomainTemplate != "" {
+		if err = appHandler.Model.SetEnvironmentDomainTemplate(a.db(c), environment.ID, request.DomainTemplate); err != nil {
+			log.Printf("Error setting the domain template of the environment: %v\n", err)
+			conureerrors.AbortWithError(c, err)
+			return
+		}
+	}
 	c.JSON(http.StatusCreated, gin.H{})
 }
 
+// UpdateEnvironment sets the domain template of the environment, the routes use it from the next deploy
+func (a *ApiHandler) UpdateEnvironment(c *gin.Context) {
+	request := UpdateEnvironmentRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	appHandler, err := NewApplicationHandler(a.db(c))
+	if err != nil {
+		log.Printf("Error creating application handler: %v\n", err)
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	if err = appHandler.GetApplicationByID(c.Param("applicationID")); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	if appHandler.Model.AccountID != c.MustGet("currentUser").(models.User).ID {
+		conureerrors.AbortWithError(c, conureerrors.ErrNotAllowed)
+		return
+	}
+	environment, err := appHandler.Model.GetEnvironmentByName(a.db(c), c.Param("environment"))
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	before := environment.DomainTemplate
+	if request.DomainTemplate != nil {
+		if err = ValidateDomainTemplate(*request.DomainTemplate); err != nil {
+			conureerrors.AbortWithError(c, err)
+			return
+		}
+		if err = appHandler.Model.SetEnvironmentDomainTemplate(a.db(c), environment.ID, *request.DomainTemplate); err != nil {
+			log.Printf("Error updating environment: %v\n", err)
+			conureerrors.AbortWithError(c, err)
+			return
+		}
+		environment.DomainTemplate = *request.DomainTemplate
+	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditEnvironmentUpdated,
+		OrganizationID: appHandler.Model.OrganizationID,
+		TargetType:     "application",
+		TargetID:       appHandler.Model.ID.Hex(),
+		Before:         map[string]interface{}{"environment": environment.Name, "domain_template": before},
+		After:          map[string]interface{}{"environment": environment.Name, "domain_template": environment.DomainTemplate},
+	})
+	c.JSON(http.StatusOK, EnvironmentResponse{Environment: environment})
+}
+
 func (a *ApiHandler) DeleteEnvironment(c *gin.Context) {
-	appHandler, err := NewApplicationHandler(a.MongoDB)
+	appHandler, err := NewApplicationHandler(a.db(c))
 	if err != nil {
 		log.Printf("Error creating application handler: %v\n", err)
 		conureerrors.AbortWithError(c, err)
@@ -57,10 +122,38 @@ func (a *ApiHandler) DeleteEnvironment(c *gin.Context) {
 		return
 	}
 
-	if err = appHandler.Model.DeleteEnvironmentByName(a.MongoDB, c.Param("environment")); err != nil {
+	environment, err := appHandler.Model.GetEnvironmentByName(a.db(c), c.Param("environment"))
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	// the variables of the environment go with it
+	err = models.RunInTransaction(a.db(c), func(tx *database.MongoDB) error {
+		if err := appHandler.Model.DeleteEnvironmentByID(tx, environment.ID); err != nil {
+			return err
+		}
+		variable := models.Variable{}
+		deleted, err := variable.DeleteByEnvironment(tx, appHandler.Model.ID, environment.ID)
+		if err != nil {
+			return err
+		}
+		log.Printf("Deleted %v variables of environment %s\n", deleted, environment.ID)
+		return nil
+	})
+	if err != nil {
 		log.Printf("Error deleting environment: %v\n", err)
 		conureerrors.AbortWithError(c, err)
 		return
 	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditEnvironmentDeleted,
+		OrganizationID: appHandler.Model.OrganizationID,
+		TargetType:     "application",
+		TargetID:       appHandler.Model.ID.Hex(),
+		Before: map[string]interface{}{
+			"name":        appHandler.Model.Name,
+			"environment": c.Param("environment"),
+		},
+	})
 	c.JSON(http.StatusOK, gin.H{})
 }
@@ -14,6 +14,7 @@ import (
 )
 
 func TestCreateEnvironment(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	createRequest := &CreateEnvironmentRequest{
 		Name: "staging",
 	}
@@ -48,6 +49,7 @@ func TestCreateEnvironment(t *testing.T) {
 }
 
 func TestCreateEnvironment_NotExist(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	createRequest := &CreateEnvironmentRequest{
 		Name: "staging",
 	}
@@ -71,6 +73,7 @@ func TestCreateEnvironment_NotExist(t *testing.T) {
 }
 
 func TestDeleteEnvironment(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	orgID := primitive.NewObjectID().Hex()
 	app, err := models.NewApplication(orgID, "test-app", testConf.authUser.ID.Hex()).Create(testConf.app.MongoDB)
 	if err != nil {
@@ -1,18 +1,31 @@
 package applications
 
 import (
+	"github.com/gin-gonic/gin"
+
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+	"github.com/coffeenights/conure/cmd/api-server/variables"
 )
 
 type ApiHandler struct {
 	MongoDB *database.MongoDB
 	Config  *apiConfig.Config
+	Cluster *providers.Cluster
+	// KeyStorage decrypts the registry integrations synced into the environments on deploy
+	KeyStorage variables.SecretKeyStorage
 }
 
-func NewApiHandler(config *apiConfig.Config, mongo *database.MongoDB) *ApiHandler {
+func NewApiHandler(config *apiConfig.Config, mongo *database.MongoDB, cluster *providers.Cluster, keyStorage variables.SecretKeyStorage) *ApiHandler {
 	return &ApiHandler{
-		MongoDB: mongo,
-		Config:  config,
+		MongoDB:    mongo,
+		Config:     config,
+		Cluster:    cluster,
+		KeyStorage: keyStorage,
 	}
 }
+
+func (h *ApiHandler) db(c *gin.Context) *database.MongoDB {
+	return h.MongoDB.ForRequest(c.Request)
+}
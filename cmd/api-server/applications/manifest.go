@@ -0,0 +1,725 @@
+package applications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+var environmentNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Manifest is the declarative form of an application, it's usually kept as conure.yaml next to the code. Applying it
+// makes the stored application match it: whatever is missing from the manifest is deleted.
+type Manifest struct {
+	Application  ManifestApplication   `json:"application"`
+	Components   []ManifestComponent   `json:"components"`
+	Environments []ManifestEnvironment `json:"environments"`
+}
+
+type ManifestApplication struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+type ManifestComponent struct {
+	Name        string                   `json:"name" binding:"required"`
+	Type        string                   `json:"type" binding:"required"`
+	Description string                   `json:"description,omitempty"`
+	Settings    models.ComponentSettings `json:"settings"`
+}
+
+type ManifestEnvironment struct {
+	Name string `json:"name" binding:"required"`
+	// DomainTemplate names the hosts of the routes without one, e.g. {component}.{env}.example.com
+	DomainTemplate string             `json:"domain_template,omitempty"`
+	Variables      []ManifestVariable `json:"variables,omitempty"`
+	// Components holds the settings and variables of the components in this environment, by component name
+	Components map[string]ManifestEnvironmentComponent `json:"components,omitempty"`
+}
+
+type ManifestEnvironmentComponent struct {
+	// Settings are merged over the settings of the component, only the fields set are overridden and lists are
+	// replaced
+	Settings  json.RawMessage    `json:"settings,omitempty"`
+	Variables []ManifestVariable `json:"variables,omitempty"`
+}
+
+type ManifestVariable struct {
+	Name  string `json:"name" binding:"required"`
+	Value string `json:"value,omitempty"`
+	// Secret references an encrypted variable set with the variables API, the value is never part of the manifest
+	Secret bool `json:"secret,omitempty"`
+}
+
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// ManifestChange is a change needed to make the stored application match the manifest
+type ManifestChange struct {
+	Action      string   `json:"action"`
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Environment string   `json:"environment,omitempty"`
+	Component   string   `json:"component,omitempty"`
+	Fields      []string `json:"fields,omitempty"`
+}
+
+// ManifestDiff is the response of a diff, the changes are listed in the order they are applied
+type ManifestDiff struct {
+	Application string           `json:"application"`
+	Changes     []ManifestChange `json:"changes"`
+	// MissingSecrets are the secrets referenced by the manifest that are not set yet, as environment/name or
+	// environment/component/name
+	MissingSecrets []string `json:"missing_secrets"`
+}
+
+type ManifestApplyResponse struct {
+	Application *models.Application `json:"application"`
+	Diff        ManifestDiff        `json:"diff"`
+}
+
+// ManifestError lists the problems found validating a manifest
+type ManifestError struct {
+	Problems []string
+}
+
+func (e *ManifestError) Error() string {
+	return "invalid manifest: " + strings.Join(e.Problems, "; ")
+}
+
+func (e *ManifestError) add(format string, args ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// ManifestState is what is stored for the application of a manifest
+type ManifestState struct {
+	OrganizationID primitive.ObjectID
+	AccountID      primitive.ObjectID
+	// Application is nil when the manifest creates it
+	Application *models.Application
+	Components  []models.Component
+	// Variables are the environment and component variables of the application
+	Variables []models.Variable
+}
+
+// LoadManifestState reads the application with the name of the manifest and its components and variables
+func LoadManifestState(db *database.MongoDB, organization *models.Organization, accountID primitive.ObjectID, name string) (*ManifestState, error) {
+	state := &ManifestState{OrganizationID: organization.ID, AccountID: accountID}
+	application, err := models.GetApplicationByName(db, organization.ID, name)
+	if err != nil {
+		if errors.Is(err, conureerrors.ErrObjectNotFound) {
+			return state, nil
+		}
+		return nil, err
+	}
+	state.Application = application
+	if state.Components, err = application.ListComponents(db); err != nil {
+		return nil, err
+	}
+	variable := models.Variable{}
+	if state.Variables, err = variable.ListByApplication(db, application.ID); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// applyContext holds the IDs known while applying, the ones of the created objects are added as they are created
+type applyContext struct {
+	db           *database.MongoDB
+	application  *models.Application
+	components   map[string]primitive.ObjectID
+	environments map[string]string
+}
+
+type manifestOperation struct {
+	change ManifestChange
+	// run applies the change and returns how to revert it
+	run func(ctx *applyContext) (func() error, error)
+}
+
+// ManifestPlan is the list of operations making the stored application match the manifest
+type ManifestPlan struct {
+	Diff        ManifestDiff
+	application *models.Application
+	operations  []manifestOperation
+	components  map[string]primitive.ObjectID
+	// environments are the IDs of the environments of the manifest, the new ones are generated when planning
+	environments map[string]string
+}
+
+func (p *ManifestPlan) add(change ManifestChange, run func(ctx *applyContext) (func() error, error)) {
+	p.Diff.Changes = append(p.Diff.Changes, change)
+	p.operations = append(p.operations, manifestOperation{change: change, run: run})
+}
+
+// Validate checks the names and references of the manifest
+func (m *Manifest) Validate() error {
+	problems := &ManifestError{}
+	if m.Application.Name == "" {
+		problems.add("application.name is required")
+	}
+	components := map[string]bool{}
+	settings := map[string]models.ComponentSettings{}
+	for i, component := range m.Components {
+		if component.Name == "" || component.Type == "" {
+			problems.add("components[%d] needs a name and a type", i)
+		}
+		if err := component.Settings.Validate(); err != nil {
+			problems.add("settings of %s: %v", component.Name, err)
+		}
+		if components[component.Name] {
+			problems.add("component %q is declared twice", component.Name)
+		}
+		components[component.Name] = true
+		settings[component.Name] = component.Settings
+	}
+	environments := map[string]bool{}
+	for i, environment := range m.Environments {
+		if !environmentNamePattern.MatchString(environment.Name) {
+			problems.add("environments[%d].name %q must be lowercase letters, numbers and dashes", i, environment.Name)
+		}
+		if environments[environment.Name] {
+			problems.add("environment %q is declared twice", environment.Name)
+		}
+		environments[environment.Name] = true
+		if err := ValidateDomainTemplate(environment.DomainTemplate); err != nil {
+			problems.add("domain template of %s: %v", environment.Name, err)
+		}
+		validateVariables(problems, environment.Name, environment.Variables)
+		for _, name := range sortedComponentNames(environment.Components) {
+			component := environment.Components[name]
+			if !components[name] {
+				problems.add("environment %q configures the undeclared component %q", environment.Name, name)
+			}
+			if len(component.Settings) > 0 {
+				merged, err := mergeSettings(settings[name], component.Settings)
+				if err != nil {
+					problems.add("settings of %s/%s: %v", environment.Name, name, err)
+				} else if err = merged.Validate(); err != nil {
+					problems.add("settings of %s/%s: %v", environment.Name, name, err)
+				}
+			}
+			validateVariables(problems, environment.Name+"/"+name, component.Variables)
+		}
+	}
+	if len(problems.Problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+func validateVariables(problems *ManifestError, scope string, variables []ManifestVariable) {
+	names := map[string]bool{}
+	for _, variable := range variables {
+		v := models.Variable{Name: variable.Name}
+		if !v.ValidateName() {
+			problems.add("variable %q of %s is not a valid name", variable.Name, scope)
+		}
+		if names[variable.Name] {
+			problems.add("variable %q of %s is declared twice", variable.Name, scope)
+		}
+		names[variable.Name] = true
+		if variable.Secret && variable.Value != "" {
+			problems.add("secret %q of %s can't have a value in the manifest", variable.Name, scope)
+		}
+		if !variable.Secret && variable.Value == "" {
+			problems.add("variable %q of %s needs a value", variable.Name, scope)
+		}
+	}
+}
+
+// mergeSettings returns the settings with the override applied over them
+func mergeSettings(settings models.ComponentSettings, override json.RawMessage) (models.ComponentSettings, error) {
+	merged := models.ComponentSettings{}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return merged, err
+	}
+	if err = json.Unmarshal(data, &merged); err != nil {
+		return merged, err
+	}
+	if len(override) > 0 {
+		err = json.Unmarshal(override, &merged)
+	}
+	return merged, err
+}
+
+// PlanManifest compares the manifest with the stored state and returns the changes, it doesn't write anything
+func PlanManifest(manifest *Manifest, state *ManifestState) (*ManifestPlan, error) {
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	plan := &ManifestPlan{
+		Diff: ManifestDiff{
+			Application:    manifest.Application.Name,
+			Changes:        []ManifestChange{},
+			MissingSecrets: []string{},
+		},
+		components:   map[string]primitive.ObjectID{},
+		environments: map[string]string{},
+	}
+	plan.planApplication(manifest, state)
+	plan.planEnvironments(manifest, state)
+	componentDeletes, err := plan.planComponents(manifest, state)
+	if err != nil {
+		return nil, err
+	}
+	if err = plan.planVariables(manifest, state); err != nil {
+		return nil, err
+	}
+	for _, operation := range componentDeletes {
+		plan.add(operation.change, operation.run)
+	}
+	plan.planEnvironmentDeletes(manifest, state)
+	return plan, nil
+}
+
+func (p *ManifestPlan) planApplication(manifest *Manifest, state *ManifestState) {
+	if state.Application == nil {
+		application := models.NewApplication(state.OrganizationID.Hex(), manifest.Application.Name, state.AccountID.Hex())
+		application.Description = manifest.Application.Description
+		p.application = application
+		p.add(ManifestChange{Action: ChangeCreate, Kind: "application", Name: application.Name},
+			func(ctx *applyContext) (func() error, error) {
+				if _, err := ctx.application.Create(ctx.db); err != nil {
+					return nil, err
+				}
+				return func() error { return ctx.application.Delete(ctx.db) }, nil
+			})
+		return
+	}
+	p.application = state.Application
+	if state.Application.Description == manifest.Application.Description {
+		return
+	}
+	description := manifest.Application.Description
+	p.add(ManifestChange{Action: ChangeUpdate, Kind: "application", Name: state.Application.Name, Fields: []string{"description"}},
+		func(ctx *applyContext) (func() error, error) {
+			before := ctx.application.Description
+			if err := ctx.application.Rename(ctx.db, ctx.application.Name, description); err != nil {
+				return nil, err
+			}
+			return func() error { return ctx.application.Rename(ctx.db, ctx.application.Name, before) }, nil
+		})
+}
+
+// setEnvironments returns an operation replacing the environments, the previous ones are restored on rollback
+func setEnvironments(update func(environments []models.Environment) []models.Environment) func(ctx *applyContext) (func() error, error) {
+	return func(ctx *applyContext) (func() error, error) {
+		before := append([]models.Environment{}, ctx.application.Environments...)
+		if err := ctx.application.SetEnvironments(ctx.db, update(before)); err != nil {
+			return nil, err
+		}
+		return func() error { return ctx.application.SetEnvironments(ctx.db, before) }, nil
+	}
+}
+
+func (p *ManifestPlan) planEnvironments(manifest *Manifest, state *ManifestState) {
+	existing := map[string]models.Environment{}
+	if state.Application != nil {
+		for _, environment := range state.Application.Environments {
+			existing[environment.Name] = environment
+		}
+	}
+	for _, environment := range manifest.Environments {
+		if current, ok := existing[environment.Name]; ok {
+			p.environments[environment.Name] = current.ID
+			if current.DomainTemplate != environment.DomainTemplate {
+				id, template := current.ID, environment.DomainTemplate
+				p.add(ManifestChange{Action: ChangeUpdate, Kind: "environment", Name: current.Name, Fields: []string{"domain_template"}},
+					setEnvironments(func(environments []models.Environment) []models.Environment {
+						updated := append([]models.Environment{}, environments...)
+						for i := range updated {
+							if updated[i].ID == id {
+								updated[i].DomainTemplate = template
+							}
+						}
+						return updated
+					}))
+			}
+			continue
+		}
+		created := *models.NewEnvironment(environment.Name)
+		created.DomainTemplate = environment.DomainTemplate
+		p.environments[environment.Name] = created.ID
+		p.add(ManifestChange{Action: ChangeCreate, Kind: "environment", Name: created.Name},
+			setEnvironments(func(environments []models.Environment) []models.Environment {
+				return append(environments, created)
+			}))
+	}
+}
+
+func (p *ManifestPlan) planEnvironmentDeletes(manifest *Manifest, state *ManifestState) {
+	if state.Application == nil {
+		return
+	}
+	for _, environment := range state.Application.Environments {
+		if _, ok := p.environments[environment.Name]; ok {
+			continue
+		}
+		name := environment.Name
+		p.add(ManifestChange{Action: ChangeDelete, Kind: "environment", Name: name},
+			setEnvironments(func(environments []models.Environment) []models.Environment {
+				kept := []models.Environment{}
+				for _, environment := range environments {
+					if environment.Name != name {
+						kept = append(kept, environment)
+					}
+				}
+				return kept
+			}))
+	}
+}
+
+// desiredComponent returns the component of the manifest with the settings of every environment
+func desiredComponent(manifest *Manifest, declared ManifestComponent) (models.Component, error) {
+	component := models.Component{
+		Name:        declared.Name,
+		Type:        declared.Type,
+		Description: declared.Description,
+		Settings:    declared.Settings,
+	}
+	for _, environment := range manifest.Environments {
+		override, ok := environment.Components[declared.Name]
+		if !ok || len(override.Settings) == 0 {
+			continue
+		}
+		settings, err := mergeSettings(declared.Settings, override.Settings)
+		if err != nil {
+			return component, err
+		}
+		if component.EnvironmentSettings == nil {
+			component.EnvironmentSettings = map[string]models.ComponentSettings{}
+		}
+		component.EnvironmentSettings[environment.Name] = settings
+	}
+	return component, nil
+}
+
+func componentChanges(current *models.Component, desired *models.Component) []string {
+	var fields []string
+	if current.Type != desired.Type {
+		fields = append(fields, "type")
+	}
+	if current.Description != desired.Description {
+		fields = append(fields, "description")
+	}
+	if !reflect.DeepEqual(current.Settings, desired.Settings) {
+		fields = append(fields, "settings")
+	}
+	if (len(current.EnvironmentSettings) > 0 || len(desired.EnvironmentSettings) > 0) &&
+		!reflect.DeepEqual(current.EnvironmentSettings, desired.EnvironmentSettings) {
+		fields = append(fields, "environment_settings")
+	}
+	return fields
+}
+
+// planComponents adds the creates and updates of the components, the deletes are returned to run after the
+// variables of the deleted components are removed
+func (p *ManifestPlan) planComponents(manifest *Manifest, state *ManifestState) ([]manifestOperation, error) {
+	existing := map[string]*models.Component{}
+	for i := range state.Components {
+		existing[state.Components[i].Name] = &state.Components[i]
+		p.components[state.Components[i].Name] = state.Components[i].ID
+	}
+	for _, declared := range manifest.Components {
+		desired, err := desiredComponent(manifest, declared)
+		if err != nil {
+			return nil, err
+		}
+		current, ok := existing[declared.Name]
+		if !ok {
+			p.add(ManifestChange{Action: ChangeCreate, Kind: "component", Name: desired.Name},
+				func(ctx *applyContext) (func() error, error) {
+					component := desired
+					component.ApplicationID = ctx.application.ID
+					if err := component.Create(ctx.db); err != nil {
+						return nil, err
+					}
+					ctx.components[component.Name] = component.ID
+					return func() error { return component.Delete(ctx.db) }, nil
+				})
+			continue
+		}
+		fields := componentChanges(current, &desired)
+		if len(fields) == 0 {
+			continue
+		}
+		before := *current
+		p.add(ManifestChange{Action: ChangeUpdate, Kind: "component", Name: desired.Name, Fields: fields},
+			func(ctx *applyContext) (func() error, error) {
+				component := before
+				component.Type = desired.Type
+				component.Description = desired.Description
+				component.Settings = desired.Settings
+				component.EnvironmentSettings = desired.EnvironmentSettings
+				if err := component.Update(ctx.db); err != nil {
+					return nil, err
+				}
+				return func() error {
+					return models.RestoreDocument(context.Background(), ctx.db, models.ComponentCollection, before.ID, before)
+				}, nil
+			})
+	}
+
+	declared := map[string]bool{}
+	for _, component := range manifest.Components {
+		declared[component.Name] = true
+	}
+	var deletes []manifestOperation
+	for i := range state.Components {
+		component := state.Components[i]
+		if declared[component.Name] {
+			continue
+		}
+		deletes = append(deletes, manifestOperation{
+			change: ManifestChange{Action: ChangeDelete, Kind: "component", Name: component.Name},
+			run: func(ctx *applyContext) (func() error, error) {
+				if err := component.Delete(ctx.db); err != nil {
+					return nil, err
+				}
+				return func() error {
+					return models.RestoreDocument(context.Background(), ctx.db, models.ComponentCollection, component.ID, component)
+				}, nil
+			},
+		})
+	}
+	return deletes, nil
+}
+
+// variableScope identifies the environment, and the component for the component variables, of a variable
+type variableScope struct {
+	environment string
+	component   string
+}
+
+func (s variableScope) String() string {
+	if s.component == "" {
+		return s.environment
+	}
+	return s.environment + "/" + s.component
+}
+
+func (p *ManifestPlan) planVariables(manifest *Manifest, state *ManifestState) error {
+	environmentNames := map[string]string{}
+	if state.Application != nil {
+		for _, environment := range state.Application.Environments {
+			environmentNames[environment.ID] = environment.Name
+		}
+	}
+	componentNames := map[primitive.ObjectID]string{}
+	for _, component := range state.Components {
+		componentNames[component.ID] = component.Name
+	}
+	stored := map[variableScope]map[string]models.Variable{}
+	var orphans []models.Variable
+	for _, variable := range state.Variables {
+		if variable.EnvironmentID == nil {
+			continue
+		}
+		scope := variableScope{environment: environmentNames[*variable.EnvironmentID]}
+		if variable.Type == models.ComponentType && variable.ComponentID != nil {
+			scope.component = componentNames[*variable.ComponentID]
+			if scope.component == "" {
+				orphans = append(orphans, variable)
+				continue
+			}
+		}
+		if scope.environment == "" {
+			orphans = append(orphans, variable)
+			continue
+		}
+		if stored[scope] == nil {
+			stored[scope] = map[string]models.Variable{}
+		}
+		stored[scope][variable.Name] = variable
+	}
+
+	problems := &ManifestError{}
+	declaredScopes := map[variableScope]bool{}
+	for _, environment := range manifest.Environments {
+		scope := variableScope{environment: environment.Name}
+		declaredScopes[scope] = true
+		p.planScopeVariables(problems, scope, environment.Variables, stored[scope])
+		for _, name := range sortedComponentNames(environment.Components) {
+			scope := variableScope{environment: environment.Name, component: name}
+			declaredScopes[scope] = true
+			p.planScopeVariables(problems, scope, environment.Components[name].Variables, stored[scope])
+		}
+	}
+	if len(problems.Problems) > 0 {
+		return problems
+	}
+	var undeclared []variableScope
+	for scope := range stored {
+		if !declaredScopes[scope] {
+			undeclared = append(undeclared, scope)
+		}
+	}
+	sort.Slice(undeclared, func(i, j int) bool { return undeclared[i].String() < undeclared[j].String() })
+	for _, scope := range undeclared {
+		for _, name := range sortedVariableNames(stored[scope]) {
+			p.deleteVariable(scope, stored[scope][name])
+		}
+	}
+	for _, variable := range orphans {
+		p.deleteVariable(variableScope{}, variable)
+	}
+	return nil
+}
+
+func sortedComponentNames(components map[string]ManifestEnvironmentComponent) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *ManifestPlan) planScopeVariables(problems *ManifestError, scope variableScope, declared []ManifestVariable, stored map[string]models.Variable) {
+	seen := map[string]bool{}
+	for _, variable := range declared {
+		seen[variable.Name] = true
+		current, exists := stored[variable.Name]
+		switch {
+		case variable.Secret:
+			if !exists || !current.IsEncrypted {
+				p.Diff.MissingSecrets = append(p.Diff.MissingSecrets, scope.String()+"/"+variable.Name)
+			}
+		case exists && current.IsEncrypted:
+			problems.add("variable %q of %s is a secret, reference it with secret: true", variable.Name, scope)
+		case !exists:
+			p.createVariable(scope, variable)
+		case current.Value != variable.Value:
+			p.updateVariable(scope, current, variable.Value)
+		}
+	}
+	for _, name := range sortedVariableNames(stored) {
+		if !seen[name] {
+			p.deleteVariable(scope, stored[name])
+		}
+	}
+}
+
+func sortedVariableNames(variables map[string]models.Variable) []string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func variableChange(action string, scope variableScope, name string) ManifestChange {
+	return ManifestChange{Action: action, Kind: "variable", Name: name, Environment: scope.environment, Component: scope.component}
+}
+
+func (p *ManifestPlan) createVariable(scope variableScope, declared ManifestVariable) {
+	p.add(variableChange(ChangeCreate, scope, declared.Name), func(ctx *applyContext) (func() error, error) {
+		environmentID := ctx.environments[scope.environment]
+		applicationID := ctx.application.ID
+		variable := models.Variable{
+			Name:           declared.Name,
+			Value:          declared.Value,
+			Type:           models.EnvironmentType,
+			OrganizationID: ctx.application.OrganizationID,
+			ApplicationID:  &applicationID,
+			EnvironmentID:  &environmentID,
+		}
+		if scope.component != "" {
+			componentID := ctx.components[scope.component]
+			variable.Type = models.ComponentType
+			variable.ComponentID = &componentID
+		}
+		if _, err := variable.Create(ctx.db); err != nil {
+			return nil, err
+		}
+		return func() error { return variable.Delete(ctx.db) }, nil
+	})
+}
+
+func (p *ManifestPlan) updateVariable(scope variableScope, current models.Variable, value string) {
+	p.add(variableChange(ChangeUpdate, scope, current.Name), func(ctx *applyContext) (func() error, error) {
+		variable := current
+		variable.Value = value
+		if err := variable.Update(ctx.db); err != nil {
+			return nil, err
+		}
+		return func() error { return current.Update(ctx.db) }, nil
+	})
+}
+
+func (p *ManifestPlan) deleteVariable(scope variableScope, current models.Variable) {
+	p.add(variableChange(ChangeDelete, scope, current.Name), func(ctx *applyContext) (func() error, error) {
+		if err := current.Delete(ctx.db); err != nil {
+			return nil, err
+		}
+		return func() error {
+			return models.RestoreDocument(context.Background(), ctx.db, models.VariableCollection, current.ID, current)
+		}, nil
+	})
+}
+
+// Apply runs the operations in order in a transaction, when one fails the application is left as it was. The
+// workloads follow on the next deploy.
+func (p *ManifestPlan) Apply(db *database.MongoDB) (*models.Application, error) {
+	var application *models.Application
+	err := models.RunInTransaction(db, func(tx *database.MongoDB) error {
+		var err error
+		application, err = p.apply(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// apply runs the operations with db. Without a transaction, a standalone server, the previous operations are
+// reverted one by one when one fails.
+func (p *ManifestPlan) apply(db *database.MongoDB) (*models.Application, error) {
+	ctx := &applyContext{
+		db:           db,
+		application:  p.application,
+		components:   map[string]primitive.ObjectID{},
+		environments: p.environments,
+	}
+	for name, id := range p.components {
+		ctx.components[name] = id
+	}
+	var undos []func() error
+	for _, operation := range p.operations {
+		undo, err := operation.run(ctx)
+		if err != nil {
+			log.Printf("Error applying %s of %s %s: %v\n", operation.change.Action, operation.change.Kind, operation.change.Name, err)
+			if db.InTransaction() {
+				// aborting the transaction discards the previous operations
+				return nil, err
+			}
+			for i := len(undos) - 1; i >= 0; i-- {
+				if undoErr := undos[i](); undoErr != nil {
+					log.Printf("Error reverting the manifest of application %s: %v\n", p.Diff.Application, undoErr)
+				}
+			}
+			return nil, err
+		}
+		undos = append(undos, undo)
+	}
+	return ctx.application, nil
+}
@@ -0,0 +1,300 @@
+package applications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+func testManifest() *Manifest {
+	return &Manifest{
+		Application: ManifestApplication{Name: "shop", Description: "The shop"},
+		Components: []ManifestComponent{
+			{Name: "web", Type: "service", Settings: models.ComponentSettings{
+				ResourcesSettings: models.ResourcesSettings{Replicas: 1, CPU: 0.5, Memory: 256},
+			}},
+		},
+		Environments: []ManifestEnvironment{
+			{
+				Name:      "production",
+				Variables: []ManifestVariable{{Name: "LOG_LEVEL", Value: "info"}},
+				Components: map[string]ManifestEnvironmentComponent{
+					"web": {
+						Settings:  json.RawMessage(`{"resources_settings":{"replicas":3}}`),
+						Variables: []ManifestVariable{{Name: "API_KEY", Secret: true}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func changeKeys(diff ManifestDiff) []string {
+	keys := make([]string, len(diff.Changes))
+	for i, change := range diff.Changes {
+		keys[i] = change.Action + " " + change.Kind + " " + change.Name
+	}
+	return keys
+}
+
+func TestPlanManifest_NewApplication(t *testing.T) {
+	state := &ManifestState{OrganizationID: primitive.NewObjectID(), AccountID: primitive.NewObjectID()}
+	plan, err := PlanManifest(testManifest(), state)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"create application shop",
+		"create environment production",
+		"create component web",
+		"create variable LOG_LEVEL",
+	}, changeKeys(plan.Diff))
+	assert.Equal(t, []string{"production/web/API_KEY"}, plan.Diff.MissingSecrets)
+	assert.Equal(t, "The shop", plan.application.Description)
+}
+
+func TestPlanManifest_ExistingApplication(t *testing.T) {
+	organizationID := primitive.NewObjectID()
+	application := &models.Application{
+		ID:             primitive.NewObjectID(),
+		OrganizationID: organizationID,
+		Name:           "shop",
+		Description:    "The shop",
+		Environments:   []models.Environment{{ID: "prod1234", Name: "production"}, {ID: "stag1234", Name: "staging"}},
+	}
+	web := models.Component{Name: "web", Type: "service", ApplicationID: application.ID, Settings: models.ComponentSettings{
+		ResourcesSettings: models.ResourcesSettings{Replicas: 1, CPU: 0.5, Memory: 256},
+	}}
+	web.ID = primitive.NewObjectID()
+	worker := models.Component{Name: "worker", Type: "service", ApplicationID: application.ID}
+	worker.ID = primitive.NewObjectID()
+	production := "prod1234"
+	staging := "stag1234"
+	state := &ManifestState{
+		OrganizationID: organizationID,
+		Application:    application,
+		Components:     []models.Component{web, worker},
+		Variables: []models.Variable{
+			{ID: primitive.NewObjectID(), Name: "LOG_LEVEL", Value: "debug", Type: models.EnvironmentType, EnvironmentID: &production},
+			{ID: primitive.NewObjectID(), Name: "API_KEY", Value: "encrypted", IsEncrypted: true, Type: models.ComponentType,
+				EnvironmentID: &production, ComponentID: &web.ID},
+			{ID: primitive.NewObjectID(), Name: "QUEUE", Value: "jobs", Type: models.ComponentType,
+				EnvironmentID: &production, ComponentID: &worker.ID},
+			{ID: primitive.NewObjectID(), Name: "LOG_LEVEL", Value: "debug", Type: models.EnvironmentType, EnvironmentID: &staging},
+		},
+	}
+	plan, err := PlanManifest(testManifest(), state)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"update component web",
+		"update variable LOG_LEVEL",
+		"delete variable QUEUE",
+		"delete variable LOG_LEVEL",
+		"delete component worker",
+		"delete environment staging",
+	}, changeKeys(plan.Diff))
+	assert.Equal(t, []string{"environment_settings"}, plan.Diff.Changes[0].Fields)
+	assert.Equal(t, "production", plan.Diff.Changes[1].Environment)
+	assert.Empty(t, plan.Diff.MissingSecrets)
+}
+
+func TestPlanManifest_UpToDate(t *testing.T) {
+	manifest := testManifest()
+	manifest.Environments[0].Components = nil
+	application := &models.Application{
+		ID:           primitive.NewObjectID(),
+		Name:         "shop",
+		Description:  "The shop",
+		Environments: []models.Environment{{ID: "prod1234", Name: "production"}},
+	}
+	web := models.Component{Name: "web", Type: "service", Settings: manifest.Components[0].Settings}
+	production := "prod1234"
+	state := &ManifestState{
+		Application: application,
+		Components:  []models.Component{web},
+		Variables: []models.Variable{
+			{Name: "LOG_LEVEL", Value: "info", Type: models.EnvironmentType, EnvironmentID: &production},
+		},
+	}
+	plan, err := PlanManifest(manifest, state)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Diff.Changes)
+}
+
+func TestPlanManifest_DomainTemplate(t *testing.T) {
+	manifest := testManifest()
+	manifest.Environments[0].Components = nil
+	manifest.Environments[0].DomainTemplate = "{component}.{env}.example.com"
+	application := &models.Application{
+		ID:           primitive.NewObjectID(),
+		Name:         "shop",
+		Description:  "The shop",
+		Environments: []models.Environment{{ID: "prod1234", Name: "production"}},
+	}
+	production := "prod1234"
+	state := &ManifestState{
+		Application: application,
+		Components:  []models.Component{{Name: "web", Type: "service", Settings: manifest.Components[0].Settings}},
+		Variables: []models.Variable{
+			{Name: "LOG_LEVEL", Value: "info", Type: models.EnvironmentType, EnvironmentID: &production},
+		},
+	}
+	plan, err := PlanManifest(manifest, state)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"update environment production"}, changeKeys(plan.Diff))
+	assert.Equal(t, []string{"domain_template"}, plan.Diff.Changes[0].Fields)
+
+	manifest.Environments[0].DomainTemplate = "{component}_{env}.example.com"
+	_, err = PlanManifest(manifest, state)
+	var manifestErr *ManifestError
+	assert.True(t, errors.As(err, &manifestErr))
+}
+
+func TestPlanManifest_Invalid(t *testing.T) {
+	manifest := testManifest()
+	manifest.Components = append(manifest.Components, ManifestComponent{Name: "web", Type: "service"})
+	manifest.Environments = append(manifest.Environments, ManifestEnvironment{
+		Name:       "Staging",
+		Variables:  []ManifestVariable{{Name: "1INVALID", Value: "x"}, {Name: "TOKEN", Secret: true, Value: "plain"}},
+		Components: map[string]ManifestEnvironmentComponent{"api": {}},
+	})
+	_, err := PlanManifest(manifest, &ManifestState{})
+	var manifestErr *ManifestError
+	require.True(t, errors.As(err, &manifestErr))
+	assert.Len(t, manifestErr.Problems, 5)
+
+	// a plain value can't replace a secret
+	production := "prod1234"
+	state := &ManifestState{
+		Application: &models.Application{Name: "shop", Environments: []models.Environment{{ID: production, Name: "production"}}},
+		Variables: []models.Variable{
+			{Name: "LOG_LEVEL", Value: "encrypted", IsEncrypted: true, Type: models.EnvironmentType, EnvironmentID: &production},
+		},
+	}
+	_, err = PlanManifest(testManifest(), state)
+	require.True(t, errors.As(err, &manifestErr))
+	assert.Contains(t, manifestErr.Problems[0], "is a secret")
+}
+
+func TestPlanManifest_InvalidSettings(t *testing.T) {
+	manifest := testManifest()
+	manifest.Components[0].Settings.HealthSettings = models.HealthSettings{
+		Readiness: &models.ProbeSettings{Type: models.HTTPProbe, Path: "health"},
+		Startup:   &models.ProbeSettings{Type: models.ExecProbe, Command: []string{"true"}},
+	}
+	manifest.Environments[0].Components["web"] = ManifestEnvironmentComponent{
+		Settings: json.RawMessage(`{"rollout_settings":{"strategy":"recreate","max_surge":"1"}}`),
+	}
+	_, err := PlanManifest(manifest, &ManifestState{})
+	var manifestErr *ManifestError
+	require.True(t, errors.As(err, &manifestErr))
+	require.Len(t, manifestErr.Problems, 2)
+	assert.Contains(t, manifestErr.Problems[0], "the readiness probe needs a port")
+	assert.Contains(t, manifestErr.Problems[0], "the path of the readiness probe must start with /")
+	assert.Contains(t, manifestErr.Problems[1], "only apply to rolling updates")
+}
+
+func TestMergeSettings(t *testing.T) {
+	base := models.ComponentSettings{
+		ResourcesSettings: models.ResourcesSettings{Replicas: 1, CPU: 0.5, Memory: 256},
+		NetworkSettings:   models.NetworkSettings{Ports: []models.PortSettings{{HostPort: 80}, {HostPort: 443}}},
+	}
+	merged, err := mergeSettings(base, json.RawMessage(`{"resources_settings":{"replicas":3},"network_settings":{"ports":[{"host_port":8080}]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, 3, merged.ResourcesSettings.Replicas)
+	assert.Equal(t, 256, merged.ResourcesSettings.Memory)
+	assert.Equal(t, []models.PortSettings{{HostPort: 8080}}, merged.NetworkSettings.Ports)
+	// the base settings are not modified
+	assert.Len(t, base.NetworkSettings.Ports, 2)
+}
+
+func TestApplyManifest(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	org := models.Organization{
+		Status:    models.OrgActive,
+		AccountID: testConf.authUser.ID,
+		Name:      "Test Organization for ApplyManifest",
+	}
+	oID, err := org.Create(testConf.app.MongoDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(`
+application:
+  name: TestApplyManifest
+components:
+  - name: web
+    type: service
+    settings:
+      resources_settings:
+        replicas: 1
+environments:
+  - name: production
+    variables:
+      - name: LOG_LEVEL
+        value: info
+    components:
+      web:
+        settings:
+          resources_settings:
+            replicas: 3
+`)
+	// the diff doesn't write anything
+	req, _ := http.NewRequest("POST", "/organizations/"+oID+"/diff", bytes.NewBuffer(body))
+	req.AddCookie(testConf.generateCookie())
+	resp := httptest.NewRecorder()
+	testConf.router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected response code 200, got: %v", resp.Code)
+	}
+	diff := ManifestDiff{}
+	_ = json.Unmarshal(resp.Body.Bytes(), &diff)
+	if len(diff.Changes) != 4 {
+		t.Errorf("Expected 4 changes, got: %v", diff.Changes)
+	}
+	if _, err = models.GetApplicationByName(testConf.app.MongoDB, org.ID, "TestApplyManifest"); err == nil {
+		t.Errorf("Expected the diff not to create the application")
+	}
+
+	req, _ = http.NewRequest("POST", "/organizations/"+oID+"/apply", bytes.NewBuffer(body))
+	req.AddCookie(testConf.generateCookie())
+	resp = httptest.NewRecorder()
+	testConf.router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected response code 200, got: %v", resp.Code)
+	}
+	application, err := models.GetApplicationByName(testConf.app.MongoDB, org.ID, "TestApplyManifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	components, _ := application.ListComponents(testConf.app.MongoDB)
+	if len(components) != 1 || components[0].SettingsFor(&application.Environments[0]).ResourcesSettings.Replicas != 3 {
+		t.Errorf("Expected the web component with 3 replicas in production, got: %v", components)
+	}
+
+	// applying again changes nothing
+	req, _ = http.NewRequest("POST", "/organizations/"+oID+"/diff", bytes.NewBuffer(body))
+	req.AddCookie(testConf.generateCookie())
+	resp = httptest.NewRecorder()
+	testConf.router.ServeHTTP(resp, req)
+	diff = ManifestDiff{}
+	_ = json.Unmarshal(resp.Body.Bytes(), &diff)
+	if len(diff.Changes) != 0 {
+		t.Errorf("Expected no changes, got: %v", diff.Changes)
+	}
+
+	variable := models.Variable{}
+	_, _ = variable.DeleteByApplication(testConf.app.MongoDB, application.ID)
+	_, _ = application.DeleteComponents(testConf.app.MongoDB)
+	_ = application.Delete(testConf.app.MongoDB)
+	_ = org.Delete(testConf.app.MongoDB)
+}
@@ -1,18 +1,24 @@
 package applications
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/coffeenights/conure/cmd/api-server/audit"
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
 )
 
 func (a *ApiHandler) DetailOrganization(c *gin.Context) {
 	organizationID := c.Param("organizationID")
 	org := models.Organization{}
-	_, err := org.GetById(a.MongoDB, organizationID)
+	_, err := org.GetById(a.db(c), organizationID)
 	if err != nil {
 		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
 		return
@@ -36,7 +42,7 @@ func (a *ApiHandler) CreateOrganization(c *gin.Context) {
 	}
 	org := request.ParseRequestToModel()
 	org.AccountID = c.MustGet("currentUser").(models.User).ID
-	_, err = org.Create(a.MongoDB)
+	_, err = org.Create(a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
 		return
@@ -49,21 +55,225 @@ func (a *ApiHandler) CreateOrganization(c *gin.Context) {
 
 func (a *ApiHandler) ListOrganization(c *gin.Context) {
 	uID := c.MustGet("currentUser").(models.User).ID
-	orgs, err := models.OrganizationList(a.MongoDB, uID.Hex())
+	opts, err := pagination.ParseListOptions(c, models.OrganizationListSpec)
 	if err != nil {
-		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	page, err := models.OrganizationPage(a.db(c), uID, opts)
+	if err != nil {
+		log.Printf("Error getting organizations list: %v\n", err)
+		conureerrors.AbortWithError(c, err)
 		return
 	}
 
-	orgResponses := make([]OrganizationResponse, len(orgs))
-	for i, org := range orgs {
+	orgResponses := make([]OrganizationResponse, len(page.Items))
+	for i := range page.Items {
 		r := OrganizationResponse{
-			Organization: org,
+			Organization: &page.Items[i],
 		}
 		orgResponses[i] = r
 	}
 	response := OrganizationListResponse{
 		Organizations: orgResponses,
+		Metadata:      pagination.SetNextCursor(c, opts, page.NextCursor),
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (a *ApiHandler) UpdateOrganizationSecurity(c *gin.Context) {
+	organizationID := c.Param("organizationID")
+	org := models.Organization{}
+	_, err := org.GetById(a.db(c), organizationID)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	user := c.MustGet("currentUser").(models.User)
+	if org.AccountID != user.ID {
+		conureerrors.AbortWithError(c, conureerrors.ErrNotAllowed)
+		return
+	}
+	request := OrganizationSecurityRequest{}
+	err = c.ShouldBindJSON(&request)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	// the owner must have 2FA enabled before requiring it, otherwise they would lock themselves out
+	if *request.RequireTwoFactor && !user.TwoFactorEnabled {
+		conureerrors.AbortWithError(c, conureerrors.ErrTwoFactorRequired)
+		return
+	}
+	err = org.SetRequireTwoFactor(a.db(c), *request.RequireTwoFactor)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	response := OrganizationResponse{
+		Organization: &org,
 	}
 	c.JSON(http.StatusOK, response)
 }
+
+// getOwnedOrganization returns the organization of the route if the current user owns it
+func (a *ApiHandler) getOwnedOrganization(c *gin.Context) (*models.Organization, error) {
+	org := &models.Organization{}
+	_, err := org.GetById(a.db(c), c.Param("organizationID"))
+	if err != nil {
+		return nil, conureerrors.ErrObjectNotFound
+	}
+	if org.AccountID != c.MustGet("currentUser").(models.User).ID {
+		return nil, conureerrors.ErrNotAllowed
+	}
+	return org, nil
+}
+
+func (a *ApiHandler) UpdateOrganization(c *gin.Context) {
+	org, err := a.getOwnedOrganization(c)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	request := UpdateOrganizationRequest{}
+	if err = c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	before := org.Name
+	if err = org.Rename(a.db(c), request.Name); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditOrganizationRenamed,
+		OrganizationID: org.ID,
+		TargetType:     "organization",
+		TargetID:       org.ID.Hex(),
+		Before:         map[string]interface{}{"name": before},
+		After:          map[string]interface{}{"name": org.Name},
+	})
+	c.JSON(http.StatusOK, OrganizationResponse{Organization: org})
+}
+
+func (a *ApiHandler) TransferOrganization(c *gin.Context) {
+	org, err := a.getOwnedOrganization(c)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	request := TransferOrganizationRequest{}
+	if err = c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	newOwner := models.User{}
+	if err = newOwner.GetByEmail(a.db(c), request.Email); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	before := org.AccountID
+	if err = org.TransferOwnership(a.db(c), newOwner.ID); err != nil {
+		log.Printf("Error transferring organization: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditOrganizationTransferred,
+		OrganizationID: org.ID,
+		TargetType:     "organization",
+		TargetID:       org.ID.Hex(),
+		Before:         map[string]interface{}{"account_id": before.Hex()},
+		After:          map[string]interface{}{"account_id": newOwner.ID.Hex(), "email": newOwner.Email},
+	})
+	c.JSON(http.StatusOK, OrganizationResponse{Organization: org})
+}
+
+func (a *ApiHandler) setOrganizationStatus(c *gin.Context, status models.OrganizationStatus, action string) {
+	org, err := a.getOwnedOrganization(c)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	before := org.Status
+	if err = org.SetStatus(a.db(c), status); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         action,
+		OrganizationID: org.ID,
+		TargetType:     "organization",
+		TargetID:       org.ID.Hex(),
+		Before:         map[string]interface{}{"status": before},
+		After:          map[string]interface{}{"status": org.Status},
+	})
+	c.JSON(http.StatusOK, OrganizationResponse{Organization: org})
+}
+
+// DisableOrganization blocks the deploys of the organization, everything can still be read
+func (a *ApiHandler) DisableOrganization(c *gin.Context) {
+	a.setOrganizationStatus(c, models.OrgDisabled, models.AuditOrganizationDisabled)
+}
+
+func (a *ApiHandler) EnableOrganization(c *gin.Context) {
+	a.setOrganizationStatus(c, models.OrgActive, models.AuditOrganizationEnabled)
+}
+
+// DeleteOrganization soft deletes the organization and its applications after tearing down their environments. The
+// variables and integrations are purged once the grace period is over, see PurgeDeletedOrganizations.
+func (a *ApiHandler) DeleteOrganization(c *gin.Context) {
+	org, err := a.getOwnedOrganization(c)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	deletedApplications, err := DeleteOrganizationCascade(a.db(c), a.Config, a.Cluster, org)
+	if err != nil {
+		log.Printf("Error deleting organization: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditOrganizationDeleted,
+		OrganizationID: org.ID,
+		TargetType:     "organization",
+		TargetID:       org.ID.Hex(),
+		Before:         map[string]interface{}{"name": org.Name, "applications": deletedApplications},
+	})
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// DeleteOrganizationCascade tears down the environments of every application, then soft deletes the applications
+// and the organization in a single transaction. Nothing is deleted until the teardowns succeed, so a failed one can
+// be retried.
+func DeleteOrganizationCascade(mongo *database.MongoDB, config *apiConfig.Config, cluster *providers.Cluster, org *models.Organization) (int, error) {
+	applications, err := models.ApplicationList(mongo, org.ID.Hex())
+	if err != nil {
+		return 0, err
+	}
+	for _, application := range applications {
+		for i := range application.Environments {
+			provider, err := NewProviderDispatcher(config, cluster, application, &application.Environments[i])
+			if err != nil {
+				return 0, err
+			}
+			if err = provider.DeleteEnvironment(mongo.Context()); err != nil {
+				return 0, err
+			}
+		}
+	}
+	err = models.RunInTransaction(mongo, func(tx *database.MongoDB) error {
+		for _, application := range applications {
+			if err := application.SoftDelete(tx); err != nil {
+				return err
+			}
+		}
+		return org.SoftDelete(tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	org.Status = models.OrgDeleted
+	return len(applications), nil
+}
@@ -12,6 +12,7 @@ import (
 )
 
 func TestDetailOrganization(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -35,6 +36,7 @@ func TestDetailOrganization(t *testing.T) {
 }
 
 func TestCreateOrganization(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	createRequest := &CreateOrganizationRequest{
 		Name: "POST Test Organization",
 	}
@@ -68,6 +70,7 @@ func TestCreateOrganization(t *testing.T) {
 }
 
 func TestListOrganization(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	// Create test organization
 	org := models.Organization{
 		Status:    models.OrgActive,
@@ -6,7 +6,6 @@ import (
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/models"
 	"github.com/coffeenights/conure/cmd/api-server/providers"
-	"github.com/coffeenights/conure/internal/config"
 )
 
 type ProviderType string
@@ -26,13 +25,15 @@ type ProviderStatus interface {
 	StreamLogs(c context.Context, podName string, logStream *providers.LogStream, linesBuffer int)
 }
 
-func NewProviderStatus(application *models.Application, environment *models.Environment) (ProviderStatus, error) {
-	appConfig := config.LoadConfig(apiConfig.Config{})
-	providerType := ProviderType(appConfig.ProviderSource)
+func NewProviderStatus(config *apiConfig.Config, cluster *providers.Cluster, application *models.Application, environment *models.Environment) (ProviderStatus, error) {
+	if cluster == nil {
+		return nil, conureerrors.ErrClusterUnavailable
+	}
+	providerType := ProviderType(config.ProviderSource)
 
 	switch providerType {
 	case Vela:
-		provider, err := providers.NewProviderStatusVela(application.OrganizationID.Hex(), application.ID.Hex(), environment.GetNamespace())
+		provider, err := providers.NewProviderStatusVela(cluster, application.OrganizationID.Hex(), application.ID.Hex(), environment.GetNamespace())
 		if err != nil {
 			return nil, err
 		}
@@ -41,18 +42,30 @@ func NewProviderStatus(application *models.Application, environment *models.Envi
 	return nil, conureerrors.ErrProviderNotSupported
 }
 
+// ProviderDispatcher changes what is deployed, the calls are traced under ctx and the deploys pass its trace on to
+// the controllers
 type ProviderDispatcher interface {
-	DeployApplication(manifest map[string]interface{}) error
-	UpdateApplication(manifest map[string]interface{}) error
+	DeployApplication(ctx context.Context, manifest map[string]interface{}) error
+	UpdateApplication(ctx context.Context, manifest map[string]interface{}) error
+	// DeleteEnvironment tears down everything deployed in the environment
+	DeleteEnvironment(ctx context.Context) error
+	// DeleteComponent removes the component from the deployed application, along with its workloads
+	DeleteComponent(ctx context.Context, componentName string) error
+	// SyncRegistryCredentials writes the docker config of the registries of the organization in the environment,
+	// nil removes it
+	SyncRegistryCredentials(ctx context.Context, dockerConfig []byte) error
 }
 
-func NewProviderDispatcher(application *models.Application, environment *models.Environment) (ProviderDispatcher, error) {
-	appConfig := config.LoadConfig(apiConfig.Config{})
-	providerType := ProviderType(appConfig.ProviderSource)
+func NewProviderDispatcher(config *apiConfig.Config, cluster *providers.Cluster, application *models.Application, environment *models.Environment) (ProviderDispatcher, error) {
+	if cluster == nil {
+		return nil, conureerrors.ErrClusterUnavailable
+	}
+	providerType := ProviderType(config.ProviderSource)
 
 	switch providerType {
 	case Vela:
 		return &providers.ProviderDispatcherVela{
+			Cluster:         cluster,
 			OrganizationID:  application.OrganizationID.Hex(),
 			ApplicationID:   application.ID.Hex(),
 			ApplicationName: application.Name,
@@ -62,3 +75,21 @@ func NewProviderDispatcher(application *models.Application, environment *models.
 	}
 	return nil, conureerrors.ErrProviderNotSupported
 }
+
+type ProviderWatcher interface {
+	// Watch streams the live status of the application in the namespace until ctx is done
+	Watch(ctx context.Context, applicationID string, namespace string) (<-chan providers.StatusEvent, error)
+}
+
+func NewProviderWatcher(config *apiConfig.Config, cluster *providers.Cluster) (ProviderWatcher, error) {
+	if cluster == nil {
+		return nil, conureerrors.ErrClusterUnavailable
+	}
+	providerType := ProviderType(config.ProviderSource)
+
+	switch providerType {
+	case Vela:
+		return cluster.Watcher, nil
+	}
+	return nil, conureerrors.ErrProviderNotSupported
+}
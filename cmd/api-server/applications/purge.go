@@ -0,0 +1,59 @@
+package applications
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+const PurgeInterval = time.Hour
+
+// PurgeDeletedOrganizations removes the variables and integrations of the organizations deleted longer than the grace
+// period ago and returns the amount of purged organizations
+func PurgeDeletedOrganizations(mongo *database.MongoDB, grace time.Duration) (int, error) {
+	organizations, err := models.ListOrganizationsToPurge(mongo, time.Now().Add(-grace))
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for i := range organizations {
+		org := &organizations[i]
+		if err = org.Purge(mongo); err != nil {
+			log.Printf("Error purging organization %s: %v\n", org.ID.Hex(), err)
+			continue
+		}
+		event := models.AuditEvent{
+			OrganizationID: org.ID,
+			Action:         models.AuditOrganizationPurged,
+			TargetType:     "organization",
+			TargetID:       org.ID.Hex(),
+		}
+		if err = event.Create(mongo); err != nil {
+			log.Printf("Error creating audit event: %v\n", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// RunOrganizationPurger purges the deleted organizations periodically until the context is done
+func RunOrganizationPurger(ctx context.Context, mongo *database.MongoDB, grace time.Duration) {
+	ticker := time.NewTicker(PurgeInterval)
+	defer ticker.Stop()
+	for {
+		purged, err := PurgeDeletedOrganizations(mongo, grace)
+		if err != nil {
+			log.Printf("Error purging deleted organizations: %v\n", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted organizations\n", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -11,13 +11,25 @@ func GenerateRoutes(relativePath string, r *gin.Engine, appHandler *ApiHandler)
 		applications.GET("/", appHandler.ListOrganization)
 		applications.POST("/", appHandler.CreateOrganization)
 		applications.GET("/:organizationID", appHandler.DetailOrganization)
+		applications.PATCH("/:organizationID", appHandler.UpdateOrganization)
+		applications.DELETE("/:organizationID", appHandler.DeleteOrganization)
+		applications.POST("/:organizationID/transfer", appHandler.TransferOrganization)
+		applications.POST("/:organizationID/disable", appHandler.DisableOrganization)
+		applications.POST("/:organizationID/enable", appHandler.EnableOrganization)
+		applications.PUT("/:organizationID/security", appHandler.UpdateOrganizationSecurity)
+		applications.POST("/:organizationID/apply", appHandler.ApplyManifest)
+		applications.POST("/:organizationID/diff", appHandler.DiffManifest)
 		applications.GET("/:organizationID/a", appHandler.ListApplications)
 		applications.POST("/:organizationID/a", appHandler.CreateApplication)
+		applications.PATCH("/:organizationID/a/:applicationID", appHandler.UpdateApplication)
+		applications.DELETE("/:organizationID/a/:applicationID", appHandler.DeleteApplication)
 		applications.POST("/:organizationID/a/:applicationID/e", appHandler.CreateEnvironment)
+		applications.PATCH("/:organizationID/a/:applicationID/e/:environment", appHandler.UpdateEnvironment)
 		applications.DELETE("/:organizationID/a/:applicationID/e/:environment", appHandler.DeleteEnvironment)
 		applications.PUT("/:organizationID/a/:applicationID/e/:environment", appHandler.DeployApplication)
 		applications.GET("/:organizationID/a/:applicationID/e/:environment", appHandler.DetailApplication)
 		applications.GET("/:organizationID/a/:applicationID/e/:environment/status", appHandler.StatusApplication)
+		applications.GET("/:organizationID/a/:applicationID/e/:environment/status/events", appHandler.WatchStatus)
 		applications.GET("/:organizationID/a/:applicationID/e/:environment/c", appHandler.ListComponents)
 		applications.POST("/:organizationID/a/:applicationID/e/:environment/c", appHandler.CreateComponent)
 		applications.GET("/:organizationID/a/:applicationID/e/:environment/c/:componentID", appHandler.DetailComponent)
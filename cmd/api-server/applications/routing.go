@@ -0,0 +1,133 @@
+package applications
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+)
+
+const (
+	IngressRouting = "ingress"
+	GatewayRouting = "gateway"
+)
+
+// Routing is how the cluster serves the routes of the components
+type Routing struct {
+	Mode             string
+	IngressClass     string
+	GatewayName      string
+	GatewayNamespace string
+	Issuer           string
+}
+
+// NewRouting returns the routing of the configuration, the gateway mode needs the Gateway the routes attach to
+func NewRouting(config *apiConfig.Config) (Routing, error) {
+	routing := Routing{
+		Mode:             config.RoutingMode,
+		IngressClass:     config.IngressClass,
+		GatewayName:      config.GatewayName,
+		GatewayNamespace: config.GatewayNamespace,
+		Issuer:           config.CertManagerIssuer,
+	}
+	if routing.Mode == "" {
+		routing.Mode = IngressRouting
+	}
+	if routing.Mode == GatewayRouting && routing.GatewayName == "" {
+		return Routing{}, conureerrors.ErrRoutingNotConfigured
+	}
+	return routing, nil
+}
+
+// RouteHost returns the host of the route. A route without one takes the domain template of the environment, the
+// {app}, {env} and {component} placeholders are replaced by the names of the application, environment and component.
+func RouteHost(route models.RouteSettings, application *models.Application, environment *models.Environment, component *models.Component) (string, error) {
+	host := route.Host
+	if host == "" {
+		host = environment.DomainTemplate
+	}
+	if host == "" {
+		return "", fmt.Errorf("%w: the route of %s has no host and environment %s no domain template", conureerrors.ErrInvalidRoute, component.Name, environment.Name)
+	}
+	host = strings.ToLower(strings.NewReplacer(
+		"{app}", application.Name,
+		"{env}", environment.Name,
+		"{component}", component.Name,
+	).Replace(host))
+	if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(host, "*.")); len(errs) > 0 {
+		return "", fmt.Errorf("%w: invalid host %s: %s", conureerrors.ErrInvalidRoute, host, strings.Join(errs, ", "))
+	}
+	return host, nil
+}
+
+// ValidateDomainTemplate checks the template names valid hosts, an empty template is valid
+func ValidateDomainTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	sample := &models.Application{Name: "app"}
+	_, err := RouteHost(models.RouteSettings{Host: template}, sample, &models.Environment{Name: "env"}, &models.Component{Name: "component"})
+	return err
+}
+
+// buildRouteTrait returns the trait rendering the routes of the component as an Ingress or HTTPRoutes, empty when
+// it has none
+func buildRouteTrait(application *models.Application, environment *models.Environment, component *models.Component, routing Routing) (map[string]interface{}, error) {
+	settings := component.Settings.NetworkSettings.Routes
+	if len(settings) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	var routes []map[string]interface{}
+	tlsHosts := []string{}
+	for _, route := range settings {
+		host, err := RouteHost(route, application, environment, component)
+		if err != nil {
+			return nil, err
+		}
+		if route.TargetPort <= 0 || route.TargetPort > 65535 {
+			return nil, fmt.Errorf("%w: invalid target port %d of host %s", conureerrors.ErrInvalidRoute, route.TargetPort, host)
+		}
+		path := route.PathPrefix
+		if path == "" {
+			path = "/"
+		}
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("%w: the path prefix %s of host %s doesn't start with /", conureerrors.ErrInvalidRoute, path, host)
+		}
+		routes = append(routes, map[string]interface{}{
+			"host": host,
+			"path": path,
+			"port": route.TargetPort,
+			"tls":  route.TLS,
+		})
+		if route.TLS && !slices.Contains(tlsHosts, host) {
+			tlsHosts = append(tlsHosts, host)
+		}
+	}
+	properties := map[string]interface{}{
+		"mode":     routing.Mode,
+		"labels":   conureLabels(application, environment, component),
+		"routes":   routes,
+		"tlsHosts": tlsHosts,
+	}
+	if routing.Mode == GatewayRouting {
+		gateway := map[string]interface{}{"name": routing.GatewayName}
+		if routing.GatewayNamespace != "" {
+			gateway["namespace"] = routing.GatewayNamespace
+		}
+		properties["gateway"] = gateway
+	} else {
+		properties["class"] = routing.IngressClass
+		properties["issuer"] = routing.Issuer
+	}
+	return map[string]interface{}{
+		"type":       providers.RouteTraitType,
+		"properties": properties,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package applications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+)
+
+func routedComponent(routes ...models.RouteSettings) *models.Component {
+	component := &models.Component{Name: "web", Settings: models.ComponentSettings{
+		NetworkSettings: models.NetworkSettings{Routes: routes},
+	}}
+	component.ID = primitive.NewObjectID()
+	return component
+}
+
+func TestRouteHost(t *testing.T) {
+	application := &models.Application{Name: "Shop"}
+	environment := &models.Environment{Name: "staging", DomainTemplate: "{component}.{env}.{app}.example.com"}
+	component := routedComponent()
+
+	host, err := RouteHost(models.RouteSettings{}, application, environment, component)
+	require.NoError(t, err)
+	assert.Equal(t, "web.staging.shop.example.com", host)
+
+	host, err = RouteHost(models.RouteSettings{Host: "www.example.com"}, application, environment, component)
+	require.NoError(t, err)
+	assert.Equal(t, "www.example.com", host)
+
+	_, err = RouteHost(models.RouteSettings{}, application, &models.Environment{Name: "staging"}, component)
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidRoute)
+	_, err = RouteHost(models.RouteSettings{Host: "web_{env}.example.com"}, application, environment, component)
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidRoute)
+}
+
+func TestNewRouting(t *testing.T) {
+	routing, err := NewRouting(&apiConfig.Config{RoutingMode: "ingress", IngressClass: "nginx", CertManagerIssuer: "letsencrypt"})
+	require.NoError(t, err)
+	assert.Equal(t, Routing{Mode: IngressRouting, IngressClass: "nginx", Issuer: "letsencrypt"}, routing)
+
+	_, err = NewRouting(&apiConfig.Config{RoutingMode: "gateway"})
+	assert.ErrorIs(t, err, conureerrors.ErrRoutingNotConfigured)
+}
+
+func TestBuildRouteTrait_Ingress(t *testing.T) {
+	application := &models.Application{ID: primitive.NewObjectID(), Name: "shop"}
+	environment := &models.Environment{Name: "staging", DomainTemplate: "{component}.{env}.example.com"}
+	component := routedComponent(
+		models.RouteSettings{TargetPort: 8080, TLS: true},
+		models.RouteSettings{PathPrefix: "/api", TargetPort: 9090, TLS: true},
+	)
+	trait, err := buildRouteTrait(application, environment, component, Routing{Mode: IngressRouting, IngressClass: "nginx", Issuer: "letsencrypt"})
+	require.NoError(t, err)
+	assert.Equal(t, providers.RouteTraitType, trait["type"])
+	properties := trait["properties"].(map[string]interface{})
+	assert.Equal(t, "nginx", properties["class"])
+	assert.Equal(t, "letsencrypt", properties["issuer"])
+	assert.Equal(t, []string{"web.staging.example.com"}, properties["tlsHosts"])
+	assert.Equal(t, []map[string]interface{}{
+		{"host": "web.staging.example.com", "path": "/", "port": 8080, "tls": true},
+		{"host": "web.staging.example.com", "path": "/api", "port": 9090, "tls": true},
+	}, properties["routes"])
+
+	expose := buildExposeTrait(component)
+	assert.Equal(t, "ClusterIP", expose["properties"].(map[string]interface{})["type"])
+	assert.Len(t, expose["properties"].(map[string]interface{})["ports"], 2)
+}
+
+func TestBuildRouteTrait_Gateway(t *testing.T) {
+	application := &models.Application{ID: primitive.NewObjectID(), Name: "shop"}
+	component := routedComponent(models.RouteSettings{Host: "www.example.com", TargetPort: 8080})
+	routing := Routing{Mode: GatewayRouting, GatewayName: "public", GatewayNamespace: "gateways"}
+	trait, err := buildRouteTrait(application, &models.Environment{Name: "production"}, component, routing)
+	require.NoError(t, err)
+	properties := trait["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "public", "namespace": "gateways"}, properties["gateway"])
+	assert.NotContains(t, properties, "issuer")
+
+	component = routedComponent(models.RouteSettings{Host: "www.example.com", PathPrefix: "api", TargetPort: 8080})
+	_, err = buildRouteTrait(application, &models.Environment{Name: "production"}, component, routing)
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidRoute)
+}
@@ -15,7 +15,10 @@ import (
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+	"github.com/coffeenights/conure/cmd/api-server/variables"
 	"github.com/coffeenights/conure/internal/config"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
 )
 
 type testConfig struct {
@@ -48,12 +51,30 @@ func setupRouter() (*gin.Engine, *ApiHandler) {
 	if err != nil {
 		log.Panic(err)
 	}
-	app := NewApiHandler(appConfig, db)
+	app := NewApiHandler(appConfig, db, setupCluster(), variables.NewLocalSecretKey("secret.key"))
 	GenerateRoutes("/organizations", router, app)
 	return router, app
 }
 
+// setupCluster connects to the cluster of the kubeconfig, without one the deployments fail with ErrClusterUnavailable
+func setupCluster() *providers.Cluster {
+	clientset, err := k8sUtils.GetClientset()
+	if err != nil {
+		log.Printf("No cluster available: %v\n", err)
+		return nil
+	}
+	cluster := providers.NewCluster(clientset)
+	if err = cluster.Start(context.Background()); err != nil {
+		log.Panic(err)
+	}
+	return cluster
+}
+
 func TestMain(m *testing.M) {
+	// the tests needing the database skip themselves without one
+	if os.Getenv("API_MONGODB_URI") == "" {
+		os.Exit(m.Run())
+	}
 	setup()
 	code := m.Run()
 	teardown()
@@ -2,6 +2,7 @@ package applications
 
 import (
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
 	"github.com/coffeenights/conure/cmd/api-server/providers"
 	k8sV1 "k8s.io/api/apps/v1"
 	"time"
@@ -35,6 +36,12 @@ type ApplicationStatusResponse struct {
 type ApplicationListResponse struct {
 	Organization OrganizationResponse  `json:"organization"`
 	Applications []ApplicationResponse `json:"applications"`
+	pagination.Metadata
+}
+
+type UpdateApplicationRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
 }
 
 type CreateApplicationRequest struct {
@@ -74,6 +81,7 @@ type ComponentResponse struct {
 
 type ComponentListResponse struct {
 	Components []ComponentResponse `json:"components"`
+	pagination.Metadata
 }
 
 type ComponentProperties struct {
@@ -110,16 +118,35 @@ func (r *CreateOrganizationRequest) ParseRequestToModel() *models.Organization {
 	}
 }
 
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type TransferOrganizationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type OrganizationSecurityRequest struct {
+	RequireTwoFactor *bool `json:"require_two_factor" binding:"required"`
+}
+
 type OrganizationResponse struct {
 	*models.Organization
 }
 
 type OrganizationListResponse struct {
 	Organizations []OrganizationResponse `json:"organizations"`
+	pagination.Metadata
 }
 
 type CreateEnvironmentRequest struct {
 	Name string `json:"name" validate:"required,regexp=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"` // TODO: Validate this field with a regex, current implementation doesn't work
+	// DomainTemplate names the hosts of the routes without one, e.g. {component}.{env}.example.com
+	DomainTemplate string `json:"domain_template,omitempty"`
+}
+
+type UpdateEnvironmentRequest struct {
+	DomainTemplate *string `json:"domain_template"`
 }
 
 type EnvironmentListResponse struct {
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/middlewares"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+// Entry is what a handler knows about the action, the actor and the request details are taken from the context.
+// Before and After are summaries of the target and must never contain secret values.
+type Entry struct {
+	Action         string
+	OrganizationID primitive.ObjectID
+	TargetType     string
+	TargetID       string
+	Before         map[string]interface{}
+	After          map[string]interface{}
+	Metadata       map[string]interface{}
+}
+
+func newEvent(c *gin.Context, entry Entry) *models.AuditEvent {
+	event := &models.AuditEvent{
+		OrganizationID: entry.OrganizationID,
+		Action:         entry.Action,
+		TargetType:     entry.TargetType,
+		TargetID:       entry.TargetID,
+		Before:         entry.Before,
+		After:          entry.After,
+		Metadata:       entry.Metadata,
+		IP:             c.ClientIP(),
+		RequestID:      c.GetString(middlewares.RequestIDKey),
+		Method:         c.Request.Method,
+		Path:           c.FullPath(),
+	}
+	if user, ok := c.Get("currentUser"); ok {
+		if u, ok := user.(models.User); ok {
+			event.ActorID = u.ID
+			event.ActorEmail = u.Email
+		}
+	}
+	if event.OrganizationID.IsZero() {
+		event.OrganizationID, _ = primitive.ObjectIDFromHex(c.Param("organizationID"))
+	}
+	return event
+}
+
+// Record writes the audit event of an action. A failure to audit is logged but never fails the request.
+func Record(c *gin.Context, mongo *database.MongoDB, entry Entry) {
+	c.Set(models.AuditRecordedKey, true)
+	event := newEvent(c, entry)
+	if err := event.Create(mongo); err != nil {
+		log.Printf("Error creating audit event: %v\n", err)
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// Middleware records the successful mutating requests that were not audited explicitly by their handler. The
+// requests of anonymous users, like the logins and the webhooks, and those outside an organization are skipped, the
+// audit log is read per organization.
+func Middleware(mongo *database.MongoDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if !isMutating(c.Request.Method) || c.GetBool(models.AuditRecordedKey) {
+			return
+		}
+		status := c.Writer.Status()
+		if status >= 400 || c.FullPath() == "" {
+			return
+		}
+		if _, authenticated := c.Get("currentUser"); !authenticated {
+			return
+		}
+		event := newEvent(c, Entry{Action: models.AuditRequest})
+		if event.OrganizationID.IsZero() {
+			return
+		}
+		event.StatusCode = status
+		if err := event.Create(mongo.ForRequest(c.Request)); err != nil {
+			log.Printf("Error creating audit event: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/coffeenights/conure/cmd/api-server/auth"
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/middlewares"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+func cleanUpDB(mongo *database.MongoDB) {
+	_ = mongo.Client.Database(mongo.DBName).Drop(context.Background())
+}
+
+func TestAudit(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	gin.SetMode(gin.TestMode)
+	config := &apiConfig.Config{
+		JWTSecret:          "test-secret",
+		MongoDBURI:         "mongodb://localhost:27017",
+		MongoDBName:        "conure-test",
+		AuthStrategySystem: "local",
+	}
+	mongo, _ := database.ConnectToMongoDB(config.MongoDBURI, config.MongoDBName)
+	defer cleanUpDB(mongo)
+
+	user := models.User{Email: "test@test.com", Client: "test-client"}
+	_ = user.Create(mongo)
+	org := models.Organization{Name: "test", AccountID: user.ID}
+	_, _ = org.Create(mongo)
+	jwt, _ := auth.GenerateToken(time.Hour, auth.JWTData{Email: user.Email, Client: user.Client}, config.JWTSecret)
+
+	router := gin.New()
+	router.Use(middlewares.RequestID(), Middleware(mongo))
+	paths := router.Group("/organizations", middlewares.CheckAuthenticatedUser(config, mongo))
+	paths.POST("/:organizationID/generic", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+	paths.POST("/:organizationID/failed", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{})
+	})
+	paths.POST("/:organizationID/hook", func(c *gin.Context) {
+		Record(c, mongo, Entry{Action: models.AuditVariableCreated, TargetType: "variable", TargetID: "1"})
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+	GenerateRoutes("/organizations", router, NewAuditHandler(config, mongo))
+	router.POST("/auth/login", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	request := func(method string, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.AddCookie(&http.Cookie{Name: "auth", Value: jwt})
+		req.Header.Set(middlewares.RequestIDHeader, "test-request")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	base := "/organizations/" + org.ID.Hex()
+	request("POST", base+"/generic")
+	request("POST", base+"/failed")
+	request("POST", base+"/hook")
+	request("POST", "/auth/login")
+
+	resp := request("GET", base+"/audit")
+	require.Equal(t, http.StatusOK, resp.Code)
+	response := ListAuditEventsResponse{}
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
+	// failed requests and the reads are not recorded, the hook replaces the generic event
+	require.Equal(t, int64(2), response.Total)
+	assert.Equal(t, models.AuditVariableCreated, response.Events[0].Action)
+	assert.Equal(t, models.AuditRequest, response.Events[1].Action)
+	assert.Equal(t, "/organizations/:organizationID/generic", response.Events[1].Path)
+	for _, event := range response.Events {
+		assert.Equal(t, user.ID, event.ActorID)
+		assert.Equal(t, org.ID, event.OrganizationID)
+		assert.Equal(t, "test-request", event.RequestID)
+	}
+
+	// the anonymous requests are not recorded
+	count, err := mongo.Client.Database(mongo.DBName).Collection(models.AuditEventCollection).CountDocuments(context.Background(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	resp = request("GET", base+"/audit?action="+models.AuditVariableCreated+"&limit=1")
+	response = ListAuditEventsResponse{}
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Len(t, response.Events, 1)
+
+	resp = request("GET", base+"/audit?since=invalid")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// only the owner of the organization can read its audit log
+	other := models.Organization{Name: "other", AccountID: org.ID}
+	_, _ = other.Create(mongo)
+	resp = request("GET", "/organizations/"+other.ID.Hex()+"/audit")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
+)
+
+type Handler struct {
+	Config  *apiConfig.Config
+	MongoDB *database.MongoDB
+}
+
+func NewAuditHandler(config *apiConfig.Config, mongo *database.MongoDB) *Handler {
+	return &Handler{
+		Config:  config,
+		MongoDB: mongo,
+	}
+}
+
+func (h *Handler) db(c *gin.Context) *database.MongoDB {
+	return h.MongoDB.ForRequest(c.Request)
+}
+
+type ListAuditEventsResponse struct {
+	Events []models.AuditEvent `json:"events"`
+	Total  int64               `json:"total"`
+	pagination.Metadata
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListAuditEvents supports the filters action, actor_id, target_type, target_id, since and until (RFC 3339) and the
+// pagination parameters limit and cursor
+func (h *Handler) ListAuditEvents(c *gin.Context) {
+	org := models.Organization{}
+	_, err := org.GetById(h.db(c), c.Param("organizationID"))
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	if org.AccountID != c.MustGet("currentUser").(models.User).ID {
+		conureerrors.AbortWithError(c, conureerrors.ErrNotAllowed)
+		return
+	}
+
+	filter := models.AuditEventFilter{
+		OrganizationID: org.ID,
+		Action:         c.Query("action"),
+		TargetType:     c.Query("target_type"),
+		TargetID:       c.Query("target_id"),
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		filter.ActorID, err = primitive.ObjectIDFromHex(actorID)
+		if err != nil {
+			conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+			return
+		}
+	}
+	if filter.Since, err = parseTimeQuery(c, "since"); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	if filter.Until, err = parseTimeQuery(c, "until"); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	opts, err := pagination.ParseListOptions(c, models.AuditEventListSpec)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+
+	page, total, err := models.ListAuditEvents(h.db(c), filter, opts)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ListAuditEventsResponse{
+		Events:   page.Items,
+		Total:    total,
+		Metadata: pagination.SetNextCursor(c, opts, page.NextCursor),
+	})
+}
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coffeenights/conure/cmd/api-server/middlewares"
+)
+
+func GenerateRoutes(relativePath string, r *gin.Engine, handler *Handler) {
+	paths := r.Group(relativePath, middlewares.CheckAuthenticatedUser(handler.Config, handler.MongoDB))
+	{
+		paths.GET("/:organizationID/audit", handler.ListAuditEvents)
+	}
+}
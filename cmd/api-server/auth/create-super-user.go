@@ -19,6 +19,7 @@ func CreateSuperuser(mongo *database.MongoDB, email string) {
 		Email:    email,
 		Password: hashedPassword,
 		Client:   client,
+		IsAdmin:  true,
 	}
 	err = user.Create(mongo)
 	if err != nil {
@@ -53,6 +54,10 @@ func ResetSuperuserPassword(mongo *database.MongoDB, email string) {
 	if err != nil {
 		log.Panic(err)
 	}
+	err = user.SetAdmin(mongo)
+	if err != nil {
+		log.Panic(err)
+	}
 
 	log.Println("x-x-x-x-x-x-x-x-x-x-x-x-x-x-x-x-x-x")
 	log.Println("Superuser password reset")
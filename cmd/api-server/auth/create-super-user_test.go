@@ -9,6 +9,7 @@ import (
 )
 
 func TestCreateSuperuser(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	config := &apiConfig.Config{
 		JWTSecret:   "test-secret",
 		MongoDBURI:  "mongodb://localhost:27017",
@@ -44,6 +45,7 @@ func TestCreateSuperuser(t *testing.T) {
 }
 
 func TestResetSuperuserPassword(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	config := &apiConfig.Config{
 		JWTSecret:   "test-secret",
 		MongoDBURI:  "mongodb://localhost:27017",
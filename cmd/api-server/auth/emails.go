@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/coffeenights/conure/cmd/api-server/mail"
+)
+
+func frontendLink(frontendURL string, path string, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", strings.TrimSuffix(frontendURL, "/"), path, url.QueryEscape(token))
+}
+
+func verificationEmail(email string, link string) mail.Message {
+	return mail.Message{
+		To:      []string{email},
+		Subject: "Verify your Conure account",
+		Body: fmt.Sprintf("Welcome to Conure!\n\nPlease confirm your email address by opening the following link:\n\n%s\n\n"+
+			"The link expires in %d hours.\n", link, int(EmailVerificationTTL.Hours())),
+	}
+}
+
+func passwordResetEmail(email string, link string) mail.Message {
+	return mail.Message{
+		To:      []string{email},
+		Subject: "Reset your Conure password",
+		Body: fmt.Sprintf("A password reset was requested for your Conure account.\n\nOpen the following link to choose a new password:\n\n%s\n\n"+
+			"The link expires in %d minutes. If you did not request it, you can ignore this email.\n", link, int(PasswordResetTTL.Minutes())),
+	}
+}
+
+func invitationEmail(email string, invitedBy string, link string) mail.Message {
+	return mail.Message{
+		To:      []string{email},
+		Subject: "You have been invited to Conure",
+		Body: fmt.Sprintf("%s invited you to join Conure.\n\nCreate your account by opening the following link:\n\n%s\n\n"+
+			"The invitation expires in %d days.\n", invitedBy, link, int(InvitationTTL.Hours()/24)),
+	}
+}
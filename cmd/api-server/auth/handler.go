@@ -10,21 +10,28 @@ import (
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/mail"
 	"github.com/coffeenights/conure/cmd/api-server/models"
 )
 
 type Handler struct {
 	Config  *apiConfig.Config
 	MongoDB *database.MongoDB
+	Mailer  mail.Sender
 }
 
-func NewAuthHandler(config *apiConfig.Config, mongo *database.MongoDB) *Handler {
+func NewAuthHandler(config *apiConfig.Config, mongo *database.MongoDB, mailer mail.Sender) *Handler {
 	return &Handler{
 		Config:  config,
 		MongoDB: mongo,
+		Mailer:  mailer,
 	}
 }
 
+func (h *Handler) db(c *gin.Context) *database.MongoDB {
+	return h.MongoDB.ForRequest(c.Request)
+}
+
 func (h *Handler) Login(c *gin.Context) {
 	loginRequest := LoginRequest{}
 	err := c.ShouldBindJSON(&loginRequest)
@@ -33,9 +40,25 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	// locks apply to any email, existing or not, so they don't reveal which accounts exist
+	loginKeys := h.loginKeys(c, loginRequest.Email)
+	retryAfter, err := h.checkLoginLocked(c, loginKeys)
+	if err != nil {
+		log.Printf("Error checking login attempts: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	if retryAfter > 0 {
+		c.Header("Retry-After", retryAfterHeader(retryAfter))
+		conureerrors.AbortWithError(c, conureerrors.ErrTooManyLoginAttempts)
+		return
+	}
+
 	user := models.User{}
-	err = user.GetByEmail(h.MongoDB, loginRequest.Email)
+	err = user.GetByEmail(h.db(c), loginRequest.Email)
 	if err != nil {
+		compareDummyPassword(loginRequest.Password)
+		h.registerLoginFailure(c, loginKeys)
 		conureerrors.AbortWithError(c, conureerrors.ErrInvalidCredentials)
 		return
 	}
@@ -46,10 +69,37 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 	if !matched {
+		h.registerLoginFailure(c, loginKeys)
 		conureerrors.AbortWithError(c, conureerrors.ErrInvalidCredentials)
 		return
 	}
 
+	err = models.ResetLoginAttempts(h.db(c), models.AccountLoginKey(loginRequest.Email))
+	if err != nil {
+		log.Printf("Error resetting login attempts: %v\n", err)
+	}
+
+	if user.PendingVerification {
+		conureerrors.AbortWithError(c, conureerrors.ErrEmailNotVerified)
+		return
+	}
+
+	// with 2FA enabled the password only grants a short-lived challenge to be exchanged in LoginTwoFactor
+	if user.TwoFactorEnabled {
+		if h.Config.JWTSecret == "" {
+			conureerrors.AbortWithError(c, conureerrors.ErrJWTKeyError)
+			return
+		}
+		challenge := signToken(user.ID.Hex(), models.TwoFactorChallengePurpose, time.Now().Add(TwoFactorChallengeTTL), h.Config.JWTSecret)
+		c.JSON(http.StatusOK, TwoFactorChallengeResponse{TwoFactorRequired: true, Challenge: challenge})
+		return
+	}
+
+	h.startSession(c, &user)
+}
+
+// startSession issues the JWT of an authenticated user and sets it in the auth cookie
+func (h *Handler) startSession(c *gin.Context, user *models.User) {
 	payload := JWTData{
 		Email:  user.Email,
 		Client: user.Client,
@@ -61,7 +111,7 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	err = user.UpdateLastLoginAt(h.MongoDB)
+	err = user.UpdateLastLoginAt(h.db(c))
 	if err != nil {
 		log.Print(err)
 		log.Println("Failed to update last login at")
@@ -106,7 +156,7 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
 		return
 	}
-	err = user.UpdatePassword(h.MongoDB, hashedPassword)
+	err = user.UpdatePassword(h.db(c), hashedPassword)
 	if err != nil {
 		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
 		return
@@ -114,3 +164,34 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
 }
+
+func (h *Handler) UnlockAccount(c *gin.Context) {
+	request := EmailRequest{}
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+
+	key := models.AccountLoginKey(request.Email)
+	err = models.ResetLoginAttempts(h.db(c), key)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+
+	admin := c.MustGet("currentUser").(models.User)
+	event := models.AuditEvent{
+		ActorID:    admin.ID,
+		ActorEmail: admin.Email,
+		Action:     models.AuditAccountUnlocked,
+		TargetID:   key,
+		IP:         c.ClientIP(),
+		Method:     c.Request.Method,
+		Path:       c.FullPath(),
+	}
+	c.Set(models.AuditRecordedKey, true)
+	h.recordAccountEvent(c, event, request.Email)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
+}
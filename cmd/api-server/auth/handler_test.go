@@ -16,15 +16,17 @@ import (
 
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/mail"
 	"github.com/coffeenights/conure/cmd/api-server/models"
 )
 
 func setupTestHandler(router *gin.Engine, mongo *database.MongoDB, conf *apiConfig.Config) {
-	authHandler := NewAuthHandler(conf, mongo)
+	authHandler := NewAuthHandler(conf, mongo, mail.NewLogSender())
 	GenerateRoutes("/auth", router, authHandler)
 }
 
 func TestHandler_Login(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	config := &apiConfig.Config{
 		JWTSecret:   "test-secret",
@@ -111,6 +113,7 @@ func TestHandler_Login(t *testing.T) {
 }
 
 func TestHandler_Me(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	config := &apiConfig.Config{
@@ -193,6 +196,7 @@ func TestHandler_Me(t *testing.T) {
 }
 
 func TestHandler_ChangePassword(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	config := &apiConfig.Config{
@@ -30,7 +30,7 @@ func CheckCurrentUser(config *apiConfig.Config, mongo *database.MongoDB) gin.Han
 
 		// get the user data and add it to the context
 		user := models.User{}
-		err = user.GetByEmail(mongo, claims.Data.Email)
+		err = user.GetByEmail(mongo.ForRequest(c.Request), claims.Data.Email)
 		if err != nil {
 			conureerrors.AbortWithError(c, conureerrors.ErrUnauthorized)
 			return
@@ -39,3 +39,15 @@ func CheckCurrentUser(config *apiConfig.Config, mongo *database.MongoDB) gin.Han
 		c.Next()
 	}
 }
+
+// CheckAdminUser must be used after CheckCurrentUser
+func CheckAdminUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("currentUser").(models.User)
+		if !user.IsAdmin {
+			conureerrors.AbortWithError(c, conureerrors.ErrNotAllowed)
+			return
+		}
+		c.Next()
+	}
+}
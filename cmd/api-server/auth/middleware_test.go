@@ -23,6 +23,7 @@ func cleanUpDB(mongo *database.MongoDB) {
 }
 
 func TestCheckCurrentUserValidToken(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	config := &apiConfig.Config{
 		JWTSecret:   "test-secret",
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+func (h *Handler) Register(c *gin.Context) {
+	request := RegisterRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	if err := models.ValidateEmail(request.Email); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	if err := models.ValidatePasswords(request.Password, request.Password2); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+
+	// An invitation proves the ownership of the email, so invited users skip the verification
+	var invitation *models.UserToken
+	switch h.Config.RegistrationMode {
+	case RegistrationOpen:
+	case RegistrationInvite:
+		if request.Token == "" {
+			conureerrors.AbortWithError(c, conureerrors.ErrRegistrationDisabled)
+			return
+		}
+		var err error
+		invitation, err = PeekUserToken(h.db(c), request.Token, models.InvitationPurpose, h.Config.JWTSecret)
+		if err != nil {
+			conureerrors.AbortWithError(c, err)
+			return
+		}
+		if !strings.EqualFold(invitation.Email, request.Email) {
+			conureerrors.AbortWithError(c, conureerrors.ErrInvalidOrExpiredToken)
+			return
+		}
+	default:
+		conureerrors.AbortWithError(c, conureerrors.ErrRegistrationDisabled)
+		return
+	}
+
+	existing := models.User{}
+	if err := existing.GetByEmail(h.db(c), request.Email); err == nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrEmailAlreadyExists)
+		return
+	}
+
+	hashedPassword, err := GenerateFromPassword(request.Password)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	user := models.User{
+		Email:               request.Email,
+		Password:            hashedPassword,
+		Client:              "conure",
+		PendingVerification: invitation == nil,
+	}
+	// The invitation is only consumed with the creation of the user, a failed creation leaves it usable
+	err = models.RunInTransaction(h.db(c), func(tx *database.MongoDB) error {
+		if err := user.Create(tx); err != nil {
+			return err
+		}
+		if invitation != nil {
+			return invitation.Consume(tx)
+		}
+		return nil
+	})
+	if errors.Is(err, conureerrors.ErrEmailAlreadyExists) || errors.Is(err, conureerrors.ErrInvalidOrExpiredToken) {
+		conureerrors.AbortWithError(c, err)
+		return
+	} else if err != nil {
+		log.Printf("Error creating user: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	if user.PendingVerification {
+		h.sendVerificationEmail(c, &user)
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+func (h *Handler) sendVerificationEmail(c *gin.Context, user *models.User) {
+	userToken := models.NewUserToken(models.EmailVerificationPurpose, user.Email, EmailVerificationTTL)
+	userToken.UserID = user.ID
+	token, err := IssueUserToken(h.db(c), userToken, h.Config.JWTSecret)
+	if err != nil {
+		log.Printf("Error issuing verification token: %v\n", err)
+		return
+	}
+	message := verificationEmail(user.Email, frontendLink(h.Config.FrontendURL, "/verify-email", token))
+	if err = h.Mailer.Send(c.Request.Context(), message); err != nil {
+		log.Printf("Error sending verification email: %v\n", err)
+	}
+}
+
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	request := TokenRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	userToken, err := ConsumeUserToken(h.db(c), request.Token, models.EmailVerificationPurpose, h.Config.JWTSecret)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	user := models.User{}
+	if err = user.GetByEmail(h.db(c), userToken.Email); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidOrExpiredToken)
+		return
+	}
+	if err = user.MarkEmailVerified(h.db(c)); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerification always answers with the same response, so it cannot be used to find out which emails exist.
+func (h *Handler) ResendVerification(c *gin.Context) {
+	request := EmailRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	user := models.User{}
+	if err := user.GetByEmail(h.db(c), request.Email); err == nil && user.PendingVerification {
+		if err = models.RevokeUserTokens(h.db(c), user.Email, models.EmailVerificationPurpose); err != nil {
+			log.Printf("Error revoking verification tokens: %v\n", err)
+		}
+		h.sendVerificationEmail(c, &user)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists and is not verified, a new email has been sent"})
+}
+
+// ForgotPassword always answers with the same response, so it cannot be used to find out which emails exist.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	request := EmailRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	response := gin.H{"message": "If the account exists, an email with the instructions has been sent"}
+	user := models.User{}
+	if err := user.GetByEmail(h.db(c), request.Email); err != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	// Only the latest reset link is valid
+	if err := models.RevokeUserTokens(h.db(c), user.Email, models.PasswordResetPurpose); err != nil {
+		log.Printf("Error revoking password reset tokens: %v\n", err)
+	}
+	userToken := models.NewUserToken(models.PasswordResetPurpose, user.Email, PasswordResetTTL)
+	userToken.UserID = user.ID
+	token, err := IssueUserToken(h.db(c), userToken, h.Config.JWTSecret)
+	if err != nil {
+		log.Printf("Error issuing password reset token: %v\n", err)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	message := passwordResetEmail(user.Email, frontendLink(h.Config.FrontendURL, "/reset-password", token))
+	if err = h.Mailer.Send(c.Request.Context(), message); err != nil {
+		log.Printf("Error sending password reset email: %v\n", err)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) ResetPassword(c *gin.Context) {
+	request := ResetPasswordRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	if err := models.ValidatePasswords(request.Password, request.Password2); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	userToken, err := ConsumeUserToken(h.db(c), request.Token, models.PasswordResetPurpose, h.Config.JWTSecret)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	user := models.User{}
+	if err = user.GetByEmail(h.db(c), userToken.Email); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidOrExpiredToken)
+		return
+	}
+
+	hashedPassword, err := GenerateFromPassword(request.Password)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	if err = user.UpdatePassword(h.db(c), hashedPassword); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	// Receiving the reset email proves the ownership of the address
+	if user.PendingVerification {
+		if err = user.MarkEmailVerified(h.db(c)); err != nil {
+			log.Printf("Error marking email as verified: %v\n", err)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+func (h *Handler) CreateInvitation(c *gin.Context) {
+	if h.Config.RegistrationMode == RegistrationClosed {
+		conureerrors.AbortWithError(c, conureerrors.ErrRegistrationDisabled)
+		return
+	}
+	request := EmailRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	currentUser := c.MustGet("currentUser").(models.User)
+	existing := models.User{}
+	if err := existing.GetByEmail(h.db(c), request.Email); err == nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrEmailAlreadyExists)
+		return
+	}
+	userToken := models.NewUserToken(models.InvitationPurpose, request.Email, InvitationTTL)
+	userToken.CreatedBy = currentUser.ID
+	token, err := IssueUserToken(h.db(c), userToken, h.Config.JWTSecret)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	message := invitationEmail(request.Email, currentUser.Email, frontendLink(h.Config.FrontendURL, "/register", token))
+	if err = h.Mailer.Send(c.Request.Context(), message); err != nil {
+		log.Printf("Error sending invitation email: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	c.JSON(http.StatusCreated, userToken)
+}
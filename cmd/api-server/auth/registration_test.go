@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/mail"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+func postJSON(router *gin.Engine, path string, body interface{}, cookie *http.Cookie) *httptest.ResponseRecorder {
+	jsonData, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// tokenFromMessage extracts the token from the link contained in an email
+func tokenFromMessage(t *testing.T, message *mail.Message) string {
+	require.NotNil(t, message)
+	index := strings.Index(message.Body, "token=")
+	require.NotEqual(t, -1, index)
+	raw := strings.Fields(message.Body[index+len("token="):])[0]
+	token, err := url.QueryUnescape(raw)
+	require.NoError(t, err)
+	return token
+}
+
+func setupRegistrationTest(mode string) (*gin.Engine, *database.MongoDB, *mail.LogSender) {
+	gin.SetMode(gin.TestMode)
+	config := &apiConfig.Config{
+		JWTSecret:        "test-secret",
+		MongoDBURI:       "mongodb://localhost:27017",
+		MongoDBName:      "conure-test",
+		FrontendURL:      "http://localhost:5173",
+		RegistrationMode: mode,
+	}
+	router := gin.New()
+	mongo, _ := database.ConnectToMongoDB(config.MongoDBURI, config.MongoDBName)
+	mailer := mail.NewLogSender()
+	GenerateRoutes("/auth", router, NewAuthHandler(config, mongo, mailer))
+	return router, mongo, mailer
+}
+
+func TestHandler_RegisterAndVerify(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	router, mongo, mailer := setupRegistrationTest(RegistrationOpen)
+	defer cleanUpDB(mongo)
+
+	registerRequest := RegisterRequest{
+		Email:     "new@test.com",
+		Password:  "Password123+",
+		Password2: "Password123+",
+	}
+	resp := postJSON(router, "/auth/register", registerRequest, nil)
+	assert.Equal(t, http.StatusCreated, resp.Code, "(register) should return 201 Created")
+
+	// Same email again
+	resp = postJSON(router, "/auth/register", registerRequest, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.Code, "(duplicated email) should return 400 Bad Request")
+
+	// Same email in a different case
+	upperRequest := registerRequest
+	upperRequest.Email = "New@Test.com"
+	resp = postJSON(router, "/auth/register", upperRequest, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.Code, "(duplicated email in a different case) should return 400 Bad Request")
+
+	// Login is not allowed until the email is verified
+	loginRequest := LoginRequest{Email: registerRequest.Email, Password: registerRequest.Password}
+	resp = postJSON(router, "/auth/login", loginRequest, nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code, "(not verified) should return 403 Forbidden")
+
+	token := tokenFromMessage(t, mailer.Last())
+	resp = postJSON(router, "/auth/verify-email", TokenRequest{Token: token}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(verify) should return 200 OK")
+
+	// The token is single use
+	resp = postJSON(router, "/auth/verify-email", TokenRequest{Token: token}, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.Code, "(token reused) should return 400 Bad Request")
+
+	resp = postJSON(router, "/auth/login", loginRequest, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(verified) should return 200 OK")
+}
+
+func TestHandler_RegisterModes(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	registerRequest := RegisterRequest{
+		Email:     "invited@test.com",
+		Password:  "Password123+",
+		Password2: "Password123+",
+	}
+
+	router, mongo, _ := setupRegistrationTest(RegistrationClosed)
+	resp := postJSON(router, "/auth/register", registerRequest, nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code, "(closed) should return 403 Forbidden")
+	cleanUpDB(mongo)
+
+	router, mongo, mailer := setupRegistrationTest(RegistrationInvite)
+	defer cleanUpDB(mongo)
+	resp = postJSON(router, "/auth/register", registerRequest, nil)
+	assert.Equal(t, http.StatusForbidden, resp.Code, "(invite without token) should return 403 Forbidden")
+
+	inviter := models.User{Email: "inviter@test.com", Client: "test-client"}
+	_ = inviter.Create(mongo)
+	jwt, _ := GenerateToken(1*time.Hour, JWTData{Email: inviter.Email, Client: inviter.Client}, "test-secret")
+	resp = postJSON(router, "/auth/invitations", EmailRequest{Email: registerRequest.Email}, &http.Cookie{Name: "auth", Value: jwt})
+	assert.Equal(t, http.StatusForbidden, resp.Code, "(invitation by a user) should return 403 Forbidden")
+
+	_ = inviter.SetAdmin(mongo)
+	resp = postJSON(router, "/auth/invitations", EmailRequest{Email: registerRequest.Email}, &http.Cookie{Name: "auth", Value: jwt})
+	assert.Equal(t, http.StatusCreated, resp.Code, "(invitation) should return 201 Created")
+
+	// The invitation is bound to the invited email
+	registerRequest.Token = tokenFromMessage(t, mailer.Last())
+	otherRequest := registerRequest
+	otherRequest.Email = "other@test.com"
+	resp = postJSON(router, "/auth/register", otherRequest, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.Code, "(invitation for other email) should return 400 Bad Request")
+
+	// The email of the invitation is compared regardless of its case
+	invitedRequest := registerRequest
+	invitedRequest.Email = "Invited@Test.com"
+	resp = postJSON(router, "/auth/register", invitedRequest, nil)
+	assert.Equal(t, http.StatusCreated, resp.Code, "(invite) should return 201 Created")
+	registerRequest.Email = invitedRequest.Email
+
+	// Invited users don't need to verify their email
+	resp = postJSON(router, "/auth/login", LoginRequest{Email: registerRequest.Email, Password: registerRequest.Password}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(invited login) should return 200 OK")
+}
+
+func TestHandler_ForgotAndResetPassword(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	router, mongo, mailer := setupRegistrationTest(RegistrationClosed)
+	defer cleanUpDB(mongo)
+
+	hashed, _ := GenerateFromPassword("password123")
+	user := models.User{Email: "test@test.com", Client: "test-client", Password: hashed}
+	_ = user.Create(mongo)
+
+	// Unknown emails get the same response and no email is sent
+	resp := postJSON(router, "/auth/forgot-password", EmailRequest{Email: "unknown@test.com"}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(unknown email) should return 200 OK")
+	assert.Nil(t, mailer.Last(), "(unknown email) should not send an email")
+
+	resp = postJSON(router, "/auth/forgot-password", EmailRequest{Email: user.Email}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(forgot password) should return 200 OK")
+	firstToken := tokenFromMessage(t, mailer.Last())
+
+	// Requesting a new link revokes the previous one
+	resp = postJSON(router, "/auth/forgot-password", EmailRequest{Email: user.Email}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(forgot password) should return 200 OK")
+	token := tokenFromMessage(t, mailer.Last())
+
+	resetRequest := ResetPasswordRequest{Token: firstToken, Password: "NewPassword123+", Password2: "NewPassword123+"}
+	resp = postJSON(router, "/auth/reset-password", resetRequest, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.Code, "(revoked token) should return 400 Bad Request")
+
+	resetRequest.Token = token
+	resp = postJSON(router, "/auth/reset-password", resetRequest, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(reset password) should return 200 OK")
+
+	resp = postJSON(router, "/auth/reset-password", resetRequest, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.Code, "(token reused) should return 400 Bad Request")
+
+	resp = postJSON(router, "/auth/login", LoginRequest{Email: user.Email, Password: "NewPassword123+"}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(new password) should return 200 OK")
+}
@@ -8,6 +8,18 @@ func GenerateRoutes(relativePath string, r *gin.Engine, handler *Handler) {
 	paths := r.Group(relativePath)
 	{
 		paths.POST("/login", handler.Login)
+		paths.POST("/login/2fa", handler.LoginTwoFactor)
+		paths.POST("/register", handler.Register)
+		paths.POST("/verify-email", handler.VerifyEmail)
+		paths.POST("/resend-verification", handler.ResendVerification)
+		paths.POST("/forgot-password", handler.ForgotPassword)
+		paths.POST("/reset-password", handler.ResetPassword)
+		paths.POST("/invitations", CheckCurrentUser(handler.Config, handler.MongoDB), CheckAdminUser(), handler.CreateInvitation)
+		paths.POST("/unlock", CheckCurrentUser(handler.Config, handler.MongoDB), CheckAdminUser(), handler.UnlockAccount)
+		paths.POST("/2fa/enroll", CheckCurrentUser(handler.Config, handler.MongoDB), handler.EnrollTwoFactor)
+		paths.POST("/2fa/verify", CheckCurrentUser(handler.Config, handler.MongoDB), handler.VerifyTwoFactor)
+		paths.POST("/2fa/disable", CheckCurrentUser(handler.Config, handler.MongoDB), handler.DisableTwoFactor)
+		paths.POST("/2fa/recovery-codes", CheckCurrentUser(handler.Config, handler.MongoDB), handler.RegenerateRecoveryCodes)
 		paths.GET("/me", CheckCurrentUser(handler.Config, handler.MongoDB), handler.Me)
 		paths.PATCH("/change-password", CheckCurrentUser(handler.Config, handler.MongoDB), handler.ChangePassword)
 	}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+// DefaultMaxLockout caps the lockouts of the policies without MaxLockout
+const DefaultMaxLockout = 24 * time.Hour
+
+// LockoutPolicy defines after how many consecutive failures a key gets locked and for how long
+type LockoutPolicy struct {
+	MaxAttempts int
+	Lockout     time.Duration
+	MaxLockout  time.Duration
+}
+
+func NewAccountLockoutPolicy(config *apiConfig.Config) LockoutPolicy {
+	return LockoutPolicy{
+		MaxAttempts: config.LoginMaxAttempts,
+		Lockout:     config.LoginLockout,
+		MaxLockout:  config.LoginMaxLockout,
+	}
+}
+
+func NewIPLockoutPolicy(config *apiConfig.Config) LockoutPolicy {
+	policy := NewAccountLockoutPolicy(config)
+	policy.MaxAttempts = config.LoginMaxAttemptsPerIP
+	return policy
+}
+
+func (p LockoutPolicy) Enabled() bool {
+	return p.MaxAttempts > 0
+}
+
+// LockoutDuration returns how long the key must be locked after the given amount of failures, the duration doubles
+// with every failure past MaxAttempts and it is capped at MaxLockout, or DefaultMaxLockout without one, so it can't
+// overflow
+func (p LockoutPolicy) LockoutDuration(failures int) time.Duration {
+	if !p.Enabled() || failures < p.MaxAttempts {
+		return 0
+	}
+	ceiling := p.MaxLockout
+	if ceiling <= 0 {
+		ceiling = DefaultMaxLockout
+	}
+	duration := min(p.Lockout, ceiling)
+	for i := p.MaxAttempts; i < failures && duration < ceiling; i++ {
+		duration *= 2
+	}
+	return min(duration, ceiling)
+}
+
+// Expired reports whether the failures are old enough to be forgotten
+func (p LockoutPolicy) Expired(attempt *models.LoginAttempt, now time.Time) bool {
+	if attempt.Failures == 0 || p.MaxLockout <= 0 {
+		return false
+	}
+	return now.Sub(attempt.LastFailureAt) > p.MaxLockout && !attempt.IsLocked(now)
+}
+
+type loginKey struct {
+	key    string
+	policy LockoutPolicy
+	// email is only set on the keys of an account
+	email string
+}
+
+func (h *Handler) loginKeys(c *gin.Context, email string) []loginKey {
+	return []loginKey{
+		{key: models.AccountLoginKey(email), policy: NewAccountLockoutPolicy(h.Config), email: email},
+		{key: models.IPLoginKey(c.ClientIP()), policy: NewIPLockoutPolicy(h.Config)},
+	}
+}
+
+// recordAccountEvent records the event in every organization of the account, so it's listed in their audit logs.
+// The events of unknown emails, of accounts without organizations or of IP addresses have no organization.
+func (h *Handler) recordAccountEvent(c *gin.Context, event models.AuditEvent, email string) {
+	var organizations []models.Organization
+	user := models.User{}
+	if email != "" && user.GetByEmail(h.db(c), email) == nil {
+		var err error
+		organizations, err = models.ListAccountOrganizations(h.db(c), user.ID)
+		if err != nil {
+			log.Printf("Error listing the organizations of the account: %v\n", err)
+		}
+	}
+	if len(organizations) == 0 {
+		organizations = []models.Organization{{}}
+	}
+	for _, org := range organizations {
+		event.OrganizationID = org.ID
+		if err := event.Create(h.db(c)); err != nil {
+			log.Printf("Error creating audit event: %v\n", err)
+		}
+	}
+}
+
+// checkLoginLocked returns for how long the login is still locked, zero means it's not locked
+func (h *Handler) checkLoginLocked(c *gin.Context, keys []loginKey) (time.Duration, error) {
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, k := range keys {
+		if !k.policy.Enabled() {
+			continue
+		}
+		attempt, err := models.GetLoginAttempt(h.db(c), k.key)
+		if err != nil {
+			return 0, err
+		}
+		if k.policy.Expired(attempt, now) {
+			err = models.ResetLoginAttempts(h.db(c), k.key)
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if attempt.IsLocked(now) && attempt.LockedUntil.Sub(now) > retryAfter {
+			retryAfter = attempt.LockedUntil.Sub(now)
+		}
+	}
+	return retryAfter, nil
+}
+
+// registerLoginFailure counts the failure for every key and locks the ones which reached the limit
+func (h *Handler) registerLoginFailure(c *gin.Context, keys []loginKey) {
+	for _, k := range keys {
+		if !k.policy.Enabled() {
+			continue
+		}
+		attempt, err := models.RegisterLoginFailure(h.db(c), k.key)
+		if err != nil {
+			log.Printf("Error registering login failure: %v\n", err)
+			continue
+		}
+		duration := k.policy.LockoutDuration(attempt.Failures)
+		if duration == 0 {
+			continue
+		}
+		lockedUntil := time.Now().Add(duration)
+		err = attempt.Lock(h.db(c), lockedUntil)
+		if err != nil {
+			log.Printf("Error locking login: %v\n", err)
+			continue
+		}
+		event := models.AuditEvent{
+			Action:   models.AuditAccountLocked,
+			TargetID: k.key,
+			IP:       c.ClientIP(),
+			Metadata: map[string]interface{}{
+				"failures":    attempt.Failures,
+				"lockedUntil": lockedUntil,
+			},
+		}
+		h.recordAccountEvent(c, event, k.email)
+	}
+}
+
+func retryAfterHeader(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// compareDummyPassword spends the same time as a real password comparison, so the response time doesn't reveal
+// whether an email exists or not
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = GenerateFromPassword(GenerateRandomPassword(16))
+	})
+	_, _ = ComparePasswordAndHash(password, dummyHash)
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/mail"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+func TestLockoutPolicy_LockoutDuration(t *testing.T) {
+	policy := LockoutPolicy{MaxAttempts: 3, Lockout: 10 * time.Second, MaxLockout: time.Minute}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: 10 * time.Second},
+		{failures: 4, want: 20 * time.Second},
+		{failures: 5, want: 40 * time.Second},
+		{failures: 6, want: time.Minute},
+		{failures: 100, want: time.Minute},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.LockoutDuration(tt.failures), "failures: %d", tt.failures)
+	}
+
+	// without MaxLockout the doubling saturates instead of overflowing
+	unbounded := LockoutPolicy{MaxAttempts: 3, Lockout: 10 * time.Second}
+	assert.Equal(t, DefaultMaxLockout, unbounded.LockoutDuration(100))
+	assert.Equal(t, DefaultMaxLockout, unbounded.LockoutDuration(math.MaxInt))
+
+	disabled := LockoutPolicy{}
+	assert.False(t, disabled.Enabled())
+	assert.Equal(t, time.Duration(0), disabled.LockoutDuration(100))
+}
+
+func TestLockoutPolicy_Expired(t *testing.T) {
+	policy := LockoutPolicy{MaxAttempts: 3, Lockout: 10 * time.Second, MaxLockout: time.Minute}
+	now := time.Now()
+	lockedUntil := now.Add(time.Minute)
+
+	assert.False(t, policy.Expired(&models.LoginAttempt{}, now))
+	assert.False(t, policy.Expired(&models.LoginAttempt{Failures: 2, LastFailureAt: now.Add(-time.Second)}, now))
+	assert.True(t, policy.Expired(&models.LoginAttempt{Failures: 2, LastFailureAt: now.Add(-2 * time.Minute)}, now))
+	assert.False(t, policy.Expired(&models.LoginAttempt{Failures: 2, LastFailureAt: now.Add(-2 * time.Minute), LockedUntil: &lockedUntil}, now))
+}
+
+func TestHandler_LoginLockout(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	gin.SetMode(gin.TestMode)
+	config := &apiConfig.Config{
+		JWTSecret:             "test-secret",
+		MongoDBURI:            "mongodb://localhost:27017",
+		MongoDBName:           "conure-test",
+		LoginMaxAttempts:      2,
+		LoginMaxAttemptsPerIP: 100,
+		LoginLockout:          time.Minute,
+		LoginMaxLockout:       time.Hour,
+	}
+	router := gin.New()
+	mongo, _ := database.ConnectToMongoDB(config.MongoDBURI, config.MongoDBName)
+	defer cleanUpDB(mongo)
+	GenerateRoutes("/auth", router, NewAuthHandler(config, mongo, mail.NewLogSender()))
+
+	hashed, _ := GenerateFromPassword("password123")
+	user := models.User{Email: "test@test.com", Client: "test-client", Password: hashed}
+	_ = user.Create(mongo)
+	org := models.Organization{Name: "test", AccountID: user.ID, Status: models.OrgActive}
+	_, _ = org.Create(mongo)
+	admin := models.User{Email: "admin@test.com", Client: "test-client", IsAdmin: true}
+	_ = admin.Create(mongo)
+
+	for _, email := range []string{user.Email, "unknown@test.com"} {
+		wrongLogin := LoginRequest{Email: email, Password: "wrong-password"}
+		for i := 0; i < 2; i++ {
+			resp := postJSON(router, "/auth/login", wrongLogin, nil)
+			assert.Equal(t, http.StatusUnauthorized, resp.Code, "(wrong password) should return 401 Unauthorized")
+		}
+		// Existing and unknown emails are locked the same way
+		resp := postJSON(router, "/auth/login", wrongLogin, nil)
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code, "(locked) should return 429 Too Many Requests")
+		assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+	}
+
+	// The lockout of the account is in the audit log of its organization
+	events := mongo.Client.Database(mongo.DBName).Collection(models.AuditEventCollection)
+	count, _ := events.CountDocuments(context.Background(), bson.M{"organizationId": org.ID, "action": models.AuditAccountLocked})
+	assert.Equal(t, int64(1), count, "the lockout should be recorded in the organization")
+
+	// The right password is rejected while the account is locked
+	resp := postJSON(router, "/auth/login", LoginRequest{Email: user.Email, Password: "password123"}, nil)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code, "(locked) should return 429 Too Many Requests")
+
+	// Only admins can unlock accounts
+	userJWT, _ := GenerateToken(time.Hour, JWTData{Email: user.Email, Client: user.Client}, config.JWTSecret)
+	resp = postJSON(router, "/auth/unlock", EmailRequest{Email: user.Email}, &http.Cookie{Name: "auth", Value: userJWT})
+	assert.Equal(t, http.StatusForbidden, resp.Code, "(not admin) should return 403 Forbidden")
+
+	adminJWT, _ := GenerateToken(time.Hour, JWTData{Email: admin.Email, Client: admin.Client}, config.JWTSecret)
+	resp = postJSON(router, "/auth/unlock", EmailRequest{Email: user.Email}, &http.Cookie{Name: "auth", Value: adminJWT})
+	assert.Equal(t, http.StatusOK, resp.Code, "(unlock) should return 200 OK")
+
+	count, _ = events.CountDocuments(context.Background(), bson.M{"organizationId": org.ID, "action": models.AuditAccountUnlocked})
+	assert.Equal(t, int64(1), count, "the unlock should be recorded in the organization")
+
+	resp = postJSON(router, "/auth/login", LoginRequest{Email: user.Email, Password: "password123"}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(unlocked) should return 200 OK")
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+const (
+	EmailVerificationTTL  = 48 * time.Hour
+	PasswordResetTTL      = 1 * time.Hour
+	InvitationTTL         = 7 * 24 * time.Hour
+	TwoFactorChallengeTTL = 5 * time.Minute
+)
+
+// signToken builds the token sent to the user: <token id>.<expiration>.<signature>. The signature covers the purpose
+// so a token issued for one flow cannot be replayed in another.
+func signToken(tokenID string, purpose models.TokenPurpose, expiresAt time.Time, secret string) string {
+	expiration := strconv.FormatInt(expiresAt.Unix(), 10)
+	return fmt.Sprintf("%s.%s.%s", tokenID, expiration, tokenSignature(tokenID, purpose, expiration, secret))
+}
+
+func tokenSignature(tokenID string, purpose models.TokenPurpose, expiration string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(string(purpose) + "." + tokenID + "." + expiration))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseSignedToken checks the signature and the expiration of a token and returns the token id.
+func parseSignedToken(token string, purpose models.TokenPurpose, secret string) (string, error) {
+	if secret == "" {
+		return "", conureerrors.ErrJWTKeyError
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", conureerrors.ErrInvalidOrExpiredToken
+	}
+	expected := tokenSignature(parts[0], purpose, parts[1], secret)
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return "", conureerrors.ErrInvalidOrExpiredToken
+	}
+	expiration, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", conureerrors.ErrInvalidOrExpiredToken
+	}
+	if time.Now().After(time.Unix(expiration, 0)) {
+		return "", conureerrors.ErrInvalidOrExpiredToken
+	}
+	return parts[0], nil
+}
+
+// IssueUserToken stores a new single-use token and returns its signed representation.
+func IssueUserToken(mongo *database.MongoDB, userToken *models.UserToken, secret string) (string, error) {
+	if secret == "" {
+		return "", conureerrors.ErrJWTKeyError
+	}
+	if err := userToken.Create(mongo); err != nil {
+		return "", err
+	}
+	return signToken(userToken.ID.Hex(), userToken.Purpose, userToken.ExpiresAt, secret), nil
+}
+
+// ConsumeUserToken validates a signed token and marks it as used, a token can only be consumed once.
+func ConsumeUserToken(mongo *database.MongoDB, token string, purpose models.TokenPurpose, secret string) (*models.UserToken, error) {
+	tokenID, err := parseSignedToken(token, purpose, secret)
+	if err != nil {
+		return nil, err
+	}
+	userToken := &models.UserToken{}
+	if err = userToken.GetByID(mongo, tokenID); err != nil {
+		return nil, conureerrors.ErrInvalidOrExpiredToken
+	}
+	if !userToken.IsValid(purpose) {
+		return nil, conureerrors.ErrInvalidOrExpiredToken
+	}
+	if err = userToken.Consume(mongo); err != nil {
+		return nil, err
+	}
+	return userToken, nil
+}
+
+// PeekUserToken validates a signed token without consuming it.
+func PeekUserToken(mongo *database.MongoDB, token string, purpose models.TokenPurpose, secret string) (*models.UserToken, error) {
+	tokenID, err := parseSignedToken(token, purpose, secret)
+	if err != nil {
+		return nil, err
+	}
+	userToken := &models.UserToken{}
+	if err = userToken.GetByID(mongo, tokenID); err != nil {
+		return nil, conureerrors.ErrInvalidOrExpiredToken
+	}
+	if !userToken.IsValid(purpose) {
+		return nil, conureerrors.ErrInvalidOrExpiredToken
+	}
+	return userToken, nil
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+func TestSignToken(t *testing.T) {
+	tokenID := primitive.NewObjectID().Hex()
+	token := signToken(tokenID, models.PasswordResetPurpose, time.Now().Add(time.Hour), "secret")
+
+	got, err := parseSignedToken(token, models.PasswordResetPurpose, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, tokenID, got)
+
+	// Test with another purpose
+	_, err = parseSignedToken(token, models.EmailVerificationPurpose, "secret")
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidOrExpiredToken)
+
+	// Test with invalid secret
+	_, err = parseSignedToken(token, models.PasswordResetPurpose, "invalid-secret")
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidOrExpiredToken)
+
+	// Test with empty secret
+	_, err = parseSignedToken(token, models.PasswordResetPurpose, "")
+	assert.ErrorIs(t, err, conureerrors.ErrJWTKeyError)
+
+	// Test with a tampered expiration
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + "9999999999" + "." + parts[2]
+	_, err = parseSignedToken(tampered, models.PasswordResetPurpose, "secret")
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidOrExpiredToken)
+
+	// Test with an expired token
+	expired := signToken(tokenID, models.PasswordResetPurpose, time.Now().Add(-time.Minute), "secret")
+	_, err = parseSignedToken(expired, models.PasswordResetPurpose, "secret")
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidOrExpiredToken)
+
+	// Test with a malformed token
+	_, err = parseSignedToken("invalid-token", models.PasswordResetPurpose, "secret")
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidOrExpiredToken)
+}
+
+func TestFrontendLink(t *testing.T) {
+	link := frontendLink("http://localhost:5173/", "/verify-email", "a.b+c")
+	assert.Equal(t, "http://localhost:5173/verify-email?token=a.b%2Bc", link)
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+)
+
+// TOTP parameters from RFC 6238, they are the defaults of every authenticator app
+const (
+	TOTPPeriod = 30
+	TOTPDigits = 6
+	// TOTPSkew is the amount of periods accepted before and after the current one to tolerate clock drift
+	TOTPSkew          = 1
+	TOTPIssuer        = "Conure"
+	RecoveryCodeCount = 10
+)
+
+func GenerateTOTPSecret() (string, error) {
+	secret, err := GenerateRandomBytes(20)
+	if err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+// TOTPProvisioningURI returns the otpauth URI to be rendered as a QR code by the frontend
+func TOTPProvisioningURI(email string, secret string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", TOTPIssuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(TOTPDigits))
+	values.Set("period", fmt.Sprint(TOTPPeriod))
+	label := url.PathEscape(TOTPIssuer + ":" + email)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, values.Encode())
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / TOTPPeriod
+}
+
+func totpCode(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulo), nil
+}
+
+// ValidateTOTPCode returns the time step matching the code, the step must be stored to prevent the code from being
+// used twice
+func ValidateTOTPCode(secret string, code string, now time.Time) (int64, error) {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != TOTPDigits {
+		return 0, conureerrors.ErrInvalidTwoFactorCode
+	}
+	current := totpStep(now)
+	for step := current - TOTPSkew; step <= current+TOTPSkew; step++ {
+		expected, err := totpCode(secret, step)
+		if err != nil {
+			return 0, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+	return 0, conureerrors.ErrInvalidTwoFactorCode
+}
+
+// GenerateRecoveryCodes returns the plain codes, shown once to the user, and their hashes to be stored
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b, err := GenerateRandomBytes(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode hashes a recovery code, codes are random enough for a fast hash to be safe
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpKey derives the key used to encrypt the TOTP secrets at rest from the JWT secret
+func totpKey(secret string) ([]byte, error) {
+	if secret == "" {
+		return nil, conureerrors.ErrJWTKeyError
+	}
+	sum := sha256.Sum256([]byte("totp." + secret))
+	return sum[:], nil
+}
+
+func EncryptTOTPSecret(totpSecret string, secret string) (string, error) {
+	key, err := totpKey(secret)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", conureerrors.ErrCryptoError
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", conureerrors.ErrCryptoError
+	}
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", conureerrors.ErrCryptoError
+	}
+	return hex.EncodeToString(aesGCM.Seal(nonce, nonce, []byte(totpSecret), nil)), nil
+}
+
+func DecryptTOTPSecret(encrypted string, secret string) (string, error) {
+	key, err := totpKey(secret)
+	if err != nil {
+		return "", err
+	}
+	enc, err := hex.DecodeString(encrypted)
+	if err != nil {
+		return "", conureerrors.ErrCryptoError
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", conureerrors.ErrCryptoError
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", conureerrors.ErrCryptoError
+	}
+	if len(enc) < aesGCM.NonceSize() {
+		return "", conureerrors.ErrCryptoError
+	}
+	nonce, ciphertext := enc[:aesGCM.NonceSize()], enc[aesGCM.NonceSize():]
+	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", conureerrors.ErrCryptoError
+	}
+	return string(plaintext), nil
+}
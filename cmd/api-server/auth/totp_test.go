@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+)
+
+// rfcSecret is the base32 encoding of the RFC 6238 test key "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		time int64
+		want string
+	}{
+		{time: 59, want: "287082"},
+		{time: 1111111109, want: "081804"},
+		{time: 1234567890, want: "005924"},
+		{time: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		got, err := totpCode(rfcSecret, totpStep(time.Unix(tt.time, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "time: %d", tt.time)
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step, err := ValidateTOTPCode(rfcSecret, "081804", now)
+	require.NoError(t, err)
+	assert.Equal(t, totpStep(now), step)
+
+	// Codes of the adjacent periods are accepted
+	step, err = ValidateTOTPCode(rfcSecret, "081 804", now.Add(TOTPPeriod*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, totpStep(now), step)
+
+	_, err = ValidateTOTPCode(rfcSecret, "081804", now.Add(3*TOTPPeriod*time.Second))
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidTwoFactorCode)
+
+	_, err = ValidateTOTPCode(rfcSecret, "12345", now)
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidTwoFactorCode)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("test@conure.io", "SECRET")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Conure:test@conure.io?"), uri)
+	assert.Contains(t, uri, "secret=SECRET")
+	assert.Contains(t, uri, "issuer=Conure")
+}
+
+func TestEncryptTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	encrypted, err := EncryptTOTPSecret(secret, "test-secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, encrypted)
+
+	decrypted, err := DecryptTOTPSecret(encrypted, "test-secret")
+	require.NoError(t, err)
+	assert.Equal(t, secret, decrypted)
+
+	_, err = DecryptTOTPSecret(encrypted, "other-secret")
+	assert.ErrorIs(t, err, conureerrors.ErrCryptoError)
+
+	_, err = EncryptTOTPSecret(secret, "")
+	assert.ErrorIs(t, err, conureerrors.ErrJWTKeyError)
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	assert.Len(t, codes, RecoveryCodeCount)
+	assert.Len(t, hashes, RecoveryCodeCount)
+	for i, code := range codes {
+		assert.Equal(t, hashes[i], HashRecoveryCode(code))
+		// The hash ignores the formatting of the code
+		assert.Equal(t, hashes[i], HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(code, "-", ""))+" "))
+	}
+}
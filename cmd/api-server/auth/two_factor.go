@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+// verifySecondFactor accepts either a TOTP code or one of the recovery codes of the user
+func (h *Handler) verifySecondFactor(c *gin.Context, user *models.User, code string, recoveryCode string) error {
+	switch {
+	case code != "":
+		secret, err := DecryptTOTPSecret(user.TOTPSecret, h.Config.JWTSecret)
+		if err != nil {
+			return err
+		}
+		step, err := ValidateTOTPCode(secret, code, time.Now())
+		if err != nil {
+			return err
+		}
+		return user.UseTOTPStep(h.db(c), step)
+	case recoveryCode != "":
+		return user.UseRecoveryCode(h.db(c), HashRecoveryCode(recoveryCode))
+	default:
+		return conureerrors.ErrInvalidTwoFactorCode
+	}
+}
+
+func (h *Handler) LoginTwoFactor(c *gin.Context) {
+	request := TwoFactorLoginRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	userID, err := parseSignedToken(request.Challenge, models.TwoFactorChallengePurpose, h.Config.JWTSecret)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	user := models.User{}
+	if err = user.GetById(h.db(c), userID); err != nil || !user.TwoFactorEnabled {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidOrExpiredToken)
+		return
+	}
+
+	// the codes are short, so they share the login throttling
+	loginKeys := h.loginKeys(c, user.Email)
+	retryAfter, err := h.checkLoginLocked(c, loginKeys)
+	if err != nil {
+		log.Printf("Error checking login attempts: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	if retryAfter > 0 {
+		c.Header("Retry-After", retryAfterHeader(retryAfter))
+		conureerrors.AbortWithError(c, conureerrors.ErrTooManyLoginAttempts)
+		return
+	}
+
+	err = h.verifySecondFactor(c, &user, request.Code, request.RecoveryCode)
+	if errors.Is(err, conureerrors.ErrInvalidTwoFactorCode) {
+		h.registerLoginFailure(c, loginKeys)
+		conureerrors.AbortWithError(c, err)
+		return
+	} else if err != nil {
+		log.Printf("Error verifying the second factor: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+
+	err = models.ResetLoginAttempts(h.db(c), models.AccountLoginKey(user.Email))
+	if err != nil {
+		log.Printf("Error resetting login attempts: %v\n", err)
+	}
+	h.startSession(c, &user)
+}
+
+// EnrollTwoFactor generates a new TOTP secret, 2FA is not enabled until a code is verified with VerifyTwoFactor
+func (h *Handler) EnrollTwoFactor(c *gin.Context) {
+	user := c.MustGet("currentUser").(models.User)
+	if user.TwoFactorEnabled {
+		conureerrors.AbortWithError(c, conureerrors.ErrTwoFactorAlreadyEnabled)
+		return
+	}
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrCryptoError)
+		return
+	}
+	encrypted, err := EncryptTOTPSecret(secret, h.Config.JWTSecret)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	err = user.SetTOTPSecret(h.db(c), encrypted)
+	if errors.Is(err, conureerrors.ErrTwoFactorAlreadyEnabled) {
+		conureerrors.AbortWithError(c, err)
+		return
+	} else if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	c.JSON(http.StatusOK, TwoFactorEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: TOTPProvisioningURI(user.Email, secret),
+	})
+}
+
+// VerifyTwoFactor enables 2FA once the user proves the authenticator is set up, the recovery codes are returned only
+// in this response
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	request := TwoFactorCodeRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	user := c.MustGet("currentUser").(models.User)
+	if user.TwoFactorEnabled {
+		conureerrors.AbortWithError(c, conureerrors.ErrTwoFactorAlreadyEnabled)
+		return
+	}
+	if user.TOTPSecret == "" {
+		conureerrors.AbortWithError(c, conureerrors.ErrTwoFactorNotEnabled)
+		return
+	}
+	secret, err := DecryptTOTPSecret(user.TOTPSecret, h.Config.JWTSecret)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	step, err := ValidateTOTPCode(secret, request.Code, time.Now())
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrCryptoError)
+		return
+	}
+	user.TOTPLastStep = step
+	if err = user.EnableTwoFactor(h.db(c), hashes); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	request := DisableTwoFactorRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	user := c.MustGet("currentUser").(models.User)
+	if !user.TwoFactorEnabled {
+		conureerrors.AbortWithError(c, conureerrors.ErrTwoFactorNotEnabled)
+		return
+	}
+	matched, err := ComparePasswordAndHash(request.Password, user.Password)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	if !matched {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidCredentials)
+		return
+	}
+	if err = h.verifySecondFactor(c, &user, request.Code, request.RecoveryCode); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	if err = user.DisableTwoFactor(h.db(c)); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces all the recovery codes of the user
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	request := TwoFactorCodeRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	user := c.MustGet("currentUser").(models.User)
+	if !user.TwoFactorEnabled {
+		conureerrors.AbortWithError(c, conureerrors.ErrTwoFactorNotEnabled)
+		return
+	}
+	if err := h.verifySecondFactor(c, &user, request.Code, ""); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrCryptoError)
+		return
+	}
+	if err = user.SetRecoveryCodes(h.db(c), hashes); err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrDatabaseError)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
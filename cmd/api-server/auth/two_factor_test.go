@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+func TestHandler_TwoFactor(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	router, mongo, _ := setupRegistrationTest(RegistrationClosed)
+	defer cleanUpDB(mongo)
+
+	hashed, _ := GenerateFromPassword("password123")
+	user := models.User{Email: "test@test.com", Client: "test-client", Password: hashed}
+	_ = user.Create(mongo)
+	jwt, _ := GenerateToken(time.Hour, JWTData{Email: user.Email, Client: user.Client}, "test-secret")
+	cookie := &http.Cookie{Name: "auth", Value: jwt}
+
+	resp := postJSON(router, "/auth/2fa/enroll", nil, cookie)
+	require.Equal(t, http.StatusOK, resp.Code, "(enroll) should return 200 OK")
+	enrollment := TwoFactorEnrollResponse{}
+	_ = json.Unmarshal(resp.Body.Bytes(), &enrollment)
+	assert.NotEmpty(t, enrollment.ProvisioningURI)
+
+	// 2FA is not enabled until a code is verified
+	resp = postJSON(router, "/auth/login", LoginRequest{Email: user.Email, Password: "password123"}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(login) should return 200 OK")
+	assert.NotEmpty(t, resp.Result().Cookies(), "(login) should set the auth cookie")
+
+	resp = postJSON(router, "/auth/2fa/verify", TwoFactorCodeRequest{Code: "000000"}, cookie)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, "(wrong code) should return 401 Unauthorized")
+
+	now := time.Now()
+	code, _ := totpCode(enrollment.Secret, totpStep(now))
+	resp = postJSON(router, "/auth/2fa/verify", TwoFactorCodeRequest{Code: code}, cookie)
+	require.Equal(t, http.StatusOK, resp.Code, "(verify) should return 200 OK")
+	recovery := RecoveryCodesResponse{}
+	_ = json.Unmarshal(resp.Body.Bytes(), &recovery)
+	assert.Len(t, recovery.RecoveryCodes, RecoveryCodeCount)
+
+	// The password alone only returns a challenge
+	resp = postJSON(router, "/auth/login", LoginRequest{Email: user.Email, Password: "password123"}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(login) should return 200 OK")
+	assert.Empty(t, resp.Result().Cookies(), "(login) should not set the auth cookie")
+	challenge := TwoFactorChallengeResponse{}
+	_ = json.Unmarshal(resp.Body.Bytes(), &challenge)
+	assert.True(t, challenge.TwoFactorRequired)
+
+	// The code used for the enrollment cannot be replayed
+	resp = postJSON(router, "/auth/login/2fa", TwoFactorLoginRequest{Challenge: challenge.Challenge, Code: code}, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, "(replayed code) should return 401 Unauthorized")
+
+	nextCode, _ := totpCode(enrollment.Secret, totpStep(now)+1)
+	resp = postJSON(router, "/auth/login/2fa", TwoFactorLoginRequest{Challenge: challenge.Challenge, Code: nextCode}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(login 2fa) should return 200 OK")
+	assert.NotEmpty(t, resp.Result().Cookies(), "(login 2fa) should set the auth cookie")
+
+	// Recovery codes can be used only once
+	recoveryLogin := TwoFactorLoginRequest{Challenge: challenge.Challenge, RecoveryCode: recovery.RecoveryCodes[0]}
+	resp = postJSON(router, "/auth/login/2fa", recoveryLogin, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(recovery code) should return 200 OK")
+	resp = postJSON(router, "/auth/login/2fa", recoveryLogin, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, "(used recovery code) should return 401 Unauthorized")
+
+	disable := DisableTwoFactorRequest{Password: "password123", RecoveryCode: recovery.RecoveryCodes[1]}
+	resp = postJSON(router, "/auth/2fa/disable", disable, cookie)
+	assert.Equal(t, http.StatusOK, resp.Code, "(disable) should return 200 OK")
+
+	resp = postJSON(router, "/auth/login", LoginRequest{Email: user.Email, Password: "password123"}, nil)
+	assert.Equal(t, http.StatusOK, resp.Code, "(login) should return 200 OK")
+	assert.NotEmpty(t, resp.Result().Cookies(), "(login) should set the auth cookie")
+}
@@ -10,3 +10,62 @@ type ChangePasswordRequest struct {
 	Password    string `json:"password" binding:"required"`
 	Password2   string `json:"password2" binding:"required"`
 }
+
+const (
+	RegistrationClosed = "closed"
+	RegistrationInvite = "invite"
+	RegistrationOpen   = "open"
+)
+
+type RegisterRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required"`
+	Password2 string `json:"password2" binding:"required"`
+	// Token is the invitation token, required when the registration mode is "invite"
+	Token string `json:"token"`
+}
+
+type TokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token     string `json:"token" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	Password2 string `json:"password2" binding:"required"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	Challenge         string `json:"challenge"`
+}
+
+// TwoFactorLoginRequest requires either a TOTP code or a recovery code
+type TwoFactorLoginRequest struct {
+	Challenge    string `json:"challenge" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
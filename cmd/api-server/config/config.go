@@ -1,15 +1,57 @@
 package config
 
+import "time"
+
+// Config of the API server, see internal/config for the tags. The settings can also be set in the YAML file in
+// CONFIG_FILE, under the lower case env names, the environment wins over the file.
 type Config struct {
-	MongoDBURI         string `env:"API_MONGODB_URI"`
-	MongoDBName        string `env:"API_MONGODB_NAME"`
-	JWTSecret          string `env:"JWT_SECRET"`
-	JWTExpiration      int    `env:"JWT_EXPIRATION_DAYS"`
-	ProviderSource     string `env:"PROVIDER_SOURCE"`
-	AESStorageStrategy string `env:"AES_STORAGE_STRATEGY"`
-	AuthServiceURL     string `env:"AUTH_SERVICE_URL"`
-	AuthStrategySystem string `env:"AUTH_STRATEGY_SYSTEM"`
-	FrontendDomain     string `env:"FRONTEND_DOMAIN"`
-	CookieSecure       bool   `env:"COOKIE_SECURE"`
-	CorsOrigins        string `env:"CORS_ORIGINS"`
+	MongoDBURI         string `env:"API_MONGODB_URI,required"`
+	MongoDBName        string `env:"API_MONGODB_NAME" default:"conure"`
+	JWTSecret          string `env:"JWT_SECRET,required"`
+	JWTExpiration      int    `env:"JWT_EXPIRATION_DAYS" default:"3"`
+	ProviderSource     string `env:"PROVIDER_SOURCE" default:"vela" oneof:"vela"`
+	AESStorageStrategy string `env:"AES_STORAGE_STRATEGY" default:"k8s" oneof:"k8s local"`
+	// AuthServiceURL is only used by the external auth strategy
+	AuthServiceURL     string   `env:"AUTH_SERVICE_URL"`
+	AuthStrategySystem string   `env:"AUTH_STRATEGY_SYSTEM" default:"local" oneof:"local external"`
+	FrontendDomain     string   `env:"FRONTEND_DOMAIN,required"`
+	FrontendURL        string   `env:"FRONTEND_URL,required"`
+	CookieSecure       bool     `env:"COOKIE_SECURE" default:"true"`
+	CorsOrigins        []string `env:"CORS_ORIGINS" sep:";"`
+	RegistrationMode   string   `env:"REGISTRATION_MODE" default:"invite" oneof:"closed invite open"`
+	MailSender         string   `env:"MAIL_SENDER" default:"log" oneof:"log smtp"`
+	// MailFrom and SMTPHost are required by the smtp mail sender
+	MailFrom               string        `env:"MAIL_FROM"`
+	SMTPHost               string        `env:"SMTP_HOST"`
+	SMTPPort               int           `env:"SMTP_PORT" default:"587"`
+	SMTPUsername           string        `env:"SMTP_USERNAME"`
+	SMTPPassword           string        `env:"SMTP_PASSWORD"`
+	LoginMaxAttempts       int           `env:"LOGIN_MAX_ATTEMPTS" default:"5"`
+	LoginMaxAttemptsPerIP  int           `env:"LOGIN_MAX_ATTEMPTS_PER_IP" default:"50"`
+	LoginLockout           time.Duration `env:"LOGIN_LOCKOUT" default:"30s"`
+	LoginMaxLockout        time.Duration `env:"LOGIN_MAX_LOCKOUT" default:"1h"`
+	OrganizationPurgeGrace time.Duration `env:"ORGANIZATION_PURGE_GRACE" default:"720h"`
+	// MongoDBMaxPoolSize, MongoDBMinPoolSize and MongoDBMaxConnIdleTime keep the driver defaults when 0
+	MongoDBMaxPoolSize     int           `env:"API_MONGODB_MAX_POOL_SIZE" default:"100"`
+	MongoDBMinPoolSize     int           `env:"API_MONGODB_MIN_POOL_SIZE"`
+	MongoDBMaxConnIdleTime time.Duration `env:"API_MONGODB_MAX_CONN_IDLE_TIME" default:"5m"`
+	MongoDBTimeout         time.Duration `env:"API_MONGODB_TIMEOUT" default:"10s"`
+	// MongoDBAllowStandalone runs the units of work without transactions on a standalone server, instead of refusing
+	// to start
+	MongoDBAllowStandalone bool          `env:"API_MONGODB_ALLOW_STANDALONE" default:"false"`
+	ShutdownTimeout        time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	LogFormat              string        `env:"LOG_FORMAT" default:"json" oneof:"json text"`
+	LogLevel               string        `env:"LOG_LEVEL" default:"info" oneof:"debug info warn error"`
+	// OTLPEndpoint is the URL of the OpenTelemetry collector receiving the traces, tracing is off when empty
+	OTLPEndpoint string `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	// MigrateOnStartup applies the pending migrations before serving, the migrate command applies them otherwise
+	MigrateOnStartup bool `env:"MIGRATE_ON_STARTUP" default:"true"`
+	// RoutingMode renders the routes of the components as Ingresses of IngressClass, or as HTTPRoutes attached to
+	// the Gateway GatewayName in GatewayNamespace
+	RoutingMode      string `env:"ROUTING_MODE" default:"ingress" oneof:"ingress gateway"`
+	IngressClass     string `env:"INGRESS_CLASS" default:"nginx"`
+	GatewayName      string `env:"GATEWAY_NAME"`
+	GatewayNamespace string `env:"GATEWAY_NAMESPACE"`
+	// CertManagerIssuer is the cert-manager ClusterIssuer of the certificates of the routes with TLS
+	CertManagerIssuer string `env:"CERT_MANAGER_ISSUER" default:"letsencrypt"`
 }
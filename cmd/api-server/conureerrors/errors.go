@@ -29,6 +29,15 @@ var (
 	ErrOldPasswordInvalid        = &ConureError{Code: "1005", Message: "old_password_invalid", StatusCode: http.StatusBadRequest}
 	ErrWrongAuthenticationSystem = &ConureError{Code: "1006", Message: "wrong_authentication_system", StatusCode: http.StatusUnauthorized}
 	ErrNotAllowed                = &ConureError{Code: "1007", Message: "not_allowed", StatusCode: http.StatusForbidden}
+	ErrRegistrationDisabled      = &ConureError{Code: "1008", Message: "registration_disabled", StatusCode: http.StatusForbidden}
+	ErrInvalidOrExpiredToken     = &ConureError{Code: "1009", Message: "invalid_or_expired_token", StatusCode: http.StatusBadRequest}
+	ErrEmailNotVerified          = &ConureError{Code: "1010", Message: "email_not_verified", StatusCode: http.StatusForbidden}
+	ErrTooManyLoginAttempts      = &ConureError{Code: "1011", Message: "too_many_login_attempts", StatusCode: http.StatusTooManyRequests}
+	ErrTwoFactorRequired         = &ConureError{Code: "1012", Message: "two_factor_required", StatusCode: http.StatusForbidden}
+	ErrInvalidTwoFactorCode      = &ConureError{Code: "1013", Message: "invalid_two_factor_code", StatusCode: http.StatusUnauthorized}
+	ErrTwoFactorAlreadyEnabled   = &ConureError{Code: "1014", Message: "two_factor_already_enabled", StatusCode: http.StatusBadRequest}
+	ErrTwoFactorNotEnabled       = &ConureError{Code: "1015", Message: "two_factor_not_enabled", StatusCode: http.StatusBadRequest}
+	ErrInvalidSignature          = &ConureError{Code: "1016", Message: "invalid_signature", StatusCode: http.StatusUnauthorized}
 
 	ErrInvalidRequest               = &ConureError{Code: "2001", Message: "invalid_request", StatusCode: http.StatusBadRequest}
 	ErrObjectNotFound               = &ConureError{Code: "2002", Message: "object_not_found", StatusCode: http.StatusNotFound}
@@ -38,16 +47,28 @@ var (
 	ErrPasswordConfirmationMismatch = &ConureError{Code: "2006", Message: "password_confirmation_mismatch", StatusCode: http.StatusBadRequest}
 	ErrFieldValidation              = &ConureError{Code: "2007", Message: "invalid_field_value", StatusCode: http.StatusBadRequest}
 	ErrEmailAlreadyExists           = &ConureError{Code: "2008", Message: "email_already_exists", StatusCode: http.StatusBadRequest}
+	ErrOrganizationDisabled         = &ConureError{Code: "2009", Message: "organization_disabled", StatusCode: http.StatusForbidden}
+	ErrInvalidCursor                = &ConureError{Code: "2010", Message: "invalid_cursor", StatusCode: http.StatusBadRequest}
+	ErrInvalidSortField             = &ConureError{Code: "2011", Message: "invalid_sort_field", StatusCode: http.StatusBadRequest}
+	ErrInvalidManifest              = &ConureError{Code: "2012", Message: "invalid_manifest", StatusCode: http.StatusBadRequest}
+	ErrInvalidRoute                 = &ConureError{Code: "2013", Message: "invalid_route", StatusCode: http.StatusBadRequest}
+	ErrInvalidAutoscaling           = &ConureError{Code: "2014", Message: "invalid_autoscaling", StatusCode: http.StatusBadRequest}
+	ErrInvalidHealthCheck           = &ConureError{Code: "2015", Message: "invalid_health_check", StatusCode: http.StatusBadRequest}
+	ErrInvalidLifecycle             = &ConureError{Code: "2016", Message: "invalid_lifecycle", StatusCode: http.StatusBadRequest}
+	ErrInvalidRollout               = &ConureError{Code: "2017", Message: "invalid_rollout", StatusCode: http.StatusBadRequest}
 
 	ErrInternalError = &ConureError{Code: "3001", Message: "internal_error", StatusCode: http.StatusInternalServerError}
 	ErrDatabaseError = &ConureError{Code: "3002", Message: "database_error", StatusCode: http.StatusInternalServerError}
 	// ErrNetworkError  = &ConureError{Code: "3003", Message: "network_error", StatusCode: http.StatusInternalServerError}
+	ErrMailSenderNotSupported = &ConureError{Code: "3004", Message: "mail_sender_not_supported", StatusCode: http.StatusInternalServerError}
 
 	ErrProviderNotSupported   = &ConureError{Code: "4001", Message: "provider_not_supported", StatusCode: http.StatusInternalServerError}
 	ErrComponentNotFound      = &ConureError{Code: "4002", Message: "component_not_found", StatusCode: http.StatusNotFound}
 	ErrApplicationExists      = &ConureError{Code: "4003", Message: "application_already_exists", StatusCode: http.StatusConflict}
 	ErrApplicationNotDeployed = &ConureError{Code: "4004", Message: "application_not_deployed", StatusCode: http.StatusNotFound}
 	ErrPodNotFound            = &ConureError{Code: "4005", Message: "pod_not_found", StatusCode: http.StatusNotFound}
+	ErrClusterUnavailable     = &ConureError{Code: "4006", Message: "cluster_unavailable", StatusCode: http.StatusServiceUnavailable}
+	ErrRoutingNotConfigured   = &ConureError{Code: "4007", Message: "routing_not_configured", StatusCode: http.StatusInternalServerError}
 )
 
 func AbortWithError(c *gin.Context, err error) {
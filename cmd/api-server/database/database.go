@@ -2,33 +2,147 @@ package database
 
 import (
 	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"log"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
 type MongoDB struct {
 	Client *mongo.Client
 	DBName string
 	err    mongo.WriteException
+	ctx    context.Context
+	// transactions is whether the deployment is a replica set or a sharded cluster, a standalone server has none
+	transactions bool
+	// standalone allows running the units of work without transactions when the server has none
+	standalone bool
+}
+
+// ErrNoTransactions is returned for a unit of work on a standalone server, unless it's allowed with AllowStandalone
+var ErrNoTransactions = errors.New("MongoDB is a standalone server, it has no transactions")
+
+// PoolConfig tunes the connection pool of the client, the zero values keep the defaults of the driver
+type PoolConfig struct {
+	MaxPoolSize     uint64
+	MinPoolSize     uint64
+	MaxConnIdleTime time.Duration
+	// Timeout bounds the connection and the server selection
+	Timeout time.Duration
+}
+
+func (p PoolConfig) apply(clientOptions *options.ClientOptions) *options.ClientOptions {
+	if p.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(p.MaxPoolSize)
+	}
+	if p.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(p.MinPoolSize)
+	}
+	if p.MaxConnIdleTime > 0 {
+		clientOptions.SetMaxConnIdleTime(p.MaxConnIdleTime)
+	}
+	if p.Timeout > 0 {
+		clientOptions.SetConnectTimeout(p.Timeout)
+		clientOptions.SetServerSelectionTimeout(p.Timeout)
+	}
+	return clientOptions
 }
 
 func ConnectToMongoDB(uri string, dbName string) (*MongoDB, error) {
-	// Set client options
-	clientOptions := options.Client().ApplyURI(uri)
+	return ConnectToMongoDBWithPool(uri, dbName, PoolConfig{})
+}
+
+func ConnectToMongoDBWithPool(uri string, dbName string, pool PoolConfig) (*MongoDB, error) {
+	// Set client options, the monitor traces every command under the span of its context
+	clientOptions := pool.apply(options.Client().ApplyURI(uri)).SetMonitor(otelmongo.NewMonitor())
 	ctx := context.Background()
 	// Connect to MongoDB
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	// Check the connection
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		log.Fatal(err)
+		_ = client.Disconnect(ctx)
+		return nil, err
 	}
 
 	log.Println("Connected to MongoDB!")
-	return &MongoDB{Client: client, DBName: dbName}, nil
+	transactions := supportsTransactions(ctx, client)
+	return &MongoDB{Client: client, DBName: dbName, transactions: transactions}, nil
+}
+
+func supportsTransactions(ctx context.Context, client *mongo.Client) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		log.Printf("Error checking the MongoDB topology: %v\n", err)
+		return false
+	}
+	// the mongos routers of a sharded cluster answer isdbgrid
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+// WithContext returns a copy sharing the client whose operations run with ctx, so they are traced and logged
+// under the request that makes them
+func (m *MongoDB) WithContext(ctx context.Context) *MongoDB {
+	scoped := *m
+	scoped.ctx = ctx
+	return &scoped
+}
+
+// ForRequest scopes the operations to the request, they are traced and logged under it. Its context isn't cancelled
+// when the client goes away, so the writes of the request aren't left half done. The handlers reach it through their
+// db(c) helper.
+func (m *MongoDB) ForRequest(r *http.Request) *MongoDB {
+	return m.WithContext(context.WithoutCancel(r.Context()))
+}
+
+// Context is the context of the operations, the background one when the connection isn't scoped to a request
+func (m *MongoDB) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+// SupportsTransactions is false on a standalone server
+func (m *MongoDB) SupportsTransactions() bool {
+	return m.transactions
+}
+
+// AllowStandalone runs the units of work without transactions on a standalone server, their writes are then
+// applied one by one
+func (m *MongoDB) AllowStandalone() {
+	m.standalone = true
+}
+
+// AllowsStandalone is whether the units of work run without transactions on a standalone server
+func (m *MongoDB) AllowsStandalone() bool {
+	return m.standalone
+}
+
+// InTransaction is whether the operations run in the transaction of a session
+func (m *MongoDB) InTransaction() bool {
+	return mongo.SessionFromContext(m.Context()) != nil
+}
+
+// Ping checks the primary is reachable
+func (m *MongoDB) Ping(ctx context.Context) error {
+	return m.Client.Ping(ctx, readpref.Primary())
+}
+
+func (m *MongoDB) Disconnect(ctx context.Context) error {
+	return m.Client.Disconnect(ctx)
 }
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckTimeout bounds every readiness check, so a hung dependency can't hold the probe past its own timeout
+const CheckTimeout = 5 * time.Second
+
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusStopping = "stopping"
+)
+
+// Check reports whether a dependency of the server is available
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Report is the body of the probes, Checks has the error of every failing check
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Probe answers the liveness and readiness probes of the server. Once stopped, the server is draining its requests
+// and the readiness probe fails so no new traffic is sent to it.
+type Probe struct {
+	mu       sync.Mutex
+	checks   []namedCheck
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+func NewProbe() *Probe {
+	return &Probe{stopping: make(chan struct{})}
+}
+
+func (p *Probe) AddCheck(name string, check Check) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks = append(p.checks, namedCheck{name: name, check: check})
+}
+
+// Stop marks the server as shutting down
+func (p *Probe) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopping)
+	})
+}
+
+// Stopping is closed when the server starts shutting down, the long-lived streams end with it
+func (p *Probe) Stopping() <-chan struct{} {
+	return p.stopping
+}
+
+// Live answers the liveness probe. It doesn't check the dependencies: restarting the server doesn't fix them, and
+// the readiness probe already takes it out of the traffic while they are failing.
+func (p *Probe) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// Ready answers the readiness probe with the result of every check
+func (p *Probe) Ready(c *gin.Context) {
+	select {
+	case <-p.stopping:
+		c.JSON(http.StatusServiceUnavailable, Report{Status: StatusStopping})
+		return
+	default:
+	}
+	report := p.Run(c.Request.Context())
+	if report.Status != StatusOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Run runs the checks concurrently
+func (p *Probe) Run(ctx context.Context) Report {
+	p.mu.Lock()
+	checks := append([]namedCheck(nil), p.checks...)
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = check.check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK}
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if report.Checks == nil {
+			report.Checks = map[string]string{}
+		}
+		report.Status = StatusFailing
+		report.Checks[checks[i].name] = err.Error()
+	}
+	return report
+}
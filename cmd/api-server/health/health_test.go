@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func probeRequest(t *testing.T, probe *Probe, path string) (int, Report) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	GenerateRoutes(router, probe)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, report
+}
+
+func TestProbe_Ready(t *testing.T) {
+	probe := NewProbe()
+	probe.AddCheck("mongodb", func(ctx context.Context) error {
+		return nil
+	})
+	if code, report := probeRequest(t, probe, "/readyz"); code != http.StatusOK || report.Status != StatusOK {
+		t.Errorf("Expected the probe to be ready, got: %d %v", code, report)
+	}
+
+	probe.AddCheck("kubernetes", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	code, report := probeRequest(t, probe, "/readyz")
+	if code != http.StatusServiceUnavailable || report.Status != StatusFailing {
+		t.Errorf("Expected the probe to fail, got: %d %v", code, report)
+	}
+	if len(report.Checks) != 1 || report.Checks["kubernetes"] != "connection refused" {
+		t.Errorf("Expected only the kubernetes check to fail, got: %v", report.Checks)
+	}
+
+	// the liveness probe doesn't depend on the checks
+	if code, _ := probeRequest(t, probe, "/healthz"); code != http.StatusOK {
+		t.Errorf("Expected the server to be live, got: %d", code)
+	}
+}
+
+func TestProbe_Stop(t *testing.T) {
+	probe := NewProbe()
+	probe.Stop()
+	probe.Stop()
+	select {
+	case <-probe.Stopping():
+	default:
+		t.Error("Expected the stopping channel to be closed")
+	}
+	if code, report := probeRequest(t, probe, "/readyz"); code != http.StatusServiceUnavailable || report.Status != StatusStopping {
+		t.Errorf("Expected the probe to be stopping, got: %d %v", code, report)
+	}
+	if code, _ := probeRequest(t, probe, "/healthz"); code != http.StatusOK {
+		t.Errorf("Expected the server to be live while draining, got: %d", code)
+	}
+}
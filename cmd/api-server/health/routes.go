@@ -0,0 +1,13 @@
+package health
+
+import "github.com/gin-gonic/gin"
+
+const (
+	LivePath  = "/healthz"
+	ReadyPath = "/readyz"
+)
+
+func GenerateRoutes(r *gin.Engine, probe *Probe) {
+	r.GET(LivePath, probe.Live)
+	r.GET(ReadyPath, probe.Ready)
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewLogger_Context(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := NewLogger(&buffer, JSON, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = WithRequestID(ctx, "req1234")
+
+	logger.InfoContext(ctx, "deployed", slog.String("application", "shop"))
+	var record map[string]interface{}
+	if err = json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"msg":         "deployed",
+		"application": "shop",
+		"request_id":  "req1234",
+		"trace_id":    traceID.String(),
+		"span_id":     spanID.String(),
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s to be %s, got: %v", key, value, record)
+		}
+	}
+
+	buffer.Reset()
+	logger.Debug("hidden")
+	if buffer.Len() != 0 {
+		t.Errorf("Expected the debug records to be dropped, got: %s", buffer.String())
+	}
+}
+
+func TestNewLogger_Invalid(t *testing.T) {
+	if _, err := NewLogger(&bytes.Buffer{}, "xml", "info"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if _, err := NewLogger(&bytes.Buffer{}, JSON, "verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
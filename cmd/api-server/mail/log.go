@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+)
+
+// LogSender writes the emails to the standard logger instead of delivering them. It is meant for development and
+// tests, the sent messages are kept in memory so they can be inspected.
+type LogSender struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (l *LogSender) Send(_ context.Context, message Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sent = append(l.sent, message)
+	log.Printf("Sending email to %s, subject: %s\n%s\n", strings.Join(message.To, ", "), message.Subject, message.Body)
+	return nil
+}
+
+// Sent returns a copy of the messages sent so far.
+func (l *LogSender) Sent() []Message {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sent := make([]Message, len(l.sent))
+	copy(sent, l.sent)
+	return sent
+}
+
+// Last returns the last message sent, or nil if none was sent.
+func (l *LogSender) Last() *Message {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.sent) == 0 {
+		return nil
+	}
+	message := l.sent[len(l.sent)-1]
+	return &message
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+)
+
+const (
+	SMTP = "smtp"
+	Log  = "log"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Sender delivers transactional emails such as verification links or password resets.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+func NewSender(config *apiConfig.Config) (Sender, error) {
+	switch config.MailSender {
+	case SMTP:
+		if config.SMTPHost == "" || config.MailFrom == "" {
+			return nil, fmt.Errorf("smtp mail sender requires SMTP_HOST and MAIL_FROM")
+		}
+		return NewSMTPSender(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom), nil
+	case Log:
+		return NewLogSender(), nil
+	}
+	return nil, conureerrors.ErrMailSenderNotSupported
+}
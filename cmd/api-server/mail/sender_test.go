@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+)
+
+func TestNewSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *apiConfig.Config
+		wantErr error
+	}{
+		{
+			name:   "Log sender",
+			config: &apiConfig.Config{MailSender: Log},
+		},
+		{
+			name:   "SMTP sender",
+			config: &apiConfig.Config{MailSender: SMTP, SMTPHost: "localhost", SMTPPort: 25, MailFrom: "noreply@conure.io"},
+		},
+		{
+			name:    "SMTP sender without host",
+			config:  &apiConfig.Config{MailSender: SMTP, MailFrom: "noreply@conure.io"},
+			wantErr: errors.New("smtp mail sender requires SMTP_HOST and MAIL_FROM"),
+		},
+		{
+			name:    "Unknown sender",
+			config:  &apiConfig.Config{MailSender: "fake-sender"},
+			wantErr: conureerrors.ErrMailSenderNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := NewSender(tt.config)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Errorf("NewSender() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || sender == nil {
+				t.Errorf("NewSender() error = %v, want a sender", err)
+			}
+		})
+	}
+}
+
+func TestLogSender(t *testing.T) {
+	sender := NewLogSender()
+	if sender.Last() != nil {
+		t.Errorf("Last() should be nil when nothing was sent")
+	}
+	for _, subject := range []string{"first", "second"} {
+		err := sender.Send(context.Background(), Message{To: []string{"test@conure.io"}, Subject: subject, Body: "body"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sender.Sent()) != 2 {
+		t.Errorf("Sent() = %d messages, want 2", len(sender.Sent()))
+	}
+	if sender.Last().Subject != "second" {
+		t.Errorf("Last() subject = %s, want second", sender.Last().Subject)
+	}
+}
+
+func TestSMTPSender_buildMessage(t *testing.T) {
+	sender := NewSMTPSender("localhost", 25, "", "", "noreply@conure.io")
+	message := string(sender.buildMessage(Message{To: []string{"a@conure.io", "b@conure.io"}, Subject: "Hello", Body: "line1\nline2"}))
+	for _, expected := range []string{"From: noreply@conure.io\r\n", "To: a@conure.io, b@conure.io\r\n", "Subject: Hello\r\n", "\r\n\r\nline1\r\nline2"} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("buildMessage() = %q, should contain %q", message, expected)
+		}
+	}
+}
@@ -1,46 +1,172 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/coffeenights/conure/cmd/api-server/applications"
 	"github.com/coffeenights/conure/cmd/api-server/auth"
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/health"
+	"github.com/coffeenights/conure/cmd/api-server/logging"
+	"github.com/coffeenights/conure/cmd/api-server/migrations"
 	"github.com/coffeenights/conure/cmd/api-server/routes"
 	"github.com/coffeenights/conure/cmd/api-server/variables"
 	"github.com/coffeenights/conure/internal/config"
+	"github.com/coffeenights/conure/internal/telemetry"
 	_ "github.com/joho/godotenv/autoload"
 )
 
 const (
 	SystemNamespace = "conure-system"
+	// ServiceName identifies the server in the traces
+	ServiceName = "conure-api-server"
 )
 
-func runServer(address string, port int) {
-	r := routes.GenerateRouter()
-	log.Println("Running the server...")
-	err := r.Run(fmt.Sprintf("%s:%d", address, port))
-	if err != nil {
+// loadConfig loads the config from the file, CONFIG_FILE when it's empty, and the environment. It exits listing
+// every problem found.
+func loadConfig(file string) *apiConfig.Config {
+	if file == "" {
+		file = os.Getenv(config.FileEnv)
+	}
+	conf := &apiConfig.Config{}
+	err := config.Load(conf, file)
+	var errs config.Errors
+	if errors.As(err, &errs) {
+		fmt.Fprintln(os.Stderr, "Invalid configuration:")
+		for _, fieldErr := range errs {
+			fmt.Fprintf(os.Stderr, "  %v\n", fieldErr)
+		}
+		os.Exit(1)
+	} else if err != nil {
 		log.Fatal(err)
 	}
+	return conf
 }
 
-func createSuperUser(email string) {
-	conf := config.LoadConfig(apiConfig.Config{})
+func connectToMongoDB(conf *apiConfig.Config) *database.MongoDB {
 	log.Println("Connecting to MongoDB")
-	mongo, err := database.ConnectToMongoDB(conf.MongoDBURI, conf.MongoDBName)
+	mongo, err := database.ConnectToMongoDBWithPool(conf.MongoDBURI, conf.MongoDBName, database.PoolConfig{
+		MaxPoolSize:     uint64(conf.MongoDBMaxPoolSize),
+		MinPoolSize:     uint64(conf.MongoDBMinPoolSize),
+		MaxConnIdleTime: conf.MongoDBMaxConnIdleTime,
+		Timeout:         conf.MongoDBTimeout,
+	})
 	if err != nil {
 		log.Panic(err)
 	}
+	if !mongo.SupportsTransactions() {
+		if !conf.MongoDBAllowStandalone {
+			log.Fatal("MongoDB is a standalone server and the units of work need transactions, run a replica set or set API_MONGODB_ALLOW_STANDALONE")
+		}
+		log.Println("WARNING: MongoDB is a standalone server, the writes of a unit of work are applied one by one without a transaction")
+		mongo.AllowStandalone()
+	}
 	log.Println("Connected to MongoDB")
+	return mongo
+}
+
+// runServer serves until SIGINT or SIGTERM, then stops taking new requests and drains the ones in flight. The
+// streams are ended as soon as the shutdown starts, they would otherwise hold it until the timeout.
+func runServer(address string, port int, configFile string) {
+	conf := loadConfig(configFile)
+	if err := logging.Setup(os.Stderr, conf.LogFormat, conf.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	shutdownTracing, err := telemetry.Setup(ctx, ServiceName, conf.OTLPEndpoint)
+	if err != nil {
+		log.Fatalf("Error setting up tracing: %v", err)
+	}
+	mongo := connectToMongoDB(conf)
+	if conf.MigrateOnStartup {
+		applyMigrations(ctx, mongo)
+	}
+	probe := health.NewProbe()
+	server := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", address, port),
+		Handler: routes.GenerateRouter(ctx, ServiceName, conf, mongo, probe),
+	}
+
+	signals, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		log.Println("Running the server...")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+	<-signals.Done()
+
+	log.Println("Shutting down the server...")
+	probe.Stop()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error draining the requests: %v\n", err)
+	}
+	cancel()
+	if err := mongo.Disconnect(shutdownCtx); err != nil {
+		log.Printf("Error disconnecting from MongoDB: %v\n", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Error flushing the traces: %v\n", err)
+	}
+	log.Println("Server stopped")
+}
+
+func applyMigrations(ctx context.Context, mongo *database.MongoDB) {
+	applied, err := migrations.Run(ctx, mongo)
+	if err != nil {
+		log.Fatalf("Error migrating the database: %v", err)
+	}
+	log.Printf("Applied %d migrations\n", applied)
+}
+
+// migrate applies the pending migrations, or lists the state of each one with status
+func migrate(status bool) {
+	conf := loadConfig("")
+	mongo := connectToMongoDB(conf)
+	ctx := context.Background()
+	if !status {
+		applyMigrations(ctx, mongo)
+		return
+	}
+	records, err := migrations.Applied(ctx, mongo)
+	if err != nil {
+		log.Panic(err)
+	}
+	appliedAt := make(map[int]time.Time, len(records))
+	for _, record := range records {
+		appliedAt[record.Version] = record.AppliedAt
+	}
+	for _, migration := range migrations.Migrations {
+		state := "pending"
+		if at, ok := appliedAt[migration.Version]; ok {
+			state = "applied " + at.Format(time.RFC3339)
+		}
+		fmt.Printf("%4d  %-30s  %s\n", migration.Version, state, migration.Description)
+	}
+}
+
+func createSuperUser(email string) {
+	conf := loadConfig("")
+	mongo := connectToMongoDB(conf)
 	auth.CreateSuperuser(mongo, email)
 }
 
 func createSecretKey() {
-	_ = config.LoadConfig(apiConfig.Config{})
+	_ = loadConfig("")
 	log.Println("Creating secret key...")
 	keyStore := variables.NewK8sSecretKey(SystemNamespace)
 	err := keyStore.Generate()
@@ -51,14 +177,19 @@ func createSecretKey() {
 }
 
 func resetSuperUserPassword(email string) {
-	conf := config.LoadConfig(apiConfig.Config{})
-	log.Println("Connecting to MongoDB")
-	mongo, err := database.ConnectToMongoDB(conf.MongoDBURI, conf.MongoDBName)
+	conf := loadConfig("")
+	mongo := connectToMongoDB(conf)
+	auth.ResetSuperuserPassword(mongo, email)
+}
+
+func purgeOrganizations() {
+	conf := loadConfig("")
+	mongo := connectToMongoDB(conf)
+	purged, err := applications.PurgeDeletedOrganizations(mongo, conf.OrganizationPurgeGrace)
 	if err != nil {
 		log.Panic(err)
 	}
-	log.Println("Connected to MongoDB")
-	auth.ResetSuperuserPassword(mongo, email)
+	log.Printf("Purged %d deleted organizations\n", purged)
 }
 
 func main() {
@@ -66,13 +197,16 @@ func main() {
 		runserverCmd              = flag.NewFlagSet("runserver", flag.ExitOnError)
 		createsuperuserCmd        = flag.NewFlagSet("createsuperuser", flag.ExitOnError)
 		resetSuperUserPasswordCmd = flag.NewFlagSet("resetsuperuserpassword", flag.ExitOnError)
+		migrateCmd                = flag.NewFlagSet("migrate", flag.ExitOnError)
 		subcommand                string
 	)
 
 	addressServer := runserverCmd.String("address", "localhost", "The HTTP server bind address.")
 	portServer := runserverCmd.Int("port", 8080, "The HTTP server port")
+	configFile := runserverCmd.String("config", "", "The YAML config file, overridden by the environment variables. Defaults to "+config.FileEnv)
 	emailSuperuser := createsuperuserCmd.String("email", "", "The email of the superuser")
 	emailSuperuserReset := resetSuperUserPasswordCmd.String("email", "", "The email of the superuser")
+	migrateStatus := migrateCmd.Bool("status", false, "List the migrations and whether they are applied, without applying them")
 
 	flag.Usage = func() {
 		fmt.Printf("Usage: \n")
@@ -82,6 +216,8 @@ func main() {
 		fmt.Printf("\tcreatesuperuser  Create the super user for your account\n")
 		fmt.Printf("\tresetsuperuserpassword  Reset the super user password\n")
 		fmt.Printf("\tcreatesecretkey  Create the secret key for your account\n")
+		fmt.Printf("\tpurgeorganizations  Purge the data of the organizations deleted after the grace period\n")
+		fmt.Printf("\tmigrate          Apply the pending database migrations\n")
 	}
 	if len(os.Args) >= 2 {
 		subcommand = os.Args[1]
@@ -93,7 +229,7 @@ func main() {
 		if err != nil {
 			log.Fatalf("failed to start the server: %v", err)
 		}
-		runServer(*addressServer, *portServer)
+		runServer(*addressServer, *portServer, *configFile)
 	case "createsuperuser":
 		err := createsuperuserCmd.Parse(os.Args[2:])
 		if err != nil {
@@ -107,6 +243,14 @@ func main() {
 		createSuperUser(*emailSuperuser)
 	case "createsecretkey":
 		createSecretKey()
+	case "purgeorganizations":
+		purgeOrganizations()
+	case "migrate":
+		err := migrateCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		migrate(*migrateStatus)
 	case "resetsuperuserpassword":
 		err := resetSuperUserPasswordCmd.Parse(os.Args[2:])
 		if err != nil {
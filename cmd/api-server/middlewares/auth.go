@@ -7,6 +7,7 @@ import (
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
 )
 
 func CheckAuthenticatedUser(config *apiConfig.Config, mongo *database.MongoDB) gin.HandlerFunc {
@@ -27,12 +28,47 @@ func CheckAuthenticatedUser(config *apiConfig.Config, mongo *database.MongoDB) g
 			return
 		}
 
-		user, err := ValidateUser(authToken, config, mongo)
+		user, err := ValidateUser(authToken, config, mongo.ForRequest(c.Request))
 		if err != nil {
 			conureerrors.AbortWithError(c, err)
 			return
 		}
 		c.Set("currentUser", user)
+
+		db := mongo.ForRequest(c.Request)
+		if err = checkRouteOrganization(c, db); err != nil {
+			conureerrors.AbortWithError(c, err)
+			return
+		}
+
+		// organizations can require 2FA from their owner, with external auth 2FA is up to the auth service
+		if organizationID := c.Param("organizationID"); organizationID != "" && config.AuthStrategySystem == "local" {
+			org := models.Organization{}
+			_, err = org.GetById(db, organizationID)
+			if err == nil && org.RequireTwoFactor && !user.TwoFactorEnabled {
+				conureerrors.AbortWithError(c, conureerrors.ErrTwoFactorRequired)
+				return
+			}
+		}
 		c.Next()
 	}
 }
+
+// checkRouteOrganization makes sure the application and the variable named by the route belong to its organization,
+// the handlers load them by their own ID
+func checkRouteOrganization(c *gin.Context, db *database.MongoDB) error {
+	organizationID := c.Param("organizationID")
+	if applicationID := c.Param("applicationID"); applicationID != "" {
+		application := models.Application{}
+		if err := application.GetByID(db, applicationID); err != nil || application.OrganizationID.Hex() != organizationID {
+			return conureerrors.ErrObjectNotFound
+		}
+	}
+	if variableID := c.Param("variableID"); variableID != "" {
+		variable := models.Variable{}
+		if err := variable.GetByID(db, variableID); err != nil || variable.OrganizationID.Hex() != organizationID {
+			return conureerrors.ErrObjectNotFound
+		}
+	}
+	return nil
+}
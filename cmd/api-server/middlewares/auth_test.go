@@ -13,6 +13,7 @@ import (
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/database"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func cleanUpDB(mongo *database.MongoDB) {
@@ -23,6 +24,7 @@ func cleanUpDB(mongo *database.MongoDB) {
 }
 
 func TestCheckAuthenticatedUser(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 
 	payload := auth.JWTData{
@@ -122,3 +124,66 @@ func TestCheckAuthenticatedUser(t *testing.T) {
 		t.Errorf("Expected %v, got %v", http.StatusUnauthorized, w.Code)
 	}
 }
+
+func TestCheckAuthenticatedUser_TwoFactor(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
+	gin.SetMode(gin.TestMode)
+	config := &apiConfig.Config{
+		JWTSecret:          "test-secret",
+		MongoDBURI:         "mongodb://localhost:27017",
+		MongoDBName:        "conure-test",
+		AuthStrategySystem: "local",
+	}
+	mongo, _ := database.ConnectToMongoDB(config.MongoDBURI, config.MongoDBName)
+	defer cleanUpDB(mongo)
+
+	user := models.User{Email: "test@test.com", Client: "test-client"}
+	_ = user.Create(mongo)
+	token, _ := auth.GenerateToken(1*time.Hour, auth.JWTData{Email: user.Email, Client: user.Client}, "test-secret")
+
+	secured := models.Organization{Name: "secured", AccountID: user.ID, RequireTwoFactor: true}
+	_, _ = secured.Create(mongo)
+	open := models.Organization{Name: "open", AccountID: user.ID}
+	_, _ = open.Create(mongo)
+	securedApplication := models.Application{Name: "secured", OrganizationID: secured.ID, AccountID: user.ID}
+	_, _ = securedApplication.Create(mongo)
+	openApplication := models.Application{Name: "open", OrganizationID: open.ID, AccountID: user.ID}
+	_, _ = openApplication.Create(mongo)
+	securedVariable := models.Variable{Name: "TOKEN", OrganizationID: secured.ID, Type: models.OrganizationType}
+	_, _ = securedVariable.Create(mongo)
+
+	router := gin.New()
+	router.Use(CheckAuthenticatedUser(config, mongo))
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+	}
+	router.GET("/:organizationID", ok)
+	router.GET("/:organizationID/a/:applicationID", ok)
+	router.GET("/:organizationID/v/:variableID", ok)
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{name: "Open organization", path: "/" + open.ID.Hex() + "/a/" + openApplication.ID.Hex(), expectedCode: http.StatusOK},
+		{name: "Secured organization", path: "/" + secured.ID.Hex(), expectedCode: http.StatusForbidden},
+		{name: "Application of a secured organization", path: "/" + secured.ID.Hex() + "/a/" + securedApplication.ID.Hex(), expectedCode: http.StatusForbidden},
+		{name: "Variable of a secured organization", path: "/" + secured.ID.Hex() + "/v/" + securedVariable.ID.Hex(), expectedCode: http.StatusForbidden},
+		{name: "Application of another organization", path: "/" + open.ID.Hex() + "/a/" + securedApplication.ID.Hex(), expectedCode: http.StatusNotFound},
+		{name: "Variable of another organization", path: "/" + open.ID.Hex() + "/v/" + securedVariable.ID.Hex(), expectedCode: http.StatusNotFound},
+		{name: "Unknown application", path: "/" + open.ID.Hex() + "/a/" + primitive.NewObjectID().Hex(), expectedCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			req.AddCookie(&http.Cookie{Name: "auth", Value: token})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected %v, got %v", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 )
 
 func TestValidateUserExternal(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	payload := auth.JWTData{
 		Email:  "test@test.com",
 		Client: "test-client",
@@ -13,6 +13,7 @@ import (
 )
 
 func TestValidateUserLocal(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	payload := auth.JWTData{
 		Email:  "test@test.com",
 		Client: "test-client",
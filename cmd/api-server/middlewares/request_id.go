@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/coffeenights/conure/cmd/api-server/logging"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	RequestIDKey    = "requestID"
+)
+
+// RequestID keeps the request id sent by the client, or a proxy, and generates one otherwise. The id goes in the
+// context of the request for the logs, and in its span.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = primitive.NewObjectID().Hex()
+		}
+		c.Set(RequestIDKey, requestID)
+		ctx := c.Request.Context()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request.id", requestID))
+		c.Request = c.Request.WithContext(logging.WithRequestID(ctx, requestID))
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
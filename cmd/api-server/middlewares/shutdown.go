@@ -0,0 +1,22 @@
+package middlewares
+
+import "github.com/gin-gonic/gin"
+
+const ShutdownKey = "shutdown"
+
+// Shutdown hands the handlers the channel closed when the server starts shutting down. The requests are drained
+// without being cancelled, but the streams never end on their own and have to watch it.
+func Shutdown(stopping <-chan struct{}) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ShutdownKey, stopping)
+		c.Next()
+	}
+}
+
+// ShuttingDown returns the channel set by Shutdown, or a nil channel that never fires when the middleware isn't used
+func ShuttingDown(c *gin.Context) <-chan struct{} {
+	if stopping, ok := c.Get(ShutdownKey); ok {
+		return stopping.(<-chan struct{})
+	}
+	return nil
+}
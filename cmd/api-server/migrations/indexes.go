@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+type collectionIndexes struct {
+	collection string
+	indexes    []mongo.IndexModel
+}
+
+// The soft deleted documents keep their deletedAt in the unique indexes, so a name can be used again once the
+// document holding it is deleted. The live documents have no deletedAt, they all share the null value.
+var indexesV1 = []collectionIndexes{
+	{models.UserCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetName("email_unique").SetUnique(true)},
+	}},
+	{models.OrganizationCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "accountId", Value: 1}, {Key: "status", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetName("account_status_name")},
+	}},
+	{models.ApplicationCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "organizationID", Value: 1}, {Key: "name", Value: 1}, {Key: "deletedAt", Value: 1}}, Options: options.Index().SetName("organization_name_unique").SetUnique(true)},
+	}},
+	{models.ComponentCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "applicationID", Value: 1}, {Key: "name", Value: 1}, {Key: "deletedAt", Value: 1}}, Options: options.Index().SetName("application_name_unique").SetUnique(true)},
+	}},
+	{models.VariableCollection, []mongo.IndexModel{
+		// the variables of an organization have no application, environment nor component, they are null in the index
+		{Keys: bson.D{
+			{Key: "organizationId", Value: 1}, {Key: "type", Value: 1}, {Key: "applicationId", Value: 1},
+			{Key: "environmentId", Value: 1}, {Key: "componentId", Value: 1}, {Key: "name", Value: 1},
+		}, Options: options.Index().SetName("scope_name_unique").SetUnique(true)},
+	}},
+	{models.IntegrationCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "organizationID", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetName("organization_name_unique").SetUnique(true)},
+		{Keys: bson.D{{Key: "organizationID", Value: 1}, {Key: "integrationType", Value: 1}}, Options: options.Index().SetName("organization_type")},
+	}},
+}
+
+// createIndexes fails on a unique index when the collection already holds duplicates, they have to be renamed or
+// removed before running the migration again
+func createIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, spec := range indexesV1 {
+		if _, err := db.Collection(spec.collection).Indexes().CreateMany(ctx, spec.indexes); err != nil {
+			return fmt.Errorf("creating the indexes of %s: %w", spec.collection, err)
+		}
+	}
+	return nil
+}
+
+// backfillOrganizationStatus marks active the organizations without status, the status filter of the list didn't
+// match them
+func backfillOrganizationStatus(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(models.OrganizationCollection).UpdateMany(ctx,
+		bson.M{"status": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"status": models.OrgActive}},
+	)
+	return err
+}
+
+// lowercaseEmails matches the stored emails with the lookups, which lowercase them. Users registered twice in a
+// different case would break the email_unique index, they are listed and have to be merged or removed first.
+func lowercaseEmails(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection(models.UserCollection)
+	cursor, err := users.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"$toLower": "$email"}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	var duplicates []struct {
+		Email string `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		emails := make([]string, len(duplicates))
+		for i, duplicate := range duplicates {
+			emails[i] = duplicate.Email
+		}
+		return fmt.Errorf("users registered with the same email in a different case: %s", strings.Join(emails, ", "))
+	}
+
+	lowercase := mongo.Pipeline{{{Key: "$set", Value: bson.M{"email": bson.M{"$toLower": "$email"}}}}}
+	for _, collection := range []string{models.UserCollection, models.UserTokenCollection} {
+		if _, err = db.Collection(collection).UpdateMany(ctx, bson.M{}, lowercase); err != nil {
+			return fmt.Errorf("lowercasing the emails of %s: %w", collection, err)
+		}
+	}
+	return nil
+}
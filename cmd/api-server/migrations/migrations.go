@@ -0,0 +1,115 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/coffeenights/conure/cmd/api-server/database"
+)
+
+// Collection records the migrations applied to the database
+const Collection = "schema_migrations"
+
+// Migration is a versioned change of the schema or the documents. Up may be run again when it failed half way, or
+// when two servers start at the same time, so it must be idempotent.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record is the document of an applied migration in the schema_migrations collection
+type Record struct {
+	Version     int       `bson:"_id" json:"version"`
+	Description string    `bson:"description" json:"description"`
+	AppliedAt   time.Time `bson:"appliedAt" json:"applied_at"`
+}
+
+// Migrations are applied in the order of their versions, new ones are appended with the next version. An applied
+// migration must not be changed, a new one fixes it.
+var Migrations = []Migration{
+	{Version: 1, Description: "create the indexes of users, organizations, applications, components, variables and integrations", Up: createIndexes},
+	{Version: 2, Description: "set the status of the organizations created before it existed", Up: backfillOrganizationStatus},
+	{Version: 3, Description: "lowercase the emails of the users and their tokens", Up: lowercaseEmails},
+}
+
+// Validate checks the versions are positive and strictly increasing
+func Validate(migrations []Migration) error {
+	previous := 0
+	for _, migration := range migrations {
+		if migration.Version <= previous {
+			return fmt.Errorf("migration %d must have a version greater than %d", migration.Version, previous)
+		}
+		if migration.Up == nil {
+			return fmt.Errorf("migration %d has no Up function", migration.Version)
+		}
+		previous = migration.Version
+	}
+	return nil
+}
+
+// Pending returns the migrations which are not in the records, in order
+func Pending(migrations []Migration, records []Record) []Migration {
+	applied := make(map[int]bool, len(records))
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+	var pending []Migration
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+// Applied returns the records of the migrations applied to the database, by version
+func Applied(ctx context.Context, db *database.MongoDB) ([]Record, error) {
+	collection := db.Client.Database(db.DBName).Collection(Collection)
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0)
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Run applies the pending Migrations and returns how many were applied. It stops at the first one failing, the
+// following ones are left pending.
+func Run(ctx context.Context, db *database.MongoDB) (int, error) {
+	return run(ctx, db, Migrations)
+}
+
+func run(ctx context.Context, db *database.MongoDB, migrations []Migration) (int, error) {
+	if err := Validate(migrations); err != nil {
+		return 0, err
+	}
+	records, err := Applied(ctx, db)
+	if err != nil {
+		return 0, fmt.Errorf("reading the applied migrations: %w", err)
+	}
+	collection := db.Client.Database(db.DBName).Collection(Collection)
+	applied := 0
+	for _, migration := range Pending(migrations, records) {
+		log.Printf("Applying migration %d: %s\n", migration.Version, migration.Description)
+		if err = migration.Up(ctx, db.Client.Database(db.DBName)); err != nil {
+			return applied, fmt.Errorf("applying migration %d: %w", migration.Version, err)
+		}
+		record := Record{Version: migration.Version, Description: migration.Description, AppliedAt: time.Now()}
+		// another server may have applied it meanwhile, the record is already there then
+		if _, err = collection.InsertOne(ctx, record); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return applied, fmt.Errorf("recording migration %d: %w", migration.Version, err)
+		}
+		applied++
+	}
+	return applied, nil
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func noop(context.Context, *mongo.Database) error {
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(Migrations); err != nil {
+		t.Errorf("Expected the registered migrations to be valid, got: %v", err)
+	}
+	invalid := map[string][]Migration{
+		"zero version": {{Version: 0, Up: noop}},
+		"duplicated":   {{Version: 1, Up: noop}, {Version: 1, Up: noop}},
+		"out of order": {{Version: 2, Up: noop}, {Version: 1, Up: noop}},
+		"no up":        {{Version: 1}},
+	}
+	for name, migrations := range invalid {
+		if err := Validate(migrations); err == nil {
+			t.Errorf("Expected an error for the %s migrations", name)
+		}
+	}
+}
+
+func TestPending(t *testing.T) {
+	migrations := []Migration{{Version: 1, Up: noop}, {Version: 2, Up: noop}, {Version: 3, Up: noop}}
+	pending := Pending(migrations, []Record{{Version: 1}, {Version: 3}})
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("Expected only the migration 2 pending, got: %+v", pending)
+	}
+	if pending = Pending(migrations, nil); len(pending) != 3 {
+		t.Errorf("Expected every migration pending on a new database, got: %+v", pending)
+	}
+}
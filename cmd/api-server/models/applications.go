@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"github.com/coffeenights/conure/internal/k8s"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -33,40 +35,13 @@ type Organization struct {
 	Status    OrganizationStatus `bson:"status" json:"status"`
 	AccountID primitive.ObjectID `bson:"accountId" json:"account_id"`
 	Name      string             `bson:"name" json:"name"`
-	CreatedAt time.Time          `bson:"createdAt" json:"created_at"`
-	DeletedAt time.Time          `bson:"deletedAt,omitempty" json:"-"`
-}
-
-func OrganizationList(db *database.MongoDB, accountID string) ([]*Organization, error) {
-	collection := db.Client.Database(db.DBName).Collection(OrganizationCollection)
-	aID, err := primitive.ObjectIDFromHex(accountID)
-	if err != nil {
-		return nil, err
-	}
-	filter := bson.M{"accountId": aID, "status": bson.M{"$ne": OrgDeleted}}
-	cursor, err := collection.Find(context.Background(), filter)
-	if err != nil {
-		return nil, err
-	}
-	defer func(cursor *mongo.Cursor, ctx context.Context) {
-		err = cursor.Close(ctx)
-		if err != nil {
-			log.Panicf("Error closing cursor: %v\n", err)
-		}
-	}(cursor, context.Background())
-	var organizations []*Organization
-	for cursor.Next(context.Background()) {
-		var org Organization
-		err = cursor.Decode(&org)
-		if err != nil {
-			return nil, err
-		}
-		organizations = append(organizations, &org)
-	}
-	if err = cursor.Err(); err != nil {
-		return nil, err
-	}
-	return organizations, nil
+	// RequireTwoFactor denies the access to the organization to users without 2FA enabled. Organizations have no
+	// members yet, only their owner can access them, so it only applies to the owner.
+	RequireTwoFactor bool      `bson:"requireTwoFactor,omitempty" json:"require_two_factor"`
+	CreatedAt        time.Time `bson:"createdAt" json:"created_at"`
+	DeletedAt        time.Time `bson:"deletedAt,omitempty" json:"-"`
+	// PurgedAt is set once the variables and integrations of a deleted organization have been removed
+	PurgedAt *time.Time `bson:"purgedAt,omitempty" json:"-"`
 }
 
 func (o *Organization) String() string {
@@ -77,7 +52,7 @@ func (o *Organization) Create(mongo *database.MongoDB) (string, error) {
 	collection := mongo.Client.Database(mongo.DBName).Collection(OrganizationCollection)
 	o.CreatedAt = time.Now()
 	o.Status = OrgActive
-	insertResult, err := collection.InsertOne(context.Background(), o)
+	insertResult, err := collection.InsertOne(mongo.Context(), o)
 	if err != nil {
 		return "", err
 	}
@@ -93,7 +68,7 @@ func (o *Organization) GetById(db *database.MongoDB, ID string) (*Organization,
 		return nil, err
 	}
 	filter := bson.M{"_id": oID, "status": bson.M{"$ne": OrgDeleted}}
-	err = collection.FindOne(context.Background(), filter).Decode(o)
+	err = collection.FindOne(db.Context(), filter).Decode(o)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return nil, conureerrors.ErrObjectNotFound
 	} else if err != nil {
@@ -112,7 +87,7 @@ func (o *Organization) Update(mongo *database.MongoDB) error {
 			Value: o,
 		},
 	}
-	updateResult, err := collection.UpdateOne(context.Background(), filter, update)
+	updateResult, err := collection.UpdateOne(mongo.Context(), filter, update)
 	if err != nil {
 		return err
 	}
@@ -120,6 +95,137 @@ func (o *Organization) Update(mongo *database.MongoDB) error {
 	return nil
 }
 
+func (o *Organization) Rename(mongo *database.MongoDB, name string) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(OrganizationCollection)
+	o.Name = name
+	filter := bson.M{"_id": o.ID, "status": bson.M{"$ne": OrgDeleted}}
+	update := bson.M{"$set": bson.M{"name": o.Name}}
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetStatus switches between active and disabled, deleting an organization is done with SoftDelete
+func (o *Organization) SetStatus(mongo *database.MongoDB, status OrganizationStatus) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(OrganizationCollection)
+	if status == OrgDeleted {
+		return conureerrors.ErrInvalidRequest
+	}
+	o.Status = status
+	filter := bson.M{"_id": o.ID, "status": bson.M{"$ne": OrgDeleted}}
+	update := bson.M{"$set": bson.M{"status": o.Status}}
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// TransferOwnership moves the organization and its applications to another account
+func (o *Organization) TransferOwnership(mongo *database.MongoDB, accountID primitive.ObjectID) error {
+	err := RunInTransaction(mongo, func(tx *database.MongoDB) error {
+		db := tx.Client.Database(tx.DBName)
+		filter := bson.M{"_id": o.ID, "status": bson.M{"$ne": OrgDeleted}}
+		update := bson.M{"$set": bson.M{"accountId": accountID}}
+		_, err := db.Collection(OrganizationCollection).UpdateOne(tx.Context(), filter, update)
+		if err != nil {
+			return err
+		}
+		appFilter := bson.M{"organizationID": o.ID}
+		appUpdate := bson.M{"$set": bson.M{"accountID": accountID}}
+		updateResult, err := db.Collection(ApplicationCollection).UpdateMany(tx.Context(), appFilter, appUpdate)
+		if err != nil {
+			return err
+		}
+		log.Printf("Transferred %v applications of organization %s\n", updateResult.ModifiedCount, o.ID.Hex())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.AccountID = accountID
+	return nil
+}
+
+// ListOrganizationsToPurge returns the organizations deleted before the given time whose data is not purged yet
+func ListOrganizationsToPurge(db *database.MongoDB, deletedBefore time.Time) ([]Organization, error) {
+	collection := db.Client.Database(db.DBName).Collection(OrganizationCollection)
+	filter := bson.M{
+		"status":    OrgDeleted,
+		"deletedAt": bson.M{"$lt": deletedBefore},
+		"purgedAt":  bson.M{"$exists": false},
+	}
+	cursor, err := collection.Find(db.Context(), filter)
+	if err != nil {
+		return nil, err
+	}
+	organizations := []Organization{}
+	if err = cursor.All(db.Context(), &organizations); err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+// ListAccountOrganizations returns the organizations owned by an account which are not deleted
+func ListAccountOrganizations(db *database.MongoDB, accountID primitive.ObjectID) ([]Organization, error) {
+	collection := db.Client.Database(db.DBName).Collection(OrganizationCollection)
+	filter := bson.M{"accountId": accountID, "status": bson.M{"$ne": OrgDeleted}}
+	cursor, err := collection.Find(db.Context(), filter)
+	if err != nil {
+		return nil, err
+	}
+	organizations := []Organization{}
+	if err = cursor.All(db.Context(), &organizations); err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+// Purge removes the variables and integrations of a deleted organization, the organization and its applications
+// are kept soft deleted
+func (o *Organization) Purge(mongo *database.MongoDB) error {
+	db := mongo.Client.Database(mongo.DBName)
+	if o.Status != OrgDeleted {
+		return conureerrors.ErrInvalidRequest
+	}
+	now := time.Now()
+	err := RunInTransaction(mongo, func(tx *database.MongoDB) error {
+		variablesResult, err := db.Collection(VariableCollection).DeleteMany(tx.Context(), bson.M{"organizationId": o.ID})
+		if err != nil {
+			return err
+		}
+		integrationsResult, err := db.Collection(IntegrationCollection).DeleteMany(tx.Context(), bson.M{"organizationID": o.ID})
+		if err != nil {
+			return err
+		}
+		_, err = db.Collection(OrganizationCollection).UpdateOne(tx.Context(), bson.M{"_id": o.ID}, bson.M{"$set": bson.M{"purgedAt": now}})
+		if err != nil {
+			return err
+		}
+		log.Printf("Purged %v variables and %v integrations of organization %s\n", variablesResult.DeletedCount, integrationsResult.DeletedCount, o.ID.Hex())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.PurgedAt = &now
+	return nil
+}
+
+func (o *Organization) SetRequireTwoFactor(mongo *database.MongoDB, require bool) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(OrganizationCollection)
+	o.RequireTwoFactor = require
+	filter := bson.M{"_id": o.ID, "status": bson.M{"$ne": OrgDeleted}}
+	update := bson.M{"$set": bson.M{"requireTwoFactor": o.RequireTwoFactor}}
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (o *Organization) Delete(mongo *database.MongoDB) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(OrganizationCollection)
 	filter := bson.D{
@@ -128,7 +234,7 @@ func (o *Organization) Delete(mongo *database.MongoDB) error {
 			Value: o.ID,
 		},
 	}
-	deleteResult, err := collection.DeleteOne(context.Background(), filter)
+	deleteResult, err := collection.DeleteOne(mongo.Context(), filter)
 	if err != nil {
 		return err
 	}
@@ -153,7 +259,7 @@ func (o *Organization) SoftDelete(mongo *database.MongoDB) error {
 			},
 		},
 	}
-	updateResult, err := collection.UpdateOne(context.Background(), filter, update)
+	updateResult, err := collection.UpdateOne(mongo.Context(), filter, update)
 	if err != nil {
 		return err
 	}
@@ -205,7 +311,7 @@ func ApplicationList(db *database.MongoDB, organizationID string) ([]*Applicatio
 		return nil, err
 	}
 	filter := bson.M{"organizationID": oID, "deletedAt": bson.M{"$exists": false}}
-	cursor, err := collection.Find(context.Background(), filter)
+	cursor, err := collection.Find(db.Context(), filter)
 	if err != nil {
 		return nil, err
 	}
@@ -214,9 +320,9 @@ func ApplicationList(db *database.MongoDB, organizationID string) ([]*Applicatio
 		if err != nil {
 			log.Panicf("Error closing cursor: %v\n", err)
 		}
-	}(cursor, context.Background())
+	}(cursor, db.Context())
 	var applications []*Application
-	for cursor.Next(context.Background()) {
+	for cursor.Next(db.Context()) {
 		var app Application
 		err = cursor.Decode(&app)
 		if err != nil {
@@ -230,6 +336,20 @@ func ApplicationList(db *database.MongoDB, organizationID string) ([]*Applicatio
 	return applications, nil
 }
 
+// GetApplicationByName returns the application of the organization with the name
+func GetApplicationByName(db *database.MongoDB, organizationID primitive.ObjectID, name string) (*Application, error) {
+	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
+	filter := bson.M{"organizationID": organizationID, "name": name, "deletedAt": bson.M{"$exists": false}}
+	application := &Application{}
+	err := collection.FindOne(db.Context(), filter).Decode(application)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, conureerrors.ErrObjectNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
 func (a *Application) GetEnvironmentByName(db *database.MongoDB, environmentName string) (*Environment, error) {
 	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
 	pipeline := mongo.Pipeline{
@@ -243,12 +363,12 @@ func (a *Application) GetEnvironmentByName(db *database.MongoDB, environmentName
 			{Key: "$match", Value: bson.D{{Key: "environments.name", Value: environmentName}}},
 		},
 	}
-	cursor, err := collection.Aggregate(context.Background(), pipeline)
+	cursor, err := collection.Aggregate(db.Context(), pipeline)
 	if err != nil {
 		return nil, err
 	}
 	var results []bson.M
-	if err = cursor.All(context.Background(), &results); err != nil {
+	if err = cursor.All(db.Context(), &results); err != nil {
 		return nil, err
 	}
 	if len(results) == 0 {
@@ -265,9 +385,9 @@ func (a *Application) GetEnvironmentByName(db *database.MongoDB, environmentName
 func (a *Application) Create(mongo *database.MongoDB) (*Application, error) {
 	collection := mongo.Client.Database(mongo.DBName).Collection(ApplicationCollection)
 	a.CreatedAt = time.Now()
-	insertResult, err := collection.InsertOne(context.Background(), a)
+	insertResult, err := collection.InsertOne(mongo.Context(), a)
 	if err != nil {
-		return nil, err
+		return nil, duplicateAsExisting(err)
 	}
 	log.Println("Inserted a single document: ", insertResult.InsertedID.(primitive.ObjectID).Hex())
 	a.ID = insertResult.InsertedID.(primitive.ObjectID)
@@ -281,7 +401,7 @@ func (a *Application) GetByID(db *database.MongoDB, ID string) error {
 		return err
 	}
 	filter := bson.M{"_id": oID, "deletedAt": bson.M{"$exists": false}}
-	err = collection.FindOne(context.Background(), filter).Decode(a)
+	err = collection.FindOne(db.Context(), filter).Decode(a)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return conureerrors.ErrObjectNotFound
 	} else if err != nil {
@@ -297,9 +417,9 @@ func (a *Application) Update(db *database.MongoDB) error {
 	update := bson.D{
 		{Key: "$set", Value: a},
 	}
-	updateResult, err := collection.UpdateOne(context.Background(), filter, update)
+	updateResult, err := collection.UpdateOne(db.Context(), filter, update)
 	if err != nil {
-		return err
+		return duplicateAsExisting(err)
 	}
 	log.Printf("Matched %v documents and updated %v documents.\n", updateResult.MatchedCount, updateResult.ModifiedCount)
 	return nil
@@ -308,7 +428,7 @@ func (a *Application) Update(db *database.MongoDB) error {
 func (a *Application) Delete(db *database.MongoDB) error {
 	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
 	filter := bson.D{{Key: "_id", Value: a.ID}}
-	deleteResult, err := collection.DeleteOne(context.Background(), filter)
+	deleteResult, err := collection.DeleteOne(db.Context(), filter)
 	if err != nil {
 		return err
 	}
@@ -324,7 +444,7 @@ func (a *Application) SoftDelete(db *database.MongoDB) error {
 			{Key: "deletedAt", Value: time.Now()},
 		}},
 	}
-	updateResult, err := collection.UpdateOne(context.Background(), filter, update)
+	updateResult, err := collection.UpdateOne(db.Context(), filter, update)
 	if err != nil {
 		return err
 	}
@@ -332,10 +452,63 @@ func (a *Application) SoftDelete(db *database.MongoDB) error {
 	return nil
 }
 
+// Rename changes the name and description, the deployed environments pick them up on the next deploy
+func (a *Application) Rename(db *database.MongoDB, name string, description string) error {
+	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
+	a.Name = name
+	a.Description = description
+	filter := bson.M{"_id": a.ID, "deletedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"name": a.Name, "description": a.Description}}
+	_, err := collection.UpdateOne(db.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClearEnvironments removes all the environments, their namespaces must be torn down before
+func (a *Application) ClearEnvironments(db *database.MongoDB) error {
+	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
+	a.Environments = nil
+	filter := bson.M{"_id": a.ID}
+	update := bson.M{"$unset": bson.M{"environments": ""}}
+	_, err := collection.UpdateOne(db.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetEnvironments replaces the environments of the application
+func (a *Application) SetEnvironments(db *database.MongoDB, environments []Environment) error {
+	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
+	if environments == nil {
+		environments = []Environment{}
+	}
+	filter := bson.M{"_id": a.ID}
+	update := bson.M{"$set": bson.M{"environments": environments}}
+	_, err := collection.UpdateOne(db.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	a.Environments = environments
+	return nil
+}
+
+func (a *Application) DeleteComponents(db *database.MongoDB) (int64, error) {
+	collection := db.Client.Database(db.DBName).Collection(ComponentCollection)
+	deleteResult, err := collection.DeleteMany(db.Context(), bson.M{"applicationID": a.ID})
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Deleted %v documents in the components collection\n", deleteResult.DeletedCount)
+	return deleteResult.DeletedCount, nil
+}
+
 func (a *Application) ListComponents(db *database.MongoDB) ([]Component, error) {
 	collection := db.Client.Database(db.DBName).Collection(ComponentCollection)
 	filter := bson.M{"applicationID": a.ID, "deletedAt": bson.M{"$exists": false}}
-	cursor, err := collection.Find(context.Background(), filter)
+	cursor, err := collection.Find(db.Context(), filter)
 	if err != nil {
 		return nil, err
 	}
@@ -344,9 +517,9 @@ func (a *Application) ListComponents(db *database.MongoDB) ([]Component, error)
 		if err != nil {
 			log.Panicf("Error closing cursor: %v\n", err)
 		}
-	}(cursor, context.Background())
+	}(cursor, db.Context())
 	var components []Component
-	for cursor.Next(context.Background()) {
+	for cursor.Next(db.Context()) {
 		var comp Component
 		err = cursor.Decode(&comp)
 		if err != nil {
@@ -363,7 +536,7 @@ func (a *Application) ListComponents(db *database.MongoDB) ([]Component, error)
 func (a *Application) CountComponents(db *database.MongoDB) (int64, error) {
 	collection := db.Client.Database(db.DBName).Collection(ComponentCollection)
 	filter := bson.M{"applicationID": a.ID, "deletedAt": bson.M{"$exists": false}}
-	count, err := collection.CountDocuments(context.Background(), filter)
+	count, err := collection.CountDocuments(db.Context(), filter)
 	if err != nil {
 		return 0, err
 	}
@@ -380,11 +553,31 @@ func (a *Application) CreateEnvironment(db *database.MongoDB, name string) (*Env
 	return env, nil
 }
 
+// SetEnvironmentDomainTemplate sets the domain template of the environment
+func (a *Application) SetEnvironmentDomainTemplate(db *database.MongoDB, envID string, template string) error {
+	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
+	filter := bson.M{"_id": a.ID, "environments._id": envID}
+	update := bson.M{"$set": bson.M{"environments.$.domainTemplate": template}}
+	updateResult, err := collection.UpdateOne(db.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	if updateResult.MatchedCount == 0 {
+		return conureerrors.ErrObjectNotFound
+	}
+	for i := range a.Environments {
+		if a.Environments[i].ID == envID {
+			a.Environments[i].DomainTemplate = template
+		}
+	}
+	return nil
+}
+
 func (a *Application) DeleteEnvironmentByID(db *database.MongoDB, envID string) error {
 	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
 	filter := bson.M{"_id": a.ID}
 	update := bson.M{"$pull": bson.M{"environments": bson.M{"_id": envID}}}
-	updateResult, err := collection.UpdateOne(context.Background(), filter, update)
+	updateResult, err := collection.UpdateOne(db.Context(), filter, update)
 	if err != nil {
 		return err
 	}
@@ -398,7 +591,7 @@ func (a *Application) DeleteEnvironmentByName(db *database.MongoDB, envName stri
 	collection := db.Client.Database(db.DBName).Collection(ApplicationCollection)
 	filter := bson.M{"_id": a.ID}
 	update := bson.M{"$pull": bson.M{"environments": bson.M{"name": envName}}}
-	updateResult, err := collection.UpdateOne(context.Background(), filter, update)
+	updateResult, err := collection.UpdateOne(db.Context(), filter, update)
 	if err != nil {
 		return err
 	}
@@ -415,6 +608,16 @@ type Component struct {
 	Description   string             `json:"description" bson:"description"`
 	ApplicationID primitive.ObjectID `json:"application_id" bson:"applicationID"`
 	Settings      ComponentSettings  `json:"settings" bson:"settings"`
+	// EnvironmentSettings replace the Settings in the environments with the name of the key
+	EnvironmentSettings map[string]ComponentSettings `json:"environment_settings,omitempty" bson:"environmentSettings"`
+}
+
+// SettingsFor returns the settings of the component in the environment
+func (c *Component) SettingsFor(environment *Environment) ComponentSettings {
+	if settings, ok := c.EnvironmentSettings[environment.Name]; ok {
+		return settings
+	}
+	return c.Settings
 }
 
 func (c *Component) GetCollectionName() string {
@@ -422,22 +625,22 @@ func (c *Component) GetCollectionName() string {
 }
 
 func (c *Component) Create(db *database.MongoDB) error {
-	err := Create(context.Background(), db, c)
+	err := Create(db.Context(), db, c)
 	return err
 }
 
 func (c *Component) Delete(db *database.MongoDB) error {
-	err := Delete(context.Background(), db, c)
+	err := Delete(db.Context(), db, c)
 	return err
 }
 
 func (c *Component) GetByID(db *database.MongoDB, ID string) error {
-	err := GetByID(context.Background(), db, ID, c)
+	err := GetByID(db.Context(), db, ID, c)
 	return err
 }
 
 func (c *Component) Update(db *database.MongoDB) error {
-	err := Update(context.Background(), db, c)
+	err := Update(db.Context(), db, c)
 	return err
 }
 
@@ -450,6 +653,8 @@ type ApplicationRevision struct {
 type Environment struct {
 	ID   string `json:"id" bson:"_id"`
 	Name string `json:"name" bson:"name"`
+	// DomainTemplate names the hosts of the routes without one, e.g. {component}.{env}.example.com
+	DomainTemplate string `json:"domain_template,omitempty" bson:"domainTemplate,omitempty"`
 }
 
 func NewEnvironment(name string) *Environment {
@@ -467,6 +672,196 @@ type ResourcesSettings struct {
 	Replicas int     `json:"replicas" bson:"replicas"`
 	CPU      float32 `json:"cpu" bson:"cpu"`
 	Memory   int     `json:"memory" bson:"memory"`
+	// Autoscaling replaces the fixed Replicas by a HorizontalPodAutoscaler
+	Autoscaling *AutoscalingSettings `json:"autoscaling,omitempty" bson:"autoscaling,omitempty"`
+}
+
+// AutoscalingSettings are the bounds of the replicas of the component and the usage they're scaled on
+type AutoscalingSettings struct {
+	MinReplicas int `json:"min_replicas" bson:"minReplicas"`
+	MaxReplicas int `json:"max_replicas" bson:"maxReplicas"`
+	// TargetCPU and TargetMemory are the average utilization of the pods, in percent of their requests
+	TargetCPU    int `json:"target_cpu,omitempty" bson:"targetCPU,omitempty"`
+	TargetMemory int `json:"target_memory,omitempty" bson:"targetMemory,omitempty"`
+	// ScaleToZero removes every replica while the service of the component receives no requests, it's scaled on
+	// the requests per second of the service over TargetRequests per replica
+	ScaleToZero    bool `json:"scale_to_zero" bson:"scaleToZero"`
+	TargetRequests int  `json:"target_requests,omitempty" bson:"targetRequests,omitempty"`
+}
+
+// Validate checks the bounds and the targets of the autoscaling, no autoscaling is valid
+func (a *AutoscalingSettings) Validate() error {
+	if a == nil {
+		return nil
+	}
+	var problems []string
+	if a.MaxReplicas < 1 {
+		problems = append(problems, "max_replicas must be at least 1")
+	}
+	if a.MinReplicas > a.MaxReplicas {
+		problems = append(problems, "min_replicas can't be over max_replicas")
+	}
+	if a.MinReplicas < 1 && !a.ScaleToZero {
+		problems = append(problems, "min_replicas must be at least 1 without scale_to_zero")
+	}
+	if a.TargetCPU < 0 || a.TargetMemory < 0 || a.TargetRequests < 0 {
+		problems = append(problems, "the targets can't be negative")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", conureerrors.ErrInvalidAutoscaling, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+type ProbeType string
+
+const (
+	HTTPProbe ProbeType = "http"
+	TCPProbe  ProbeType = "tcp"
+	ExecProbe ProbeType = "exec"
+)
+
+// ProbeSettings checks the container of the component with an HTTP GET of Path on Port, a TCP connection to Port
+// or by running Command. The timings left at zero take the defaults of Kubernetes.
+type ProbeSettings struct {
+	Type                ProbeType `json:"type" bson:"type"`
+	Path                string    `json:"path,omitempty" bson:"path,omitempty"`
+	Port                int       `json:"port,omitempty" bson:"port,omitempty"`
+	Command             []string  `json:"command,omitempty" bson:"command,omitempty"`
+	InitialDelaySeconds int       `json:"initial_delay_seconds,omitempty" bson:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int       `json:"period_seconds,omitempty" bson:"periodSeconds,omitempty"`
+	TimeoutSeconds      int       `json:"timeout_seconds,omitempty" bson:"timeoutSeconds,omitempty"`
+	FailureThreshold    int       `json:"failure_threshold,omitempty" bson:"failureThreshold,omitempty"`
+}
+
+func (p *ProbeSettings) problems(name string) []string {
+	if p == nil {
+		return nil
+	}
+	var problems []string
+	switch p.Type {
+	case HTTPProbe, TCPProbe:
+		if p.Port <= 0 || p.Port > 65535 {
+			problems = append(problems, fmt.Sprintf("the %s probe needs a port between 1 and 65535", name))
+		}
+		if p.Type == HTTPProbe && p.Path != "" && !strings.HasPrefix(p.Path, "/") {
+			problems = append(problems, fmt.Sprintf("the path of the %s probe must start with /", name))
+		}
+	case ExecProbe:
+		if len(p.Command) == 0 {
+			problems = append(problems, fmt.Sprintf("the %s probe needs a command", name))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("the %s probe type must be http, tcp or exec", name))
+	}
+	if p.InitialDelaySeconds < 0 || p.PeriodSeconds < 0 || p.TimeoutSeconds < 0 || p.FailureThreshold < 0 {
+		problems = append(problems, fmt.Sprintf("the timings of the %s probe can't be negative", name))
+	}
+	return problems
+}
+
+// HealthSettings are the probes of the container of the component. The startup probe holds the other two back until
+// it succeeds, which gives the slow starting components time to start.
+type HealthSettings struct {
+	Liveness  *ProbeSettings `json:"liveness,omitempty" bson:"liveness,omitempty"`
+	Readiness *ProbeSettings `json:"readiness,omitempty" bson:"readiness,omitempty"`
+	Startup   *ProbeSettings `json:"startup,omitempty" bson:"startup,omitempty"`
+}
+
+// Validate checks the probes, the missing probes are valid
+func (h *HealthSettings) Validate() error {
+	var problems []string
+	problems = append(problems, h.Liveness.problems("liveness")...)
+	problems = append(problems, h.Readiness.problems("readiness")...)
+	problems = append(problems, h.Startup.problems("startup")...)
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", conureerrors.ErrInvalidHealthCheck, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// LifecycleSettings are how the container of the component is stopped
+type LifecycleSettings struct {
+	// PreStopCommand runs in the container before it's sent SIGTERM, to drain its connections
+	PreStopCommand []string `json:"pre_stop_command,omitempty" bson:"preStopCommand,omitempty"`
+	// TerminationGracePeriodSeconds is how long the container has to stop before it's killed, the default of
+	// Kubernetes when nil
+	TerminationGracePeriodSeconds *int `json:"termination_grace_period_seconds,omitempty" bson:"terminationGracePeriodSeconds,omitempty"`
+}
+
+// Validate checks the grace period isn't negative
+func (l *LifecycleSettings) Validate() error {
+	if l.TerminationGracePeriodSeconds != nil && *l.TerminationGracePeriodSeconds < 0 {
+		return fmt.Errorf("%w: termination_grace_period_seconds can't be negative", conureerrors.ErrInvalidLifecycle)
+	}
+	return nil
+}
+
+type RolloutStrategy string
+
+const (
+	RollingUpdate RolloutStrategy = "rolling"
+	Recreate      RolloutStrategy = "recreate"
+)
+
+// RolloutSettings are how a new version of the component replaces the running one. A rolling update starts up to
+// MaxSurge new pods over the replicas and stops up to MaxUnavailable old ones at once, both a number of pods or a
+// percentage of the replicas. Recreate stops every old pod before starting the new ones.
+type RolloutSettings struct {
+	Strategy       RolloutStrategy `json:"strategy,omitempty" bson:"strategy,omitempty"`
+	MaxSurge       string          `json:"max_surge,omitempty" bson:"maxSurge,omitempty"`
+	MaxUnavailable string          `json:"max_unavailable,omitempty" bson:"maxUnavailable,omitempty"`
+}
+
+// Validate checks the strategy and its bounds, an empty rollout is a rolling update with the defaults of Kubernetes
+func (r *RolloutSettings) Validate() error {
+	var problems []string
+	switch r.Strategy {
+	case "", RollingUpdate:
+		surge, surgeErr := rolloutBound(r.MaxSurge)
+		if surgeErr != nil {
+			problems = append(problems, "max_surge "+surgeErr.Error())
+		}
+		unavailable, unavailableErr := rolloutBound(r.MaxUnavailable)
+		if unavailableErr != nil {
+			problems = append(problems, "max_unavailable "+unavailableErr.Error())
+		}
+		if surgeErr == nil && unavailableErr == nil && surge == 0 && unavailable == 0 {
+			problems = append(problems, "max_surge and max_unavailable can't both be 0")
+		}
+	case Recreate:
+		if r.MaxSurge != "" || r.MaxUnavailable != "" {
+			problems = append(problems, "max_surge and max_unavailable only apply to rolling updates")
+		}
+	default:
+		problems = append(problems, "the strategy must be rolling or recreate")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", conureerrors.ErrInvalidRollout, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// rolloutBound parses a number of pods or a percentage, -1 when it's empty and takes the default of Kubernetes
+func rolloutBound(bound string) (int, error) {
+	if bound == "" {
+		return -1, nil
+	}
+	value, err := strconv.Atoi(strings.TrimSuffix(bound, "%"))
+	if err != nil || value < 0 || (strings.HasSuffix(bound, "%") && value > 100) {
+		return 0, fmt.Errorf("must be a number of pods or a percentage, got %q", bound)
+	}
+	return value, nil
+}
+
+// Validate checks the settings which can't be checked by the binding of the request
+func (s *ComponentSettings) Validate() error {
+	return errors.Join(
+		s.ResourcesSettings.Autoscaling.Validate(),
+		s.HealthSettings.Validate(),
+		s.LifecycleSettings.Validate(),
+		s.RolloutSettings.Validate(),
+	)
 }
 
 type AccessType string
@@ -489,15 +884,31 @@ type PortSettings struct {
 	Protocol   Protocol `json:"protocol" bson:"protocol"`
 }
 
+// RouteSettings is an HTTP route to a port of the component, served by the Ingress controller or the Gateway of
+// the cluster
+type RouteSettings struct {
+	// Host is the hostname of the route, the domain template of the environment names it when it's empty
+	Host       string `json:"host,omitempty" bson:"host,omitempty"`
+	PathPrefix string `json:"path_prefix,omitempty" bson:"pathPrefix,omitempty"`
+	TargetPort int    `json:"target_port" bson:"targetPort"`
+	// TLS requests a certificate of the host from cert-manager
+	TLS bool `json:"tls" bson:"tls"`
+}
+
 type NetworkSettings struct {
-	Exposed bool           `json:"exposed" bson:"exposed"`
-	Type    AccessType     `json:"type" bson:"type"`
-	Ports   []PortSettings `json:"ports" bson:"ports"`
+	Exposed bool            `json:"exposed" bson:"exposed"`
+	Type    AccessType      `json:"type" bson:"type"`
+	Ports   []PortSettings  `json:"ports" bson:"ports"`
+	Routes  []RouteSettings `json:"routes,omitempty" bson:"routes,omitempty"`
 }
 
 type SourceSettings struct {
 	Repository string `json:"repository" bson:"repository"`
 	Command    string `json:"command" bson:"command"`
+	// GitRepository and GitBranch are the source tracked by the component, a push to the branch builds and
+	// deploys it again
+	GitRepository string `json:"git_repository,omitempty" bson:"gitRepository,omitempty"`
+	GitBranch     string `json:"git_branch,omitempty" bson:"gitBranch,omitempty"`
 }
 
 type StorageSettings struct {
@@ -511,4 +922,25 @@ type ComponentSettings struct {
 	SourceSettings    SourceSettings    `json:"source_settings" bson:"sourceSettings"`
 	NetworkSettings   NetworkSettings   `json:"network_settings" bson:"networkSettings"`
 	StorageSettings   []StorageSettings `json:"storage_settings" bson:"storageSettings"`
+	HealthSettings    HealthSettings    `json:"health_settings" bson:"healthSettings"`
+	LifecycleSettings LifecycleSettings `json:"lifecycle_settings" bson:"lifecycleSettings"`
+	RolloutSettings   RolloutSettings   `json:"rollout_settings" bson:"rolloutSettings"`
+}
+
+// OrganizationPage returns a page of the organizations owned by the account
+func OrganizationPage(db *database.MongoDB, accountID primitive.ObjectID, opts ListOptions) (*Page[Organization], error) {
+	filter := bson.M{"accountId": accountID, "status": bson.M{"$ne": OrgDeleted}}
+	return Paginate[Organization](db, OrganizationCollection, filter, OrganizationListSpec, opts)
+}
+
+// ApplicationPage returns a page of the applications of the organization
+func ApplicationPage(db *database.MongoDB, organizationID primitive.ObjectID, opts ListOptions) (*Page[Application], error) {
+	filter := bson.M{"organizationID": organizationID, "deletedAt": bson.M{"$exists": false}}
+	return Paginate[Application](db, ApplicationCollection, filter, ApplicationListSpec, opts)
+}
+
+// ComponentPage returns a page of the components of the application
+func (a *Application) ComponentPage(db *database.MongoDB, opts ListOptions) (*Page[Component], error) {
+	filter := bson.M{"applicationID": a.ID, "deletedAt": bson.M{"$exists": false}}
+	return Paginate[Component](db, ComponentCollection, filter, ComponentListSpec, opts)
 }
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"testing"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,6 +12,7 @@ import (
 )
 
 func TestOrganization_Create(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -25,6 +27,7 @@ func TestOrganization_Create(t *testing.T) {
 }
 
 func TestOrganization_GetById(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -43,6 +46,7 @@ func TestOrganization_GetById(t *testing.T) {
 }
 
 func TestOrganization_Update(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -67,6 +71,7 @@ func TestOrganization_Update(t *testing.T) {
 }
 
 func TestOrganization_Delete(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -90,6 +95,7 @@ func TestOrganization_Delete(t *testing.T) {
 }
 
 func TestOrganization_SoftDelete(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -117,6 +123,7 @@ func TestOrganization_SoftDelete(t *testing.T) {
 }
 
 func TestApplication_Create(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -138,6 +145,7 @@ func TestApplication_Create(t *testing.T) {
 }
 
 func TestApplication_GetById(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -158,6 +166,7 @@ func TestApplication_GetById(t *testing.T) {
 }
 
 func TestApplication_GetById_NotExist(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -176,6 +185,7 @@ func TestApplication_GetById_NotExist(t *testing.T) {
 }
 
 func TestApplication_Update(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -201,6 +211,7 @@ func TestApplication_Update(t *testing.T) {
 }
 
 func TestApplication_SoftDelete(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -224,6 +235,7 @@ func TestApplication_SoftDelete(t *testing.T) {
 }
 
 func Test_ApplicationList(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -255,6 +267,7 @@ func Test_ApplicationList(t *testing.T) {
 }
 
 func TestApplication_ListNotDeleted(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -292,6 +305,7 @@ func TestApplication_ListNotDeleted(t *testing.T) {
 }
 
 func TestApplication_CountComponents(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -324,6 +338,7 @@ func TestApplication_CountComponents(t *testing.T) {
 }
 
 func TestComponent_CreateList(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -356,6 +371,7 @@ func TestComponent_CreateList(t *testing.T) {
 }
 
 func TestApplication_CreateEnvironment(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -373,6 +389,7 @@ func TestApplication_CreateEnvironment(t *testing.T) {
 }
 
 func TestApplication_DeleteEnvironmentByID(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -403,6 +420,7 @@ func TestApplication_DeleteEnvironmentByID(t *testing.T) {
 }
 
 func TestApplication_DeleteEnvironmentByName(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -433,6 +451,7 @@ func TestApplication_DeleteEnvironmentByName(t *testing.T) {
 }
 
 func TestApplication_GetEnvironmentByName(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -460,6 +479,7 @@ func TestApplication_GetEnvironmentByName(t *testing.T) {
 }
 
 func TestApplication_GetEnvironmentByName_NotFound(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -479,6 +499,7 @@ func TestApplication_GetEnvironmentByName_NotFound(t *testing.T) {
 }
 
 func TestApplication_DeleteEnvironment_NotExist(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -495,6 +516,7 @@ func TestApplication_DeleteEnvironment_NotExist(t *testing.T) {
 }
 
 func TestComponent_GetByID(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -523,6 +545,7 @@ func TestComponent_GetByID(t *testing.T) {
 }
 
 func TestComponent_Create(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -542,6 +565,7 @@ func TestComponent_Create(t *testing.T) {
 }
 
 func TestComponent_Create_Duplicate(t *testing.T) {
+	SkipWithoutMongoDB(t)
 	client, err := SetupDB()
 	if err != nil {
 		t.Fatal(err)
@@ -566,3 +590,152 @@ func TestComponent_Create_Duplicate(t *testing.T) {
 	}
 	_ = comp.Delete(client)
 }
+
+func TestOrganization_TransferOwnership(t *testing.T) {
+	SkipWithoutMongoDB(t)
+	client, err := SetupDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org := &Organization{Status: OrgActive, AccountID: primitive.NewObjectID()}
+	id, err := org.Create(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := NewApplication(id, "test-app", org.AccountID.Hex())
+	if _, err = app.Create(client); err != nil {
+		t.Fatal(err)
+	}
+
+	newOwner := primitive.NewObjectID()
+	if err = org.TransferOwnership(client, newOwner); err != nil {
+		t.Errorf("Failed to transfer organization: %v", err)
+	}
+
+	got, _ := org.GetById(client, id)
+	if got.AccountID != newOwner {
+		t.Errorf("Got %v, want %v", got.AccountID, newOwner)
+	}
+	gotApp := Application{}
+	_ = gotApp.GetByID(client, app.ID.Hex())
+	if gotApp.AccountID != newOwner {
+		t.Errorf("Got %v, want %v", gotApp.AccountID, newOwner)
+	}
+}
+
+func TestOrganization_SetStatus(t *testing.T) {
+	SkipWithoutMongoDB(t)
+	client, err := SetupDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org := &Organization{Status: OrgActive, AccountID: primitive.NewObjectID()}
+	id, err := org.Create(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = org.SetStatus(client, OrgDisabled); err != nil {
+		t.Errorf("Failed to disable organization: %v", err)
+	}
+	got, _ := org.GetById(client, id)
+	if got.Status != OrgDisabled {
+		t.Errorf("Got %v, want %v", got.Status, OrgDisabled)
+	}
+	if err = org.SetStatus(client, OrgDeleted); !errors.Is(err, conureerrors.ErrInvalidRequest) {
+		t.Errorf("Got %v, want %v", err, conureerrors.ErrInvalidRequest)
+	}
+}
+
+func TestOrganization_Purge(t *testing.T) {
+	SkipWithoutMongoDB(t)
+	client, err := SetupDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org := &Organization{Status: OrgActive, AccountID: primitive.NewObjectID()}
+	if _, err = org.Create(client); err != nil {
+		t.Fatal(err)
+	}
+	variable := &Variable{Name: "TEST", Value: "test", Type: OrganizationType, OrganizationID: org.ID}
+	if _, err = variable.Create(client); err != nil {
+		t.Fatal(err)
+	}
+	if err = org.SoftDelete(client); err != nil {
+		t.Fatal(err)
+	}
+
+	// still inside the grace period
+	organizations, err := ListOrganizationsToPurge(client, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range organizations {
+		if o.ID == org.ID {
+			t.Errorf("Organization %v should not be purged yet", org.ID)
+		}
+	}
+
+	organizations, err = ListOrganizationsToPurge(client, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found *Organization
+	for i := range organizations {
+		if organizations[i].ID == org.ID {
+			found = &organizations[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("Organization %v should be purged", org.ID)
+	}
+	if err = found.Purge(client); err != nil {
+		t.Errorf("Failed to purge organization: %v", err)
+	}
+	if err = variable.GetByID(client, variable.ID.Hex()); err == nil {
+		t.Errorf("Variable %v should be purged", variable.ID)
+	}
+}
+
+func TestVariable_DeleteByComponent(t *testing.T) {
+	SkipWithoutMongoDB(t)
+	client, err := SetupDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applicationID := primitive.NewObjectID()
+	componentID := primitive.NewObjectID()
+	componentVariable := &Variable{Name: "COMPONENT", Value: "test", Type: ComponentType, OrganizationID: primitive.NewObjectID(), ApplicationID: &applicationID, ComponentID: &componentID}
+	if _, err = componentVariable.Create(client); err != nil {
+		t.Fatal(err)
+	}
+	appVariable := &Variable{Name: "APPLICATION", Value: "test", Type: EnvironmentType, OrganizationID: primitive.NewObjectID(), ApplicationID: &applicationID}
+	if _, err = appVariable.Create(client); err != nil {
+		t.Fatal(err)
+	}
+
+	variables, err := componentVariable.ListByApplication(client, applicationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(variables) != 2 {
+		t.Errorf("Got %v, want %v", len(variables), 2)
+	}
+	deleted, err := componentVariable.DeleteByComponent(client, componentID)
+	if err != nil {
+		t.Errorf("Failed to delete variables: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Got %v, want %v", deleted, 1)
+	}
+	deleted, err = appVariable.DeleteByApplication(client, applicationID)
+	if err != nil {
+		t.Errorf("Failed to delete variables: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Got %v, want %v", deleted, 1)
+	}
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/database"
+)
+
+const AuditEventCollection string = "audit_events"
+
+const (
+	AuditAccountLocked           = "auth.account_locked"
+	AuditAccountUnlocked         = "auth.account_unlocked"
+	AuditApplicationDeployed     = "application.deployed"
+	AuditEnvironmentDeleted      = "environment.deleted"
+	AuditEnvironmentUpdated      = "environment.updated"
+	AuditApplicationUpdated      = "application.updated"
+	AuditApplicationDeleted      = "application.deleted"
+	AuditComponentDeleted        = "component.deleted"
+	AuditManifestApplied         = "application.manifest_applied"
+	AuditVariableCreated         = "variable.created"
+	AuditVariableRead            = "variable.read"
+	AuditIntegrationCreated      = "integration.created"
+	AuditIntegrationUpdated      = "integration.updated"
+	AuditIntegrationDeleted      = "integration.deleted"
+	AuditOrganizationRenamed     = "organization.renamed"
+	AuditOrganizationTransferred = "organization.transferred"
+	AuditOrganizationDisabled    = "organization.disabled"
+	AuditOrganizationEnabled     = "organization.enabled"
+	AuditOrganizationDeleted     = "organization.deleted"
+	AuditOrganizationPurged      = "organization.purged"
+	// AuditRequest is recorded by the audit middleware for mutating requests without an explicit audit hook
+	AuditRequest = "http.request"
+)
+
+// AuditRecordedKey flags in the gin context the requests already audited by their handler
+const AuditRecordedKey = "auditRecorded"
+
+// AuditEvent is an append-only record of a security relevant action, events are never updated nor deleted
+type AuditEvent struct {
+	ID             primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	OrganizationID primitive.ObjectID     `bson:"organizationId,omitempty" json:"organization_id,omitempty"`
+	ActorID        primitive.ObjectID     `bson:"actorId,omitempty" json:"actor_id,omitempty"`
+	ActorEmail     string                 `bson:"actorEmail,omitempty" json:"actor_email,omitempty"`
+	Action         string                 `bson:"action" json:"action"`
+	TargetType     string                 `bson:"targetType,omitempty" json:"target_type,omitempty"`
+	TargetID       string                 `bson:"targetId,omitempty" json:"target_id,omitempty"`
+	Before         map[string]interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After          map[string]interface{} `bson:"after,omitempty" json:"after,omitempty"`
+	IP             string                 `bson:"ip,omitempty" json:"ip,omitempty"`
+	RequestID      string                 `bson:"requestId,omitempty" json:"request_id,omitempty"`
+	Method         string                 `bson:"method,omitempty" json:"method,omitempty"`
+	Path           string                 `bson:"path,omitempty" json:"path,omitempty"`
+	StatusCode     int                    `bson:"statusCode,omitempty" json:"status_code,omitempty"`
+	Metadata       map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	CreatedAt      time.Time              `bson:"createdAt" json:"created_at"`
+}
+
+func (e *AuditEvent) GetCollectionName() string {
+	return AuditEventCollection
+}
+
+func (e *AuditEvent) Create(db *database.MongoDB) error {
+	collection := db.Client.Database(db.DBName).Collection(AuditEventCollection)
+	e.ID = primitive.NewObjectID()
+	e.CreatedAt = time.Now()
+	_, err := collection.InsertOne(db.Context(), e)
+	return err
+}
+
+type AuditEventFilter struct {
+	OrganizationID primitive.ObjectID
+	Action         string
+	ActorID        primitive.ObjectID
+	TargetType     string
+	TargetID       string
+	Since          *time.Time
+	Until          *time.Time
+}
+
+func (f *AuditEventFilter) toBson() bson.M {
+	filter := bson.M{"organizationId": f.OrganizationID}
+	if f.Action != "" {
+		filter["action"] = f.Action
+	}
+	if !f.ActorID.IsZero() {
+		filter["actorId"] = f.ActorID
+	}
+	if f.TargetType != "" {
+		filter["targetType"] = f.TargetType
+	}
+	if f.TargetID != "" {
+		filter["targetId"] = f.TargetID
+	}
+	createdAt := bson.M{}
+	if f.Since != nil {
+		createdAt["$gte"] = *f.Since
+	}
+	if f.Until != nil {
+		createdAt["$lt"] = *f.Until
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+	return filter
+}
+
+// ListAuditEvents returns a page of the matching events, newest first, and the total amount of matching events
+func ListAuditEvents(db *database.MongoDB, filter AuditEventFilter, opts ListOptions) (*Page[AuditEvent], int64, error) {
+	collection := db.Client.Database(db.DBName).Collection(AuditEventCollection)
+	query := filter.toBson()
+	total, err := collection.CountDocuments(db.Context(), query)
+	if err != nil {
+		return nil, 0, err
+	}
+	page, err := Paginate[AuditEvent](db, AuditEventCollection, query, AuditEventListSpec, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return page, total, nil
+}
@@ -1,8 +1,8 @@
 package models
 
 import (
-	"context"
 	"regexp"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -15,14 +15,20 @@ import (
 const UserCollection string = "users"
 
 type User struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Email       string             `bson:"email" json:"email"`
-	Password    string             `bson:"password" json:"-"`
-	IsActive    bool               `bson:"isActive" json:"is_active"`
-	LastLoginAt *time.Time         `bson:"lastLoginAt,omitempty" json:"last_login_at"`
-	CreatedAt   time.Time          `bson:"createdAt" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updatedAt" json:"updated_at"`
-	Client      string             `bson:"client,omitempty" json:"client"`
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email               string             `bson:"email" json:"email"`
+	Password            string             `bson:"password" json:"-"`
+	IsActive            bool               `bson:"isActive" json:"is_active"`
+	IsAdmin             bool               `bson:"isAdmin,omitempty" json:"is_admin"`
+	PendingVerification bool               `bson:"pendingVerification,omitempty" json:"pending_verification"`
+	TwoFactorEnabled    bool               `bson:"twoFactorEnabled,omitempty" json:"two_factor_enabled"`
+	TOTPSecret          string             `bson:"totpSecret,omitempty" json:"-"`
+	TOTPLastStep        int64              `bson:"totpLastStep,omitempty" json:"-"`
+	RecoveryCodes       []string           `bson:"recoveryCodes,omitempty" json:"-"`
+	LastLoginAt         *time.Time         `bson:"lastLoginAt,omitempty" json:"last_login_at"`
+	CreatedAt           time.Time          `bson:"createdAt" json:"created_at"`
+	UpdatedAt           time.Time          `bson:"updatedAt" json:"updated_at"`
+	Client              string             `bson:"client,omitempty" json:"client"`
 }
 
 func (u *User) Create(mongo *database.MongoDB) error {
@@ -30,16 +36,20 @@ func (u *User) Create(mongo *database.MongoDB) error {
 	u.CreatedAt = time.Now()
 	u.UpdatedAt = u.CreatedAt
 	u.IsActive = true
+	u.Email = NormalizeEmail(u.Email)
 
 	// check if email exists
 	filter := bson.M{"email": u.Email}
-	err := collection.FindOne(context.Background(), filter).Decode(u)
+	err := collection.FindOne(mongo.Context(), filter).Decode(u)
 	if err == nil {
 		return conureerrors.ErrEmailAlreadyExists
 	}
 
-	insertResult, err := collection.InsertOne(context.Background(), u)
-	if err != nil {
+	insertResult, err := collection.InsertOne(mongo.Context(), u)
+	if isDuplicate(err) {
+		// registered meanwhile by a concurrent request
+		return conureerrors.ErrEmailAlreadyExists
+	} else if err != nil {
 		return err
 	}
 	u.ID = insertResult.InsertedID.(primitive.ObjectID)
@@ -50,7 +60,7 @@ func (u *User) GetById(mongo *database.MongoDB, id string) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
 	IDHex, _ := primitive.ObjectIDFromHex(id)
 	filter := bson.M{"_id": IDHex, "isActive": true}
-	err := collection.FindOne(context.Background(), filter).Decode(u)
+	err := collection.FindOne(mongo.Context(), filter).Decode(u)
 	if err != nil {
 		return err
 	}
@@ -59,8 +69,8 @@ func (u *User) GetById(mongo *database.MongoDB, id string) error {
 
 func (u *User) GetByEmail(mongo *database.MongoDB, email string) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
-	filter := bson.M{"email": email, "isActive": true}
-	err := collection.FindOne(context.Background(), filter).Decode(u)
+	filter := bson.M{"email": NormalizeEmail(email), "isActive": true}
+	err := collection.FindOne(mongo.Context(), filter).Decode(u)
 	if err != nil {
 		return err
 	}
@@ -73,7 +83,7 @@ func (u *User) UpdatePassword(mongo *database.MongoDB, password string) error {
 	u.Password = password
 	filter := bson.M{"_id": u.ID}
 	update := bson.M{"$set": bson.M{"password": u.Password, "updatedAt": u.UpdatedAt}}
-	_, err := collection.UpdateOne(context.Background(), filter, update)
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
 	if err != nil {
 		return err
 	}
@@ -86,23 +96,158 @@ func (u *User) UpdateLastLoginAt(mongo *database.MongoDB) error {
 	u.LastLoginAt = &now
 	filter := bson.M{"_id": u.ID}
 	update := bson.M{"$set": bson.M{"lastLoginAt": u.LastLoginAt}}
-	_, err := collection.UpdateOne(context.Background(), filter, update)
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *User) MarkEmailVerified(mongo *database.MongoDB) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
+	u.UpdatedAt = time.Now()
+	u.PendingVerification = false
+	filter := bson.M{"_id": u.ID}
+	update := bson.M{"$set": bson.M{"updatedAt": u.UpdatedAt}, "$unset": bson.M{"pendingVerification": ""}}
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *User) SetAdmin(mongo *database.MongoDB) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
+	u.UpdatedAt = time.Now()
+	u.IsAdmin = true
+	filter := bson.M{"_id": u.ID}
+	update := bson.M{"$set": bson.M{"isAdmin": u.IsAdmin, "updatedAt": u.UpdatedAt}}
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetTOTPSecret stores the encrypted secret of a pending enrollment, 2FA is enabled once a code is verified
+func (u *User) SetTOTPSecret(mongo *database.MongoDB, secret string) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
+	u.UpdatedAt = time.Now()
+	u.TOTPSecret = secret
+	filter := bson.M{"_id": u.ID, "twoFactorEnabled": bson.M{"$ne": true}}
+	update := bson.M{"$set": bson.M{"totpSecret": u.TOTPSecret, "updatedAt": u.UpdatedAt}}
+	result, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return conureerrors.ErrTwoFactorAlreadyEnabled
+	}
+	return nil
+}
+
+func (u *User) EnableTwoFactor(mongo *database.MongoDB, recoveryCodes []string) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
+	u.UpdatedAt = time.Now()
+	u.TwoFactorEnabled = true
+	u.RecoveryCodes = recoveryCodes
+	filter := bson.M{"_id": u.ID}
+	update := bson.M{"$set": bson.M{
+		"twoFactorEnabled": u.TwoFactorEnabled,
+		"recoveryCodes":    u.RecoveryCodes,
+		"totpLastStep":     u.TOTPLastStep,
+		"updatedAt":        u.UpdatedAt,
+	}}
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+func (u *User) DisableTwoFactor(mongo *database.MongoDB) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
+	u.UpdatedAt = time.Now()
+	u.TwoFactorEnabled = false
+	u.TOTPSecret = ""
+	u.TOTPLastStep = 0
+	u.RecoveryCodes = nil
+	filter := bson.M{"_id": u.ID}
+	update := bson.M{
+		"$set":   bson.M{"updatedAt": u.UpdatedAt},
+		"$unset": bson.M{"twoFactorEnabled": "", "totpSecret": "", "totpLastStep": "", "recoveryCodes": ""},
+	}
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *User) SetRecoveryCodes(mongo *database.MongoDB, recoveryCodes []string) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
+	u.UpdatedAt = time.Now()
+	u.RecoveryCodes = recoveryCodes
+	filter := bson.M{"_id": u.ID}
+	update := bson.M{"$set": bson.M{"recoveryCodes": u.RecoveryCodes, "updatedAt": u.UpdatedAt}}
+	_, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted code, it fails if the step or a later one was already used so
+// a code cannot be replayed
+func (u *User) UseTOTPStep(mongo *database.MongoDB, step int64) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
+	filter := bson.M{"_id": u.ID, "$or": bson.A{
+		bson.M{"totpLastStep": bson.M{"$exists": false}},
+		bson.M{"totpLastStep": bson.M{"$lt": step}},
+	}}
+	update := bson.M{"$set": bson.M{"totpLastStep": step}}
+	result, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return conureerrors.ErrInvalidTwoFactorCode
+	}
+	u.TOTPLastStep = step
+	return nil
+}
+
+// UseRecoveryCode removes the hashed recovery code, each code can be used only once
+func (u *User) UseRecoveryCode(mongo *database.MongoDB, hashedCode string) error {
+	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
+	filter := bson.M{"_id": u.ID, "recoveryCodes": hashedCode}
+	update := bson.M{"$pull": bson.M{"recoveryCodes": hashedCode}}
+	result, err := collection.UpdateOne(mongo.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return conureerrors.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
 func (u *User) Delete(mongo *database.MongoDB) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(UserCollection)
 	filter := bson.M{"_id": u.ID}
-	_, err := collection.DeleteOne(context.Background(), filter)
+	_, err := collection.DeleteOne(mongo.Context(), filter)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// NormalizeEmail lowercases the emails stored and looked up, so an address can't be registered twice in a different
+// case
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func ValidateEmail(email string) error {
 	pattern := `^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`
 	if matched, err := regexp.MatchString(pattern, email); err != nil {
@@ -114,3 +114,9 @@ func TestValidatePasswords(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeEmail(t *testing.T) {
+	if got := NormalizeEmail(" Test@Test.COM "); got != "test@test.com" {
+		t.Errorf("NormalizeEmail() = %q, want %q", got, "test@test.com")
+	}
+}
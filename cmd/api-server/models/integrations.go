@@ -1,8 +1,6 @@
 package models
 
 import (
-	"context"
-
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
@@ -17,6 +15,8 @@ type Integration struct {
 	IntegrationType  string             `json:"integration_type" bson:"integrationType"`
 	Name             string             `json:"name" bson:"name"`
 	IntegrationValue interface{}        `json:"-" bson:"integrationValue"`
+	// Fields are the fields of the value which aren't secret, they are only in the API responses
+	Fields map[string]interface{} `json:"fields,omitempty" bson:"-"`
 }
 
 func (i *Integration) GetCollectionName() string {
@@ -24,41 +24,42 @@ func (i *Integration) GetCollectionName() string {
 }
 
 func (i *Integration) Create(db *database.MongoDB) error {
-	err := Create(context.Background(), db, i)
+	err := Create(db.Context(), db, i)
 	return err
 }
 
 func (i *Integration) Delete(db *database.MongoDB) error {
-	err := Delete(context.Background(), db, i)
+	err := Delete(db.Context(), db, i)
 	return err
 }
 
 func (i *Integration) GetByID(db *database.MongoDB, ID string) error {
-	err := GetByID(context.Background(), db, ID, i)
+	err := GetByID(db.Context(), db, ID, i)
 	return err
 }
 
 func (i *Integration) Update(db *database.MongoDB) error {
-	err := Update(context.Background(), db, i)
+	err := Update(db.Context(), db, i)
 	return err
 }
 
 func (i *Integration) ListIntegrations(db *database.MongoDB) ([]Integration, error) {
 	collection := db.Client.Database(db.DBName).Collection(IntegrationCollection)
 	filter := bson.M{"organizationID": i.OrganizationID}
-	cursor, err := collection.Find(context.Background(), filter)
+	cursor, err := collection.Find(db.Context(), filter)
 	if err != nil {
 		return nil, err
 	}
 	var integrations = make([]Integration, 0)
-	err = cursor.All(context.Background(), &integrations)
+	err = cursor.All(db.Context(), &integrations)
 	if err != nil {
 		return nil, err
 	}
 	return integrations, nil
 }
 
-type IntegrationTypeInterface interface {
-	EncryptValues() error
-	DecryptValues() error
+// IntegrationPage returns a page of the integrations of the organization
+func (i *Integration) IntegrationPage(db *database.MongoDB, opts ListOptions) (*Page[Integration], error) {
+	filter := bson.M{"organizationID": i.OrganizationID}
+	return Paginate[Integration](db, IntegrationCollection, filter, IntegrationListSpec, opts)
 }
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/coffeenights/conure/cmd/api-server/database"
+)
+
+const LoginAttemptCollection string = "login_attempts"
+
+// LoginAttempt keeps track of the consecutive failed logins for a key, which is either an account or an IP address
+type LoginAttempt struct {
+	Key           string     `bson:"key" json:"key"`
+	Failures      int        `bson:"failures" json:"failures"`
+	LastFailureAt time.Time  `bson:"lastFailureAt" json:"last_failure_at"`
+	LockedUntil   *time.Time `bson:"lockedUntil,omitempty" json:"locked_until"`
+}
+
+func AccountLoginKey(email string) string {
+	return "account:" + NormalizeEmail(email)
+}
+
+func IPLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && a.LockedUntil.After(now)
+}
+
+// GetLoginAttempt returns the attempts registered for the key, an empty attempt is returned if there is none
+func GetLoginAttempt(db *database.MongoDB, key string) (*LoginAttempt, error) {
+	collection := db.Client.Database(db.DBName).Collection(LoginAttemptCollection)
+	attempt := &LoginAttempt{Key: key}
+	err := collection.FindOne(db.Context(), bson.M{"key": key}).Decode(attempt)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// RegisterLoginFailure atomically increments the failures for the key and returns the updated attempt
+func RegisterLoginFailure(db *database.MongoDB, key string) (*LoginAttempt, error) {
+	collection := db.Client.Database(db.DBName).Collection(LoginAttemptCollection)
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{"lastFailureAt": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	attempt := &LoginAttempt{}
+	err := collection.FindOneAndUpdate(db.Context(), bson.M{"key": key}, update, opts).Decode(attempt)
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+func (a *LoginAttempt) Lock(db *database.MongoDB, until time.Time) error {
+	collection := db.Client.Database(db.DBName).Collection(LoginAttemptCollection)
+	a.LockedUntil = &until
+	_, err := collection.UpdateOne(db.Context(), bson.M{"key": a.Key}, bson.M{"$set": bson.M{"lockedUntil": until}})
+	return err
+}
+
+// ResetLoginAttempts forgets the failures and the lock of the key
+func ResetLoginAttempts(db *database.MongoDB, key string) error {
+	collection := db.Client.Database(db.DBName).Collection(LoginAttemptCollection)
+	_, err := collection.DeleteOne(db.Context(), bson.M{"key": key})
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 	"time"
 )
@@ -43,6 +44,9 @@ type ModelInterface interface {
 	GetID() primitive.ObjectID
 }
 
+// The generic operations run with ctx, the Context of the tx given by RunInTransaction makes them part of its
+// transaction. A session context of the driver, mongo.SessionContext, works the same.
+
 func GetByID(ctx context.Context, db *database.MongoDB, ID string, model ModelInterface) error {
 	collection := db.Client.Database(db.DBName).Collection(model.GetCollectionName())
 	oID, err := primitive.ObjectIDFromHex(ID)
@@ -67,14 +71,7 @@ func Create(ctx context.Context, db *database.MongoDB, model ModelInterface) err
 	model.SetID(primitive.NewObjectID())
 	insertResult, err := collection.InsertOne(ctx, model)
 	if err != nil {
-		var writeException mongo.WriteException
-		switch {
-		case errors.As(err, &writeException):
-			if err.(mongo.WriteException).WriteErrors[0].Code == 11000 {
-				return conureerrors.ErrObjectAlreadyExists
-			}
-		}
-		return err
+		return duplicateAsExisting(err)
 	}
 	model.SetID(insertResult.InsertedID.(primitive.ObjectID))
 	log.Println("Inserted a single document: ", insertResult.InsertedID.(primitive.ObjectID).Hex())
@@ -90,7 +87,7 @@ func Update(ctx context.Context, db *database.MongoDB, model ModelInterface) err
 	model.SetUpdatedAt(time.Now())
 	updateResult, err := collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return err
+		return duplicateAsExisting(err)
 	}
 	log.Printf("Matched %v documents and updated %v documents.\n", updateResult.MatchedCount, updateResult.ModifiedCount)
 	return nil
@@ -122,3 +119,22 @@ func SoftDelete(ctx context.Context, db *database.MongoDB, model ModelInterface)
 	log.Printf("Matched %v documents and deleted %v documents.\n", updateResult.MatchedCount, updateResult.ModifiedCount)
 	return nil
 }
+
+// RestoreDocument writes back a previous version of a document, it's inserted again if it was deleted
+func RestoreDocument(ctx context.Context, db *database.MongoDB, collectionName string, ID primitive.ObjectID, document interface{}) error {
+	collection := db.Client.Database(db.DBName).Collection(collectionName)
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": ID}, document, options.Replace().SetUpsert(true))
+	return err
+}
+
+// duplicateAsExisting maps the violations of the unique indexes created by the migrations to ErrObjectAlreadyExists
+func duplicateAsExisting(err error) error {
+	if isDuplicate(err) {
+		return conureerrors.ErrObjectAlreadyExists
+	}
+	return err
+}
+
+func isDuplicate(err error) bool {
+	return mongo.IsDuplicateKeyError(err)
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// ListOptions holds the pagination, sorting and filtering parameters of a list query
+type ListOptions struct {
+	Limit int64
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+	// Sort is the API name of the sort field, prefixed with "-" for a descending order
+	Sort string
+	// Filters are keyed by API name, only the fields declared in the ListSpec are accepted
+	Filters map[string]string
+}
+
+// ListFilter maps an API filter to a document field, Prefix filters match the beginning of the value
+type ListFilter struct {
+	Field  string
+	Prefix bool
+}
+
+// ListSpec declares the fields a list can be sorted and filtered by
+type ListSpec struct {
+	SortFields  map[string]string
+	Filters     map[string]ListFilter
+	DefaultSort string
+}
+
+type Page[T any] struct {
+	Items []T
+	// NextCursor is empty when there are no more items
+	NextCursor string
+}
+
+// The specs of the list endpoints, all of them can be filtered by name prefix
+var (
+	OrganizationListSpec = ListSpec{
+		SortFields:  map[string]string{"name": "name", "created_at": "createdAt"},
+		Filters:     map[string]ListFilter{"name_prefix": {Field: "name", Prefix: true}, "status": {Field: "status"}},
+		DefaultSort: "name",
+	}
+	ApplicationListSpec = ListSpec{
+		SortFields:  map[string]string{"name": "name", "created_at": "createdAt"},
+		Filters:     map[string]ListFilter{"name_prefix": {Field: "name", Prefix: true}},
+		DefaultSort: "name",
+	}
+	ComponentListSpec = ListSpec{
+		SortFields:  map[string]string{"name": "name", "type": "type", "created_at": "createdAt"},
+		Filters:     map[string]ListFilter{"name_prefix": {Field: "name", Prefix: true}, "type": {Field: "type"}},
+		DefaultSort: "name",
+	}
+	VariableListSpec = ListSpec{
+		SortFields:  map[string]string{"name": "name", "created_at": "createdAt", "updated_at": "updatedAt"},
+		Filters:     map[string]ListFilter{"name_prefix": {Field: "name", Prefix: true}, "scope": {Field: "type"}},
+		DefaultSort: "name",
+	}
+	IntegrationListSpec = ListSpec{
+		SortFields:  map[string]string{"name": "name", "integration_type": "integrationType", "created_at": "createdAt"},
+		Filters:     map[string]ListFilter{"name_prefix": {Field: "name", Prefix: true}, "integration_type": {Field: "integrationType"}},
+		DefaultSort: "name",
+	}
+	AuditEventListSpec = ListSpec{
+		SortFields:  map[string]string{"created_at": "createdAt"},
+		DefaultSort: "-created_at",
+	}
+)
+
+// pageCursor is the position after the last item of a page, the _id breaks the ties between equal sort values
+type pageCursor struct {
+	Value interface{}        `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+func encodeCursor(cursor pageCursor) (string, error) {
+	data, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, conureerrors.ErrInvalidCursor
+	}
+	cursor := &pageCursor{}
+	if err = bson.Unmarshal(data, cursor); err != nil || cursor.ID.IsZero() {
+		return nil, conureerrors.ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// resolveSort returns the document field to sort by and the sort direction
+func (s *ListSpec) resolveSort(sort string) (string, int, error) {
+	if sort == "" {
+		sort = s.DefaultSort
+	}
+	direction := 1
+	if strings.HasPrefix(sort, "-") {
+		direction = -1
+		sort = sort[1:]
+	}
+	field, ok := s.SortFields[sort]
+	if !ok {
+		return "", 0, conureerrors.ErrInvalidSortField
+	}
+	return field, direction, nil
+}
+
+// filterConditions converts the filters of the options to query conditions, unknown filters are rejected
+func (s *ListSpec) filterConditions(filters map[string]string) (bson.A, error) {
+	conditions := bson.A{}
+	for name, value := range filters {
+		if value == "" {
+			continue
+		}
+		filter, ok := s.Filters[name]
+		if !ok {
+			return nil, conureerrors.ErrInvalidRequest
+		}
+		if filter.Prefix {
+			conditions = append(conditions, bson.M{filter.Field: bson.M{"$regex": "^" + regexp.QuoteMeta(value)}})
+		} else {
+			conditions = append(conditions, bson.M{filter.Field: value})
+		}
+	}
+	return conditions, nil
+}
+
+// Paginate runs a keyset paginated query on the collection, sorted by the requested field and then by _id so the
+// pages stay stable while documents are inserted
+func Paginate[T any](db *database.MongoDB, collectionName string, query bson.M, spec ListSpec, opts ListOptions) (*Page[T], error) {
+	field, direction, err := spec.resolveSort(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	// the filters are added as separate conditions so they can't widen the base query
+	conditions, err := spec.filterConditions(opts.Filters)
+	if err != nil {
+		return nil, err
+	}
+	conditions = append(bson.A{query}, conditions...)
+	if opts.Cursor != "" {
+		cursor, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		operator := "$gt"
+		if direction < 0 {
+			operator = "$lt"
+		}
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{field: bson.M{operator: cursor.Value}},
+			bson.M{field: cursor.Value, "_id": bson.M{operator: cursor.ID}},
+		}})
+	}
+	filter := bson.M{"$and": conditions}
+
+	collection := db.Client.Database(db.DBName).Collection(collectionName)
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}).
+		// one more item tells whether there is a next page
+		SetLimit(limit + 1)
+	cursor, err := collection.Find(db.Context(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		err = cursor.Close(ctx)
+		if err != nil {
+			log.Printf("Error closing cursor: %v\n", err)
+		}
+	}(cursor, db.Context())
+	var documents []bson.Raw
+	if err = cursor.All(db.Context(), &documents); err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: make([]T, 0, len(documents))}
+	if int64(len(documents)) > limit {
+		documents = documents[:limit]
+		last := documents[len(documents)-1]
+		next := pageCursor{}
+		if value, err := last.LookupErr(strings.Split(field, ".")...); err == nil {
+			next.Value = value
+		}
+		next.ID, _ = last.Lookup("_id").ObjectIDOK()
+		if page.NextCursor, err = encodeCursor(next); err != nil {
+			return nil, err
+		}
+	}
+	for _, document := range documents {
+		var item T
+		if err = bson.Unmarshal(document, &item); err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	return page, nil
+}
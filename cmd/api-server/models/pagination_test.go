@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	id := primitive.NewObjectID()
+	encoded, err := encodeCursor(pageCursor{Value: "app", ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor.Value != "app" || cursor.ID != id {
+		t.Errorf("Got %v, want %v", cursor, pageCursor{Value: "app", ID: id})
+	}
+	if _, err = decodeCursor("not a cursor"); !errors.Is(err, conureerrors.ErrInvalidCursor) {
+		t.Errorf("Got %v, want %v", err, conureerrors.ErrInvalidCursor)
+	}
+}
+
+func TestListSpec_ResolveSort(t *testing.T) {
+	field, direction, err := ComponentListSpec.resolveSort("-created_at")
+	if err != nil || field != "createdAt" || direction != -1 {
+		t.Errorf("Got %v %v %v, want createdAt -1", field, direction, err)
+	}
+	field, direction, err = ComponentListSpec.resolveSort("")
+	if err != nil || field != "name" || direction != 1 {
+		t.Errorf("Got %v %v %v, want name 1", field, direction, err)
+	}
+	if _, _, err = ComponentListSpec.resolveSort("password"); !errors.Is(err, conureerrors.ErrInvalidSortField) {
+		t.Errorf("Got %v, want %v", err, conureerrors.ErrInvalidSortField)
+	}
+}
+
+func TestListSpec_FilterConditions(t *testing.T) {
+	conditions, err := ComponentListSpec.filterConditions(map[string]string{"name_prefix": "a.b", "type": "service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conditions) != 2 {
+		t.Errorf("Got %v, want 2 conditions", conditions)
+	}
+	if _, err = ComponentListSpec.filterConditions(map[string]string{"accountId": "x"}); !errors.Is(err, conureerrors.ErrInvalidRequest) {
+		t.Errorf("Got %v, want %v", err, conureerrors.ErrInvalidRequest)
+	}
+}
+
+func TestApplicationPage(t *testing.T) {
+	SkipWithoutMongoDB(t)
+	client, err := SetupDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orgID := primitive.NewObjectID()
+	for i := 0; i < 5; i++ {
+		app := NewApplication(orgID.Hex(), fmt.Sprintf("app-%d", i), primitive.NewObjectID().Hex())
+		if _, err = app.Create(client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := NewApplication(orgID.Hex(), "other", primitive.NewObjectID().Hex())
+	if _, err = other.Create(client); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	opts := ListOptions{Limit: 2, Filters: map[string]string{"name_prefix": "app-"}}
+	for {
+		page, err := ApplicationPage(client, orgID, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, app := range page.Items {
+			names = append(names, app.Name)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	want := []string{"app-0", "app-1", "app-2", "app-3", "app-4"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("Got %v, want %v", names, want)
+	}
+
+	page, err := ApplicationPage(client, orgID, ListOptions{Limit: 1, Sort: "-name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Name != "other" {
+		t.Errorf("Got %v, want other", page.Items)
+	}
+}
@@ -1,20 +1,38 @@
 package models
 
 import (
+	"errors"
+	"os"
+	"testing"
+
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/database"
 	"github.com/coffeenights/conure/internal/config"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrNoMongoDB is returned by SetupDB when API_MONGODB_URI is unset
+var ErrNoMongoDB = errors.New("API_MONGODB_URI is unset, the test needs a MongoDB")
+
+// SkipWithoutMongoDB skips the test when API_MONGODB_URI is unset, so the tests pass on a checkout without a MongoDB
+func SkipWithoutMongoDB(t testing.TB) {
+	t.Helper()
+	if os.Getenv("API_MONGODB_URI") == "" {
+		t.Skip(ErrNoMongoDB)
+	}
+}
+
 func SetupDB() (*database.MongoDB, error) {
+	if os.Getenv("API_MONGODB_URI") == "" {
+		return nil, ErrNoMongoDB
+	}
 	appConfig := config.LoadConfig(apiConfig.Config{})
 	testDBName := appConfig.MongoDBName + "-test"
-	client, err := database.ConnectToMongoDB(appConfig.MongoDBURI, testDBName)
+	db, err := database.ConnectToMongoDB(appConfig.MongoDBURI, testDBName)
 	if err != nil {
 		return nil, err
 	}
-	return &database.MongoDB{Client: client.Client, DBName: testDBName}, nil
+	return db, nil
 }
 
 func ComponentTemplate(appID primitive.ObjectID, name string) *Component {
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+)
+
+const UserTokenCollection = "user_tokens"
+
+type TokenPurpose string
+
+const (
+	EmailVerificationPurpose TokenPurpose = "email_verification"
+	PasswordResetPurpose     TokenPurpose = "password_reset"
+	InvitationPurpose        TokenPurpose = "invitation"
+	// TwoFactorChallengePurpose tokens are never stored, they only carry the user between the two login steps
+	TwoFactorChallengePurpose TokenPurpose = "two_factor_challenge"
+)
+
+// UserToken is the server side record of a single-use token sent by email. Only the signed reference to this
+// document travels to the user, the token is consumed by setting UsedAt.
+type UserToken struct {
+	Model     `bson:",inline"`
+	Purpose   TokenPurpose       `json:"purpose" bson:"purpose"`
+	Email     string             `json:"email" bson:"email"`
+	UserID    primitive.ObjectID `json:"user_id,omitempty" bson:"userID,omitempty"`
+	CreatedBy primitive.ObjectID `json:"created_by,omitempty" bson:"createdBy,omitempty"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expiresAt"`
+	UsedAt    *time.Time         `json:"used_at,omitempty" bson:"usedAt,omitempty"`
+}
+
+func NewUserToken(purpose TokenPurpose, email string, ttl time.Duration) *UserToken {
+	return &UserToken{
+		Purpose:   purpose,
+		Email:     NormalizeEmail(email),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
+func (t *UserToken) GetCollectionName() string {
+	return UserTokenCollection
+}
+
+func (t *UserToken) Create(db *database.MongoDB) error {
+	return Create(db.Context(), db, t)
+}
+
+func (t *UserToken) GetByID(db *database.MongoDB, ID string) error {
+	return GetByID(db.Context(), db, ID, t)
+}
+
+func (t *UserToken) IsValid(purpose TokenPurpose) bool {
+	return t.Purpose == purpose && t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+// Consume marks the token as used. The update only matches tokens that were not used before, so two concurrent
+// requests cannot consume the same token.
+func (t *UserToken) Consume(db *database.MongoDB) error {
+	collection := db.Client.Database(db.DBName).Collection(UserTokenCollection)
+	now := time.Now()
+	filter := bson.M{"_id": t.ID, "usedAt": bson.M{"$exists": false}, "expiresAt": bson.M{"$gt": now}}
+	update := bson.M{"$set": bson.M{"usedAt": now}}
+	updateResult, err := collection.UpdateOne(db.Context(), filter, update)
+	if err != nil {
+		return err
+	}
+	if updateResult.ModifiedCount == 0 {
+		return conureerrors.ErrInvalidOrExpiredToken
+	}
+	t.UsedAt = &now
+	return nil
+}
+
+// RevokeUserTokens consumes every pending token with the given purpose for an email address.
+func RevokeUserTokens(db *database.MongoDB, email string, purpose TokenPurpose) error {
+	collection := db.Client.Database(db.DBName).Collection(UserTokenCollection)
+	filter := bson.M{"email": NormalizeEmail(email), "purpose": purpose, "usedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"usedAt": time.Now()}}
+	_, err := collection.UpdateMany(db.Context(), filter, update)
+	return err
+}
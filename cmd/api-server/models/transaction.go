@@ -0,0 +1,35 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/coffeenights/conure/cmd/api-server/database"
+)
+
+// RunInTransaction runs fn as a unit of work: its writes are committed together when it returns nil, and discarded
+// when it returns an error. fn must make its operations with tx, a copy of db scoped to the session, the ones made
+// with db are outside the transaction. fn is run again on the transient errors of the transaction, so it must not
+// have effects outside the database.
+//
+// A call made within fn joins the running transaction. A standalone server has no transactions, it returns
+// database.ErrNoTransactions unless db allows it, fn then runs with db and its writes are applied one by one.
+func RunInTransaction(db *database.MongoDB, fn func(tx *database.MongoDB) error) error {
+	if db.InTransaction() {
+		return fn(db)
+	}
+	if !db.SupportsTransactions() {
+		if !db.AllowsStandalone() {
+			return database.ErrNoTransactions
+		}
+		return fn(db)
+	}
+	session, err := db.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(db.Context())
+	_, err = session.WithTransaction(db.Context(), func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(db.WithContext(sessionCtx))
+	})
+	return err
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	_ "github.com/joho/godotenv/autoload"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+)
+
+func setupReplicaSet(t *testing.T) *database.MongoDB {
+	t.Helper()
+	client, err := SetupDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.SupportsTransactions() {
+		t.Fatal("The transactions need a replica set, run the mongo of docker-compose.yml")
+	}
+	return client
+}
+
+func TestRunInTransaction_Standalone(t *testing.T) {
+	// the zero value is a standalone server
+	db := &database.MongoDB{}
+	called := false
+	err := RunInTransaction(db, func(tx *database.MongoDB) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, database.ErrNoTransactions) || called {
+		t.Errorf("Got %v and called %v, want %v without calling fn", err, called, database.ErrNoTransactions)
+	}
+
+	db.AllowStandalone()
+	if err = RunInTransaction(db, func(tx *database.MongoDB) error {
+		called = tx == db
+		return nil
+	}); err != nil || !called {
+		t.Errorf("Got %v and called %v, want fn called with db", err, called)
+	}
+}
+
+func TestRunInTransaction_Commit(t *testing.T) {
+	SkipWithoutMongoDB(t)
+	client := setupReplicaSet(t)
+	org := &Organization{AccountID: primitive.NewObjectID(), Name: "TestRunInTransactionCommit"}
+	var app *Application
+	err := RunInTransaction(client, func(tx *database.MongoDB) error {
+		if !tx.InTransaction() {
+			t.Error("Expected tx to be in the transaction")
+		}
+		if _, err := org.Create(tx); err != nil {
+			return err
+		}
+		var err error
+		app, err = NewApplication(org.ID.Hex(), "TestRunInTransactionCommit", primitive.NewObjectID().Hex()).Create(tx)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer org.Delete(client)
+	defer app.Delete(client)
+
+	if _, err = (&Organization{}).GetById(client, org.ID.Hex()); err != nil {
+		t.Errorf("Expected the organization committed, got: %v", err)
+	}
+	if err = (&Application{}).GetByID(client, app.ID.Hex()); err != nil {
+		t.Errorf("Expected the application committed, got: %v", err)
+	}
+}
+
+func TestRunInTransaction_Abort(t *testing.T) {
+	SkipWithoutMongoDB(t)
+	client := setupReplicaSet(t)
+	org := &Organization{AccountID: primitive.NewObjectID(), Name: "TestRunInTransactionAbort"}
+	failure := errors.New("failure")
+	err := RunInTransaction(client, func(tx *database.MongoDB) error {
+		if _, err := org.Create(tx); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the error of the unit of work, got: %v", err)
+	}
+	if _, err = (&Organization{}).GetById(client, org.ID.Hex()); err == nil {
+		_ = org.Delete(client)
+		t.Error("Expected the organization discarded")
+	}
+}
+
+func TestRunInTransaction_Nested(t *testing.T) {
+	SkipWithoutMongoDB(t)
+	client := setupReplicaSet(t)
+	app, err := NewApplication(primitive.NewObjectID().Hex(), "TestRunInTransactionNested", primitive.NewObjectID().Hex()).Create(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Delete(client)
+
+	err = RunInTransaction(client, func(tx *database.MongoDB) error {
+		if err := app.SoftDelete(tx); err != nil {
+			return err
+		}
+		// joins the transaction, its failure discards the soft delete too
+		return RunInTransaction(tx, func(nested *database.MongoDB) error {
+			return conureerrors.ErrInternalError
+		})
+	})
+	if !errors.Is(err, conureerrors.ErrInternalError) {
+		t.Fatalf("Expected the error of the nested unit of work, got: %v", err)
+	}
+	if err = (&Application{}).GetByID(client, app.ID.Hex()); err != nil {
+		t.Errorf("Expected the application not deleted, got: %v", err)
+	}
+}
@@ -1,7 +1,6 @@
 package models
 
 import (
-	"context"
 	"errors"
 	"log"
 	"regexp"
@@ -61,9 +60,9 @@ func (v *Variable) Create(mongo *database.MongoDB) (string, error) {
 	v.CreatedAt = time.Now()
 	v.UpdatedAt = v.CreatedAt
 
-	insertResult, err := collection.InsertOne(context.Background(), v)
+	insertResult, err := collection.InsertOne(mongo.Context(), v)
 	if err != nil {
-		return "", err
+		return "", duplicateAsExisting(err)
 	}
 	v.ID = insertResult.InsertedID.(primitive.ObjectID)
 	log.Println("Inserted a single document: ", v.ID.Hex())
@@ -74,16 +73,16 @@ func (v *Variable) Update(mongo *database.MongoDB) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
 	v.UpdatedAt = time.Now()
 
-	_, err := collection.ReplaceOne(context.Background(), primitive.M{"_id": v.ID}, v)
+	_, err := collection.ReplaceOne(mongo.Context(), primitive.M{"_id": v.ID}, v)
 	if err != nil {
-		return err
+		return duplicateAsExisting(err)
 	}
 	return nil
 }
 
 func (v *Variable) Delete(mongo *database.MongoDB) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
-	_, err := collection.DeleteOne(context.Background(), primitive.M{"_id": v.ID})
+	_, err := collection.DeleteOne(mongo.Context(), primitive.M{"_id": v.ID})
 	if err != nil {
 		return err
 	}
@@ -94,12 +93,12 @@ func (v *Variable) ListByOrg(mongo *database.MongoDB, organizationID primitive.O
 	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
 	findOptions := options.Find()
 	findOptions.SetSort(bson.D{{Key: "name", Value: 1}})
-	cursor, err := collection.Find(context.Background(), primitive.M{"organizationId": organizationID, "type": OrganizationType}, findOptions)
+	cursor, err := collection.Find(mongo.Context(), primitive.M{"organizationId": organizationID, "type": OrganizationType}, findOptions)
 	if err != nil {
 		return nil, err
 	}
 	var variables = make([]Variable, 0)
-	err = cursor.All(context.Background(), &variables)
+	err = cursor.All(mongo.Context(), &variables)
 	if err != nil {
 		return nil, err
 	}
@@ -110,14 +109,14 @@ func (v *Variable) ListByEnv(mongo *database.MongoDB, organizationID, applicatio
 	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
 	findOptions := options.Find()
 	findOptions.SetSort(bson.D{{Key: "name", Value: 1}})
-	cursor, err := collection.Find(context.Background(), primitive.M{
+	cursor, err := collection.Find(mongo.Context(), primitive.M{
 		"organizationId": organizationID, "type": EnvironmentType, "applicationId": applicationID,
 		"environmentId": environmentID}, findOptions)
 	if err != nil {
 		return nil, err
 	}
 	var variables = make([]Variable, 0)
-	err = cursor.All(context.Background(), &variables)
+	err = cursor.All(mongo.Context(), &variables)
 	if err != nil {
 		return nil, err
 	}
@@ -128,14 +127,14 @@ func (v *Variable) ListByComp(mongo *database.MongoDB, organizationID, applicati
 	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
 	findOptions := options.Find()
 	findOptions.SetSort(bson.D{{Key: "name", Value: 1}})
-	cursor, err := collection.Find(context.Background(), primitive.M{
+	cursor, err := collection.Find(mongo.Context(), primitive.M{
 		"organizationId": organizationID, "type": ComponentType, "applicationId": applicationID,
 		"environmentId": environmentID, "componentId": componentID}, findOptions)
 	if err != nil {
 		return nil, err
 	}
 	var variables = make([]Variable, 0)
-	err = cursor.All(context.Background(), &variables)
+	err = cursor.All(mongo.Context(), &variables)
 	if err != nil {
 		return nil, err
 	}
@@ -144,7 +143,7 @@ func (v *Variable) ListByComp(mongo *database.MongoDB, organizationID, applicati
 
 func (v *Variable) GetByOrgAndName(mongo *database.MongoDB, organizationID primitive.ObjectID, name string) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
-	err := collection.FindOne(context.Background(), primitive.M{"organizationId": organizationID, "name": name}).Decode(v)
+	err := collection.FindOne(mongo.Context(), primitive.M{"organizationId": organizationID, "name": name}).Decode(v)
 	if err != nil {
 		return err
 	}
@@ -153,7 +152,7 @@ func (v *Variable) GetByOrgAndName(mongo *database.MongoDB, organizationID primi
 
 func (v *Variable) GetByAppIDAndEnvAndName(mongo *database.MongoDB, applicationID primitive.ObjectID, t VariableType, environmentID *string, name string) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
-	err := collection.FindOne(context.Background(), primitive.M{"applicationId": applicationID,
+	err := collection.FindOne(mongo.Context(), primitive.M{"applicationId": applicationID,
 		"type": t, "name": name, "environmentId": environmentID}).Decode(v)
 	if err != nil {
 		return err
@@ -163,7 +162,7 @@ func (v *Variable) GetByAppIDAndEnvAndName(mongo *database.MongoDB, applicationI
 
 func (v *Variable) GetByAppIDAndEnvAndCompAndName(mongo *database.MongoDB, applicationID primitive.ObjectID, t VariableType, environmentID *string, componentID *primitive.ObjectID, name string) error {
 	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
-	err := collection.FindOne(context.Background(), primitive.M{"applicationId": applicationID,
+	err := collection.FindOne(mongo.Context(), primitive.M{"applicationId": applicationID,
 		"type": t, "name": name, "environmentId": environmentID, "componentId": componentID}).Decode(v)
 	if err != nil {
 		return err
@@ -177,7 +176,7 @@ func (v *Variable) GetByID(db *database.MongoDB, id string) error {
 	if err != nil {
 		return err
 	}
-	err = collection.FindOne(context.Background(), primitive.M{"_id": objectID}).Decode(v)
+	err = collection.FindOne(db.Context(), primitive.M{"_id": objectID}).Decode(v)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return conureerrors.ErrObjectNotFound
 	} else if err != nil {
@@ -185,3 +184,81 @@ func (v *Variable) GetByID(db *database.MongoDB, id string) error {
 	}
 	return nil
 }
+
+// ListByApplication returns the variables of the application and its components in every environment
+func (v *Variable) ListByApplication(mongo *database.MongoDB, applicationID primitive.ObjectID) ([]Variable, error) {
+	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
+	cursor, err := collection.Find(mongo.Context(), primitive.M{"applicationId": applicationID})
+	if err != nil {
+		return nil, err
+	}
+	variables := []Variable{}
+	if err = cursor.All(mongo.Context(), &variables); err != nil {
+		return nil, err
+	}
+	return variables, nil
+}
+
+// ListByComponent returns the variables of the component in every environment
+func (v *Variable) ListByComponent(mongo *database.MongoDB, componentID primitive.ObjectID) ([]Variable, error) {
+	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
+	cursor, err := collection.Find(mongo.Context(), primitive.M{"componentId": componentID})
+	if err != nil {
+		return nil, err
+	}
+	variables := []Variable{}
+	if err = cursor.All(mongo.Context(), &variables); err != nil {
+		return nil, err
+	}
+	return variables, nil
+}
+
+func (v *Variable) DeleteByApplication(mongo *database.MongoDB, applicationID primitive.ObjectID) (int64, error) {
+	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
+	result, err := collection.DeleteMany(mongo.Context(), primitive.M{"applicationId": applicationID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (v *Variable) DeleteByComponent(mongo *database.MongoDB, componentID primitive.ObjectID) (int64, error) {
+	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
+	result, err := collection.DeleteMany(mongo.Context(), primitive.M{"componentId": componentID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteByEnvironment removes the variables of the environment and of its components
+func (v *Variable) DeleteByEnvironment(mongo *database.MongoDB, applicationID primitive.ObjectID, environmentID string) (int64, error) {
+	collection := mongo.Client.Database(mongo.DBName).Collection(VariableCollection)
+	result, err := collection.DeleteMany(mongo.Context(), primitive.M{"applicationId": applicationID, "environmentId": environmentID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// PageByOrg returns a page of the organization variables
+func (v *Variable) PageByOrg(mongo *database.MongoDB, organizationID primitive.ObjectID, opts ListOptions) (*Page[Variable], error) {
+	filter := bson.M{"organizationId": organizationID, "type": OrganizationType}
+	return Paginate[Variable](mongo, VariableCollection, filter, VariableListSpec, opts)
+}
+
+// PageByEnv returns a page of the variables of an application environment
+func (v *Variable) PageByEnv(mongo *database.MongoDB, organizationID, applicationID primitive.ObjectID, environmentID string, opts ListOptions) (*Page[Variable], error) {
+	filter := bson.M{
+		"organizationId": organizationID, "type": EnvironmentType, "applicationId": applicationID,
+		"environmentId": environmentID}
+	return Paginate[Variable](mongo, VariableCollection, filter, VariableListSpec, opts)
+}
+
+// PageByComp returns a page of the variables of a component in an environment
+func (v *Variable) PageByComp(mongo *database.MongoDB, organizationID, applicationID primitive.ObjectID, environmentID string, componentID primitive.ObjectID, opts ListOptions) (*Page[Variable], error) {
+	filter := bson.M{
+		"organizationId": organizationID, "type": ComponentType, "applicationId": applicationID,
+		"environmentId": environmentID, "componentId": componentID}
+	return Paginate[Variable](mongo, VariableCollection, filter, VariableListSpec, opts)
+}
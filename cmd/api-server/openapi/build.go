@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
+)
+
+const (
+	Version            = "1.0.0"
+	cookieAuthScheme   = "cookieAuth"
+	jsonContentType    = "application/json"
+	errorComponentName = "ErrorResponse"
+)
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// openAPIPath converts the gin path parameters to the OpenAPI syntax and returns their names
+func openAPIPath(ginPath string) (string, []string) {
+	var parameters []string
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			parameters = append(parameters, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{jsonContentType: {Schema: schema}}
+}
+
+func (r *schemaRegistry) operation(route Route) *Operation {
+	_, pathParameters := openAPIPath(route.Path)
+	operation := &Operation{
+		OperationID: route.OperationID,
+		Tags:        []string{route.Tag},
+		Summary:     route.Summary,
+		Responses:   map[string]Response{},
+		Security:    []map[string][]string{},
+	}
+	if !route.Public {
+		operation.Security = append(operation.Security, map[string][]string{cookieAuthScheme: {}})
+	}
+	for _, name := range pathParameters {
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	paginated := false
+	for _, query := range route.Query {
+		paginated = paginated || query.Name == "cursor"
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name: query.Name, In: "query", Description: query.Description, Required: query.Required,
+			Schema: &Schema{Type: "string"},
+		})
+	}
+	if route.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(r.schemaFor(reflect.TypeOf(route.Request))),
+		}
+	}
+	for status, body := range route.Responses {
+		response := Response{Description: http.StatusText(status)}
+		switch {
+		case route.Stream != "":
+			response.Content = map[string]MediaType{route.Stream: {Schema: &Schema{Type: "string"}}}
+		case body != nil:
+			response.Content = jsonContent(r.schemaFor(reflect.TypeOf(body)))
+		}
+		if paginated {
+			response.Headers = map[string]Header{pagination.NextCursorHeader: {
+				Description: "Cursor of the next page, missing on the last page",
+				Schema:      &Schema{Type: "string"},
+			}}
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+	operation.Responses["default"] = Response{
+		Description: "Error",
+		Content:     jsonContent(&Schema{Ref: "#/components/schemas/" + errorComponentName}),
+	}
+	return operation
+}
+
+// Build generates the document from the Routes
+func Build() *Document {
+	registry := newSchemaRegistry()
+	registry.schemaFor(reflect.TypeOf(ErrorResponse{}))
+	document := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Conure API",
+			Description: "The REST API of the Conure api-server",
+			Version:     Version,
+		},
+		Paths: map[string]*PathItem{},
+	}
+	for _, route := range Routes {
+		path, _ := openAPIPath(route.Path)
+		item, ok := document.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			document.Paths[path] = item
+		}
+		if operation := item.operation(route.Method); operation != nil {
+			*operation = registry.operation(route)
+		}
+	}
+	document.Components = Components{
+		Schemas: registry.schemas,
+		SecuritySchemes: map[string]SecurityScheme{cookieAuthScheme: {
+			Type:        "apiKey",
+			In:          "cookie",
+			Name:        "auth",
+			Description: "The token returned by Login",
+		}},
+	}
+	return document
+}
+
+// Spec returns the document, it's built once as the routes don't change at runtime
+var Spec = sync.OnceValue(Build)
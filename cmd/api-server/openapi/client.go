@@ -0,0 +1,214 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"sort"
+	"strings"
+)
+
+// initialisms are kept upper case in the generated Go names
+var initialisms = map[string]string{
+	"id": "ID", "url": "URL", "uri": "URI", "ip": "IP", "totp": "TOTP", "api": "API", "json": "JSON", "http": "HTTP",
+}
+
+// goName converts the snake_case and camelCase names of the document to exported Go names
+func goName(name string) string {
+	var out strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			out.WriteString(initialism)
+			continue
+		}
+		out.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return out.String()
+}
+
+// goParameter converts a path parameter name to an unexported Go identifier
+func goParameter(name string) string {
+	exported := goName(name)
+	for short, initialism := range initialisms {
+		if exported == initialism {
+			return short
+		}
+	}
+	return strings.ToLower(exported[:1]) + exported[1:]
+}
+
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+func goType(schema *Schema) string {
+	if schema.Ref != "" {
+		return refName(schema.Ref)
+	}
+	switch schema.Type {
+	case "string":
+		if schema.Format == "date-time" {
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + goType(schema.Items)
+	case "object":
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + goType(schema.AdditionalProperties)
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+func writeType(buf *bytes.Buffer, name string, schema *Schema) {
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	required := map[string]bool{}
+	for _, field := range schema.Required {
+		required[field] = true
+	}
+	for _, property := range sortedKeys(schema.Properties) {
+		tag := property
+		if !required[property] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(buf, "\t%s %s `json:%q`\n", goName(property), goType(schema.Properties[property]), tag)
+	}
+	buf.WriteString("}\n\n")
+}
+
+type clientOperation struct {
+	method    string
+	path      string
+	operation *Operation
+}
+
+// successResponse returns the first success response with a body and whether the operation can answer without one
+func successResponse(operation *Operation) (*Response, bool) {
+	var found *Response
+	noContent := false
+	for _, status := range sortedKeys(operation.Responses) {
+		response := operation.Responses[status]
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		if len(response.Content) == 0 {
+			noContent = true
+		} else if found == nil {
+			found = &response
+		}
+	}
+	return found, noContent
+}
+
+func writeOperation(buf *bytes.Buffer, op clientOperation) {
+	operation := op.operation
+	var arguments []string
+	pathExpression := `"` + op.path + `"`
+	hasQuery := false
+	for _, parameter := range operation.Parameters {
+		if parameter.In == "query" {
+			hasQuery = true
+			continue
+		}
+		name := goParameter(parameter.Name)
+		arguments = append(arguments, name+" string")
+		pathExpression = strings.Replace(pathExpression, "{"+parameter.Name+"}", `" + url.PathEscape(`+name+`) + "`, 1)
+	}
+	pathExpression = strings.TrimSuffix(pathExpression, ` + ""`)
+	body := "nil"
+	if operation.RequestBody != nil {
+		arguments = append(arguments, "body *"+goType(operation.RequestBody.Content[jsonContentType].Schema))
+		body = "body"
+	}
+	query := "nil"
+	if hasQuery {
+		arguments = append(arguments, "query url.Values")
+		query = "query"
+	}
+	signature := strings.Join(append([]string{"ctx context.Context"}, arguments...), ", ")
+
+	name := operation.OperationID
+	fmt.Fprintf(buf, "// %s calls %s %s\n", name, op.method, op.path)
+	if operation.Summary != "" {
+		fmt.Fprintf(buf, "//\n// %s\n", operation.Summary)
+	}
+
+	response, noContent := successResponse(operation)
+	if response == nil {
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) error {\n", name, signature)
+		fmt.Fprintf(buf, "\t_, _, err := c.do(ctx, %q, %s, %s, %s, nil)\n\treturn err\n}\n\n", op.method, pathExpression, query, body)
+		return
+	}
+	for contentType := range response.Content {
+		if contentType != jsonContentType {
+			fmt.Fprintf(buf, "func (c *Client) %s(%s) (io.ReadCloser, error) {\n", name, signature)
+			fmt.Fprintf(buf, "\treturn c.stream(ctx, %q, %s, %s)\n}\n\n", op.method, pathExpression, query)
+			return
+		}
+	}
+	schema := response.Content[jsonContentType].Schema
+	resultType := goType(schema)
+	switch {
+	case schema.Ref == "":
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) (%s, error) {\n", name, signature, resultType)
+		fmt.Fprintf(buf, "\tvar out %s\n", resultType)
+		fmt.Fprintf(buf, "\tif _, _, err := c.do(ctx, %q, %s, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", op.method, pathExpression, query, body)
+		fmt.Fprintf(buf, "\treturn out, nil\n}\n\n")
+	default:
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) (*%s, error) {\n", name, signature, resultType)
+		fmt.Fprintf(buf, "\tout := &%s{}\n", resultType)
+		if noContent {
+			fmt.Fprintf(buf, "\tstatus, _, err := c.do(ctx, %q, %s, %s, %s, out)\n", op.method, pathExpression, query, body)
+			fmt.Fprintf(buf, "\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+			fmt.Fprintf(buf, "\tif status == http.StatusNoContent {\n\t\treturn nil, nil\n\t}\n")
+		} else {
+			fmt.Fprintf(buf, "\tif _, _, err := c.do(ctx, %q, %s, %s, %s, out); err != nil {\n\t\treturn nil, err\n\t}\n", op.method, pathExpression, query, body)
+		}
+		fmt.Fprintf(buf, "\treturn out, nil\n}\n\n")
+	}
+}
+
+// GenerateClient renders the types and the methods of the Go client of the document
+func GenerateClient(document *Document, packageName string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, name := range sortedKeys(document.Components.Schemas) {
+		writeType(buf, name, document.Components.Schemas[name])
+	}
+
+	var operations []clientOperation
+	for _, p := range sortedKeys(document.Paths) {
+		for method, operation := range document.Paths[p].Operations() {
+			operations = append(operations, clientOperation{method: method, path: p, operation: operation})
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].operation.OperationID < operations[j].operation.OperationID
+	})
+	for _, op := range operations {
+		writeOperation(buf, op)
+	}
+
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "// Code generated by clientgen from the OpenAPI document. DO NOT EDIT.\n\n")
+	fmt.Fprintf(source, "package %s\n\nimport (\n", packageName)
+	for _, pkg := range []string{"context", "io", "net/http", "net/url", "time"} {
+		if bytes.Contains(buf.Bytes(), []byte(path.Base(pkg)+".")) {
+			fmt.Fprintf(source, "\t%q\n", pkg)
+		}
+	}
+	source.WriteString(")\n\n")
+	source.Write(buf.Bytes())
+	return format.Source(source.Bytes())
+}
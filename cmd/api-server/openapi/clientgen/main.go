@@ -0,0 +1,24 @@
+// Command clientgen generates the Go client of the API from its OpenAPI document
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/coffeenights/conure/cmd/api-server/openapi"
+)
+
+func main() {
+	output := flag.String("o", "zz_generated.client.go", "The file to write the client to")
+	packageName := flag.String("package", "apiclient", "The package name of the client")
+	flag.Parse()
+
+	source, err := openapi.GenerateClient(openapi.Build(), *packageName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile(*output, source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeenights/conure/cmd/api-server/applications"
+	"github.com/coffeenights/conure/cmd/api-server/audit"
+	"github.com/coffeenights/conure/cmd/api-server/auth"
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/health"
+	"github.com/coffeenights/conure/cmd/api-server/settings"
+	"github.com/coffeenights/conure/cmd/api-server/variables"
+	"github.com/coffeenights/conure/cmd/api-server/webhooks"
+)
+
+// newRouter registers the routes like routes.GenerateRouter, the handlers are never called
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	conf := &apiConfig.Config{}
+	router := gin.New()
+	auth.GenerateRoutes("/auth", router, auth.NewAuthHandler(conf, nil, nil))
+	applications.GenerateRoutes("/organizations", router, applications.NewApiHandler(conf, nil, nil, nil))
+	audit.GenerateRoutes("/organizations", router, audit.NewAuditHandler(conf, nil))
+	settings.GenerateRoutes("/settings", router, settings.NewApiHandler(conf, nil, nil, nil))
+	variables.GenerateRoutes("/variables", router, variables.NewVariablesHandler(conf, nil, nil))
+	webhooks.GenerateRoutes("/webhooks", router, webhooks.NewApiHandler(conf, nil, nil, nil))
+	health.GenerateRoutes(router, health.NewProbe())
+	GenerateRoutes("/openapi.json", router)
+	return router
+}
+
+func TestRoutes_MatchGin(t *testing.T) {
+	documented := map[string]bool{}
+	operationIDs := map[string]bool{}
+	for _, route := range Routes {
+		key := route.Method + " " + route.Path
+		assert.False(t, documented[key], "%s is documented twice", key)
+		assert.False(t, operationIDs[route.OperationID], "duplicated operation id %s", route.OperationID)
+		documented[key] = true
+		operationIDs[route.OperationID] = true
+	}
+	registered := map[string]bool{}
+	for _, route := range newRouter().Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		assert.True(t, documented[key], "%s is not documented", key)
+	}
+	for key := range documented {
+		assert.True(t, registered[key], "%s is documented but not registered", key)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	document := Build()
+	item := document.Paths["/organizations/{organizationID}/a/{applicationID}"]
+	require.NotNil(t, item)
+	require.NotNil(t, item.Delete)
+	assert.Equal(t, "DeleteApplication", item.Delete.OperationID)
+	assert.Equal(t, "applicationID", item.Delete.Parameters[1].Name)
+	assert.Contains(t, item.Delete.Responses, "204")
+	assert.NotEmpty(t, item.Delete.Security)
+
+	// the embedded models are flattened
+	schema := document.Components.Schemas["ApplicationResponse"]
+	require.NotNil(t, schema)
+	assert.Contains(t, schema.Properties, "name")
+	assert.Contains(t, schema.Properties, "total_components")
+	assert.Equal(t, "date-time", schema.Properties["created_at"].Format)
+
+	// the binding tags mark the required fields and the hidden fields are left out
+	assert.Equal(t, []string{"email", "password"}, document.Components.Schemas["LoginRequest"].Required)
+	assert.NotContains(t, document.Components.Schemas["User"].Properties, "password")
+
+	// every reference points to a component
+	data, err := json.Marshal(document)
+	require.NoError(t, err)
+	for _, part := range strings.Split(string(data), `"$ref":"#/components/schemas/`)[1:] {
+		name := part[:strings.Index(part, `"`)]
+		assert.Contains(t, document.Components.Schemas, name)
+	}
+}
+
+func TestServeDocument(t *testing.T) {
+	router := newRouter()
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest("GET", "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	document := Document{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &document))
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	assert.NotEmpty(t, document.Paths)
+}
+
+func TestGenerateClient_UpToDate(t *testing.T) {
+	source, err := GenerateClient(Build(), "apiclient")
+	require.NoError(t, err)
+	generated, err := os.ReadFile("../../../pkg/apiclient/zz_generated.client.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(source), "the client is outdated, run go generate ./pkg/apiclient")
+}
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "OrganizationID", goName("organization_id"))
+	assert.Equal(t, "TwoFactorRequired", goName("two_factor_required"))
+	assert.Equal(t, "ProvisioningURI", goName("provisioning_uri"))
+	assert.Equal(t, "organizationID", goParameter("organizationID"))
+	assert.Equal(t, "environment", goParameter("environment"))
+}
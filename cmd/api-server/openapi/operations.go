@@ -0,0 +1,259 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/coffeenights/conure/cmd/api-server/applications"
+	"github.com/coffeenights/conure/cmd/api-server/audit"
+	"github.com/coffeenights/conure/cmd/api-server/auth"
+	"github.com/coffeenights/conure/cmd/api-server/health"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+	"github.com/coffeenights/conure/cmd/api-server/settings"
+	"github.com/coffeenights/conure/cmd/api-server/variables"
+	"github.com/coffeenights/conure/cmd/api-server/webhooks"
+)
+
+// Route documents a route registered in gin, the tests check that every gin route is documented
+type Route struct {
+	Method string
+	// Path uses the gin syntax, the parameters are documented from it
+	Path        string
+	OperationID string
+	Tag         string
+	Summary     string
+	// Request is a value of the type bound from the JSON body, nil when the route has no body
+	Request interface{}
+	// Responses are keyed by status code, a nil value is a response without body
+	Responses map[int]interface{}
+	Query     []QueryParameter
+	Public    bool
+	// Stream is the content type of routes streaming their response instead of returning JSON
+	Stream string
+}
+
+type QueryParameter struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Bodies of the responses built with gin.H
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type TokenResponse struct {
+	Token string `json:"token"`
+}
+
+// LoginResponse has either the token or, when 2FA is enabled, the challenge for LoginTwoFactor
+type LoginResponse struct {
+	Token string `json:"token,omitempty"`
+	auth.TwoFactorChallengeResponse
+}
+
+type StatusResponse struct {
+	Status applications.ApplicationStatus `json:"status"`
+}
+
+type EmptyResponse struct{}
+
+// ErrorResponse is the body of every error, see conureerrors.AbortWithError
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+	Fields  string `json:"fields,omitempty"`
+}
+
+// listQuery documents the pagination parameters and the filters declared by the spec
+func listQuery(spec models.ListSpec) []QueryParameter {
+	parameters := []QueryParameter{
+		{Name: "limit", Description: "Maximum amount of items of the page"},
+		{Name: "cursor", Description: "The next_cursor of the previous page"},
+		{Name: "sort", Description: "Field to sort by, prefixed with - for a descending order"},
+	}
+	for _, name := range sortedKeys(spec.Filters) {
+		parameters = append(parameters, QueryParameter{Name: name, Description: "Filter by " + name})
+	}
+	return parameters
+}
+
+var dryRunQuery = []QueryParameter{
+	{Name: "dry_run", Description: "Only return what would be deleted"},
+}
+
+var message = map[int]interface{}{http.StatusOK: MessageResponse{}}
+
+const (
+	organizationPath = "/organizations/:organizationID"
+	applicationPath  = organizationPath + "/a/:applicationID"
+	environmentPath  = applicationPath + "/e/:environment"
+	componentPath    = environmentPath + "/c/:componentID"
+	variablesPath    = "/variables/:organizationID"
+)
+
+var Routes = []Route{
+	// auth
+	{Method: "POST", Path: "/auth/login", OperationID: "Login", Tag: "auth", Public: true,
+		Summary: "Log in, answers with a two-factor challenge when 2FA is enabled",
+		Request: auth.LoginRequest{}, Responses: map[int]interface{}{http.StatusOK: LoginResponse{}}},
+	{Method: "POST", Path: "/auth/login/2fa", OperationID: "LoginTwoFactor", Tag: "auth", Public: true,
+		Summary: "Complete a login with a TOTP or recovery code",
+		Request: auth.TwoFactorLoginRequest{}, Responses: map[int]interface{}{http.StatusOK: TokenResponse{}}},
+	{Method: "POST", Path: "/auth/register", OperationID: "Register", Tag: "auth", Public: true,
+		Request: auth.RegisterRequest{}, Responses: map[int]interface{}{http.StatusCreated: models.User{}}},
+	{Method: "POST", Path: "/auth/verify-email", OperationID: "VerifyEmail", Tag: "auth", Public: true,
+		Request: auth.TokenRequest{}, Responses: message},
+	{Method: "POST", Path: "/auth/resend-verification", OperationID: "ResendVerification", Tag: "auth", Public: true,
+		Request: auth.EmailRequest{}, Responses: message},
+	{Method: "POST", Path: "/auth/forgot-password", OperationID: "ForgotPassword", Tag: "auth", Public: true,
+		Request: auth.EmailRequest{}, Responses: message},
+	{Method: "POST", Path: "/auth/reset-password", OperationID: "ResetPassword", Tag: "auth", Public: true,
+		Request: auth.ResetPasswordRequest{}, Responses: message},
+	{Method: "POST", Path: "/auth/invitations", OperationID: "CreateInvitation", Tag: "auth", Summary: "Invite a user to register, admins only",
+		Request: auth.EmailRequest{}, Responses: map[int]interface{}{http.StatusCreated: models.UserToken{}}},
+	{Method: "POST", Path: "/auth/unlock", OperationID: "UnlockAccount", Tag: "auth", Summary: "Unlock a locked account, admins only",
+		Request: auth.EmailRequest{}, Responses: message},
+	{Method: "POST", Path: "/auth/2fa/enroll", OperationID: "EnrollTwoFactor", Tag: "auth",
+		Responses: map[int]interface{}{http.StatusOK: auth.TwoFactorEnrollResponse{}}},
+	{Method: "POST", Path: "/auth/2fa/verify", OperationID: "VerifyTwoFactor", Tag: "auth",
+		Request: auth.TwoFactorCodeRequest{}, Responses: map[int]interface{}{http.StatusOK: auth.RecoveryCodesResponse{}}},
+	{Method: "POST", Path: "/auth/2fa/disable", OperationID: "DisableTwoFactor", Tag: "auth",
+		Request: auth.DisableTwoFactorRequest{}, Responses: message},
+	{Method: "POST", Path: "/auth/2fa/recovery-codes", OperationID: "RegenerateRecoveryCodes", Tag: "auth",
+		Request: auth.TwoFactorCodeRequest{}, Responses: map[int]interface{}{http.StatusOK: auth.RecoveryCodesResponse{}}},
+	{Method: "GET", Path: "/auth/me", OperationID: "Me", Tag: "auth",
+		Responses: map[int]interface{}{http.StatusOK: models.User{}}},
+	{Method: "PATCH", Path: "/auth/change-password", OperationID: "ChangePassword", Tag: "auth",
+		Request: auth.ChangePasswordRequest{}, Responses: message},
+
+	// organizations
+	{Method: "GET", Path: "/organizations/", OperationID: "ListOrganizations", Tag: "organizations",
+		Query: listQuery(models.OrganizationListSpec), Responses: map[int]interface{}{http.StatusOK: applications.OrganizationListResponse{}}},
+	{Method: "POST", Path: "/organizations/", OperationID: "CreateOrganization", Tag: "organizations",
+		Request: applications.CreateOrganizationRequest{}, Responses: map[int]interface{}{http.StatusCreated: applications.OrganizationResponse{}}},
+	{Method: "GET", Path: organizationPath, OperationID: "DetailOrganization", Tag: "organizations",
+		Responses: map[int]interface{}{http.StatusOK: applications.OrganizationResponse{}}},
+	{Method: "PATCH", Path: organizationPath, OperationID: "UpdateOrganization", Tag: "organizations",
+		Request: applications.UpdateOrganizationRequest{}, Responses: map[int]interface{}{http.StatusOK: applications.OrganizationResponse{}}},
+	{Method: "DELETE", Path: organizationPath, OperationID: "DeleteOrganization", Tag: "organizations",
+		Summary:   "Delete the organization, its data is purged after the grace period",
+		Responses: map[int]interface{}{http.StatusOK: EmptyResponse{}}},
+	{Method: "POST", Path: organizationPath + "/transfer", OperationID: "TransferOrganization", Tag: "organizations",
+		Request: applications.TransferOrganizationRequest{}, Responses: map[int]interface{}{http.StatusOK: applications.OrganizationResponse{}}},
+	{Method: "POST", Path: organizationPath + "/disable", OperationID: "DisableOrganization", Tag: "organizations",
+		Responses: map[int]interface{}{http.StatusOK: applications.OrganizationResponse{}}},
+	{Method: "POST", Path: organizationPath + "/enable", OperationID: "EnableOrganization", Tag: "organizations",
+		Responses: map[int]interface{}{http.StatusOK: applications.OrganizationResponse{}}},
+	{Method: "PUT", Path: organizationPath + "/security", OperationID: "UpdateOrganizationSecurity", Tag: "organizations",
+		Summary: "Require 2FA to access the organization, organizations have no members yet so it only applies to the owner",
+		Request: applications.OrganizationSecurityRequest{}, Responses: map[int]interface{}{http.StatusOK: applications.OrganizationResponse{}}},
+	{Method: "GET", Path: organizationPath + "/audit", OperationID: "ListAuditEvents", Tag: "organizations",
+		Query: append(listQuery(models.AuditEventListSpec),
+			QueryParameter{Name: "action"}, QueryParameter{Name: "actor_id"}, QueryParameter{Name: "target_type"},
+			QueryParameter{Name: "target_id"}, QueryParameter{Name: "since", Description: "RFC 3339 time"},
+			QueryParameter{Name: "until", Description: "RFC 3339 time"}),
+		Responses: map[int]interface{}{http.StatusOK: audit.ListAuditEventsResponse{}}},
+
+	// applications
+	{Method: "POST", Path: organizationPath + "/apply", OperationID: "ApplyManifest", Tag: "applications",
+		Summary: "Create, update and delete the application of the manifest with its environments, components and variables",
+		Request: applications.Manifest{}, Responses: map[int]interface{}{http.StatusOK: applications.ManifestApplyResponse{}}},
+	{Method: "POST", Path: organizationPath + "/diff", OperationID: "DiffManifest", Tag: "applications",
+		Summary: "List the changes applying the manifest would make",
+		Request: applications.Manifest{}, Responses: map[int]interface{}{http.StatusOK: applications.ManifestDiff{}}},
+	{Method: "GET", Path: organizationPath + "/a", OperationID: "ListApplications", Tag: "applications",
+		Query: listQuery(models.ApplicationListSpec), Responses: map[int]interface{}{http.StatusOK: applications.ApplicationListResponse{}}},
+	{Method: "POST", Path: organizationPath + "/a", OperationID: "CreateApplication", Tag: "applications",
+		Request: applications.CreateApplicationRequest{}, Responses: map[int]interface{}{http.StatusCreated: models.Application{}}},
+	{Method: "PATCH", Path: applicationPath, OperationID: "UpdateApplication", Tag: "applications",
+		Request: applications.UpdateApplicationRequest{}, Responses: map[int]interface{}{http.StatusOK: applications.ApplicationResponse{}}},
+	{Method: "DELETE", Path: applicationPath, OperationID: "DeleteApplication", Tag: "applications",
+		Summary: "Delete the application with its components, variables and environments",
+		Query:   dryRunQuery, Responses: map[int]interface{}{http.StatusOK: applications.DeletionPlan{}, http.StatusNoContent: nil}},
+	{Method: "POST", Path: applicationPath + "/e", OperationID: "CreateEnvironment", Tag: "applications",
+		Request: applications.CreateEnvironmentRequest{}, Responses: map[int]interface{}{http.StatusCreated: EmptyResponse{}}},
+	{Method: "PATCH", Path: environmentPath, OperationID: "UpdateEnvironment", Tag: "applications",
+		Summary: "Set the domain template naming the hosts of the routes of the environment",
+		Request: applications.UpdateEnvironmentRequest{}, Responses: map[int]interface{}{http.StatusOK: applications.EnvironmentResponse{}}},
+	{Method: "DELETE", Path: environmentPath, OperationID: "DeleteEnvironment", Tag: "applications",
+		Responses: map[int]interface{}{http.StatusOK: EmptyResponse{}}},
+	{Method: "PUT", Path: environmentPath, OperationID: "DeployApplication", Tag: "applications", Responses: message},
+	{Method: "GET", Path: environmentPath, OperationID: "DetailApplication", Tag: "applications",
+		Responses: map[int]interface{}{http.StatusOK: applications.ApplicationResponse{}}},
+	{Method: "GET", Path: environmentPath + "/status", OperationID: "StatusApplication", Tag: "applications",
+		Responses: map[int]interface{}{http.StatusOK: StatusResponse{}}},
+	{Method: "GET", Path: environmentPath + "/status/events", OperationID: "WatchStatus", Tag: "applications",
+		Summary: "Stream the status of the environment as server-sent events, named application, component, pod and workflow",
+		Stream:  "text/event-stream", Responses: map[int]interface{}{http.StatusOK: ""}},
+
+	// components
+	{Method: "GET", Path: environmentPath + "/c", OperationID: "ListComponents", Tag: "components",
+		Query: listQuery(models.ComponentListSpec), Responses: map[int]interface{}{http.StatusOK: applications.ComponentListResponse{}}},
+	{Method: "POST", Path: environmentPath + "/c", OperationID: "CreateComponent", Tag: "components",
+		Request: applications.CreateComponentRequest{}, Responses: map[int]interface{}{http.StatusCreated: models.Component{}}},
+	{Method: "GET", Path: componentPath, OperationID: "DetailComponent", Tag: "components",
+		Responses: map[int]interface{}{http.StatusOK: applications.ComponentResponse{}}},
+	{Method: "PUT", Path: componentPath, OperationID: "UpdateComponent", Tag: "components",
+		Request: applications.CreateComponentRequest{}, Responses: map[int]interface{}{http.StatusOK: models.Component{}}},
+	{Method: "DELETE", Path: componentPath, OperationID: "DeleteComponent", Tag: "components",
+		Summary: "Delete the component from every environment along with its variables",
+		Query:   dryRunQuery, Responses: map[int]interface{}{http.StatusOK: applications.DeletionPlan{}, http.StatusNoContent: nil}},
+	{Method: "GET", Path: componentPath + "/status", OperationID: "StatusComponent", Tag: "components",
+		Responses: map[int]interface{}{http.StatusOK: applications.ComponentStatusResponse{}}},
+	{Method: "GET", Path: componentPath + "/status/health", OperationID: "StatusComponentHealth", Tag: "components",
+		Responses: map[int]interface{}{http.StatusOK: providers.ComponentStatusHealth{}}},
+	{Method: "GET", Path: componentPath + "/status/logs", OperationID: "StreamLogs", Tag: "components",
+		Summary: "Stream the logs of the pods as server-sent events", Stream: "text/event-stream",
+		Query:     []QueryParameter{{Name: "pods", Description: "Comma separated pod names", Required: true}},
+		Responses: map[int]interface{}{http.StatusOK: ""}},
+	{Method: "GET", Path: componentPath + "/status/pods", OperationID: "ComponentPods", Tag: "components",
+		Responses: map[int]interface{}{http.StatusOK: applications.ComponentPodsResponse{}}},
+
+	// settings
+	{Method: "POST", Path: "/settings/:organizationID/i", OperationID: "CreateIntegration", Tag: "settings",
+		Request: settings.CreateIntegrationRequest{}, Responses: map[int]interface{}{http.StatusCreated: models.Integration{}}},
+	{Method: "GET", Path: "/settings/:organizationID/i", OperationID: "ListIntegrations", Tag: "settings",
+		Query: listQuery(models.IntegrationListSpec), Responses: map[int]interface{}{http.StatusOK: settings.IntegrationListResponse{}}},
+	{Method: "PUT", Path: "/settings/:organizationID/i/:integrationID", OperationID: "UpdateIntegration", Tag: "settings",
+		Request: settings.CreateIntegrationRequest{}, Responses: map[int]interface{}{http.StatusOK: models.Integration{}}},
+	{Method: "DELETE", Path: "/settings/:organizationID/i/:integrationID", OperationID: "DeleteIntegration", Tag: "settings",
+		Responses: map[int]interface{}{http.StatusNoContent: nil}},
+	{Method: "POST", Path: "/settings/:organizationID/i/:integrationID/test", OperationID: "TestIntegration", Tag: "settings",
+		Summary:   "Check the stored value of the integration against its service",
+		Responses: map[int]interface{}{http.StatusOK: settings.TestConnectionResponse{}}},
+	{Method: "GET", Path: "/settings/integration-types", OperationID: "ListIntegrationTypes", Tag: "settings",
+		Responses: map[int]interface{}{http.StatusOK: []settings.IntegrationSchema{}}},
+
+	// variables
+	{Method: "POST", Path: variablesPath, OperationID: "CreateOrganizationVariable", Tag: "variables",
+		Request: models.Variable{}, Responses: map[int]interface{}{http.StatusCreated: models.Variable{}}},
+	{Method: "GET", Path: variablesPath, OperationID: "ListOrganizationVariables", Tag: "variables",
+		Query: listQuery(models.VariableListSpec), Responses: map[int]interface{}{http.StatusOK: variables.VariableListResponse{}}},
+	{Method: "DELETE", Path: variablesPath + "/:variableID", OperationID: "DeleteVariable", Tag: "variables",
+		Responses: map[int]interface{}{http.StatusNoContent: nil}},
+	{Method: "POST", Path: variablesPath + "/:applicationID/e/:environmentID", OperationID: "CreateEnvironmentVariable", Tag: "variables",
+		Request: models.Variable{}, Responses: map[int]interface{}{http.StatusCreated: models.Variable{}}},
+	{Method: "GET", Path: variablesPath + "/:applicationID/e/:environmentID", OperationID: "ListEnvironmentVariables", Tag: "variables",
+		Query: listQuery(models.VariableListSpec), Responses: map[int]interface{}{http.StatusOK: variables.VariableListResponse{}}},
+	{Method: "POST", Path: variablesPath + "/:applicationID/e/:environmentID/c/:componentID", OperationID: "CreateComponentVariable", Tag: "variables",
+		Request: models.Variable{}, Responses: map[int]interface{}{http.StatusCreated: models.Variable{}}},
+	{Method: "GET", Path: variablesPath + "/:applicationID/e/:environmentID/c/:componentID", OperationID: "ListComponentVariables", Tag: "variables",
+		Query: listQuery(models.VariableListSpec), Responses: map[int]interface{}{http.StatusOK: variables.VariableListResponse{}}},
+
+	// webhooks
+	{Method: "POST", Path: "/webhooks/:integrationID", OperationID: "ReceiveWebhook", Tag: "webhooks", Public: true,
+		Summary: "Push webhook of a GitHub, GitLab or Gitea integration, authenticated by its signature",
+		Request: map[string]interface{}{}, Responses: map[int]interface{}{http.StatusAccepted: webhooks.WebhookResponse{}, http.StatusNoContent: nil}},
+
+	{Method: "GET", Path: "/healthz", OperationID: "Liveness", Tag: "meta", Public: true,
+		Summary: "Liveness probe", Responses: map[int]interface{}{http.StatusOK: health.Report{}}},
+	{Method: "GET", Path: "/readyz", OperationID: "Readiness", Tag: "meta", Public: true,
+		Summary:   "Readiness probe, fails while a dependency is down or the server is shutting down",
+		Responses: map[int]interface{}{http.StatusOK: health.Report{}, http.StatusServiceUnavailable: health.Report{}}},
+	{Method: "GET", Path: "/openapi.json", OperationID: "GetOpenAPIDocument", Tag: "meta", Public: true,
+		Summary: "This document", Responses: map[int]interface{}{http.StatusOK: map[string]interface{}{}}},
+}
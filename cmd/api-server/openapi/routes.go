@@ -0,0 +1,15 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ServeDocument(c *gin.Context) {
+	c.JSON(http.StatusOK, Spec())
+}
+
+func GenerateRoutes(relativePath string, r *gin.Engine) {
+	r.GET(relativePath, ServeDocument)
+}
@@ -0,0 +1,52 @@
+package pagination
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+// NextCursorHeader carries the cursor of the next page in every list response, along with the next_cursor of the
+// Metadata
+const NextCursorHeader = "X-Next-Cursor"
+
+// Metadata is embedded in the list responses
+type Metadata struct {
+	NextCursor string `json:"next_cursor"`
+	Limit      int64  `json:"limit"`
+}
+
+// ParseListOptions reads the limit, cursor and sort query parameters, the query parameters declared as filters by the
+// spec are used as filters and the rest are ignored
+func ParseListOptions(c *gin.Context, spec models.ListSpec) (models.ListOptions, error) {
+	opts := models.ListOptions{
+		Limit:   models.DefaultPageLimit,
+		Cursor:  c.Query("cursor"),
+		Sort:    c.Query("sort"),
+		Filters: map[string]string{},
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 1 {
+			return opts, conureerrors.ErrInvalidRequest
+		}
+		opts.Limit = min(limit, models.MaxPageLimit)
+	}
+	for name := range spec.Filters {
+		if value := c.Query(name); value != "" {
+			opts.Filters[name] = value
+		}
+	}
+	return opts, nil
+}
+
+// SetNextCursor sets the NextCursorHeader and returns the metadata to be embedded in the response
+func SetNextCursor(c *gin.Context, opts models.ListOptions, nextCursor string) Metadata {
+	if nextCursor != "" {
+		c.Header(NextCursorHeader, nextCursor)
+	}
+	return Metadata{NextCursor: nextCursor, Limit: opts.Limit}
+}
@@ -0,0 +1,45 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coffeenights/conure/cmd/api-server/models"
+)
+
+func contextFor(url string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	c.Request = httptest.NewRequest("GET", url, nil)
+	return c, resp
+}
+
+func TestParseListOptions(t *testing.T) {
+	c, _ := contextFor("/?limit=500&cursor=abc&sort=-name&type=service&name_prefix=web&dry_run=true")
+	opts, err := ParseListOptions(c, models.ComponentListSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(models.MaxPageLimit), opts.Limit)
+	assert.Equal(t, "abc", opts.Cursor)
+	assert.Equal(t, "-name", opts.Sort)
+	assert.Equal(t, map[string]string{"type": "service", "name_prefix": "web"}, opts.Filters)
+
+	c, _ = contextFor("/")
+	opts, err = ParseListOptions(c, models.ComponentListSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(models.DefaultPageLimit), opts.Limit)
+
+	c, _ = contextFor("/?limit=0")
+	_, err = ParseListOptions(c, models.ComponentListSpec)
+	assert.Error(t, err)
+}
+
+func TestSetNextCursor(t *testing.T) {
+	c, resp := contextFor("/")
+	metadata := SetNextCursor(c, models.ListOptions{Limit: 10}, "next")
+	assert.Equal(t, Metadata{NextCursor: "next", Limit: 10}, metadata)
+	assert.Equal(t, "next", resp.Header().Get(NextCursorHeader))
+}
@@ -0,0 +1,220 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/coffeenights/conure/apis/vela"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v2"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Cluster is the connection of the API server to Kubernetes, created once at startup and shared by every request.
+// Its informers cache the objects labelled with a Conure application id, so the status reads don't reach the
+// apiserver.
+type Cluster struct {
+	Clientset *k8sUtils.GenericClientset
+	Watcher   *StatusWatcher
+
+	k8s            kubernetes.Interface
+	dynamic        dynamic.Interface
+	factory        informers.SharedInformerFactory
+	dynamicFactory dynamicinformer.DynamicSharedInformerFactory
+	// eventFactory caches the events of the autoscalers, the events carry no labels
+	eventFactory informers.SharedInformerFactory
+	// applications is nil when vela is not installed in the cluster, and httpRoutes without the Gateway API
+	applications cache.GenericLister
+	httpRoutes   cache.GenericLister
+	deployments  appslisters.DeploymentLister
+	services     corelisters.ServiceLister
+	pods         corelisters.PodLister
+	ingresses    networkinglisters.IngressLister
+	autoscalers  autoscalinglisters.HorizontalPodAutoscalerLister
+	events       corelisters.EventLister
+}
+
+func NewCluster(clientset *k8sUtils.GenericClientset) *Cluster {
+	cluster := newCluster(clientset.K8s, clientset.Dynamic)
+	cluster.Clientset = clientset
+	return cluster
+}
+
+func newCluster(k8s kubernetes.Interface, dynamicClient dynamic.Interface) *Cluster {
+	conureObjects := func(options *metav1.ListOptions) {
+		options.LabelSelector = k8sUtils.ApplicationIDLabel
+	}
+	cluster := &Cluster{
+		Watcher:        NewStatusWatcher(k8s, dynamicClient),
+		k8s:            k8s,
+		dynamic:        dynamicClient,
+		factory:        informers.NewSharedInformerFactoryWithOptions(k8s, 0, informers.WithTweakListOptions(conureObjects)),
+		dynamicFactory: dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, metav1.NamespaceAll, conureObjects),
+		eventFactory: informers.NewSharedInformerFactoryWithOptions(k8s, 0, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = "involvedObject.kind=HorizontalPodAutoscaler"
+		})),
+	}
+	cluster.deployments = cluster.factory.Apps().V1().Deployments().Lister()
+	cluster.services = cluster.factory.Core().V1().Services().Lister()
+	cluster.pods = cluster.factory.Core().V1().Pods().Lister()
+	cluster.ingresses = cluster.factory.Networking().V1().Ingresses().Lister()
+	cluster.autoscalers = cluster.factory.Autoscaling().V2().HorizontalPodAutoscalers().Lister()
+	cluster.events = cluster.eventFactory.Core().V1().Events().Lister()
+	if served(k8s.Discovery(), velaApplicationResource) {
+		cluster.applications = cluster.dynamicFactory.ForResource(velaApplicationResource).Lister()
+	}
+	if served(k8s.Discovery(), httpRouteResource) {
+		cluster.httpRoutes = cluster.dynamicFactory.ForResource(httpRouteResource).Lister()
+	}
+	return cluster
+}
+
+// Start runs the informers until ctx is done and waits for their caches to be filled
+func (c *Cluster) Start(ctx context.Context) error {
+	stop := ctx.Done()
+	c.factory.Start(stop)
+	c.dynamicFactory.Start(stop)
+	c.eventFactory.Start(stop)
+	for _, factory := range []informers.SharedInformerFactory{c.factory, c.eventFactory} {
+		for informerType, synced := range factory.WaitForCacheSync(stop) {
+			if !synced {
+				return fmt.Errorf("cache of %v not synced", informerType)
+			}
+		}
+	}
+	for resource, synced := range c.dynamicFactory.WaitForCacheSync(stop) {
+		if !synced {
+			return fmt.Errorf("cache of %v not synced", resource)
+		}
+	}
+	return nil
+}
+
+// Ping checks the apiserver is reachable
+func (c *Cluster) Ping(_ context.Context) error {
+	_, err := c.k8s.Discovery().ServerVersion()
+	return err
+}
+
+func (c *Cluster) getApplication(namespace string, selector labels.Set) (*vela.Application, error) {
+	if c.applications == nil {
+		return nil, k8sUtils.ErrApplicationNotFound
+	}
+	objects, err := c.applications.ByNamespace(namespace).List(selector.AsSelector())
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, k8sUtils.ErrApplicationNotFound
+	}
+	var application vela.Application
+	if !fromUnstructured(objects[0], &application) {
+		return nil, fmt.Errorf("invalid application in namespace %s", namespace)
+	}
+	return &application, nil
+}
+
+func (c *Cluster) listDeployments(namespace string, selector labels.Set) ([]*appsv1.Deployment, error) {
+	return c.deployments.Deployments(namespace).List(selector.AsSelector())
+}
+
+func (c *Cluster) listServices(namespace string, selector labels.Set) ([]*corev1.Service, error) {
+	return c.services.Services(namespace).List(selector.AsSelector())
+}
+
+func (c *Cluster) listPods(namespace string, selector labels.Set) ([]*corev1.Pod, error) {
+	return c.pods.Pods(namespace).List(selector.AsSelector())
+}
+
+func (c *Cluster) listAutoscalers(namespace string, selector labels.Set) ([]*autoscalingv2.HorizontalPodAutoscaler, error) {
+	return c.autoscalers.HorizontalPodAutoscalers(namespace).List(selector.AsSelector())
+}
+
+// listAutoscalerEvents returns the events of the autoscaler, the latest first
+func (c *Cluster) listAutoscalerEvents(namespace string, name string) ([]*corev1.Event, error) {
+	events, err := c.events.Events(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var autoscalerEvents []*corev1.Event
+	for _, event := range events {
+		if event.InvolvedObject.Name == name {
+			autoscalerEvents = append(autoscalerEvents, event)
+		}
+	}
+	sort.SliceStable(autoscalerEvents, func(i, j int) bool {
+		return eventTime(autoscalerEvents[i]).After(eventTime(autoscalerEvents[j]))
+	})
+	return autoscalerEvents, nil
+}
+
+// eventTime returns when the event last happened, the events recorded by the newer clients only have an event time
+func eventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+func (c *Cluster) listIngresses(namespace string, selector labels.Set) ([]*networkingv1.Ingress, error) {
+	return c.ingresses.Ingresses(namespace).List(selector.AsSelector())
+}
+
+// listHTTPRoutes returns no routes when the Gateway API is not installed in the cluster
+func (c *Cluster) listHTTPRoutes(namespace string, selector labels.Set) ([]httpRoute, error) {
+	if c.httpRoutes == nil {
+		return nil, nil
+	}
+	objects, err := c.httpRoutes.ByNamespace(namespace).List(selector.AsSelector())
+	if err != nil {
+		return nil, err
+	}
+	var routes []httpRoute
+	for _, object := range objects {
+		var route httpRoute
+		if fromUnstructured(object, &route) {
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+// served reports whether the cluster has the resource, the custom resources are missing when the operator owning
+// them is not installed
+func served(client discovery.DiscoveryInterface, resource schema.GroupVersionResource) bool {
+	resources, err := client.ServerResourcesForGroupVersion(resource.GroupVersion().String())
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			log.Printf("Error discovering %s: %v\n", resource.GroupVersion(), err)
+		}
+		return false
+	}
+	for _, apiResource := range resources.APIResources {
+		if apiResource.Name == resource.Resource {
+			return true
+		}
+	}
+	return false
+}
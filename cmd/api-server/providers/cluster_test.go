@@ -0,0 +1,333 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	"github.com/coffeenights/conure/internal/telemetry"
+	"go.opentelemetry.io/otel/trace"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestCluster(t *testing.T, objects ...runtime.Object) *Cluster {
+	t.Helper()
+	conureLabels := map[string]string{
+		k8sUtils.ApplicationIDLabel:  "app1234",
+		k8sUtils.OrganizationIDLabel: "org1234",
+		k8sUtils.NamespaceLabel:      testNamespace,
+	}
+	podLabels := map[string]string{
+		k8sUtils.ApplicationIDLabel: "app1234",
+		ApplicationNameLabel:        "shop",
+		ComponentNameLabel:          "web",
+	}
+	k8s := k8sfake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespace,
+			Labels: map[string]string{k8sUtils.ApplicationIDLabel: "app1234", k8sUtils.OrganizationIDLabel: "org1234",
+				k8sUtils.NamespaceLabel: testNamespace, ComponentNameLabel: "web"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: testNamespace, Labels: podLabels},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		// not deployed by Conure, so not cached
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: testNamespace,
+			Labels: map[string]string{ApplicationNameLabel: "shop", ComponentNameLabel: "web"}}},
+		&networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespace, Labels: podLabels},
+			Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+				Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.10"}},
+			}}},
+		&autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespace, Labels: podLabels},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 2, DesiredReplicas: 3,
+				LastScaleTime: &metav1.Time{Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}}},
+		autoscalerEvent("web.1", "web", "SuccessfulRescale", time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)),
+		autoscalerEvent("web.2", "web", "SuccessfulRescale", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)),
+		autoscalerEvent("api.1", "api", "FailedGetObjectMetric", time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)),
+	)
+	k8s.Resources = []*metav1.APIResourceList{
+		{GroupVersion: velaApplicationResource.GroupVersion().String(), APIResources: []metav1.APIResource{{Name: "applications"}}},
+		{GroupVersion: httpRouteResource.GroupVersion().String(), APIResources: []metav1.APIResource{{Name: "httproutes"}}},
+	}
+	application := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": velaApplicationResource.GroupVersion().String(),
+		"kind":       "Application",
+		"metadata":   map[string]interface{}{"name": "shop", "namespace": testNamespace, "labels": toInterfaceMap(conureLabels)},
+		"spec": map[string]interface{}{"components": []interface{}{map[string]interface{}{
+			"name": "web", "type": "service", "properties": map[string]interface{}{"cpu": "0.5", "memory": "256Mi"},
+			"traits": []interface{}{
+				map[string]interface{}{"type": RouteTraitType, "properties": map[string]interface{}{
+					"mode": "ingress",
+					"routes": []interface{}{
+						map[string]interface{}{"host": "web.staging.example.com", "path": "/", "port": int64(8080), "tls": true},
+					},
+				}},
+				map[string]interface{}{"type": AutoscalerTraitType, "properties": map[string]interface{}{
+					"min": int64(1), "max": int64(5), "cpu": int64(80),
+				}},
+			},
+		}}},
+		"status": map[string]interface{}{
+			"status":   "running",
+			"services": []interface{}{map[string]interface{}{"name": "web", "healthy": true, "message": "Ready:1/1"}},
+		},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		velaApplicationResource: "ApplicationList",
+		httpRouteResource:       "HTTPRouteList",
+	}, append([]runtime.Object{application}, objects...)...)
+
+	cluster := newCluster(k8s, dynamicClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := cluster.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return cluster
+}
+
+func autoscalerEvent(name string, autoscaler string, reason string, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "HorizontalPodAutoscaler", Name: autoscaler},
+		Reason:         reason,
+		Message:        "New size: 3; reason: cpu resource utilization (percentage of request) above target",
+		Type:           corev1.EventTypeNormal,
+		Count:          1,
+		LastTimestamp:  metav1.Time{Time: at},
+	}
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		out[key] = value
+	}
+	return out
+}
+
+func TestProviderStatusVela_FromCache(t *testing.T) {
+	cluster := newTestCluster(t)
+	status, err := NewProviderStatusVela(cluster, "org1234", "app1234", testNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if phase, _ := status.GetApplicationStatus(); phase != "running" {
+		t.Errorf("Expected the application to be running, got: %v", phase)
+	}
+
+	health, err := status.GetComponentStatus("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !health.Healthy || health.Message != "Ready:1/1" {
+		t.Errorf("Expected the component to be healthy, got: %v", health)
+	}
+
+	resources, err := status.GetResourcesProperties("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources.CPU != "0.5" || resources.Memory != "256Mi" {
+		t.Errorf("Expected the resources from the spec, got: %v", resources)
+	}
+
+	pods, err := status.GetPodList("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 1 || pods[0].Name != "web-1" {
+		t.Errorf("Expected only the pod labelled by Conure, got: %v", pods)
+	}
+}
+
+func TestProviderStatusVela_Autoscaling(t *testing.T) {
+	cluster := newTestCluster(t)
+	status, err := NewProviderStatusVela(cluster, "org1234", "app1234", testNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources, err := status.GetResourcesProperties("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	autoscaling := resources.Autoscaling
+	if autoscaling == nil {
+		t.Fatal("Expected the autoscaling of the component")
+	}
+	if resources.Replicas != 2 || autoscaling.MinReplicas != 1 || autoscaling.MaxReplicas != 5 ||
+		autoscaling.CurrentReplicas != 2 || autoscaling.DesiredReplicas != 3 {
+		t.Errorf("Expected the replicas of the autoscaler, got: %d %+v", resources.Replicas, autoscaling)
+	}
+	if autoscaling.LastScaleTime == nil || !autoscaling.LastScaleTime.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the last scale time, got: %v", autoscaling.LastScaleTime)
+	}
+	if len(autoscaling.Events) != 2 || !autoscaling.Events[0].Time.After(autoscaling.Events[1].Time) {
+		t.Errorf("Expected the events of the autoscaler, the latest first, got: %+v", autoscaling.Events)
+	}
+}
+
+func TestProviderStatusVela_GetNetworkProperties_Routes(t *testing.T) {
+	cluster := newTestCluster(t)
+	status, err := NewProviderStatusVela(cluster, "org1234", "app1234", testNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties, err := status.GetNetworkProperties("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []RouteProperties{{
+		Host:    "web.staging.example.com",
+		Path:    "/",
+		Port:    8080,
+		URL:     "https://web.staging.example.com/",
+		TLS:     true,
+		Ready:   true,
+		Address: "203.0.113.10",
+	}}
+	if !reflect.DeepEqual(properties.Routes, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, properties.Routes)
+	}
+}
+
+func TestProviderStatusVela_HTTPRoutesStatus(t *testing.T) {
+	route := func(name string, host string, accepted metav1.ConditionStatus) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": httpRouteResource.GroupVersion().String(),
+			"kind":       "HTTPRoute",
+			"metadata": map[string]interface{}{"name": name, "namespace": testNamespace, "labels": map[string]interface{}{
+				k8sUtils.ApplicationIDLabel: "app1234", ApplicationNameLabel: "shop", ComponentNameLabel: "web",
+			}},
+			"spec": map[string]interface{}{"hostnames": []interface{}{host}},
+			"status": map[string]interface{}{"parents": []interface{}{map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{
+					"type": "Accepted", "status": string(accepted), "reason": "NotAllowedByListeners",
+					"message": "No listener accepts the route", "lastTransitionTime": "2024-05-01T10:00:00Z",
+				}},
+			}}},
+		}}
+	}
+	cluster := newTestCluster(t,
+		route("web-0", "web.staging.example.com", metav1.ConditionTrue),
+		route("web-1", "api.staging.example.com", metav1.ConditionFalse),
+	)
+	status, err := NewProviderStatusVela(cluster, "org1234", "app1234", testNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := []RouteProperties{{Host: "web.staging.example.com"}, {Host: "api.staging.example.com"}, {Host: "www.example.com"}}
+	if err = status.setRoutesStatus("web", "gateway", routes); err != nil {
+		t.Fatal(err)
+	}
+	if !routes[0].Ready || routes[0].Message != "" {
+		t.Errorf("Expected the accepted route to be ready, got: %+v", routes[0])
+	}
+	if routes[1].Ready || routes[1].Message != "No listener accepts the route" {
+		t.Errorf("Expected the reason the route wasn't accepted, got: %+v", routes[1])
+	}
+	if routes[2].Ready || routes[2].Message != "Waiting for the HTTPRoute" {
+		t.Errorf("Expected the route without HTTPRoute to be waiting, got: %+v", routes[2])
+	}
+}
+
+func TestProviderStatusVela_NotDeployed(t *testing.T) {
+	cluster := newTestCluster(t)
+	_, err := NewProviderStatusVela(cluster, "org1234", "other", testNamespace)
+	if err != k8sUtils.ErrApplicationNotFound {
+		t.Errorf("Expected ErrApplicationNotFound, got: %v", err)
+	}
+}
+
+func TestProviderDispatcherVela_DeleteComponent_Renamed(t *testing.T) {
+	// the application was deployed as shop and as shop-v1, then renamed to store without being deployed again
+	previous := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": velaApplicationResource.GroupVersion().String(),
+		"kind":       "Application",
+		"metadata": map[string]interface{}{"name": "shop-v1", "namespace": testNamespace, "labels": map[string]interface{}{
+			k8sUtils.ApplicationIDLabel: "app1234",
+		}},
+		"spec": map[string]interface{}{"components": []interface{}{
+			map[string]interface{}{"name": "web", "type": "service"},
+			map[string]interface{}{"name": "worker", "type": "worker"},
+		}},
+	}}
+	cluster := newTestCluster(t, previous)
+	dispatcher := &ProviderDispatcherVela{
+		Cluster:         cluster,
+		OrganizationID:  "org1234",
+		ApplicationID:   "app1234",
+		ApplicationName: "store",
+		Namespace:       testNamespace,
+		Environment:     "staging",
+	}
+	applications := cluster.dynamic.Resource(velaApplicationResource).Namespace(testNamespace)
+
+	if err := dispatcher.DeleteComponent(context.Background(), "worker"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := applications.Get(context.Background(), "shop-v1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	components, _, _ := unstructured.NestedSlice(updated.Object, "spec", "components")
+	if len(components) != 1 {
+		t.Errorf("Expected the worker to be removed from shop-v1, got: %v", components)
+	}
+
+	if err = dispatcher.DeleteComponent(context.Background(), "web"); err != nil {
+		t.Fatal(err)
+	}
+	list, err := applications.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("Expected the applications without components to be deleted, got %d", len(list.Items))
+	}
+}
+
+func TestProviderDispatcherVela_DeployApplication_Trace(t *testing.T) {
+	cluster := newTestCluster(t)
+	dispatcher := &ProviderDispatcherVela{
+		Cluster:         cluster,
+		OrganizationID:  "org1234",
+		ApplicationID:   "app1234",
+		ApplicationName: "blog",
+		Namespace:       "env5678-staging",
+		Environment:     "staging",
+	}
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	manifest := map[string]interface{}{
+		"apiVersion": velaApplicationResource.GroupVersion().String(),
+		"kind":       "Application",
+		"metadata":   map[string]interface{}{"name": "blog", "namespace": "env5678-staging"},
+	}
+	if err := dispatcher.DeployApplication(ctx, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	deployed, err := cluster.dynamic.Resource(velaApplicationResource).Namespace("env5678-staging").Get(context.Background(), "blog", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	traceParent := deployed.GetAnnotations()[telemetry.TraceParentAnnotation]
+	if !strings.Contains(traceParent, traceID.String()) {
+		t.Errorf("Expected the application to carry the trace %s, got: %q", traceID, traceParent)
+	}
+}
@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"log"
+
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SyncRegistryCredentials writes the docker config into the registry credentials secret of the namespace, or
+// removes the secret when dockerConfig is nil. Nothing is written in a namespace which doesn't exist, the
+// environment is synced when it's deployed.
+func (c *Cluster) SyncRegistryCredentials(ctx context.Context, namespace string, dockerConfig []byte) error {
+	secrets := c.k8s.CoreV1().Secrets(namespace)
+	if dockerConfig == nil {
+		err := secrets.Delete(ctx, k8sUtils.RegistryCredentialsSecret, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	secret, err := secrets.Get(ctx, k8sUtils.RegistryCredentialsSecret, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   k8sUtils.RegistryCredentialsSecret,
+				Labels: map[string]string{k8sUtils.CreatedByLabel: "conure"},
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: dockerConfig},
+		}
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+		if k8sErrors.IsNotFound(err) {
+			// the namespace doesn't exist
+			return nil
+		} else if err != nil {
+			return err
+		}
+		log.Printf("Created the registry credentials of namespace %q.\n", namespace)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if bytes.Equal(secret.Data[corev1.DockerConfigJsonKey], dockerConfig) {
+		return nil
+	}
+	secret.Data = map[string][]byte{corev1.DockerConfigJsonKey: dockerConfig}
+	if _, err = secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	log.Printf("Rotated the registry credentials of namespace %q.\n", namespace)
+	return nil
+}
@@ -0,0 +1,43 @@
+package providers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/coffeenights/conure/internal/telemetry"
+)
+
+var tracer = otel.Tracer("github.com/coffeenights/conure/cmd/api-server/providers")
+
+// startSpan traces a call of the dispatcher to the cluster
+func (p *ProviderDispatcherVela) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "vela."+name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("conure.application.id", p.ApplicationID),
+		attribute.String("conure.environment", p.Environment),
+		attribute.String("k8s.namespace.name", p.Namespace),
+	))
+}
+
+// endSpan ends the span with the result of the call
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// setTraceAnnotations passes the trace of the deploy to the controllers reconciling the object
+func setTraceAnnotations(ctx context.Context, object *unstructured.Unstructured) {
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	telemetry.InjectAnnotations(ctx, annotations)
+	object.SetAnnotations(annotations)
+}
@@ -3,16 +3,49 @@ package providers
 import "time"
 
 type NetworkProperties struct {
-	IP         string  `json:"ip"`
-	ExternalIP string  `json:"external_ip"`
-	Host       string  `json:"host"`
-	Ports      []int32 `json:"port"`
+	IP         string            `json:"ip"`
+	ExternalIP string            `json:"external_ip"`
+	Host       string            `json:"host"`
+	Ports      []int32           `json:"port"`
+	Routes     []RouteProperties `json:"routes"`
+}
+
+// RouteProperties is an HTTP route of the component and whether the Ingress controller or the Gateway serves it
+type RouteProperties struct {
+	Host string `json:"host"`
+	Path string `json:"path"`
+	Port int32  `json:"port"`
+	URL  string `json:"url"`
+	TLS  bool   `json:"tls"`
+	// Ready is set once the Ingress has an address or every parent Gateway accepted the HTTPRoute
+	Ready   bool   `json:"ready"`
+	Address string `json:"address,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 type ResourcesProperties struct {
-	Replicas int32  `json:"replicas"`
-	CPU      string `json:"cpu"`
-	Memory   string `json:"memory"`
+	Replicas    int32                  `json:"replicas"`
+	CPU         string                 `json:"cpu"`
+	Memory      string                 `json:"memory"`
+	Autoscaling *AutoscalingProperties `json:"autoscaling,omitempty"`
+}
+
+// AutoscalingProperties is the state of the HorizontalPodAutoscaler of the component, with its latest events
+type AutoscalingProperties struct {
+	MinReplicas     int32          `json:"min_replicas"`
+	MaxReplicas     int32          `json:"max_replicas"`
+	CurrentReplicas int32          `json:"current_replicas"`
+	DesiredReplicas int32          `json:"desired_replicas"`
+	LastScaleTime   *time.Time     `json:"last_scale_time,omitempty"`
+	Events          []ScalingEvent `json:"events"`
+}
+
+type ScalingEvent struct {
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+	Warning bool      `json:"warning"`
+	Count   int32     `json:"count"`
+	Time    time.Time `json:"time"`
 }
 
 type VolumeProperties struct {
@@ -67,3 +100,57 @@ type Pod struct {
 	Phase      string         `json:"phase"`
 	Conditions []PodCondition `json:"conditions"`
 }
+
+// StatusEventType is the name of the server-sent event carrying each status payload
+type StatusEventType string
+
+const (
+	ApplicationPhaseEventType   StatusEventType = "application"
+	ComponentReadinessEventType StatusEventType = "component"
+	PodPhaseEventType           StatusEventType = "pod"
+	WorkflowProgressEventType   StatusEventType = "workflow"
+)
+
+// StatusEvent is a change in the live status of an environment, Data is one of the *Event payloads below
+type StatusEvent struct {
+	Type StatusEventType
+	Data interface{}
+	// key identifies the object the event is about within its type
+	key string
+}
+
+type ApplicationPhaseEvent struct {
+	Phase   string `json:"phase"`
+	Deleted bool   `json:"deleted"`
+}
+
+type ComponentReadinessEvent struct {
+	Component string `json:"component"`
+	Healthy   bool   `json:"healthy"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Deleted   bool   `json:"deleted"`
+}
+
+type PodPhaseEvent struct {
+	Component string `json:"component"`
+	Pod       Pod    `json:"pod"`
+	Deleted   bool   `json:"deleted"`
+}
+
+type WorkflowRunPhase string
+
+const (
+	WorkflowRunPending   WorkflowRunPhase = "pending"
+	WorkflowRunRunning   WorkflowRunPhase = "running"
+	WorkflowRunSucceeded WorkflowRunPhase = "succeeded"
+	WorkflowRunFailed    WorkflowRunPhase = "failed"
+)
+
+type WorkflowProgressEvent struct {
+	Component   string           `json:"component"`
+	WorkflowRun string           `json:"workflow_run"`
+	Phase       WorkflowRunPhase `json:"phase"`
+	Message     string           `json:"message"`
+	Deleted     bool             `json:"deleted"`
+}
@@ -12,16 +12,27 @@ import (
 	"io"
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"log"
+	"slices"
 )
 
 const (
 	ComponentNameLabel   = "app.oam.dev/component"
 	ApplicationNameLabel = "app.oam.dev/name"
+	// RouteTraitType is the trait of k8s/vela/route-trait.cue rendering the routes of a component
+	RouteTraitType = "conure-route"
+	// AutoscalerTraitType is the trait of k8s/vela/autoscaler-trait.cue rendering the autoscaler of a component
+	AutoscalerTraitType = "conure-autoscaler"
+	// WorkloadTraitType is the trait of k8s/vela/workload-trait.cue patching the probes, the lifecycle and the
+	// rollout strategy into the workload of a component
+	WorkloadTraitType = "conure-workload"
+	// maxScalingEvents is how many of the latest events of an autoscaler are reported
+	maxScalingEvents = 10
 )
 
 type VelaComponent struct {
@@ -49,29 +60,26 @@ const (
 )
 
 type ProviderStatusVela struct {
+	Cluster         *Cluster
 	OrganizationID  string
 	ApplicationID   string
 	Namespace       string
 	VelaApplication *vela.Application
 }
 
-func NewProviderStatusVela(organizationID string, applicationID string, namespace string) (*ProviderStatusVela, error) {
-	clientset, err := k8sUtils.GetClientset()
-	if err != nil {
-		log.Printf("Error getting clientset: %v\n", err)
-		return nil, err
-	}
+func NewProviderStatusVela(cluster *Cluster, organizationID string, applicationID string, namespace string) (*ProviderStatusVela, error) {
 	filter := map[string]string{
 		k8sUtils.OrganizationIDLabel: organizationID,
 		k8sUtils.ApplicationIDLabel:  applicationID,
 	}
 
-	velaApplication, err := k8sUtils.GetApplicationByLabels(clientset, namespace, filter)
+	velaApplication, err := cluster.getApplication(namespace, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ProviderStatusVela{
+		Cluster:         cluster,
 		OrganizationID:  organizationID,
 		ApplicationID:   applicationID,
 		Namespace:       namespace,
@@ -83,48 +91,18 @@ func (p *ProviderStatusVela) GetApplicationStatus() (string, error) {
 	return string(p.VelaApplication.Status.Phase), nil
 }
 
-// TODO: Migrate this function to use the dynamic client
-//func (p *ProviderStatusVela) WatchApplicationStatus() error {
-//	clientset, err := k8sUtils.GetClientset()
-//	if err != nil {
-//		return err
-//	}
-//	watchInterface, err := clientset.Vela.CoreV1beta1().Applications(p.Namespace).Watch(context.Background(), metav1.ListOptions{})
-//	if err != nil {
-//		log.Fatalf("Error opening watch: %v", err)
-//	}
-//	defer watchInterface.Stop()
-//	for event := range watchInterface.ResultChan() {
-//		switch event.Type {
-//		case watch.Added:
-//			fmt.Println("Application added:", event.Object)
-//		case watch.Modified:
-//			fmt.Println("Application modified:", event.Object)
-//		case watch.Deleted:
-//			fmt.Println("Application deleted:", event.Object)
-//		case watch.Error:
-//			fmt.Println("Error:", event.Object)
-//		}
-//	}
-//	return nil
-//}
-
 func (p *ProviderStatusVela) GetComponentStatus(componentName string) (*ComponentStatusHealth, error) {
 	comp, err := p.getVelaComponent(componentName)
 	if err != nil {
 		return nil, err
 	}
-	clientset, err := k8sUtils.GetClientset()
-	if err != nil {
-		return nil, err
-	}
 	labels := map[string]string{
 		k8sUtils.ApplicationIDLabel:  p.ApplicationID,
 		k8sUtils.OrganizationIDLabel: p.OrganizationID,
 		k8sUtils.NamespaceLabel:      p.Namespace,
 		ComponentNameLabel:           comp.ComponentSpec.Name,
 	}
-	deployments, err := k8sUtils.GetDeploymentsByLabels(clientset.K8s, p.Namespace, labels)
+	deployments, err := p.Cluster.listDeployments(p.Namespace, labels)
 	if err != nil {
 		return nil, err
 	}
@@ -172,6 +150,7 @@ func (p *ProviderStatusVela) GetNetworkProperties(componentName string) (*Networ
 		return nil, err
 	}
 	// Information from trait
+	routeMode := ""
 	for _, trait := range velaComponent.ComponentSpec.Traits {
 		if trait.Type == "expose" {
 			err := getExposeTraitProperties(&trait, &properties)
@@ -179,19 +158,25 @@ func (p *ProviderStatusVela) GetNetworkProperties(componentName string) (*Networ
 				return nil, err
 			}
 		}
+		if trait.Type == RouteTraitType {
+			routeMode, err = getRouteTraitProperties(&trait, &properties)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(properties.Routes) > 0 {
+		if err = p.setRoutesStatus(componentName, routeMode, properties.Routes); err != nil {
+			return nil, err
+		}
 	}
 
 	// Information from Service
-	clientset, err := k8sUtils.GetClientset()
-	if err != nil {
-		log.Printf("Error getting clientset: %v\n", err)
-		return nil, err
-	}
 	filter := map[string]string{
 		k8sUtils.OrganizationIDLabel: p.OrganizationID,
 		k8sUtils.ApplicationIDLabel:  p.ApplicationID,
 	}
-	err = getNetworkPropertiesFromService(clientset, p.Namespace, filter, &properties)
+	err = getNetworkPropertiesFromService(p.Cluster, p.Namespace, filter, &properties)
 	if err != nil {
 		switch {
 		case !errors.Is(err, k8sUtils.ErrServiceNotFound):
@@ -216,6 +201,21 @@ func (p *ProviderStatusVela) GetResourcesProperties(componentName string) (*Reso
 			}
 			resources.Replicas = int32(traitsData["replicas"].(float64))
 		}
+		if trait.Type == AutoscalerTraitType {
+			traitsData, err := k8sUtils.ExtractMapFromRawExtension(trait.Properties)
+			if err != nil {
+				return nil, err
+			}
+			resources.Autoscaling = &AutoscalingProperties{
+				MinReplicas: int32(traitsData["min"].(float64)),
+				MaxReplicas: int32(traitsData["max"].(float64)),
+				Events:      []ScalingEvent{},
+			}
+			if err = p.setAutoscalingStatus(componentName, resources.Autoscaling); err != nil {
+				return nil, err
+			}
+			resources.Replicas = resources.Autoscaling.CurrentReplicas
+		}
 	}
 	propertiesData, err := k8sUtils.ExtractMapFromRawExtension(velaComponent.ComponentSpec.Properties)
 	if err != nil {
@@ -229,6 +229,40 @@ func (p *ProviderStatusVela) GetResourcesProperties(componentName string) (*Reso
 	return &resources, nil
 }
 
+// setAutoscalingStatus sets the replicas of the HorizontalPodAutoscaler of the component and its latest events
+func (p *ProviderStatusVela) setAutoscalingStatus(componentName string, autoscaling *AutoscalingProperties) error {
+	autoscalers, err := p.Cluster.listAutoscalers(p.Namespace, map[string]string{
+		ApplicationNameLabel: p.VelaApplication.Name,
+		ComponentNameLabel:   componentName,
+	})
+	if err != nil || len(autoscalers) == 0 {
+		return err
+	}
+	autoscaler := autoscalers[0]
+	autoscaling.CurrentReplicas = autoscaler.Status.CurrentReplicas
+	autoscaling.DesiredReplicas = autoscaler.Status.DesiredReplicas
+	if autoscaler.Status.LastScaleTime != nil {
+		autoscaling.LastScaleTime = &autoscaler.Status.LastScaleTime.Time
+	}
+	events, err := p.Cluster.listAutoscalerEvents(p.Namespace, autoscaler.Name)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if len(autoscaling.Events) == maxScalingEvents {
+			break
+		}
+		autoscaling.Events = append(autoscaling.Events, ScalingEvent{
+			Reason:  event.Reason,
+			Message: event.Message,
+			Warning: event.Type == corev1.EventTypeWarning,
+			Count:   event.Count,
+			Time:    eventTime(event),
+		})
+	}
+	return nil
+}
+
 func (p *ProviderStatusVela) GetStorageProperties(componentName string) (*StorageProperties, error) {
 	var storages StorageProperties
 	storages.Volumes = []VolumeProperties{}
@@ -298,20 +332,19 @@ func (p *ProviderStatusVela) GetSourceProperties(componentName string) (*SourceP
 }
 
 func (p *ProviderStatusVela) GetActivity(componentID string) error {
-	clientset, err := k8sUtils.GetClientset()
-	if err != nil {
-		return err
-	}
 	labels := map[string]string{
 		k8sUtils.ApplicationIDLabel:  p.ApplicationID,
 		k8sUtils.OrganizationIDLabel: p.OrganizationID,
 		k8sUtils.NamespaceLabel:      p.Namespace,
 		k8sUtils.ComponentIDLabel:    componentID,
 	}
-	deployments, err := k8sUtils.GetDeploymentsByLabels(clientset.K8s, p.Namespace, labels)
+	deployments, err := p.Cluster.listDeployments(p.Namespace, labels)
 	if err != nil {
 		return err
 	}
+	if len(deployments) == 0 {
+		return conureerrors.ErrComponentNotFound
+	}
 	deploymentSelector := fields.SelectorFromSet(fields.Set{
 		"involvedObject.kind": "Deployment",
 		"involvedObject.name": componentID,
@@ -320,7 +353,7 @@ func (p *ProviderStatusVela) GetActivity(componentID string) error {
 	listOptions := metav1.ListOptions{
 		FieldSelector: deploymentSelector.String(),
 	}
-	events, err := clientset.K8s.CoreV1().Events(p.Namespace).List(context.Background(), listOptions)
+	events, err := p.Cluster.k8s.CoreV1().Events(p.Namespace).List(context.Background(), listOptions)
 	if err != nil {
 		return err
 	}
@@ -329,54 +362,43 @@ func (p *ProviderStatusVela) GetActivity(componentID string) error {
 }
 
 func (p *ProviderStatusVela) GetPodList(componentName string) ([]Pod, error) {
-	clientset, err := k8sUtils.GetClientset()
-	if err != nil {
-		return nil, err
-	}
-
-	podSelector := fields.SelectorFromSet(fields.Set{
+	pods, err := p.Cluster.listPods(p.Namespace, map[string]string{
 		ApplicationNameLabel: p.VelaApplication.Name,
 		ComponentNameLabel:   componentName,
 	})
-
-	listOptions := metav1.ListOptions{
-		LabelSelector: podSelector.String(),
-	}
-	pods, err := clientset.K8s.CoreV1().Pods(p.Namespace).List(context.Background(), listOptions)
 	if err != nil {
 		return nil, err
 	}
 	var podList []Pod
-	for _, k8sPod := range pods.Items {
-		pod := Pod{
-			Name:  k8sPod.Name,
-			Phase: string(k8sPod.Status.Phase),
-		}
-		for _, k8sCondition := range k8sPod.Status.Conditions {
-			cond := PodCondition{
-				Type:    string(k8sCondition.Type),
-				Status:  string(k8sCondition.Status),
-				Reason:  k8sCondition.Reason,
-				Message: k8sCondition.Message,
-			}
-			pod.Conditions = append(pod.Conditions, cond)
-		}
-		podList = append(podList, pod)
+	for _, k8sPod := range pods {
+		podList = append(podList, podFromK8s(k8sPod))
 	}
 	return podList, nil
 }
 
-func (p *ProviderStatusVela) StreamLogs(c context.Context, podName string, logStream *LogStream, linesBuffer int) {
-	clientset, err := k8sUtils.GetClientset()
-	if err != nil {
-		logStream.Error <- err
-		return
+func podFromK8s(k8sPod *corev1.Pod) Pod {
+	pod := Pod{
+		Name:  k8sPod.Name,
+		Phase: string(k8sPod.Status.Phase),
+	}
+	for _, k8sCondition := range k8sPod.Status.Conditions {
+		cond := PodCondition{
+			Type:    string(k8sCondition.Type),
+			Status:  string(k8sCondition.Status),
+			Reason:  k8sCondition.Reason,
+			Message: k8sCondition.Message,
+		}
+		pod.Conditions = append(pod.Conditions, cond)
 	}
+	return pod
+}
+
+func (p *ProviderStatusVela) StreamLogs(c context.Context, podName string, logStream *LogStream, linesBuffer int) {
 	podLogOpts := corev1.PodLogOptions{
 		Follow: true,
 	}
 
-	req := clientset.K8s.CoreV1().Pods(p.Namespace).GetLogs(podName, &podLogOpts)
+	req := p.Cluster.k8s.CoreV1().Pods(p.Namespace).GetLogs(podName, &podLogOpts)
 	podLogs, err := req.Stream(c)
 	var statusError *k8sErrors.StatusError
 	if err != nil {
@@ -409,8 +431,8 @@ func (p *ProviderStatusVela) StreamLogs(c context.Context, podName string, logSt
 	}
 }
 
-func getNetworkPropertiesFromService(clientset *k8sUtils.GenericClientset, namespace string, labels map[string]string, properties *NetworkProperties) error {
-	services, err := k8sUtils.GetServicesByLabels(clientset, namespace, labels)
+func getNetworkPropertiesFromService(cluster *Cluster, namespace string, labels map[string]string, properties *NetworkProperties) error {
+	services, err := cluster.listServices(namespace, labels)
 	if err != nil {
 		return fmt.Errorf("error getting services: %v", err)
 	}
@@ -441,7 +463,87 @@ func getExposeTraitProperties(trait *vela.ApplicationTrait, properties *NetworkP
 	return nil
 }
 
+// getRouteTraitProperties adds the routes of the trait to the properties and returns how they're served
+func getRouteTraitProperties(trait *vela.ApplicationTrait, properties *NetworkProperties) (string, error) {
+	var routeTrait struct {
+		Mode   string
+		Routes []struct {
+			Host string
+			Path string
+			Port int32
+			TLS  bool
+		}
+	}
+	traitsData, err := k8sUtils.ExtractMapFromRawExtension(trait.Properties)
+	if err != nil {
+		return "", err
+	}
+	if err = mapstructure.Decode(traitsData, &routeTrait); err != nil {
+		return "", err
+	}
+	for _, route := range routeTrait.Routes {
+		scheme := "http"
+		if route.TLS {
+			scheme = "https"
+		}
+		properties.Routes = append(properties.Routes, RouteProperties{
+			Host: route.Host,
+			Path: route.Path,
+			Port: route.Port,
+			URL:  scheme + "://" + route.Host + route.Path,
+			TLS:  route.TLS,
+		})
+	}
+	return routeTrait.Mode, nil
+}
+
+// setRoutesStatus sets whether the routes are served from the Ingress or the HTTPRoutes rendered by the route trait
+func (p *ProviderStatusVela) setRoutesStatus(componentName string, mode string, routes []RouteProperties) error {
+	selector := map[string]string{
+		ApplicationNameLabel: p.VelaApplication.Name,
+		ComponentNameLabel:   componentName,
+	}
+	if mode == "gateway" {
+		httpRoutes, err := p.Cluster.listHTTPRoutes(p.Namespace, selector)
+		if err != nil {
+			return err
+		}
+		for i := range routes {
+			routes[i].Message = "Waiting for the HTTPRoute"
+			for _, httpRoute := range httpRoutes {
+				if slices.Contains(httpRoute.Spec.Hostnames, routes[i].Host) {
+					routes[i].Ready, routes[i].Message = httpRoute.accepted()
+					break
+				}
+			}
+		}
+		return nil
+	}
+	ingresses, err := p.Cluster.listIngresses(p.Namespace, selector)
+	if err != nil {
+		return err
+	}
+	for i := range routes {
+		routes[i].Message = "Waiting for the Ingress"
+		if len(ingresses) == 0 {
+			continue
+		}
+		routes[i].Message = "Waiting for an address from the Ingress controller"
+		for _, address := range ingresses[0].Status.LoadBalancer.Ingress {
+			routes[i].Address = address.IP
+			if address.Hostname != "" {
+				routes[i].Address = address.Hostname
+			}
+			routes[i].Ready = true
+			routes[i].Message = ""
+			break
+		}
+	}
+	return nil
+}
+
 type ProviderDispatcherVela struct {
+	Cluster         *Cluster
 	OrganizationID  string
 	ApplicationID   string
 	ApplicationName string
@@ -449,7 +551,7 @@ type ProviderDispatcherVela struct {
 	Environment     string
 }
 
-func (p *ProviderDispatcherVela) createNamespace(clientset *k8sUtils.GenericClientset) error {
+func (p *ProviderDispatcherVela) createNamespace(ctx context.Context) error {
 	options := metav1.CreateOptions{}
 	namespaceManifest := corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -461,30 +563,42 @@ func (p *ProviderDispatcherVela) createNamespace(clientset *k8sUtils.GenericClie
 			},
 		},
 	}
-	_, err := clientset.K8s.CoreV1().Namespaces().Create(context.Background(), &namespaceManifest, options)
+	_, err := p.Cluster.k8s.CoreV1().Namespaces().Create(ctx, &namespaceManifest, options)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (p *ProviderDispatcherVela) DeployApplication(manifest map[string]interface{}) error {
-	var statusError *k8sErrors.StatusError
-
-	clientset, err := k8sUtils.GetClientset()
-	if err != nil {
-		log.Printf("Error getting clientset: %v\n", err)
-		return err
+// ensureNamespace creates the namespace of the environment if necessary
+func (p *ProviderDispatcherVela) ensureNamespace(ctx context.Context) error {
+	err := p.createNamespace(ctx)
+	if k8sErrors.IsAlreadyExists(err) {
+		log.Printf("Namespace already exists, reusing it\n")
+		return nil
 	}
-	// Create namespace if necessary
-	err = p.createNamespace(clientset)
-	if errors.As(err, &statusError) {
-		if statusError.ErrStatus.Code == 409 {
-			log.Printf("Namespace already exists, reusing it\n")
-		} else {
+	return err
+}
+
+// SyncRegistryCredentials writes the registry credentials secret in the namespace of the environment, created if
+// necessary, before the application is deployed so its first pulls are authenticated
+func (p *ProviderDispatcherVela) SyncRegistryCredentials(ctx context.Context, dockerConfig []byte) (err error) {
+	ctx, span := p.startSpan(ctx, "SyncRegistryCredentials")
+	defer func() { endSpan(span, err) }()
+	if dockerConfig != nil {
+		if err = p.ensureNamespace(ctx); err != nil {
 			return err
 		}
-	} else if err != nil {
+	}
+	return p.Cluster.SyncRegistryCredentials(ctx, p.Namespace, dockerConfig)
+}
+
+func (p *ProviderDispatcherVela) DeployApplication(ctx context.Context, manifest map[string]interface{}) (err error) {
+	ctx, span := p.startSpan(ctx, "DeployApplication")
+	defer func() { endSpan(span, err) }()
+	var statusError *k8sErrors.StatusError
+
+	if err = p.ensureNamespace(ctx); err != nil {
 		return err
 	}
 
@@ -492,7 +606,8 @@ func (p *ProviderDispatcherVela) DeployApplication(manifest map[string]interface
 	deployment := &unstructured.Unstructured{
 		Object: manifest,
 	}
-	result, err := clientset.Dynamic.Resource(deploymentRes).Namespace(p.Namespace).Create(context.Background(), deployment, metav1.CreateOptions{})
+	setTraceAnnotations(ctx, deployment)
+	result, err := p.Cluster.dynamic.Resource(deploymentRes).Namespace(p.Namespace).Create(ctx, deployment, metav1.CreateOptions{})
 	if err != nil {
 		if errors.As(err, &statusError) {
 			if statusError.ErrStatus.Code == 409 {
@@ -503,17 +618,14 @@ func (p *ProviderDispatcherVela) DeployApplication(manifest map[string]interface
 		return err
 	}
 	log.Printf("Created deployment %q.\n", result.GetName())
-	return nil
+	return p.removeStaleApplications(ctx)
 }
 
-func (p *ProviderDispatcherVela) UpdateApplication(manifest map[string]interface{}) error {
-	clientset, err := k8sUtils.GetClientset()
-	if err != nil {
-		log.Printf("Error getting clientset: %v\n", err)
-		return err
-	}
+func (p *ProviderDispatcherVela) UpdateApplication(ctx context.Context, manifest map[string]interface{}) (err error) {
+	ctx, span := p.startSpan(ctx, "UpdateApplication")
+	defer func() { endSpan(span, err) }()
 	deploymentRes := schema.GroupVersionResource{Group: "core.oam.dev", Version: "v1beta1", Resource: "applications"}
-	resource, err := clientset.Dynamic.Resource(deploymentRes).Namespace(p.Namespace).Get(context.Background(), p.ApplicationName, metav1.GetOptions{})
+	resource, err := p.Cluster.dynamic.Resource(deploymentRes).Namespace(p.Namespace).Get(ctx, p.ApplicationName, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -521,10 +633,136 @@ func (p *ProviderDispatcherVela) UpdateApplication(manifest map[string]interface
 		Object: manifest,
 	}
 	deployment.SetResourceVersion(resource.GetResourceVersion())
-	result, err := clientset.Dynamic.Resource(deploymentRes).Namespace(p.Namespace).Update(context.Background(), deployment, metav1.UpdateOptions{})
+	setTraceAnnotations(ctx, deployment)
+	result, err := p.Cluster.dynamic.Resource(deploymentRes).Namespace(p.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
 	if err != nil {
 		return err
 	}
 	log.Printf("Updated deployment %q.\n", result.GetName())
+	return p.removeStaleApplications(ctx)
+}
+
+func (p *ProviderDispatcherVela) DeleteEnvironment(ctx context.Context) (err error) {
+	ctx, span := p.startSpan(ctx, "DeleteEnvironment")
+	defer func() { endSpan(span, err) }()
+	// Deleting the namespace removes the vela application and all the workloads it created
+	err = p.Cluster.k8s.CoreV1().Namespaces().Delete(ctx, p.Namespace, metav1.DeleteOptions{})
+	if k8sErrors.IsNotFound(err) {
+		log.Printf("Namespace %q not found, nothing to delete\n", p.Namespace)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	log.Printf("Deleted namespace %q.\n", p.Namespace)
+	return nil
+}
+
+var velaApplicationResource = schema.GroupVersionResource{Group: "core.oam.dev", Version: "v1beta1", Resource: "applications"}
+
+var httpRouteResource = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+
+// httpRoute holds the fields of the Gateway API HTTPRoutes read by Conure
+type httpRoute struct {
+	Spec struct {
+		Hostnames []string `json:"hostnames"`
+	} `json:"spec"`
+	Status struct {
+		Parents []struct {
+			Conditions []metav1.Condition `json:"conditions"`
+		} `json:"parents"`
+	} `json:"status"`
+}
+
+// accepted reports whether every parent Gateway accepted the route, with the reason of the first one which didn't
+func (r httpRoute) accepted() (bool, string) {
+	if len(r.Status.Parents) == 0 {
+		return false, "Waiting for the Gateway to accept the HTTPRoute"
+	}
+	for _, parent := range r.Status.Parents {
+		accepted := meta.FindStatusCondition(parent.Conditions, "Accepted")
+		if accepted == nil || accepted.Status != metav1.ConditionTrue {
+			if accepted != nil && accepted.Message != "" {
+				return false, accepted.Message
+			}
+			return false, "Waiting for the Gateway to accept the HTTPRoute"
+		}
+	}
+	return true, ""
+}
+
+// removeStaleApplications deletes the vela applications left behind in the namespace when the application is renamed,
+// the vela application name is immutable so a rename deploys a new one
+func (p *ProviderDispatcherVela) removeStaleApplications(ctx context.Context) error {
+	selector := fmt.Sprintf("%s=%s", k8sUtils.ApplicationIDLabel, p.ApplicationID)
+	list, err := p.Cluster.dynamic.Resource(velaApplicationResource).Namespace(p.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	for _, item := range list.Items {
+		if item.GetName() == p.ApplicationName {
+			continue
+		}
+		err = p.Cluster.dynamic.Resource(velaApplicationResource).Namespace(p.Namespace).Delete(ctx, item.GetName(), metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+		log.Printf("Deleted stale application %q.\n", item.GetName())
+	}
+	return nil
+}
+
+// DeleteComponent removes the component from the vela applications of the application in the namespace. They are
+// found by the id label and not by name, a renamed application keeps running under its old name until it's deployed
+// again.
+func (p *ProviderDispatcherVela) DeleteComponent(ctx context.Context, componentName string) (err error) {
+	ctx, span := p.startSpan(ctx, "DeleteComponent")
+	defer func() { endSpan(span, err) }()
+	selector := fmt.Sprintf("%s=%s", k8sUtils.ApplicationIDLabel, p.ApplicationID)
+	list, err := p.Cluster.dynamic.Resource(velaApplicationResource).Namespace(p.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	// an empty list means the application was never deployed in this environment
+	for i := range list.Items {
+		if err = p.removeComponent(ctx, &list.Items[i], componentName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProviderDispatcherVela) removeComponent(ctx context.Context, resource *unstructured.Unstructured, componentName string) error {
+	components, _, err := unstructured.NestedSlice(resource.Object, "spec", "components")
+	if err != nil {
+		return err
+	}
+	var remaining []interface{}
+	for _, component := range components {
+		if c, ok := component.(map[string]interface{}); ok && c["name"] == componentName {
+			continue
+		}
+		remaining = append(remaining, component)
+	}
+	if len(remaining) == len(components) {
+		return nil
+	}
+	if len(remaining) == 0 {
+		// an application without components is not valid, remove it completely
+		err = p.Cluster.dynamic.Resource(velaApplicationResource).Namespace(p.Namespace).Delete(ctx, resource.GetName(), metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+		log.Printf("Deleted application %q.\n", resource.GetName())
+		return nil
+	}
+	// vela garbage collects the workloads of the components removed from the spec
+	if err = unstructured.SetNestedSlice(resource.Object, remaining, "spec", "components"); err != nil {
+		return err
+	}
+	_, err = p.Cluster.dynamic.Resource(velaApplicationResource).Namespace(p.Namespace).Update(ctx, resource, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	log.Printf("Removed component %q from application %q.\n", componentName, resource.GetName())
 	return nil
 }
@@ -0,0 +1,353 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	"github.com/coffeenights/conure/apis/vela"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// statusEventsBuffer is how many events a client can fall behind before its stream is closed
+const statusEventsBuffer = 100
+
+var (
+	conureComponentResource   = conurev1alpha1.GroupVersion.WithResource("components")
+	conureWorkflowRunResource = conurev1alpha1.GroupVersion.WithResource("workflowruns")
+)
+
+// StatusWatcher streams the live status of the environments. The informers of a namespace are shared by all the
+// clients watching it and stopped when the last one leaves.
+type StatusWatcher struct {
+	k8s     kubernetes.Interface
+	dynamic dynamic.Interface
+	mu      sync.Mutex
+	watches map[watchKey]*namespaceWatch
+}
+
+type watchKey struct {
+	namespace     string
+	applicationID string
+}
+
+func NewStatusWatcher(k8s kubernetes.Interface, dynamicClient dynamic.Interface) *StatusWatcher {
+	return &StatusWatcher{
+		k8s:     k8s,
+		dynamic: dynamicClient,
+		watches: map[watchKey]*namespaceWatch{},
+	}
+}
+
+// Watch sends the current status of the application in the namespace, then every change until ctx is done.
+// The channel is closed when ctx is done or when the client falls too far behind.
+func (w *StatusWatcher) Watch(ctx context.Context, applicationID string, namespace string) (<-chan StatusEvent, error) {
+	key := watchKey{namespace: namespace, applicationID: applicationID}
+	nw := w.acquire(key)
+	if !cache.WaitForCacheSync(ctx.Done(), nw.synced...) {
+		w.release(key)
+		return nil, ctx.Err()
+	}
+	events := nw.subscribe()
+	go func() {
+		<-ctx.Done()
+		nw.unsubscribe(events)
+		w.release(key)
+	}()
+	return events, nil
+}
+
+func (w *StatusWatcher) acquire(key watchKey) *namespaceWatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	nw, ok := w.watches[key]
+	if !ok {
+		nw = w.start(key)
+		w.watches[key] = nw
+	}
+	nw.refs++
+	return nw
+}
+
+func (w *StatusWatcher) release(key watchKey) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	nw := w.watches[key]
+	nw.refs--
+	if nw.refs == 0 {
+		close(nw.stop)
+		delete(w.watches, key)
+	}
+}
+
+// start runs the informers of the resources served by the cluster, the Conure CRDs are missing when the
+// environments are deployed by another provider and the other way around
+func (w *StatusWatcher) start(key watchKey) *namespaceWatch {
+	nw := &namespaceWatch{
+		stop:        make(chan struct{}),
+		subscribers: map[chan StatusEvent]struct{}{},
+	}
+	applicationSelector := func(options *metav1.ListOptions) {
+		options.LabelSelector = fmt.Sprintf("%s=%s", k8sUtils.ApplicationIDLabel, key.applicationID)
+	}
+	sources := []struct {
+		resource schema.GroupVersionResource
+		events   func(obj interface{}) []StatusEvent
+		tweak    func(options *metav1.ListOptions)
+	}{
+		{velaApplicationResource, velaApplicationEvents, applicationSelector},
+		{conureComponentResource, conureComponentEvents, nil},
+		{conureWorkflowRunResource, workflowRunEvents, nil},
+	}
+	for _, source := range sources {
+		if !served(w.k8s.Discovery(), source.resource) {
+			continue
+		}
+		informer := dynamicinformer.NewFilteredDynamicInformer(w.dynamic, source.resource, key.namespace, 0, cache.Indexers{}, source.tweak).Informer()
+		nw.add(informer, source.events)
+	}
+	nw.add(coreinformers.NewPodInformer(w.k8s, key.namespace, 0, cache.Indexers{}), podEvents)
+	for _, informer := range nw.informers {
+		go informer.Run(nw.stop)
+	}
+	return nw
+}
+
+type namespaceWatch struct {
+	refs      int
+	stop      chan struct{}
+	informers []cache.SharedIndexInformer
+	synced    []cache.InformerSynced
+	snapshots []func() []StatusEvent
+	// mu serializes the broadcasts with the subscriptions, so a new client gets the snapshot before any change
+	mu          sync.Mutex
+	subscribers map[chan StatusEvent]struct{}
+}
+
+func (nw *namespaceWatch) add(informer cache.SharedIndexInformer, events func(obj interface{}) []StatusEvent) {
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			nw.broadcast(events(obj))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			nw.broadcast(changedEvents(events(oldObj), events(newObj)))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			nw.broadcast(changedEvents(events(obj), nil))
+		},
+	})
+	nw.informers = append(nw.informers, informer)
+	nw.synced = append(nw.synced, informer.HasSynced)
+	nw.snapshots = append(nw.snapshots, func() []StatusEvent {
+		var snapshot []StatusEvent
+		for _, obj := range informer.GetStore().List() {
+			snapshot = append(snapshot, events(obj)...)
+		}
+		return snapshot
+	})
+}
+
+func (nw *namespaceWatch) subscribe() chan StatusEvent {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	var snapshot []StatusEvent
+	for _, list := range nw.snapshots {
+		snapshot = append(snapshot, list()...)
+	}
+	events := make(chan StatusEvent, len(snapshot)+statusEventsBuffer)
+	for _, event := range snapshot {
+		events <- event
+	}
+	nw.subscribers[events] = struct{}{}
+	return events
+}
+
+func (nw *namespaceWatch) unsubscribe(events chan StatusEvent) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	if _, ok := nw.subscribers[events]; ok {
+		delete(nw.subscribers, events)
+		close(events)
+	}
+}
+
+func (nw *namespaceWatch) broadcast(events []StatusEvent) {
+	if len(events) == 0 {
+		return
+	}
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	for subscriber := range nw.subscribers {
+		for _, event := range events {
+			select {
+			case subscriber <- event:
+				continue
+			default:
+			}
+			// the client can't keep up, closing its stream makes it reconnect and start over from a snapshot
+			log.Printf("Status client fell behind, closing its stream\n")
+			delete(nw.subscribers, subscriber)
+			close(subscriber)
+			break
+		}
+	}
+}
+
+// changedEvents returns the events that differ between the old and the new state of an object, and the deletion of
+// the ones it no longer has
+func changedEvents(oldEvents []StatusEvent, newEvents []StatusEvent) []StatusEvent {
+	previous := make(map[string]StatusEvent, len(oldEvents))
+	for _, event := range oldEvents {
+		previous[string(event.Type)+"/"+event.key] = event
+	}
+	var changed []StatusEvent
+	for _, event := range newEvents {
+		id := string(event.Type) + "/" + event.key
+		if old, ok := previous[id]; !ok || !reflect.DeepEqual(old.Data, event.Data) {
+			changed = append(changed, event)
+		}
+		delete(previous, id)
+	}
+	for _, event := range oldEvents {
+		if _, ok := previous[string(event.Type)+"/"+event.key]; ok {
+			changed = append(changed, event.deleted())
+		}
+	}
+	return changed
+}
+
+func (e StatusEvent) deleted() StatusEvent {
+	switch data := e.Data.(type) {
+	case ApplicationPhaseEvent:
+		data.Deleted = true
+		e.Data = data
+	case ComponentReadinessEvent:
+		data.Deleted = true
+		e.Data = data
+	case PodPhaseEvent:
+		data.Deleted = true
+		e.Data = data
+	case WorkflowProgressEvent:
+		data.Deleted = true
+		e.Data = data
+	}
+	return e
+}
+
+func fromUnstructured(obj interface{}, out interface{}) bool {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, out); err != nil {
+		log.Printf("Error converting %s %q: %v\n", u.GetKind(), u.GetName(), err)
+		return false
+	}
+	return true
+}
+
+func velaApplicationEvents(obj interface{}) []StatusEvent {
+	var application vela.Application
+	if !fromUnstructured(obj, &application) {
+		return nil
+	}
+	events := []StatusEvent{{
+		Type: ApplicationPhaseEventType,
+		Data: ApplicationPhaseEvent{Phase: string(application.Status.Phase)},
+	}}
+	for _, service := range application.Status.Services {
+		events = append(events, StatusEvent{
+			Type: ComponentReadinessEventType,
+			key:  service.Name,
+			Data: ComponentReadinessEvent{Component: service.Name, Healthy: service.Healthy, Message: service.Message},
+		})
+	}
+	return events
+}
+
+func conureComponentEvents(obj interface{}) []StatusEvent {
+	var component conurev1alpha1.Component
+	if !fromUnstructured(obj, &component) {
+		return nil
+	}
+	readiness := ComponentReadinessEvent{Component: component.Name}
+	for _, condition := range component.Status.Conditions {
+		if condition.Type == conurev1alpha1.ComponentConditionTypeReady.String() {
+			readiness.Healthy = condition.Status == metav1.ConditionTrue &&
+				condition.Reason == conurev1alpha1.ComponentReadyRunningReason.String()
+			readiness.Reason = condition.Reason
+			readiness.Message = condition.Message
+		}
+	}
+	return []StatusEvent{{Type: ComponentReadinessEventType, key: component.Name, Data: readiness}}
+}
+
+func workflowRunEvents(obj interface{}) []StatusEvent {
+	var workflowRun conurev1alpha1.WorkflowRun
+	if !fromUnstructured(obj, &workflowRun) {
+		return nil
+	}
+	progress := WorkflowRunProgress(&workflowRun)
+	return []StatusEvent{{Type: WorkflowProgressEventType, key: workflowRun.Name, Data: progress}}
+}
+
+// WorkflowRunProgress returns the phase of the workflow run from its conditions
+func WorkflowRunProgress(workflowRun *conurev1alpha1.WorkflowRun) WorkflowProgressEvent {
+	progress := WorkflowProgressEvent{
+		Component:   workflowRun.Spec.ComponentName,
+		WorkflowRun: workflowRun.Name,
+		Phase:       WorkflowRunPending,
+	}
+	// the workflow controller keeps a single condition with the current step of the run
+	for _, condition := range workflowRun.Status.Conditions {
+		switch condition.Type {
+		case conurev1alpha1.ConditionTypeRunningAction.String(), conurev1alpha1.ConditionTypeFinishedAction.String():
+			progress.Phase = WorkflowRunRunning
+		case conurev1alpha1.ConditionTypeFinished.String():
+			progress.Phase = WorkflowRunFailed
+			if condition.Status == metav1.ConditionTrue {
+				progress.Phase = WorkflowRunSucceeded
+			}
+		default:
+			continue
+		}
+		progress.Message = condition.Message
+	}
+	return progress
+}
+
+func podEvents(obj interface{}) []StatusEvent {
+	k8sPod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+	component := k8sPod.Labels[ComponentNameLabel]
+	if component == "" {
+		component = k8sPod.Labels[k8sUtils.ComponentNameLabel]
+	}
+	if component == "" {
+		// not a workload of a component
+		return nil
+	}
+	return []StatusEvent{{
+		Type: PodPhaseEventType,
+		key:  k8sPod.Name,
+		Data: PodPhaseEvent{Component: component, Pod: podFromK8s(k8sPod)},
+	}}
+}
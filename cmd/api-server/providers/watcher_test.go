@@ -0,0 +1,178 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "env1234-production"
+
+func newTestWatcher(objects ...runtime.Object) (*StatusWatcher, *k8sfake.Clientset, *dynamicfake.FakeDynamicClient) {
+	k8s := k8sfake.NewSimpleClientset()
+	k8s.Resources = []*metav1.APIResourceList{
+		{GroupVersion: conurev1alpha1.GroupVersion.String(), APIResources: []metav1.APIResource{{Name: "components"}, {Name: "workflowruns"}}},
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		velaApplicationResource:   "ApplicationList",
+		conureComponentResource:   "ComponentList",
+		conureWorkflowRunResource: "WorkflowRunList",
+	}, objects...)
+	return NewStatusWatcher(k8s, dynamicClient), k8s, dynamicClient
+}
+
+func testWorkflowRun(conditions ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": conurev1alpha1.GroupVersion.String(),
+		"kind":       "WorkflowRun",
+		"metadata":   map[string]interface{}{"name": "web-abcde", "namespace": testNamespace},
+		"spec":       map[string]interface{}{"componentName": "web", "workflowName": "web", "applicationName": "shop"},
+		"status":     map[string]interface{}{"conditions": conditions},
+	}}
+}
+
+func testPod(phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: testNamespace, Labels: map[string]string{ComponentNameLabel: "web"}},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func nextEvent(t *testing.T, events <-chan StatusEvent) StatusEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Expected an event, the stream is closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+	return StatusEvent{}
+}
+
+func TestStatusWatcher_Watch(t *testing.T) {
+	component := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": conurev1alpha1.GroupVersion.String(),
+		"kind":       "Component",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": testNamespace},
+		"status": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{
+			"type": "Ready", "status": "True", "reason": "Running", "message": "Running", "lastTransitionTime": "2024-01-01T00:00:00Z",
+		}}},
+	}}
+	watcher, k8s, dynamicClient := newTestWatcher(component, testWorkflowRun())
+	_, err := k8s.CoreV1().Pods(testNamespace).Create(context.Background(), testPod(corev1.PodPending), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := watcher.Watch(ctx, "app1234", testNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the current state comes first
+	snapshot := map[StatusEventType]interface{}{}
+	for i := 0; i < 3; i++ {
+		event := nextEvent(t, events)
+		snapshot[event.Type] = event.Data
+	}
+	expected := map[StatusEventType]interface{}{
+		ComponentReadinessEventType: ComponentReadinessEvent{Component: "web", Healthy: true, Reason: "Running", Message: "Running"},
+		WorkflowProgressEventType:   WorkflowProgressEvent{Component: "web", WorkflowRun: "web-abcde", Phase: WorkflowRunPending},
+		PodPhaseEventType:           PodPhaseEvent{Component: "web", Pod: Pod{Name: "web-1", Phase: "Pending"}},
+	}
+	for eventType, data := range expected {
+		if !reflect.DeepEqual(snapshot[eventType], data) {
+			t.Errorf("Expected %v, got: %v", data, snapshot[eventType])
+		}
+	}
+
+	// then the changes
+	_, err = k8s.CoreV1().Pods(testNamespace).Update(context.Background(), testPod(corev1.PodRunning), metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := nextEvent(t, events)
+	if data, ok := event.Data.(PodPhaseEvent); !ok || data.Pod.Phase != "Running" {
+		t.Errorf("Expected the pod to be running, got: %v", event)
+	}
+
+	finished := map[string]interface{}{
+		"type": "Finished", "status": "True", "reason": "FinishedSuccessfully", "message": "Finished", "lastTransitionTime": "2024-01-01T00:00:00Z",
+	}
+	_, err = dynamicClient.Resource(conureWorkflowRunResource).Namespace(testNamespace).Update(context.Background(), testWorkflowRun(finished), metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event = nextEvent(t, events)
+	if data, ok := event.Data.(WorkflowProgressEvent); !ok || data.Phase != WorkflowRunSucceeded {
+		t.Errorf("Expected the workflow run to succeed, got: %v", event)
+	}
+
+	err = k8s.CoreV1().Pods(testNamespace).Delete(context.Background(), "web-1", metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event = nextEvent(t, events)
+	if data, ok := event.Data.(PodPhaseEvent); !ok || !data.Deleted {
+		t.Errorf("Expected the pod to be deleted, got: %v", event)
+	}
+
+	// the informers stop with the last client
+	cancel()
+	for range events {
+	}
+	time.Sleep(10 * time.Millisecond)
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+	if len(watcher.watches) != 0 {
+		t.Errorf("Expected no watches left, got: %v", watcher.watches)
+	}
+}
+
+func TestChangedEvents(t *testing.T) {
+	running := StatusEvent{Type: PodPhaseEventType, key: "web-1", Data: PodPhaseEvent{Component: "web", Pod: Pod{Name: "web-1", Phase: "Running"}}}
+	pending := StatusEvent{Type: PodPhaseEventType, key: "web-1", Data: PodPhaseEvent{Component: "web", Pod: Pod{Name: "web-1", Phase: "Pending"}}}
+	healthy := StatusEvent{Type: ComponentReadinessEventType, key: "web", Data: ComponentReadinessEvent{Component: "web", Healthy: true}}
+
+	if changed := changedEvents([]StatusEvent{running}, []StatusEvent{running}); len(changed) != 0 {
+		t.Errorf("Expected no changes, got: %v", changed)
+	}
+	changed := changedEvents([]StatusEvent{pending, healthy}, []StatusEvent{running})
+	if len(changed) != 2 {
+		t.Fatalf("Expected 2 changes, got: %v", changed)
+	}
+	if changed[0].Data.(PodPhaseEvent).Pod.Phase != "Running" {
+		t.Errorf("Expected the pod to be running, got: %v", changed[0])
+	}
+	if !changed[1].Data.(ComponentReadinessEvent).Deleted {
+		t.Errorf("Expected the component to be deleted, got: %v", changed[1])
+	}
+}
+
+func TestPodEvents_IgnoresOtherPods(t *testing.T) {
+	pod := testPod(corev1.PodRunning)
+	pod.Labels = map[string]string{k8sUtils.ComponentNameLabel: "worker"}
+	events := podEvents(pod)
+	if len(events) != 1 || events[0].Data.(PodPhaseEvent).Component != "worker" {
+		t.Errorf("Expected an event for the worker component, got: %v", events)
+	}
+	pod.Labels = nil
+	if events = podEvents(pod); len(events) != 0 {
+		t.Errorf("Expected no events for a pod outside the components, got: %v", events)
+	}
+}
@@ -0,0 +1,79 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CreateWorkflowRun triggers the workflow of a component, the workflow controller runs its actions
+func (c *Cluster) CreateWorkflowRun(ctx context.Context, workflowRun *conurev1alpha1.WorkflowRun) (*conurev1alpha1.WorkflowRun, error) {
+	workflowRun.TypeMeta = metav1.TypeMeta{
+		APIVersion: conurev1alpha1.GroupVersion.String(),
+		Kind:       "WorkflowRun",
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(workflowRun)
+	if err != nil {
+		return nil, err
+	}
+	created, err := c.dynamic.Resource(conureWorkflowRunResource).Namespace(workflowRun.Namespace).
+		Create(ctx, &unstructured.Unstructured{Object: object}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := &conurev1alpha1.WorkflowRun{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(created.Object, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WatchGitWorkflowRuns calls handle with the workflow runs triggered by a push, when they are added and on every
+// change, until ctx is done. It returns at once when the Conure CRDs aren't installed.
+func (c *Cluster) WatchGitWorkflowRuns(ctx context.Context, handle func(workflowRun *conurev1alpha1.WorkflowRun)) {
+	if !served(c.k8s.Discovery(), conureWorkflowRunResource) {
+		log.Println("The workflow runs aren't served, the commit statuses won't be reported")
+		return
+	}
+	gitRuns := func(options *metav1.ListOptions) {
+		options.LabelSelector = k8sUtils.GitCommitLabel
+	}
+	informer := dynamicinformer.NewFilteredDynamicInformer(c.dynamic, conureWorkflowRunResource, metav1.NamespaceAll, 0, cache.Indexers{}, gitRuns).Informer()
+	onChange := func(obj interface{}) {
+		var workflowRun conurev1alpha1.WorkflowRun
+		if fromUnstructured(obj, &workflowRun) {
+			handle(&workflowRun)
+		}
+	}
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: onChange,
+		UpdateFunc: func(_, newObj interface{}) {
+			onChange(newObj)
+		},
+	})
+	informer.Run(ctx.Done())
+}
+
+// AnnotateWorkflowRun sets an annotation of the workflow run
+func (c *Cluster) AnnotateWorkflowRun(ctx context.Context, workflowRun *conurev1alpha1.WorkflowRun, key string, value string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{key: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamic.Resource(conureWorkflowRunResource).Namespace(workflowRun.Namespace).
+		Patch(ctx, workflowRun.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
@@ -1,29 +1,33 @@
 package routes
 
 import (
+	"context"
 	"log"
-	"strings"
+	"net/http"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	apps "github.com/coffeenights/conure/cmd/api-server/applications"
+	"github.com/coffeenights/conure/cmd/api-server/audit"
 	"github.com/coffeenights/conure/cmd/api-server/auth"
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/health"
+	"github.com/coffeenights/conure/cmd/api-server/mail"
+	"github.com/coffeenights/conure/cmd/api-server/middlewares"
+	"github.com/coffeenights/conure/cmd/api-server/openapi"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
 	"github.com/coffeenights/conure/cmd/api-server/settings"
 	"github.com/coffeenights/conure/cmd/api-server/variables"
-	"github.com/coffeenights/conure/internal/config"
+	"github.com/coffeenights/conure/cmd/api-server/webhooks"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
 )
 
-func GenerateRouter() *gin.Engine {
-	conf := config.LoadConfig(apiConfig.Config{})
-	log.Println("Connecting to MongoDB")
-	mongo, err := database.ConnectToMongoDB(conf.MongoDBURI, conf.MongoDBName)
-	if err != nil {
-		log.Panic(err)
-	}
-	log.Println("Connected to MongoDB")
+// GenerateRouter wires the handlers, the informers and the background jobs run until ctx is done
+func GenerateRouter(ctx context.Context, serviceName string, conf *apiConfig.Config, mongo *database.MongoDB, probe *health.Probe) *gin.Engine {
+	var err error
 	var keyStorage variables.SecretKeyStorage
 	switch conf.AESStorageStrategy {
 	case "k8s":
@@ -47,35 +51,79 @@ func GenerateRouter() *gin.Engine {
 		log.Panic("Unknown AES storage strategy")
 	}
 
+	mailer, err := mail.NewSender(conf)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	log.Println("Connecting to Kubernetes")
+	clientset, err := k8sUtils.GetClientset()
+	if err != nil {
+		log.Panic(err)
+	}
+	cluster := providers.NewCluster(clientset)
+	if err = cluster.Start(ctx); err != nil {
+		log.Panic(err)
+	}
+	log.Println("Connected to Kubernetes")
+
+	probe.AddCheck("mongodb", mongo.Ping)
+	probe.AddCheck("kubernetes", cluster.Ping)
+	probe.AddCheck("secret_key", func(_ context.Context) error {
+		_, err := keyStorage.Load()
+		return err
+	})
+
+	go apps.RunOrganizationPurger(ctx, mongo, conf.OrganizationPurgeGrace)
+	go webhooks.NewStatusReporter(mongo, keyStorage, cluster).Run(ctx)
+
 	router := gin.New()
-	router.Use(gin.Logger(), gin.Recovery(), getCorsMiddleware())
-	appHandler := apps.NewApiHandler(conf, mongo)
-	settingsHandler := settings.NewApiHandler(conf, mongo, keyStorage)
-	authHandler := auth.NewAuthHandler(conf, mongo)
+	router.Use(
+		otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+			// the probes would flood the traces
+			return r.URL.Path != health.LivePath && r.URL.Path != health.ReadyPath
+		})),
+		middlewares.RequestID(),
+		middlewares.Logger(health.LivePath, health.ReadyPath),
+		gin.Recovery(),
+		getCorsMiddleware(conf.CorsOrigins),
+		middlewares.Shutdown(probe.Stopping()),
+		audit.Middleware(mongo),
+	)
+	health.GenerateRoutes(router, probe)
+	appHandler := apps.NewApiHandler(conf, mongo, cluster, keyStorage)
+	settingsHandler := settings.NewApiHandler(conf, mongo, keyStorage, cluster)
+	authHandler := auth.NewAuthHandler(conf, mongo, mailer)
 	variablesHandler := variables.NewVariablesHandler(conf, mongo, keyStorage)
+	auditHandler := audit.NewAuditHandler(conf, mongo)
+	webhooksHandler := webhooks.NewApiHandler(conf, mongo, keyStorage, cluster)
 	auth.GenerateRoutes("/auth", router, authHandler)
 	apps.GenerateRoutes("/organizations", router, appHandler)
+	audit.GenerateRoutes("/organizations", router, auditHandler)
 	settings.GenerateRoutes("/settings", router, settingsHandler)
 	variables.GenerateRoutes("/variables", router, variablesHandler)
+	webhooks.GenerateRoutes("/webhooks", router, webhooksHandler)
+	openapi.GenerateRoutes("/openapi.json", router)
 	return router
 }
 
-func allowOrigin(origin string) bool {
-	conf := config.LoadConfig(apiConfig.Config{})
-	origins := strings.Split(conf.CorsOrigins, ";")
+// allowOrigins returns whether an origin is one of origins, "*" allows them all
+func allowOrigins(origins []string) func(origin string) bool {
+	allowed := make(map[string]bool, len(origins))
 	for _, o := range origins {
-		if o == origin || o == "*" {
-			return true
-		}
+		allowed[o] = true
+	}
+	return func(origin string) bool {
+		return allowed["*"] || allowed[origin]
 	}
-	return true
 }
 
-func getCorsMiddleware() gin.HandlerFunc {
+func getCorsMiddleware(origins []string) gin.HandlerFunc {
 	return cors.New(cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "X-Request-ID"},
+		ExposeHeaders:    []string{"X-Request-ID", "Retry-After", "X-Next-Cursor"},
 		AllowCredentials: true,
-		AllowOriginFunc:  allowOrigin,
+		AllowOriginFunc:  allowOrigins(origins),
 	})
 }
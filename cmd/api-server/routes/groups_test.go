@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetCorsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		allowed bool
+	}{
+		{"allowed origin", []string{"https://app.example.com"}, "https://app.example.com", true},
+		{"disallowed origin", []string{"https://app.example.com"}, "https://evil.example.com", false},
+		{"wildcard", []string{"*"}, "https://evil.example.com", true},
+		{"no origins", nil, "https://app.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(getCorsMiddleware(tt.origins))
+			router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if tt.allowed {
+				if w.Code != http.StatusOK {
+					t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+				}
+				if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+					t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.origin, got)
+				}
+			} else {
+				if w.Code != http.StatusForbidden {
+					t.Errorf("expected status %d, got %d", http.StatusForbidden, w.Code)
+				}
+				if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+					t.Errorf("expected no Access-Control-Allow-Origin, got %q", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+)
+
+var errInvalidCredentials = errors.New("the credentials were refused")
+
+func (g *GitHub) ApiURL() string {
+	if g.ApiUrl == "" {
+		return "https://api.github.com"
+	}
+	return strings.TrimSuffix(g.ApiUrl, "/")
+}
+
+func (g *GitHub) TestConnection(ctx context.Context, client *http.Client) error {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+g.Token)
+	header.Set("Accept", "application/vnd.github+json")
+	return get(ctx, client, g.ApiURL()+"/user", header)
+}
+
+func (g *GitLab) ApiURL() string {
+	if g.ApiUrl == "" {
+		return "https://gitlab.com"
+	}
+	return strings.TrimSuffix(g.ApiUrl, "/")
+}
+
+func (g *GitLab) TestConnection(ctx context.Context, client *http.Client) error {
+	header := http.Header{}
+	header.Set("PRIVATE-TOKEN", g.Token)
+	return get(ctx, client, g.ApiURL()+"/api/v4/user", header)
+}
+
+func (g *Gitea) ApiURL() string {
+	return strings.TrimSuffix(g.ApiUrl, "/")
+}
+
+// Validate requires the URL of the server, gitea is only self-hosted
+func (g *Gitea) Validate() error {
+	if g.ApiUrl == "" {
+		return conureerrors.ErrInvalidRequest
+	}
+	return nil
+}
+
+func (g *Gitea) TestConnection(ctx context.Context, client *http.Client) error {
+	header := http.Header{}
+	header.Set("Authorization", "token "+g.Token)
+	return get(ctx, client, g.ApiURL()+"/api/v1/user", header)
+}
+
+// TestConnection logs in the registry like docker login, with the token of the registry when it asks for one
+func (r *DockerRegistry) TestConnection(ctx context.Context, client *http.Client) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, registryURL(r.RegistryUrl)+"/v2/", nil)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(r.Username, r.Password)
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+	challenge := response.Header.Get("WWW-Authenticate")
+	if response.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(challenge, "Bearer ") {
+		return statusError(response)
+	}
+	params := parseChallenge(strings.TrimPrefix(challenge, "Bearer "))
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("the registry sent an invalid challenge: %s", challenge)
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	realm.RawQuery = query.Encode()
+	request, err = http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(r.Username, r.Password)
+	tokenResponse, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer tokenResponse.Body.Close()
+	if tokenResponse.StatusCode != http.StatusOK {
+		return statusError(tokenResponse)
+	}
+	return nil
+}
+
+// registryURL returns the base URL of the registry API, https unless the URL of the integration says otherwise
+func registryURL(registry string) string {
+	host := k8sUtils.RegistryHost(registry)
+	if host == "index.docker.io" {
+		host = "registry-1.docker.io"
+	}
+	if strings.HasPrefix(registry, "http://") {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+// parseChallenge returns the parameters of a WWW-Authenticate challenge, like realm="https://auth.docker.io/token"
+func parseChallenge(challenge string) map[string]string {
+	params := map[string]string{}
+	for challenge != "" {
+		key, rest, ok := strings.Cut(challenge, "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			// the quoted values may hold commas, like the scopes
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+			rest = strings.TrimPrefix(rest, ",")
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+		challenge = strings.TrimSpace(rest)
+	}
+	return params
+}
+
+func get(ctx context.Context, client *http.Client, endpoint string, header http.Header) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header = header
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return statusError(response)
+	}
+	return nil
+}
+
+func statusError(response *http.Response) error {
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		return errInvalidCredentials
+	}
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("%s returned %d: %s", response.Request.URL.Redacted(), response.StatusCode, message)
+}
@@ -1,22 +1,41 @@
 package settings
 
 import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
 	"github.com/coffeenights/conure/cmd/api-server/variables"
 )
 
+// testConnectionTimeout bounds the calls to the services of the integrations
+const testConnectionTimeout = 10 * time.Second
+
 type ApiHandler struct {
 	MongoDB    *database.MongoDB
 	Config     *apiConfig.Config
 	keyStorage variables.SecretKeyStorage
+	// cluster receives the rotated registry credentials, they are only synced on deploy when nil
+	cluster *providers.Cluster
+	// client tests the connections of the integrations
+	client *http.Client
 }
 
 func NewApiHandler(config *apiConfig.Config, mongo *database.MongoDB,
-	keyStorage variables.SecretKeyStorage) *ApiHandler {
+	keyStorage variables.SecretKeyStorage, cluster *providers.Cluster) *ApiHandler {
 	return &ApiHandler{
 		MongoDB:    mongo,
 		Config:     config,
 		keyStorage: keyStorage,
+		cluster:    cluster,
+		client:     &http.Client{Timeout: testConnectionTimeout},
 	}
 }
+
+func (h *ApiHandler) db(c *gin.Context) *database.MongoDB {
+	return h.MongoDB.ForRequest(c.Request)
+}
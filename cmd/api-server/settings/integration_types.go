@@ -0,0 +1,182 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/variables"
+)
+
+// IntegrationValue is the decrypted value of an integration. It's a struct whose tags describe its fields: json
+// names them, validate checks them and secret:"true" keeps them out of the API responses.
+type IntegrationValue interface {
+	// TestConnection checks the value against the service it connects to
+	TestConnection(ctx context.Context, client *http.Client) error
+}
+
+// valueValidator is implemented by the values with rules spanning several fields
+type valueValidator interface {
+	Validate() error
+}
+
+type IntegrationType struct {
+	Name        string
+	Description string
+	// New returns an empty value of the type
+	New func() IntegrationValue
+}
+
+type IntegrationField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Secret   bool   `json:"secret"`
+}
+
+type IntegrationSchema struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Fields      []IntegrationField `json:"fields"`
+}
+
+var integrationTypes = map[string]*IntegrationType{}
+
+var validate = validator.New(validator.WithRequiredStructEnabled())
+
+func init() {
+	// the validation errors are named after the fields of the requests
+	validate.RegisterTagNameFunc(jsonName)
+
+	RegisterIntegrationType(&IntegrationType{Name: DOCKER_REGISTRY, Description: "Credentials of an OCI registry, to pull and push the images",
+		New: func() IntegrationValue { return &DockerRegistry{} }})
+	RegisterIntegrationType(&IntegrationType{Name: GITHUB, Description: "GitHub or GitHub Enterprise account, to build on push",
+		New: func() IntegrationValue { return &GitHub{} }})
+	RegisterIntegrationType(&IntegrationType{Name: GITLAB, Description: "GitLab account, to build on push",
+		New: func() IntegrationValue { return &GitLab{} }})
+	RegisterIntegrationType(&IntegrationType{Name: GITEA, Description: "Gitea server, to build on push",
+		New: func() IntegrationValue { return &Gitea{} }})
+}
+
+// RegisterIntegrationType makes a type of integration available to the API, the names are unique
+func RegisterIntegrationType(integrationType *IntegrationType) {
+	if _, exists := integrationTypes[integrationType.Name]; exists {
+		panic(fmt.Sprintf("integration type %s registered twice", integrationType.Name))
+	}
+	integrationTypes[integrationType.Name] = integrationType
+}
+
+func GetIntegrationType(name string) (*IntegrationType, bool) {
+	integrationType, ok := integrationTypes[name]
+	return integrationType, ok
+}
+
+// IntegrationSchemas returns the schemas of the registered types, sorted by name
+func IntegrationSchemas() []IntegrationSchema {
+	schemas := make([]IntegrationSchema, 0, len(integrationTypes))
+	for _, integrationType := range integrationTypes {
+		schemas = append(schemas, integrationType.Schema())
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+	return schemas
+}
+
+func (t *IntegrationType) Schema() IntegrationSchema {
+	schema := IntegrationSchema{Name: t.Name, Description: t.Description, Fields: []IntegrationField{}}
+	eachField(reflect.ValueOf(t.New()), func(field reflect.StructField, _ reflect.Value) {
+		schema.Fields = append(schema.Fields, IntegrationField{
+			Name:     jsonName(field),
+			Type:     field.Type.Kind().String(),
+			Required: strings.Contains(field.Tag.Get("validate"), "required"),
+			Secret:   field.Tag.Get("secret") == "true",
+		})
+	})
+	return schema
+}
+
+// Decode returns the value of a request, validated
+func (t *IntegrationType) Decode(raw interface{}) (IntegrationValue, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	value := t.New()
+	if err = json.Unmarshal(data, value); err != nil {
+		return nil, err
+	}
+	if err = validate.Struct(value); err != nil {
+		return nil, err
+	}
+	if v, ok := value.(valueValidator); ok {
+		if err = v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// Redact returns the fields of the value which aren't secret
+func Redact(value IntegrationValue) map[string]interface{} {
+	fields := map[string]interface{}{}
+	eachField(reflect.ValueOf(value), func(field reflect.StructField, fieldValue reflect.Value) {
+		if field.Tag.Get("secret") != "true" {
+			fields[jsonName(field)] = fieldValue.Interface()
+		}
+	})
+	return fields
+}
+
+// EncryptIntegration sets the encrypted value of the integration
+func EncryptIntegration(keyStorage variables.SecretKeyStorage, integration *models.Integration, value IntegrationValue) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	integration.IntegrationValue = variables.EncryptValue(keyStorage, string(data))
+	return nil
+}
+
+// DecryptIntegrationValue returns the value of the integration, typed after its integration type
+func DecryptIntegrationValue(keyStorage variables.SecretKeyStorage, integration models.Integration) (IntegrationValue, error) {
+	integrationType, ok := GetIntegrationType(integration.IntegrationType)
+	if !ok {
+		return nil, fmt.Errorf("integration %s has the unknown type %s", integration.ID.Hex(), integration.IntegrationType)
+	}
+	value := integrationType.New()
+	if err := DecryptIntegration(keyStorage, integration, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// eachField calls fn with the fields of the struct pointed by value, the fields of the embedded structs included
+func eachField(value reflect.Value, fn func(field reflect.StructField, fieldValue reflect.Value)) {
+	value = reflect.Indirect(value)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			eachField(value.Field(i), fn)
+			continue
+		}
+		if field.IsExported() && jsonName(field) != "" {
+			fn(field, value.Field(i))
+		}
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,155 @@
+package settings
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+)
+
+func decode(t *testing.T, integrationType string, raw map[string]string) (IntegrationValue, error) {
+	t.Helper()
+	registered, ok := GetIntegrationType(integrationType)
+	require.True(t, ok, integrationType)
+	return registered.Decode(raw)
+}
+
+func TestIntegrationSchemas(t *testing.T) {
+	schemas := IntegrationSchemas()
+	var names []string
+	for _, schema := range schemas {
+		names = append(names, schema.Name)
+	}
+	assert.Equal(t, []string{DOCKER_REGISTRY, GITEA, GITHUB, GITLAB}, names)
+
+	assert.Equal(t, []IntegrationField{
+		{Name: "registry_url", Type: "string", Required: true},
+		{Name: "username", Type: "string", Required: true},
+		{Name: "password", Type: "string", Required: true, Secret: true},
+	}, schemas[0].Fields)
+	// the fields of the embedded GitProvider
+	assert.Equal(t, []IntegrationField{
+		{Name: "api_url", Type: "string"},
+		{Name: "token", Type: "string", Required: true, Secret: true},
+		{Name: "webhook_secret", Type: "string", Required: true, Secret: true},
+	}, schemas[2].Fields)
+}
+
+func TestIntegrationType_Decode(t *testing.T) {
+	value, err := decode(t, DOCKER_REGISTRY, map[string]string{"registry_url": "ghcr.io", "username": "conure", "password": "s3cr3t"})
+	require.NoError(t, err)
+	assert.Equal(t, &DockerRegistry{RegistryUrl: "ghcr.io", Username: "conure", Password: "s3cr3t"}, value)
+
+	_, err = decode(t, DOCKER_REGISTRY, map[string]string{"registry_url": "ghcr.io"})
+	var validationErrs validator.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	var fields []string
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, fieldErr.Field())
+	}
+	assert.Equal(t, []string{"username", "password"}, fields)
+
+	_, err = decode(t, GITHUB, map[string]string{"api_url": "not a url", "token": "token", "webhook_secret": "secret"})
+	assert.ErrorAs(t, err, &validationErrs)
+
+	// gitea is only self-hosted
+	_, err = decode(t, GITEA, map[string]string{"token": "token", "webhook_secret": "secret"})
+	assert.ErrorIs(t, err, conureerrors.ErrInvalidRequest)
+	_, err = decode(t, GITEA, map[string]string{"api_url": "https://git.coffeenights.dev", "token": "token", "webhook_secret": "secret"})
+	assert.NoError(t, err)
+}
+
+func TestRedact(t *testing.T) {
+	value, err := decode(t, GITLAB, map[string]string{"api_url": "https://gitlab.coffeenights.dev", "token": "token", "webhook_secret": "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"api_url": "https://gitlab.coffeenights.dev"}, Redact(value))
+
+	value, err = decode(t, DOCKER_REGISTRY, map[string]string{"registry_url": "ghcr.io", "username": "conure", "password": "s3cr3t"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"registry_url": "ghcr.io", "username": "conure"}, Redact(value))
+}
+
+// newRegistry is a stand-in registry, asking for a token like Docker Hub when tokenAuth is set
+func newRegistry(t *testing.T, tokenAuth bool) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		valid := username == "conure" && password == "s3cr3t"
+		switch {
+		case r.URL.Path == "/token":
+			if !valid || r.URL.Query().Get("service") != "registry.test" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token": "abc"}`))
+		case r.URL.Path == "/v2/" && tokenAuth:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry.test",scope="repository:a:pull,push"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/" && valid:
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDockerRegistry_TestConnection(t *testing.T) {
+	for _, tokenAuth := range []bool{false, true} {
+		server := newRegistry(t, tokenAuth)
+		registry := &DockerRegistry{RegistryUrl: server.URL, Username: "conure", Password: "s3cr3t"}
+		assert.NoError(t, registry.TestConnection(context.Background(), server.Client()), "token auth: %v", tokenAuth)
+
+		registry.Password = "wrong"
+		assert.ErrorIs(t, registry.TestConnection(context.Background(), server.Client()), errInvalidCredentials, "token auth: %v", tokenAuth)
+	}
+}
+
+func TestGitProviders_TestConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized := map[string]bool{
+			"/user":        r.Header.Get("Authorization") == "Bearer token",
+			"/api/v4/user": r.Header.Get("PRIVATE-TOKEN") == "token",
+			"/api/v1/user": r.Header.Get("Authorization") == "token token",
+		}
+		ok, known := authorized[r.URL.Path]
+		switch {
+		case !known:
+			w.WriteHeader(http.StatusNotFound)
+		case !ok:
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte(`{"login": "conure"}`))
+		}
+	}))
+	defer server.Close()
+
+	for _, integrationType := range []string{GITHUB, GITLAB, GITEA} {
+		value, err := decode(t, integrationType, map[string]string{"api_url": server.URL, "token": "token", "webhook_secret": "secret"})
+		require.NoError(t, err)
+		assert.NoError(t, value.TestConnection(context.Background(), server.Client()), integrationType)
+
+		value, err = decode(t, integrationType, map[string]string{"api_url": server.URL, "token": "wrong", "webhook_secret": "secret"})
+		require.NoError(t, err)
+		assert.ErrorIs(t, value.TestConnection(context.Background(), server.Client()), errInvalidCredentials, integrationType)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	params := parseChallenge(`realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull,push",
+	}, params)
+}
@@ -1,16 +1,20 @@
 package settings
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/coffeenights/conure/cmd/api-server/audit"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
 	"github.com/coffeenights/conure/cmd/api-server/variables"
 )
 
@@ -22,7 +26,7 @@ func (a *ApiHandler) CreateIntegration(c *gin.Context) {
 		return
 	}
 	org := models.Organization{}
-	_, err := org.GetById(a.MongoDB, c.Param("organizationID"))
+	_, err := org.GetById(a.db(c), c.Param("organizationID"))
 	if err != nil {
 		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
 		return
@@ -39,98 +43,173 @@ func (a *ApiHandler) CreateIntegration(c *gin.Context) {
 		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
 		return
 	}
-	if validateIntegrationType(c, request) {
+	value, err := decodeIntegrationValue(request)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
 		return
 	}
 
-	stringValue, err := convertToString(request.IntegrationValue)
+	integration := models.Integration{
+		Name:            request.Name,
+		OrganizationID:  org.ID,
+		IntegrationType: request.IntegrationType,
+	}
+	if err = EncryptIntegration(a.keyStorage, &integration, value); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	err = integration.Create(a.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
-	valueEncrypted := variables.EncryptValue(a.keyStorage, stringValue)
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditIntegrationCreated,
+		OrganizationID: org.ID,
+		TargetType:     "integration",
+		TargetID:       integration.ID.Hex(),
+		After: map[string]interface{}{
+			"name":             integration.Name,
+			"integration_type": integration.IntegrationType,
+		},
+	})
+	if integration.IntegrationType == DOCKER_REGISTRY {
+		a.rotateRegistryCredentials(context.WithoutCancel(c.Request.Context()), a.db(c), org.ID)
+	}
+	integration.Fields = Redact(value)
+	c.JSON(http.StatusCreated, integration)
+}
 
-	integration := models.Integration{
-		Name:             request.Name,
-		OrganizationID:   org.ID,
-		IntegrationType:  request.IntegrationType,
-		IntegrationValue: valueEncrypted,
+// UpdateIntegration replaces the name and the value of an integration, the registry credentials synced in the
+// environments of the organization are rotated with it
+func (a *ApiHandler) UpdateIntegration(c *gin.Context) {
+	org, integration, ok := a.getIntegration(c)
+	if !ok {
+		return
 	}
-	err = integration.Create(a.MongoDB)
+	request := CreateIntegrationRequest{}
+	err := c.BindJSON(&request)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	// the type of an integration can't change
+	if request.IntegrationType != integration.IntegrationType {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	value, err := decodeIntegrationValue(request)
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, integration)
+
+	before := map[string]interface{}{"name": integration.Name}
+	integration.Name = request.Name
+	if err = EncryptIntegration(a.keyStorage, integration, value); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	err = integration.Update(a.db(c))
+	if err != nil {
+		log.Printf("Error updating integration: %v\n", err)
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditIntegrationUpdated,
+		OrganizationID: org.ID,
+		TargetType:     "integration",
+		TargetID:       integration.ID.Hex(),
+		Before:         before,
+		After: map[string]interface{}{
+			"name":             integration.Name,
+			"integration_type": integration.IntegrationType,
+		},
+	})
+	if integration.IntegrationType == DOCKER_REGISTRY {
+		a.rotateRegistryCredentials(context.WithoutCancel(c.Request.Context()), a.db(c), org.ID)
+	}
+	integration.Fields = Redact(value)
+	c.JSON(http.StatusOK, integration)
 }
 
-func validateIntegrationType(c *gin.Context, request CreateIntegrationRequest) bool {
+// TestIntegration checks the stored value of the integration against its service, a failure is reported in the
+// response
+func (a *ApiHandler) TestIntegration(c *gin.Context) {
+	_, integration, ok := a.getIntegration(c)
+	if !ok {
+		return
+	}
+	value, err := DecryptIntegrationValue(a.keyStorage, *integration)
+	if err != nil {
+		log.Printf("Error decrypting integration: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	response := TestConnectionResponse{Success: true}
+	if err = value.TestConnection(c.Request.Context(), a.client); err != nil {
+		response = TestConnectionResponse{Success: false, Message: err.Error()}
+	}
+	c.JSON(http.StatusOK, response)
+}
 
-	switch request.IntegrationType {
-	case DOCKER_REGISTRY:
-		// if the integration type is DOCKER_REGISTRY, the value should be a JSON object with the following keys:
-		// - registryUrl
-		// - username
-		// - password
-		dockerRegistry := DockerRegistry{}
-		jsonValue, err := json.Marshal(request.IntegrationValue)
-		if err != nil {
-			conureerrors.AbortWithError(c, err)
-			return true
-		}
+// ListIntegrationTypes returns the schemas of the values of the integration types
+func (a *ApiHandler) ListIntegrationTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, IntegrationSchemas())
+}
 
-		err = json.Unmarshal(jsonValue, &dockerRegistry)
-		if err != nil {
-			conureerrors.AbortWithError(c, err)
-			return true
-		}
-		if dockerRegistry.RegistryUrl == "" || dockerRegistry.Username == "" || dockerRegistry.Password == "" {
-			conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
-			return true
-		}
-	default:
-		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
-		return true
+// decodeIntegrationValue returns the validated value of the request
+func decodeIntegrationValue(request CreateIntegrationRequest) (IntegrationValue, error) {
+	integrationType, ok := GetIntegrationType(request.IntegrationType)
+	if !ok || request.IntegrationValue == nil {
+		return nil, conureerrors.ErrInvalidRequest
 	}
-	return false
+	value, err := integrationType.Decode(request.IntegrationValue)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return nil, conureerrors.ErrInvalidRequest
+	}
+	return value, err
 }
 
-func (a *ApiHandler) ListIntegrations(c *gin.Context) {
+// getIntegration returns the integration of the route with its organization, owned by the current user. The
+// request is aborted when it returns false.
+func (a *ApiHandler) getIntegration(c *gin.Context) (*models.Organization, *models.Integration, bool) {
 	// Escape the organizationID
 	if _, err := primitive.ObjectIDFromHex(c.Param("organizationID")); err != nil {
 		log.Printf("Error parsing organizationID: %v\n", err)
 		conureerrors.AbortWithError(c, err)
-		return
+		return nil, nil, false
 	}
-	org := models.Organization{}
-	_, err := org.GetById(a.MongoDB, c.Param("organizationID"))
+	org := &models.Organization{}
+	_, err := org.GetById(a.db(c), c.Param("organizationID"))
 	if errors.Is(err, conureerrors.ErrObjectNotFound) {
 		conureerrors.AbortWithError(c, err)
-		return
+		return nil, nil, false
 	} else if err != nil {
 		log.Printf("Error getting organization: %v\n", err)
 		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
-		return
-
+		return nil, nil, false
 	}
 	if org.AccountID != c.MustGet("currentUser").(models.User).ID {
 		conureerrors.AbortWithError(c, conureerrors.ErrNotAllowed)
-		return
+		return nil, nil, false
 	}
-	integration := models.Integration{
-		OrganizationID: org.ID,
-	}
-	integrations, err := integration.ListIntegrations(a.MongoDB)
+	integration := &models.Integration{}
+	err = integration.GetByID(a.db(c), c.Param("integrationID"))
 	if err != nil {
-		log.Printf("Error getting applications list: %v\n", err)
 		conureerrors.AbortWithError(c, err)
-		return
+		return nil, nil, false
 	}
-
-	c.JSON(http.StatusOK, integrations)
+	if integration.OrganizationID != org.ID {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return nil, nil, false
+	}
+	return org, integration, true
 }
 
-func (a *ApiHandler) DeleteIntegration(c *gin.Context) {
+func (a *ApiHandler) ListIntegrations(c *gin.Context) {
 	// Escape the organizationID
 	if _, err := primitive.ObjectIDFromHex(c.Param("organizationID")); err != nil {
 		log.Printf("Error parsing organizationID: %v\n", err)
@@ -138,7 +217,7 @@ func (a *ApiHandler) DeleteIntegration(c *gin.Context) {
 		return
 	}
 	org := models.Organization{}
-	_, err := org.GetById(a.MongoDB, c.Param("organizationID"))
+	_, err := org.GetById(a.db(c), c.Param("organizationID"))
 	if errors.Is(err, conureerrors.ErrObjectNotFound) {
 		conureerrors.AbortWithError(c, err)
 		return
@@ -152,25 +231,72 @@ func (a *ApiHandler) DeleteIntegration(c *gin.Context) {
 		conureerrors.AbortWithError(c, conureerrors.ErrNotAllowed)
 		return
 	}
-	integration := &models.Integration{}
-	err = integration.GetByID(a.MongoDB, c.Param("integrationID"))
+	integration := models.Integration{
+		OrganizationID: org.ID,
+	}
+	opts, err := pagination.ParseListOptions(c, models.IntegrationListSpec)
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
+		return
 	}
-	err = integration.Delete(a.MongoDB)
+	page, err := integration.IntegrationPage(a.db(c), opts)
+	if err != nil {
+		log.Printf("Error getting integrations list: %v\n", err)
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	// the list shows the fields which aren't secret
+	for i := range page.Items {
+		value, err := DecryptIntegrationValue(a.keyStorage, page.Items[i])
+		if err != nil {
+			log.Printf("Error decrypting integration: %v\n", err)
+			continue
+		}
+		page.Items[i].Fields = Redact(value)
+	}
+
+	c.JSON(http.StatusOK, IntegrationListResponse{
+		Integrations: page.Items,
+		Metadata:     pagination.SetNextCursor(c, opts, page.NextCursor),
+	})
+}
+
+func (a *ApiHandler) DeleteIntegration(c *gin.Context) {
+	org, integration, ok := a.getIntegration(c)
+	if !ok {
+		return
+	}
+	err := integration.Delete(a.db(c))
 	if err != nil {
 		log.Printf("Error deleting integration: %v\n", err)
 		conureerrors.AbortWithError(c, err)
 		return
 	}
+	audit.Record(c, a.db(c), audit.Entry{
+		Action:         models.AuditIntegrationDeleted,
+		OrganizationID: org.ID,
+		TargetType:     "integration",
+		TargetID:       integration.ID.Hex(),
+		Before: map[string]interface{}{
+			"name":             integration.Name,
+			"integration_type": integration.IntegrationType,
+		},
+	})
+	if integration.IntegrationType == DOCKER_REGISTRY {
+		a.rotateRegistryCredentials(context.WithoutCancel(c.Request.Context()), a.db(c), org.ID)
+	}
 
 	c.Status(http.StatusNoContent)
 }
 
-func convertToString(value interface{}) (string, error) {
-	bytes, err := json.Marshal(value)
-	if err != nil {
-		return "", err
+// DecryptIntegration decodes the encrypted value of the integration into value
+func DecryptIntegration(keyStorage variables.SecretKeyStorage, integration models.Integration, value interface{}) error {
+	encrypted, ok := integration.IntegrationValue.(string)
+	if !ok {
+		return fmt.Errorf("integration %s has no encrypted value", integration.ID.Hex())
+	}
+	if err := json.Unmarshal([]byte(variables.DecryptValue(keyStorage, encrypted)), value); err != nil {
+		return fmt.Errorf("decoding integration %s: %w", integration.ID.Hex(), err)
 	}
-	return string(bytes), nil
+	return nil
 }
@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/variables"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+)
+
+// RegistryCredentials builds the docker config of the docker_registry integrations of the organization, nil when it
+// has none
+func RegistryCredentials(db *database.MongoDB, keyStorage variables.SecretKeyStorage, organizationID primitive.ObjectID) ([]byte, error) {
+	integration := models.Integration{OrganizationID: organizationID}
+	integrations, err := integration.ListIntegrations(db)
+	if err != nil {
+		return nil, err
+	}
+	dockerConfig := k8sUtils.NewDockerConfig()
+	for _, integration := range integrations {
+		if integration.IntegrationType != DOCKER_REGISTRY {
+			continue
+		}
+		registry, err := decryptDockerRegistry(keyStorage, integration)
+		if err != nil {
+			return nil, err
+		}
+		dockerConfig.Add(registry.RegistryUrl, registry.Username, registry.Password)
+	}
+	if len(dockerConfig.Auths) == 0 {
+		return nil, nil
+	}
+	return dockerConfig.Marshal()
+}
+
+func decryptDockerRegistry(keyStorage variables.SecretKeyStorage, integration models.Integration) (*DockerRegistry, error) {
+	registry := &DockerRegistry{}
+	if err := DecryptIntegration(keyStorage, integration, registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// rotateRegistryCredentials syncs the registry credentials of the organization into the namespaces of its deployed
+// environments, after one of its registries changed. A failure is only logged, the next deploy syncs them again.
+func (a *ApiHandler) rotateRegistryCredentials(ctx context.Context, db *database.MongoDB, organizationID primitive.ObjectID) {
+	if a.cluster == nil {
+		return
+	}
+	dockerConfig, err := RegistryCredentials(db, a.keyStorage, organizationID)
+	if err != nil {
+		log.Printf("Error building the registry credentials of organization %s: %v\n", organizationID.Hex(), err)
+		return
+	}
+	applications, err := models.ApplicationList(db, organizationID.Hex())
+	if err != nil {
+		log.Printf("Error listing the applications of organization %s: %v\n", organizationID.Hex(), err)
+		return
+	}
+	for _, application := range applications {
+		for _, environment := range application.Environments {
+			err = a.cluster.SyncRegistryCredentials(ctx, environment.GetNamespace(), dockerConfig)
+			if err != nil {
+				log.Printf("Error syncing the registry credentials of namespace %s: %v\n", environment.GetNamespace(), err)
+			}
+		}
+	}
+}
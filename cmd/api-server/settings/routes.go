@@ -9,8 +9,11 @@ import (
 func GenerateRoutes(relativePath string, r *gin.Engine, appHandler *ApiHandler) {
 	applications := r.Group(relativePath, middlewares.CheckAuthenticatedUser(appHandler.Config, appHandler.MongoDB))
 	{
+		applications.GET("/integration-types", appHandler.ListIntegrationTypes)
 		applications.POST("/:organizationID/i", appHandler.CreateIntegration)
 		applications.GET("/:organizationID/i", appHandler.ListIntegrations)
+		applications.PUT("/:organizationID/i/:integrationID", appHandler.UpdateIntegration)
 		applications.DELETE("/:organizationID/i/:integrationID", appHandler.DeleteIntegration)
+		applications.POST("/:organizationID/i/:integrationID/test", appHandler.TestIntegration)
 	}
 }
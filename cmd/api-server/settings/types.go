@@ -1,16 +1,63 @@
 package settings
 
-const DOCKER_REGISTRY = "docker_registry"
+import (
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
+)
+
+const (
+	DOCKER_REGISTRY = "docker_registry"
+	GITHUB          = "github"
+	GITLAB          = "gitlab"
+	GITEA           = "gitea"
+)
+
+// IsGitProvider tells whether the integration type is a git hosting service sending push webhooks
+func IsGitProvider(integrationType string) bool {
+	return integrationType == GITHUB || integrationType == GITLAB || integrationType == GITEA
+}
 
 type CreateIntegrationRequest struct {
 	Name            string `json:"name" validate:"required"`
 	IntegrationType string `json:"integration_type" validate:"required"`
-	// Integration value could be a dictionary or a string depending on the integration type
+	// Integration value is an object with the fields of the schema of the integration type
 	IntegrationValue interface{} `json:"integration_value" validate:"required"`
 }
 
+type IntegrationListResponse struct {
+	Integrations []models.Integration `json:"integrations"`
+	pagination.Metadata
+}
+
+type TestConnectionResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
 type DockerRegistry struct {
 	RegistryUrl string `json:"registry_url" validate:"required"`
 	Username    string `json:"username" validate:"required"`
-	Password    string `json:"password" validate:"required"`
+	Password    string `json:"password" validate:"required" secret:"true"`
+}
+
+// GitProvider is the value of the github, gitlab and gitea integrations
+type GitProvider struct {
+	// ApiUrl is the API of a self-hosted server, GitHub and GitLab default to their public services
+	ApiUrl string `json:"api_url" validate:"omitempty,url"`
+	// Token reports the commit statuses
+	Token string `json:"token" validate:"required" secret:"true"`
+	// WebhookSecret signs the webhooks of the repositories
+	WebhookSecret string `json:"webhook_secret" validate:"required" secret:"true"`
+}
+
+type GitHub struct {
+	GitProvider
+}
+
+type GitLab struct {
+	GitProvider
+}
+
+type Gitea struct {
+	GitProvider
 }
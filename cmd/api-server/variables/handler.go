@@ -8,10 +8,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/coffeenights/conure/cmd/api-server/audit"
 	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
 	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
 	"github.com/coffeenights/conure/cmd/api-server/database"
 	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/pagination"
 )
 
 type Handler struct {
@@ -28,6 +30,15 @@ func NewVariablesHandler(config *apiConfig.Config, mongo *database.MongoDB, keyS
 	}
 }
 
+func (h *Handler) db(c *gin.Context) *database.MongoDB {
+	return h.MongoDB.ForRequest(c.Request)
+}
+
+type VariableListResponse struct {
+	Variables []models.Variable `json:"variables"`
+	pagination.Metadata
+}
+
 func (h *Handler) ListOrganizationVariables(c *gin.Context) {
 	var variable models.Variable
 
@@ -37,19 +48,29 @@ func (h *Handler) ListOrganizationVariables(c *gin.Context) {
 		return
 	}
 
-	variables, err := variable.ListByOrg(h.MongoDB, organizationID)
+	opts, err := pagination.ParseListOptions(c, models.VariableListSpec)
 	if err != nil {
-		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	page, err := variable.PageByOrg(h.db(c), organizationID, opts)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
 		return
 	}
+	variables := page.Items
 	// Decrypt the values of the variables
 	for i, v := range variables {
 		if v.IsEncrypted {
 			variables[i].Value = DecryptValue(h.KeyStorage, v.Value)
 		}
 	}
+	h.auditSecretsRead(c, variables)
 
-	c.JSON(http.StatusOK, variables)
+	c.JSON(http.StatusOK, VariableListResponse{
+		Variables: variables,
+		Metadata:  pagination.SetNextCursor(c, opts, page.NextCursor),
+	})
 }
 
 func (h *Handler) ListEnvironmentVariables(c *gin.Context) {
@@ -68,19 +89,29 @@ func (h *Handler) ListEnvironmentVariables(c *gin.Context) {
 
 	environmentID := c.Param("environmentID")
 
-	variables, err := variable.ListByEnv(h.MongoDB, organizationID, applicationID, environmentID)
+	opts, err := pagination.ParseListOptions(c, models.VariableListSpec)
 	if err != nil {
-		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		conureerrors.AbortWithError(c, err)
 		return
 	}
+	page, err := variable.PageByEnv(h.db(c), organizationID, applicationID, environmentID, opts)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	variables := page.Items
 	// Decrypt the values of the variables
 	for i, v := range variables {
 		if v.IsEncrypted {
 			variables[i].Value = DecryptValue(h.KeyStorage, v.Value)
 		}
 	}
+	h.auditSecretsRead(c, variables)
 
-	c.JSON(http.StatusOK, variables)
+	c.JSON(http.StatusOK, VariableListResponse{
+		Variables: variables,
+		Metadata:  pagination.SetNextCursor(c, opts, page.NextCursor),
+	})
 }
 
 func (h *Handler) ListComponentVariables(c *gin.Context) {
@@ -103,19 +134,29 @@ func (h *Handler) ListComponentVariables(c *gin.Context) {
 	}
 	environmentID := c.Param("environmentID")
 
-	variables, err := variable.ListByComp(h.MongoDB, organizationID, applicationID, environmentID, componentID)
+	opts, err := pagination.ParseListOptions(c, models.VariableListSpec)
 	if err != nil {
-		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		conureerrors.AbortWithError(c, err)
 		return
 	}
+	page, err := variable.PageByComp(h.db(c), organizationID, applicationID, environmentID, componentID, opts)
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	variables := page.Items
 	// Decrypt the values of the variables
 	for i, v := range variables {
 		if v.IsEncrypted {
 			variables[i].Value = DecryptValue(h.KeyStorage, v.Value)
 		}
 	}
+	h.auditSecretsRead(c, variables)
 
-	c.JSON(http.StatusOK, variables)
+	c.JSON(http.StatusOK, VariableListResponse{
+		Variables: variables,
+		Metadata:  pagination.SetNextCursor(c, opts, page.NextCursor),
+	})
 }
 
 func (h *Handler) CreateVariable(c *gin.Context) {
@@ -172,7 +213,7 @@ func (h *Handler) CreateVariable(c *gin.Context) {
 		variable.ApplicationID = &appID
 	}
 
-	if err := checkVariable(h, variable); err != nil {
+	if err := checkVariable(h.db(c), variable); err != nil {
 		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
 		return
 	}
@@ -182,11 +223,18 @@ func (h *Handler) CreateVariable(c *gin.Context) {
 	}
 
 	// save the variable to the database
-	_, err = variable.Create(h.MongoDB)
+	_, err = variable.Create(h.db(c))
 	if err != nil {
 		conureerrors.AbortWithError(c, err)
 		return
 	}
+	audit.Record(c, h.db(c), audit.Entry{
+		Action:         models.AuditVariableCreated,
+		OrganizationID: variable.OrganizationID,
+		TargetType:     "variable",
+		TargetID:       variable.ID.Hex(),
+		After:          variableSummary(variable),
+	})
 
 	c.JSON(http.StatusCreated, variable)
 }
@@ -208,7 +256,7 @@ func (h *Handler) DeleteVariable(c *gin.Context) {
 	}
 
 	org := models.Organization{}
-	_, err = org.GetById(h.MongoDB, orgID.Hex())
+	_, err = org.GetById(h.db(c), orgID.Hex())
 	if err != nil {
 		log.Printf("Error getting organization: %v", err)
 		conureerrors.AbortWithError(c, err)
@@ -221,7 +269,7 @@ func (h *Handler) DeleteVariable(c *gin.Context) {
 	}
 
 	variable.ID = varID
-	err = variable.Delete(h.MongoDB)
+	err = variable.Delete(h.db(c))
 	if err != nil {
 		log.Printf("Error deleting variable: %v", err)
 		conureerrors.AbortWithError(c, err)
@@ -231,7 +279,7 @@ func (h *Handler) DeleteVariable(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-func checkVariable(h *Handler, variable models.Variable) error {
+func checkVariable(db *database.MongoDB, variable models.Variable) error {
 	// When creating a new variable, the application ID is required for component and environment types
 	if (variable.Type == models.ComponentType || variable.Type == models.EnvironmentType) && (variable.
 		ApplicationID == nil || variable.EnvironmentID == nil) {
@@ -245,20 +293,20 @@ func checkVariable(h *Handler, variable models.Variable) error {
 
 	variableDB := models.Variable{}
 	if variable.Type == models.OrganizationType {
-		err := variableDB.GetByOrgAndName(h.MongoDB, variable.OrganizationID, variable.Name)
+		err := variableDB.GetByOrgAndName(db, variable.OrganizationID, variable.Name)
 		if err == nil {
 			return conureerrors.ErrObjectAlreadyExists
 		}
 	}
 	if variable.Type == models.EnvironmentType {
-		err := variableDB.GetByAppIDAndEnvAndName(h.MongoDB, *variable.ApplicationID, models.EnvironmentType,
+		err := variableDB.GetByAppIDAndEnvAndName(db, *variable.ApplicationID, models.EnvironmentType,
 			variable.EnvironmentID, variable.Name)
 		if err == nil {
 			return conureerrors.ErrObjectAlreadyExists
 		}
 	}
 	if variable.Type == models.ComponentType {
-		err := variableDB.GetByAppIDAndEnvAndCompAndName(h.MongoDB, *variable.ApplicationID,
+		err := variableDB.GetByAppIDAndEnvAndCompAndName(db, *variable.ApplicationID,
 			models.ComponentType, variable.EnvironmentID, variable.ComponentID, variable.Name)
 		if err == nil {
 			return conureerrors.ErrObjectAlreadyExists
@@ -287,3 +335,40 @@ func DecryptValue(storage SecretKeyStorage, value string) string {
 
 	return decryptedValue
 }
+
+// variableSummary describes a variable for the audit log, the value is never included
+func variableSummary(variable models.Variable) map[string]interface{} {
+	summary := map[string]interface{}{
+		"name":         variable.Name,
+		"type":         variable.Type,
+		"is_encrypted": variable.IsEncrypted,
+	}
+	if variable.ApplicationID != nil {
+		summary["application_id"] = variable.ApplicationID.Hex()
+	}
+	if variable.EnvironmentID != nil {
+		summary["environment_id"] = *variable.EnvironmentID
+	}
+	if variable.ComponentID != nil {
+		summary["component_id"] = variable.ComponentID.Hex()
+	}
+	return summary
+}
+
+// auditSecretsRead records which encrypted variables were returned decrypted to the user
+func (h *Handler) auditSecretsRead(c *gin.Context, variables []models.Variable) {
+	var names []string
+	for _, v := range variables {
+		if v.IsEncrypted {
+			names = append(names, v.Name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	audit.Record(c, h.db(c), audit.Entry{
+		Action:     models.AuditVariableRead,
+		TargetType: "variable",
+		Metadata:   map[string]interface{}{"variables": names},
+	})
+}
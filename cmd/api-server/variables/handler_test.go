@@ -35,6 +35,7 @@ func setupTestHandler(router *gin.Engine, mongo *database.MongoDB, conf *apiConf
 }
 
 func TestHandler_ListOrganizationVariables(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -81,7 +82,7 @@ func TestHandler_ListOrganizationVariables(t *testing.T) {
 	_, _ = orgVar2.Create(mongo)
 
 	setupTestHandler(router, mongo, config, keyStorage)
-	var variables []models.Variable
+	var response VariableListResponse
 
 	req, _ := http.NewRequest("GET", "/variables/"+orgID.Hex(), nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -89,13 +90,13 @@ func TestHandler_ListOrganizationVariables(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusOK, resp.Code, "should return 200 OK")
-	assert.Equal(t, 2, len(variables), "should return 2 results")
-	assert.Equal(t, orgVar.OrganizationID, variables[0].OrganizationID, "should return the correct organization")
-	assert.Equal(t, orgVar.Type, variables[0].Type, "should return the correct type of variable")
-	assert.True(t, variables[1].IsEncrypted, "should return the correct type of variable")
+	assert.Equal(t, 2, len(response.Variables), "should return 2 results")
+	assert.Equal(t, orgVar.OrganizationID, response.Variables[0].OrganizationID, "should return the correct organization")
+	assert.Equal(t, orgVar.Type, response.Variables[0].Type, "should return the correct type of variable")
+	assert.True(t, response.Variables[1].IsEncrypted, "should return the correct type of variable")
 
 	req, _ = http.NewRequest("GET", "/variables/fakeOrg", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -103,7 +104,7 @@ func TestHandler_ListOrganizationVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code, "should return 400 Bad Request")
 
@@ -113,22 +114,23 @@ func TestHandler_ListOrganizationVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusOK, resp.Code, "should return 200 OK")
-	assert.Equal(t, 0, len(variables), "should return 0 results")
+	assert.Equal(t, 0, len(response.Variables), "should return 0 results")
 
 	req, _ = http.NewRequest("GET", "/variables/fakeOrg", nil)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusUnauthorized, resp.Code, "should return 401 Unauthorized")
 }
 
 func TestHandler_ListEnvironmentVariables(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -182,7 +184,7 @@ func TestHandler_ListEnvironmentVariables(t *testing.T) {
 	_, _ = orgVar2.Create(mongo)
 
 	setupTestHandler(router, mongo, config, keyStorage)
-	var variables []models.Variable
+	var response VariableListResponse
 
 	urlFormat := "/variables/%s/%s/e/%s"
 	url := fmt.Sprintf(urlFormat, orgID1.Hex(), app1.Hex(), env1)
@@ -192,13 +194,13 @@ func TestHandler_ListEnvironmentVariables(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusOK, resp.Code, "should return 200 OK")
-	assert.Equal(t, 2, len(variables), "should return 2 results")
-	assert.Equal(t, orgVar.OrganizationID, variables[0].OrganizationID, "should return the correct organization")
-	assert.Equal(t, orgVar.Type, variables[0].Type, "should return the correct type of variable")
-	assert.True(t, variables[1].IsEncrypted, "should return the correct type of variable")
+	assert.Equal(t, 2, len(response.Variables), "should return 2 results")
+	assert.Equal(t, orgVar.OrganizationID, response.Variables[0].OrganizationID, "should return the correct organization")
+	assert.Equal(t, orgVar.Type, response.Variables[0].Type, "should return the correct type of variable")
+	assert.True(t, response.Variables[1].IsEncrypted, "should return the correct type of variable")
 
 	fakeURL := fmt.Sprintf(urlFormat, orgID1.Hex(), app1.Hex(), "fakeEnv")
 	req, _ = http.NewRequest("GET", fakeURL, nil)
@@ -207,17 +209,17 @@ func TestHandler_ListEnvironmentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusOK, resp.Code, "should return 200 OK")
-	assert.Equal(t, 0, len(variables), "should return 0 results")
+	assert.Equal(t, 0, len(response.Variables), "should return 0 results")
 
 	req, _ = http.NewRequest("GET", fakeURL, nil)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusUnauthorized, resp.Code, "should return 401 Unauthorized")
 
@@ -228,7 +230,7 @@ func TestHandler_ListEnvironmentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code, "should return 400 Bad Request")
 
@@ -239,7 +241,7 @@ func TestHandler_ListEnvironmentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code, "should return 400 Bad Request")
 
@@ -250,13 +252,14 @@ func TestHandler_ListEnvironmentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusOK, resp.Code, "should return 200 OK")
-	assert.Equal(t, 0, len(variables), "should return 0 results")
+	assert.Equal(t, 0, len(response.Variables), "should return 0 results")
 }
 
 func TestHandler_ListComponentVariables(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -313,7 +316,7 @@ func TestHandler_ListComponentVariables(t *testing.T) {
 	_, _ = orgVar2.Create(mongo)
 
 	setupTestHandler(router, mongo, config, keyStorage)
-	var variables []models.Variable
+	var response VariableListResponse
 
 	urlFormat := "/variables/%s/%s/e/%s/c/%s"
 	url := fmt.Sprintf(urlFormat, orgID1.Hex(), app1.Hex(), env1, comp1.Hex())
@@ -323,13 +326,13 @@ func TestHandler_ListComponentVariables(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusOK, resp.Code, "should return 200 OK")
-	assert.Equal(t, 2, len(variables), "should return 2 results")
-	assert.Equal(t, orgVar.OrganizationID, variables[0].OrganizationID, "should return the correct organization")
-	assert.Equal(t, orgVar.Type, variables[0].Type, "should return the correct type of variable")
-	assert.True(t, variables[1].IsEncrypted, "should return the correct type of variable")
+	assert.Equal(t, 2, len(response.Variables), "should return 2 results")
+	assert.Equal(t, orgVar.OrganizationID, response.Variables[0].OrganizationID, "should return the correct organization")
+	assert.Equal(t, orgVar.Type, response.Variables[0].Type, "should return the correct type of variable")
+	assert.True(t, response.Variables[1].IsEncrypted, "should return the correct type of variable")
 
 	fakeURL := fmt.Sprintf(urlFormat, orgID1.Hex(), app1.Hex(), env1, primitive.NewObjectID().Hex())
 	req, _ = http.NewRequest("GET", fakeURL, nil)
@@ -338,10 +341,10 @@ func TestHandler_ListComponentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusOK, resp.Code, "should return 200 OK")
-	assert.Equal(t, 0, len(variables), "should return 0 results")
+	assert.Equal(t, 0, len(response.Variables), "should return 0 results")
 
 	fakeURL = fmt.Sprintf(urlFormat, orgID1.Hex(), app1.Hex(), env1, "fakeComp")
 	req, _ = http.NewRequest("GET", fakeURL, nil)
@@ -350,7 +353,7 @@ func TestHandler_ListComponentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code, "should return 400 OK")
 
@@ -361,7 +364,7 @@ func TestHandler_ListComponentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code, "should return 400 Bad Request")
 
@@ -370,7 +373,7 @@ func TestHandler_ListComponentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusUnauthorized, resp.Code, "should return 401 Unauthorized")
 
@@ -381,7 +384,7 @@ func TestHandler_ListComponentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code, "should return 400 Bad Request")
 
@@ -392,13 +395,14 @@ func TestHandler_ListComponentVariables(t *testing.T) {
 
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	_ = json.Unmarshal(resp.Body.Bytes(), &variables)
+	_ = json.Unmarshal(resp.Body.Bytes(), &response)
 
 	assert.Equal(t, http.StatusOK, resp.Code, "should return 200 OK")
-	assert.Equal(t, 0, len(variables), "should return 0 results")
+	assert.Equal(t, 0, len(response.Variables), "should return 0 results")
 }
 
 func TestHandler_CreateVariableOrg(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -534,6 +538,7 @@ func TestHandler_CreateVariableOrg(t *testing.T) {
 }
 
 func TestHandler_CreateVariableEnv(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -666,6 +671,7 @@ func TestHandler_CreateVariableEnv(t *testing.T) {
 }
 
 func TestHandler_CreateVariableComp(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -834,6 +840,7 @@ func TestHandler_CreateVariableComp(t *testing.T) {
 }
 
 func TestHandler_DeleteVariable(t *testing.T) {
+	models.SkipWithoutMongoDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -0,0 +1,298 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/settings"
+)
+
+// deletedCommit is the commit after the push deleting a branch
+const deletedCommit = "0000000000000000000000000000000000000000"
+
+// PushEvent is a push to a branch, the components tracking the branch of one of its clone URLs are built
+type PushEvent struct {
+	// Repository is the path of the repository on its server, like coffeenights/conure
+	Repository string
+	CloneURLs  []string
+	Branch     string
+	Commit     string
+}
+
+// Tracks tells whether the source of a component is the pushed branch
+func (p *PushEvent) Tracks(source models.SourceSettings) bool {
+	if source.GitRepository == "" || source.GitBranch != p.Branch {
+		return false
+	}
+	repository := normalizeRepository(source.GitRepository)
+	for _, cloneURL := range p.CloneURLs {
+		if normalizeRepository(cloneURL) == repository {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeRepository turns the https and ssh URLs of a repository into host/path
+func normalizeRepository(repository string) string {
+	repository = strings.ToLower(strings.TrimSpace(repository))
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://"} {
+		repository = strings.TrimPrefix(repository, scheme)
+	}
+	if _, rest, ok := strings.Cut(repository, "@"); ok {
+		repository = rest
+	}
+	// the scp-like syntax of ssh, git@github.com:coffeenights/conure.git
+	if host, path, ok := strings.Cut(repository, ":"); ok && !strings.Contains(host, "/") {
+		port, _, _ := strings.Cut(path, "/")
+		if _, err := strconv.Atoi(port); err != nil {
+			repository = host + "/" + path
+		}
+	}
+	repository = strings.TrimSuffix(repository, "/")
+	return strings.TrimSuffix(repository, ".git")
+}
+
+type CommitState string
+
+const (
+	CommitPending CommitState = "pending"
+	CommitRunning CommitState = "running"
+	CommitSuccess CommitState = "success"
+	CommitFailure CommitState = "failure"
+)
+
+// CommitStatus is the state of the build of a commit, reported to the git server
+type CommitStatus struct {
+	Repository string
+	Commit     string
+	State      CommitState
+	// Context names the status on the commit, one per component and environment
+	Context     string
+	Description string
+}
+
+// gitProvider is the webhooks and the commit statuses API of a git server
+type gitProvider interface {
+	// verify checks the webhook was signed with the secret of the integration
+	verify(header http.Header, body []byte) error
+	// parsePush returns the push of the webhook, nil for the other events and the deleted branches
+	parsePush(header http.Header, body []byte) (*PushEvent, error)
+	setCommitStatus(ctx context.Context, status CommitStatus) error
+}
+
+// newGitProvider returns the provider of the value of a git integration
+func newGitProvider(value settings.IntegrationValue, client *http.Client) (gitProvider, error) {
+	switch value := value.(type) {
+	case *settings.GitHub:
+		return &github{api: value.ApiURL(), value: value.GitProvider, client: client}, nil
+	case *settings.GitLab:
+		return &gitlab{api: value.ApiURL(), value: value.GitProvider, client: client}, nil
+	case *settings.Gitea:
+		return &gitea{api: value.ApiURL(), value: value.GitProvider, client: client}, nil
+	}
+	return nil, fmt.Errorf("%T is not a git provider", value)
+}
+
+// verifyHMAC checks the hex HMAC-SHA256 of the body signed with the secret
+func verifyHMAC(signature string, body []byte, secret string) error {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if signature == "" || !hmac.Equal([]byte(signature), []byte(expected)) {
+		return conureerrors.ErrInvalidSignature
+	}
+	return nil
+}
+
+// branchOf returns the branch of a ref, false for the tags
+func branchOf(ref string) (string, bool) {
+	return strings.CutPrefix(ref, "refs/heads/")
+}
+
+// postJSON sends a commit status, any response but a 2xx is an error
+func postJSON(ctx context.Context, client *http.Client, endpoint string, header http.Header, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header = header
+	request.Header.Set("Content-Type", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("%s returned %d: %s", endpoint, response.StatusCode, message)
+	}
+	return nil
+}
+
+// githubPush is the push event of GitHub, Gitea sends the same fields
+type githubPush struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+}
+
+func (p *githubPush) event() *PushEvent {
+	branch, ok := branchOf(p.Ref)
+	if !ok || p.After == deletedCommit {
+		return nil
+	}
+	return &PushEvent{
+		Repository: p.Repository.FullName,
+		CloneURLs:  []string{p.Repository.CloneURL, p.Repository.SSHURL, p.Repository.HTMLURL},
+		Branch:     branch,
+		Commit:     p.After,
+	}
+}
+
+type github struct {
+	api    string
+	value  settings.GitProvider
+	client *http.Client
+}
+
+func (g *github) verify(header http.Header, body []byte) error {
+	signature, _ := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	return verifyHMAC(signature, body, g.value.WebhookSecret)
+}
+
+func (g *github) parsePush(header http.Header, body []byte) (*PushEvent, error) {
+	if header.Get("X-GitHub-Event") != "push" {
+		return nil, nil
+	}
+	push := githubPush{}
+	if err := json.Unmarshal(body, &push); err != nil {
+		return nil, err
+	}
+	return push.event(), nil
+}
+
+func (g *github) setCommitStatus(ctx context.Context, status CommitStatus) error {
+	// GitHub has no running state
+	state := map[CommitState]string{CommitPending: "pending", CommitRunning: "pending", CommitSuccess: "success", CommitFailure: "failure"}[status.State]
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+g.value.Token)
+	header.Set("Accept", "application/vnd.github+json")
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", g.api, status.Repository, status.Commit)
+	return postJSON(ctx, g.client, endpoint, header, map[string]string{
+		"state":       state,
+		"context":     status.Context,
+		"description": status.Description,
+	})
+}
+
+type gitlab struct {
+	api    string
+	value  settings.GitProvider
+	client *http.Client
+}
+
+func (g *gitlab) verify(header http.Header, _ []byte) error {
+	// GitLab sends the secret itself instead of a signature
+	token := header.Get("X-Gitlab-Token")
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(g.value.WebhookSecret)) != 1 {
+		return conureerrors.ErrInvalidSignature
+	}
+	return nil
+}
+
+func (g *gitlab) parsePush(header http.Header, body []byte) (*PushEvent, error) {
+	if header.Get("X-Gitlab-Event") != "Push Hook" {
+		return nil, nil
+	}
+	push := struct {
+		Ref     string `json:"ref"`
+		After   string `json:"after"`
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+			GitHTTPURL        string `json:"git_http_url"`
+			GitSSHURL         string `json:"git_ssh_url"`
+			WebURL            string `json:"web_url"`
+		} `json:"project"`
+	}{}
+	if err := json.Unmarshal(body, &push); err != nil {
+		return nil, err
+	}
+	branch, ok := branchOf(push.Ref)
+	if !ok || push.After == deletedCommit {
+		return nil, nil
+	}
+	return &PushEvent{
+		Repository: push.Project.PathWithNamespace,
+		CloneURLs:  []string{push.Project.GitHTTPURL, push.Project.GitSSHURL, push.Project.WebURL},
+		Branch:     branch,
+		Commit:     push.After,
+	}, nil
+}
+
+func (g *gitlab) setCommitStatus(ctx context.Context, status CommitStatus) error {
+	state := map[CommitState]string{CommitPending: "pending", CommitRunning: "running", CommitSuccess: "success", CommitFailure: "failed"}[status.State]
+	header := http.Header{}
+	header.Set("PRIVATE-TOKEN", g.value.Token)
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/statuses/%s", g.api, url.PathEscape(status.Repository), status.Commit)
+	return postJSON(ctx, g.client, endpoint, header, map[string]string{
+		"state":       state,
+		"name":        status.Context,
+		"description": status.Description,
+	})
+}
+
+type gitea struct {
+	api    string
+	value  settings.GitProvider
+	client *http.Client
+}
+
+func (g *gitea) verify(header http.Header, body []byte) error {
+	return verifyHMAC(header.Get("X-Gitea-Signature"), body, g.value.WebhookSecret)
+}
+
+func (g *gitea) parsePush(header http.Header, body []byte) (*PushEvent, error) {
+	if header.Get("X-Gitea-Event") != "push" {
+		return nil, nil
+	}
+	push := githubPush{}
+	if err := json.Unmarshal(body, &push); err != nil {
+		return nil, err
+	}
+	return push.event(), nil
+}
+
+func (g *gitea) setCommitStatus(ctx context.Context, status CommitStatus) error {
+	state := map[CommitState]string{CommitPending: "pending", CommitRunning: "pending", CommitSuccess: "success", CommitFailure: "failure"}[status.State]
+	header := http.Header{}
+	header.Set("Authorization", "token "+g.value.Token)
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/statuses/%s", g.api, status.Repository, status.Commit)
+	return postJSON(ctx, g.client, endpoint, header, map[string]string{
+		"state":       state,
+		"context":     status.Context,
+		"description": status.Description,
+	})
+}
@@ -0,0 +1,187 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	apiConfig "github.com/coffeenights/conure/cmd/api-server/config"
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+	"github.com/coffeenights/conure/cmd/api-server/settings"
+	"github.com/coffeenights/conure/cmd/api-server/variables"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	"github.com/coffeenights/conure/internal/telemetry"
+)
+
+// maxPayloadSize bounds the webhooks read, the pushes of GitHub are capped at 25MB
+const maxPayloadSize = 25 << 20
+
+// gitTimeout bounds the calls to the git servers
+const gitTimeout = 10 * time.Second
+
+type ApiHandler struct {
+	MongoDB    *database.MongoDB
+	Config     *apiConfig.Config
+	keyStorage variables.SecretKeyStorage
+	cluster    *providers.Cluster
+	client     *http.Client
+}
+
+func NewApiHandler(config *apiConfig.Config, mongo *database.MongoDB,
+	keyStorage variables.SecretKeyStorage, cluster *providers.Cluster) *ApiHandler {
+	return &ApiHandler{
+		MongoDB:    mongo,
+		Config:     config,
+		keyStorage: keyStorage,
+		cluster:    cluster,
+		client:     &http.Client{Timeout: gitTimeout},
+	}
+}
+
+func (h *ApiHandler) db(c *gin.Context) *database.MongoDB {
+	return h.MongoDB.ForRequest(c.Request)
+}
+
+type WebhookResponse struct {
+	// WorkflowRuns are the names of the runs triggered by the push
+	WorkflowRuns []string `json:"workflow_runs"`
+}
+
+// ReceiveWebhook triggers the workflows of the components tracking the pushed branch. The webhook is signed with
+// the secret of the git integration in its path, the other events are acknowledged and ignored.
+func (h *ApiHandler) ReceiveWebhook(c *gin.Context) {
+	integration := models.Integration{}
+	err := integration.GetByID(h.db(c), c.Param("integrationID"))
+	if err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	if !settings.IsGitProvider(integration.IntegrationType) {
+		conureerrors.AbortWithError(c, conureerrors.ErrObjectNotFound)
+		return
+	}
+	value, err := settings.DecryptIntegrationValue(h.keyStorage, integration)
+	if err != nil {
+		log.Printf("Error decrypting integration: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	provider, err := newGitProvider(value, h.client)
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPayloadSize))
+	if err != nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	if err = provider.verify(c.Request.Header, body); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	push, err := provider.parsePush(c.Request.Header, body)
+	if err != nil {
+		log.Printf("Error parsing webhook: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInvalidRequest)
+		return
+	}
+	if push == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	org := models.Organization{}
+	if _, err = org.GetById(h.db(c), integration.OrganizationID.Hex()); err != nil {
+		conureerrors.AbortWithError(c, err)
+		return
+	}
+	if org.Status == models.OrgDisabled {
+		conureerrors.AbortWithError(c, conureerrors.ErrOrganizationDisabled)
+		return
+	}
+	if h.cluster == nil {
+		conureerrors.AbortWithError(c, conureerrors.ErrClusterUnavailable)
+		return
+	}
+	// like the deploys, the runs aren't cancelled half created when the git server goes away
+	ctx := context.WithoutCancel(c.Request.Context())
+	runs, err := h.triggerWorkflows(ctx, h.db(c), &integration, push)
+	if err != nil {
+		log.Printf("Error triggering workflows: %v\n", err)
+		conureerrors.AbortWithError(c, conureerrors.ErrInternalError)
+		return
+	}
+	c.JSON(http.StatusAccepted, WebhookResponse{WorkflowRuns: runs})
+}
+
+// triggerWorkflows creates a workflow run of the pushed commit in every environment where a component tracks the
+// branch, the reporter sends their commit statuses as they progress
+func (h *ApiHandler) triggerWorkflows(ctx context.Context, db *database.MongoDB, integration *models.Integration, push *PushEvent) ([]string, error) {
+	applications, err := models.ApplicationList(db, integration.OrganizationID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]string, 0)
+	var errs []error
+	for _, application := range applications {
+		components, err := application.ListComponents(db)
+		if err != nil {
+			return nil, err
+		}
+		for _, component := range components {
+			for _, environment := range application.Environments {
+				if !push.Tracks(component.SettingsFor(&environment).SourceSettings) {
+					continue
+				}
+				workflowRun, err := h.cluster.CreateWorkflowRun(ctx, newWorkflowRun(ctx, application, &environment, &component, integration, push))
+				if err != nil {
+					// the other environments are still built
+					errs = append(errs, err)
+					continue
+				}
+				log.Printf("Push of %s to %s triggered workflow run %s/%s.\n", push.Repository, push.Branch, workflowRun.Namespace, workflowRun.Name)
+				runs = append(runs, workflowRun.Name)
+			}
+		}
+	}
+	return runs, errors.Join(errs...)
+}
+
+func newWorkflowRun(ctx context.Context, application *models.Application, environment *models.Environment, component *models.Component, integration *models.Integration, push *PushEvent) *conurev1alpha1.WorkflowRun {
+	annotations := map[string]string{
+		k8sUtils.GitRepositoryAnnotation: push.Repository,
+		k8sUtils.GitBranchAnnotation:     push.Branch,
+		k8sUtils.IntegrationIDAnnotation: integration.ID.Hex(),
+	}
+	telemetry.InjectAnnotations(ctx, annotations)
+	return &conurev1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: component.Name + "-",
+			Namespace:    environment.GetNamespace(),
+			Annotations:  annotations,
+			Labels: map[string]string{
+				k8sUtils.ApplicationNameLabel: application.Name,
+				k8sUtils.ComponentNameLabel:   component.Name,
+				k8sUtils.EnvironmentLabel:     environment.Name,
+				k8sUtils.GitCommitLabel:       push.Commit,
+			},
+		},
+		Spec: conurev1alpha1.WorkflowRunSpec{
+			ApplicationName: application.Name,
+			ComponentName:   component.Name,
+			WorkflowName:    component.Name,
+			Revision:        push.Commit,
+		},
+	}
+}
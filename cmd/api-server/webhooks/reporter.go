@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	"github.com/coffeenights/conure/cmd/api-server/database"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/providers"
+	"github.com/coffeenights/conure/cmd/api-server/settings"
+	"github.com/coffeenights/conure/cmd/api-server/variables"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+)
+
+var commitStates = map[providers.WorkflowRunPhase]CommitState{
+	providers.WorkflowRunPending:   CommitPending,
+	providers.WorkflowRunRunning:   CommitRunning,
+	providers.WorkflowRunSucceeded: CommitSuccess,
+	providers.WorkflowRunFailed:    CommitFailure,
+}
+
+var commitDescriptions = map[CommitState]string{
+	CommitPending: "Waiting to build",
+	CommitRunning: "Building",
+	CommitSuccess: "Built",
+	CommitFailure: "Build failed",
+}
+
+// StatusReporter reports the progress of the workflow runs triggered by a push as statuses of the pushed commit
+type StatusReporter struct {
+	mongo      *database.MongoDB
+	keyStorage variables.SecretKeyStorage
+	cluster    *providers.Cluster
+	client     *http.Client
+}
+
+func NewStatusReporter(mongo *database.MongoDB, keyStorage variables.SecretKeyStorage, cluster *providers.Cluster) *StatusReporter {
+	return &StatusReporter{
+		mongo:      mongo,
+		keyStorage: keyStorage,
+		cluster:    cluster,
+		client:     &http.Client{Timeout: gitTimeout},
+	}
+}
+
+// Run reports the statuses until ctx is done
+func (r *StatusReporter) Run(ctx context.Context) {
+	r.cluster.WatchGitWorkflowRuns(ctx, func(workflowRun *conurev1alpha1.WorkflowRun) {
+		if err := r.report(ctx, workflowRun); err != nil {
+			log.Printf("Error reporting the commit status of workflow run %s/%s: %v\n", workflowRun.Namespace, workflowRun.Name, err)
+		}
+	})
+}
+
+func (r *StatusReporter) report(ctx context.Context, workflowRun *conurev1alpha1.WorkflowRun) error {
+	status := commitStatus(workflowRun)
+	if workflowRun.Annotations[k8sUtils.CommitStatusAnnotation] == string(status.State) {
+		return nil
+	}
+	integration := models.Integration{}
+	db := r.mongo.WithContext(ctx)
+	if err := integration.GetByID(db, workflowRun.Annotations[k8sUtils.IntegrationIDAnnotation]); err != nil {
+		return err
+	}
+	value, err := settings.DecryptIntegrationValue(r.keyStorage, integration)
+	if err != nil {
+		return err
+	}
+	provider, err := newGitProvider(value, r.client)
+	if err != nil {
+		return err
+	}
+	if err = provider.setCommitStatus(ctx, status); err != nil {
+		return err
+	}
+	return r.cluster.AnnotateWorkflowRun(ctx, workflowRun, k8sUtils.CommitStatusAnnotation, string(status.State))
+}
+
+// commitStatus returns the status of the commit built by the workflow run, named after its component and
+// environment
+func commitStatus(workflowRun *conurev1alpha1.WorkflowRun) CommitStatus {
+	progress := providers.WorkflowRunProgress(workflowRun)
+	state := commitStates[progress.Phase]
+	description := commitDescriptions[state]
+	if state == CommitFailure && progress.Message != "" {
+		description = progress.Message
+	}
+	return CommitStatus{
+		Repository:  workflowRun.Annotations[k8sUtils.GitRepositoryAnnotation],
+		Commit:      workflowRun.Labels[k8sUtils.GitCommitLabel],
+		State:       state,
+		Context:     fmt.Sprintf("conure/%s/%s", workflowRun.Labels[k8sUtils.EnvironmentLabel], workflowRun.Spec.ComponentName),
+		Description: description,
+	}
+}
@@ -0,0 +1,13 @@
+package webhooks
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// GenerateRoutes registers the receiver of the git webhooks, they are authenticated by their signature
+func GenerateRoutes(relativePath string, r *gin.Engine, handler *ApiHandler) {
+	webhooks := r.Group(relativePath)
+	{
+		webhooks.POST("/:integrationID", handler.ReceiveWebhook)
+	}
+}
@@ -0,0 +1,221 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	"github.com/coffeenights/conure/cmd/api-server/conureerrors"
+	"github.com/coffeenights/conure/cmd/api-server/models"
+	"github.com/coffeenights/conure/cmd/api-server/settings"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+)
+
+const testSecret = "s3cr3t"
+
+func payload(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return body
+}
+
+func sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newTestProvider(t *testing.T, integrationType string, api string) gitProvider {
+	t.Helper()
+	integration, ok := settings.GetIntegrationType(integrationType)
+	require.True(t, ok)
+	value, err := integration.Decode(map[string]string{"api_url": api, "token": "token", "webhook_secret": testSecret})
+	require.NoError(t, err)
+	provider, err := newGitProvider(value, http.DefaultClient)
+	require.NoError(t, err)
+	return provider
+}
+
+func TestParsePush(t *testing.T) {
+	cases := []struct {
+		integrationType string
+		payload         string
+		header          http.Header
+		expected        *PushEvent
+	}{
+		{settings.GITHUB, "github_push.json", http.Header{"X-Github-Event": {"push"}}, &PushEvent{
+			Repository: "coffeenights/conure",
+			CloneURLs:  []string{"https://github.com/coffeenights/conure.git", "git@github.com:coffeenights/conure.git", "https://github.com/coffeenights/conure"},
+			Branch:     "main",
+			Commit:     "59b20b8d5c6ff8d09518454d4dd8b7a30f095ab5",
+		}},
+		{settings.GITLAB, "gitlab_push.json", http.Header{"X-Gitlab-Event": {"Push Hook"}}, &PushEvent{
+			Repository: "coffeenights/django",
+			CloneURLs:  []string{"https://gitlab.com/coffeenights/django.git", "git@gitlab.com:coffeenights/django.git", "https://gitlab.com/coffeenights/django"},
+			Branch:     "develop",
+			Commit:     "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		}},
+		{settings.GITEA, "gitea_push.json", http.Header{"X-Gitea-Event": {"push"}}, &PushEvent{
+			Repository: "coffeenights/webapp",
+			CloneURLs:  []string{"https://git.coffeenights.dev/coffeenights/webapp.git", "ssh://git@git.coffeenights.dev:2222/coffeenights/webapp.git", "https://git.coffeenights.dev/coffeenights/webapp"},
+			Branch:     "main",
+			Commit:     "bffeb74224043ba2feb48d137756c8a9331c449a",
+		}},
+		// a deleted branch has nothing to build
+		{settings.GITHUB, "github_delete_branch.json", http.Header{"X-Github-Event": {"push"}}, nil},
+		// the other events are ignored
+		{settings.GITHUB, "github_push.json", http.Header{"X-Github-Event": {"ping"}}, nil},
+	}
+	for _, tc := range cases {
+		provider := newTestProvider(t, tc.integrationType, "https://git.coffeenights.dev")
+		push, err := provider.parsePush(tc.header, payload(t, tc.payload))
+		require.NoError(t, err, tc.payload)
+		assert.Equal(t, tc.expected, push, tc.payload)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	github := payload(t, "github_push.json")
+	gitea := payload(t, "gitea_push.json")
+	cases := []struct {
+		name            string
+		integrationType string
+		header          http.Header
+		body            []byte
+		valid           bool
+	}{
+		{"github", settings.GITHUB, http.Header{"X-Hub-Signature-256": {"sha256=" + sign(github, testSecret)}}, github, true},
+		{"github wrong secret", settings.GITHUB, http.Header{"X-Hub-Signature-256": {"sha256=" + sign(github, "other")}}, github, false},
+		{"github tampered", settings.GITHUB, http.Header{"X-Hub-Signature-256": {"sha256=" + sign(github, testSecret)}}, gitea, false},
+		{"github unsigned", settings.GITHUB, http.Header{}, github, false},
+		{"gitlab", settings.GITLAB, http.Header{"X-Gitlab-Token": {testSecret}}, github, true},
+		{"gitlab wrong token", settings.GITLAB, http.Header{"X-Gitlab-Token": {"other"}}, github, false},
+		{"gitea", settings.GITEA, http.Header{"X-Gitea-Signature": {sign(gitea, testSecret)}}, gitea, true},
+		{"gitea wrong secret", settings.GITEA, http.Header{"X-Gitea-Signature": {sign(gitea, "other")}}, gitea, false},
+	}
+	for _, tc := range cases {
+		err := newTestProvider(t, tc.integrationType, "https://git.coffeenights.dev").verify(tc.header, tc.body)
+		if tc.valid {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.ErrorIs(t, err, conureerrors.ErrInvalidSignature, tc.name)
+		}
+	}
+}
+
+func TestPushEvent_Tracks(t *testing.T) {
+	provider := newTestProvider(t, settings.GITHUB, "")
+	push, err := provider.parsePush(http.Header{"X-Github-Event": {"push"}}, payload(t, "github_push.json"))
+	require.NoError(t, err)
+
+	cases := map[models.SourceSettings]bool{
+		{GitRepository: "https://github.com/coffeenights/conure", GitBranch: "main"}:     true,
+		{GitRepository: "https://github.com/coffeenights/conure.git", GitBranch: "main"}: true,
+		{GitRepository: "git@github.com:coffeenights/conure.git", GitBranch: "main"}:     true,
+		{GitRepository: "https://GitHub.com/CoffeeNights/Conure/", GitBranch: "main"}:    true,
+		{GitRepository: "https://github.com/coffeenights/conure", GitBranch: "develop"}:  false,
+		{GitRepository: "https://github.com/coffeenights/django", GitBranch: "main"}:     false,
+		{GitRepository: "https://gitlab.com/coffeenights/conure", GitBranch: "main"}:     false,
+		{Repository: "ghcr.io/coffeenights/conure", GitBranch: "main"}:                   false,
+	}
+	for source, expected := range cases {
+		assert.Equal(t, expected, push.Tracks(source), "%+v", source)
+	}
+}
+
+func TestSetCommitStatus(t *testing.T) {
+	type request struct {
+		path   string
+		header http.Header
+		body   map[string]string
+	}
+	var received request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = request{path: r.URL.EscapedPath(), header: r.Header}
+		_ = json.Unmarshal(body, &received.body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	status := CommitStatus{
+		Repository:  "coffeenights/conure",
+		Commit:      "59b20b8d5c6ff8d09518454d4dd8b7a30f095ab5",
+		State:       CommitRunning,
+		Context:     "conure/production/web",
+		Description: "Building",
+	}
+	cases := []struct {
+		integrationType string
+		path            string
+		authHeader      string
+		authValue       string
+		body            map[string]string
+	}{
+		{settings.GITHUB, "/repos/coffeenights/conure/statuses/59b20b8d5c6ff8d09518454d4dd8b7a30f095ab5", "Authorization", "Bearer token",
+			map[string]string{"state": "pending", "context": "conure/production/web", "description": "Building"}},
+		{settings.GITLAB, "/api/v4/projects/coffeenights%2Fconure/statuses/59b20b8d5c6ff8d09518454d4dd8b7a30f095ab5", "Private-Token", "token",
+			map[string]string{"state": "running", "name": "conure/production/web", "description": "Building"}},
+		{settings.GITEA, "/api/v1/repos/coffeenights/conure/statuses/59b20b8d5c6ff8d09518454d4dd8b7a30f095ab5", "Authorization", "token token",
+			map[string]string{"state": "pending", "context": "conure/production/web", "description": "Building"}},
+	}
+	for _, tc := range cases {
+		err := newTestProvider(t, tc.integrationType, server.URL).setCommitStatus(context.Background(), status)
+		require.NoError(t, err, tc.integrationType)
+		assert.Equal(t, tc.path, received.path, tc.integrationType)
+		assert.Equal(t, tc.authValue, received.header.Get(tc.authHeader), tc.integrationType)
+		assert.Equal(t, tc.body, received.body, tc.integrationType)
+	}
+}
+
+func TestSetCommitStatus_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message": "Bad credentials"}`))
+	}))
+	defer server.Close()
+
+	err := newTestProvider(t, settings.GITHUB, server.URL).setCommitStatus(context.Background(), CommitStatus{State: CommitPending})
+	assert.ErrorContains(t, err, "Bad credentials")
+}
+
+func TestCommitStatus(t *testing.T) {
+	workflowRun := &conurev1alpha1.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web-x7k2p",
+			Labels:      map[string]string{k8sUtils.EnvironmentLabel: "production", k8sUtils.GitCommitLabel: "59b20b8d"},
+			Annotations: map[string]string{k8sUtils.GitRepositoryAnnotation: "coffeenights/conure"},
+		},
+		Spec: conurev1alpha1.WorkflowRunSpec{ComponentName: "web"},
+	}
+	assert.Equal(t, CommitStatus{
+		Repository:  "coffeenights/conure",
+		Commit:      "59b20b8d",
+		State:       CommitPending,
+		Context:     "conure/production/web",
+		Description: "Waiting to build",
+	}, commitStatus(workflowRun))
+
+	workflowRun.Status.Conditions = []metav1.Condition{{
+		Type:    conurev1alpha1.ConditionTypeFinished.String(),
+		Status:  metav1.ConditionFalse,
+		Reason:  conurev1alpha1.FinishedFailedReason.String(),
+		Message: "Failed to run actions",
+	}}
+	status := commitStatus(workflowRun)
+	assert.Equal(t, CommitFailure, status.State)
+	assert.Equal(t, "Failed to run actions", status.Description)
+}
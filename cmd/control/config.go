@@ -0,0 +1,22 @@
+package main
+
+// Config of the controller manager, loaded from the file in CONFIG_FILE and the environment. The command-line flags
+// override it.
+type Config struct {
+	Metrics MetricsConfig `envPrefix:"METRICS_" yaml:"metrics"`
+	// ProbeAddr is the address the probe endpoint binds to
+	ProbeAddr   string `env:"HEALTH_PROBE_BIND_ADDRESS" default:":8081"`
+	LeaderElect bool   `env:"LEADER_ELECT"`
+	EnableHTTP2 bool   `env:"ENABLE_HTTP2"`
+	Development bool   `env:"DEVELOPMENT"`
+	// LogLevel is 0 for info, 1 for debug, 5 for the debug of the reconcilers
+	LogLevel int `env:"LOG_LEVEL"`
+	// OTLPEndpoint is the URL of the OpenTelemetry collector receiving the traces, tracing is off when empty
+	OTLPEndpoint string `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+}
+
+type MetricsConfig struct {
+	// BindAddress is :8443 for HTTPS or :8080 for HTTP, 0 disables the metrics service
+	BindAddress string `env:"BIND_ADDRESS" default:"0"`
+	Secure      bool   `env:"SECURE" default:"true"`
+}
@@ -17,9 +17,13 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"github.com/coffeenights/conure/internal/config"
 	"github.com/coffeenights/conure/internal/controller/core"
+	"github.com/coffeenights/conure/internal/telemetry"
 	"go.uber.org/zap/zapcore"
 	"os"
 
@@ -54,6 +58,11 @@ func init() {
 }
 
 func main() {
+	conf := &Config{}
+	if err := config.Load(conf, os.Getenv(config.FileEnv)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
@@ -61,19 +70,22 @@ func main() {
 	var enableHTTP2 bool
 	var enableDevelopment bool
 	var logLevel int
+	var otlpEndpoint string
 	var tlsOpts []func(*tls.Config)
-	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
+	flag.StringVar(&metricsAddr, "metrics-bind-address", conf.Metrics.BindAddress, "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
-	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
+	flag.StringVar(&probeAddr, "health-probe-bind-address", conf.ProbeAddr, "The address the probe endpoint binds to.")
+	flag.BoolVar(&enableLeaderElection, "leader-elect", conf.LeaderElect,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	flag.BoolVar(&secureMetrics, "metrics-secure", true,
+	flag.BoolVar(&secureMetrics, "metrics-secure", conf.Metrics.Secure,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
-	flag.BoolVar(&enableHTTP2, "enable-http2", false,
+	flag.BoolVar(&enableHTTP2, "enable-http2", conf.EnableHTTP2,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
-	flag.BoolVar(&enableDevelopment, "development", false, "Enable development mode")
-	flag.IntVar(&logLevel, "log-level", 0, "Log level: 0 - info, 1 - debug, 5 - Reconciler debug")
+	flag.BoolVar(&enableDevelopment, "development", conf.Development, "Enable development mode")
+	flag.IntVar(&logLevel, "log-level", conf.LogLevel, "Log level: 0 - info, 1 - debug, 5 - Reconciler debug")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", conf.OTLPEndpoint,
+		"The URL of the OpenTelemetry collector receiving the traces, tracing is off when empty.")
 	flag.Parse()
 	opts := zap.Options{
 		Development: enableDevelopment,
@@ -166,8 +178,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+	shutdownTracing, err := telemetry.Setup(ctx, "conure-controller", otlpEndpoint)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctx)
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		setupLog.Error(shutdownErr, "unable to flush the traces")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
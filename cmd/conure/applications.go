@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coffeenights/conure/pkg/apiclient"
+)
+
+// anyEnvironment fills the environment of the routes that don't use it, like the application detail
+const anyEnvironment = "-"
+
+func runApplication(config *cliConfig, args []string) error {
+	name, args, err := subcommand(args, "list", "create", "deploy", "status", "delete")
+	if err != nil {
+		return err
+	}
+	fs, s := newFlagSet("app "+name, config)
+	var list *listFlags
+	if name == "list" {
+		list = addListFlags(fs)
+	}
+	description := fs.String("description", "", "The description of the application")
+	watch := fs.Bool("watch", false, "Keep printing the status until interrupted")
+	interval := fs.Duration("interval", 5*time.Second, "The interval between the status checks with -watch")
+	dryRun := fs.Bool("dry-run", false, "Only print what would be deleted")
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+	client := config.client()
+	ctx := context.Background()
+
+	switch name {
+	case "list":
+		if err = s.require(false, false); err != nil {
+			return err
+		}
+		applications, err := paginate(list, func(query url.Values) ([]apiclient.ApplicationResponse, string, error) {
+			response, err := client.ListApplications(ctx, s.organization, query)
+			if err != nil {
+				return nil, "", err
+			}
+			return response.Applications, response.NextCursor, nil
+		})
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "COMPONENTS", "ENVIRONMENTS", "CREATED"}}
+		for _, application := range applications {
+			t.add(application.ID, application.Name, strconv.FormatInt(application.TotalComponents, 10),
+				environmentNames(application.Environments), formatTime(application.CreatedAt))
+		}
+		return printResult(s.output, applications, t)
+	case "create":
+		if err = s.require(false, false); err != nil {
+			return err
+		}
+		applicationName, err := positional(fs, "application name")
+		if err != nil {
+			return err
+		}
+		application, err := client.CreateApplication(ctx, s.organization, &apiclient.CreateApplicationRequest{
+			Name:        applicationName,
+			Description: *description,
+		})
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME"}}
+		t.add(application.ID, application.Name)
+		return printResult(s.output, application, t)
+	case "deploy":
+		if err = s.require(true, true); err != nil {
+			return err
+		}
+		response, err := client.DeployApplication(ctx, s.organization, s.application, s.environment)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, response.Message)
+	case "status":
+		if err = s.require(true, true); err != nil {
+			return err
+		}
+		for {
+			status, err := client.StatusApplication(ctx, s.organization, s.application, s.environment)
+			if err != nil {
+				return err
+			}
+			t := &table{headers: []string{"APPLICATION", "ENVIRONMENT", "STATUS", "CHECKED"}}
+			t.add(s.application, s.environment, status.Status, formatTime(time.Now()))
+			if err = printResult(s.output, status, t); err != nil || !*watch {
+				return err
+			}
+			time.Sleep(*interval)
+		}
+	case "delete":
+		if err = s.require(true, false); err != nil {
+			return err
+		}
+		plan, err := client.DeleteApplication(ctx, s.organization, s.application, dryRunQuery(*dryRun))
+		if err != nil {
+			return err
+		}
+		return printDeletion(s.output, "application", s.application, plan)
+	}
+	return nil
+}
+
+func runEnvironment(config *cliConfig, args []string) error {
+	name, args, err := subcommand(args, "list", "create", "update", "delete")
+	if err != nil {
+		return err
+	}
+	fs, s := newFlagSet("env "+name, config)
+	domain := fs.String("domain", "", "The domain template of the hosts of the routes, e.g. {component}.{env}.example.com")
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+	if err = s.require(true, false); err != nil {
+		return err
+	}
+	client := config.client()
+	ctx := context.Background()
+
+	switch name {
+	case "list":
+		application, err := client.DetailApplication(ctx, s.organization, s.application, anyEnvironment)
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "DOMAIN"}}
+		for _, environment := range application.Environments {
+			t.add(environment.ID, environment.Name, environment.DomainTemplate)
+		}
+		return printResult(s.output, application.Environments, t)
+	case "create":
+		environmentName, err := positional(fs, "environment name")
+		if err != nil {
+			return err
+		}
+		request := &apiclient.CreateEnvironmentRequest{Name: environmentName, DomainTemplate: *domain}
+		if _, err = client.CreateEnvironment(ctx, s.organization, s.application, request); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Environment %s created\n", environmentName)
+	case "update":
+		environmentName, err := positional(fs, "environment name")
+		if err != nil {
+			return err
+		}
+		if _, err = client.UpdateEnvironment(ctx, s.organization, s.application, environmentName, &apiclient.UpdateEnvironmentRequest{DomainTemplate: *domain}); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Environment %s updated\n", environmentName)
+	case "delete":
+		environmentName, err := positional(fs, "environment name")
+		if err != nil {
+			return err
+		}
+		if _, err = client.DeleteEnvironment(ctx, s.organization, s.application, environmentName); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Environment %s deleted\n", environmentName)
+	}
+	return nil
+}
+
+// environmentID resolves the ID of the environment selected by name with -env
+func environmentID(ctx context.Context, client *apiclient.Client, s *scope) (string, error) {
+	application, err := client.DetailApplication(ctx, s.organization, s.application, anyEnvironment)
+	if err != nil {
+		return "", err
+	}
+	for _, environment := range application.Environments {
+		if environment.Name == s.environment {
+			return environment.ID, nil
+		}
+	}
+	return "", fmt.Errorf("environment %q not found in the application %s", s.environment, s.application)
+}
+
+func environmentNames(environments []apiclient.Environment) string {
+	names := make([]string, 0, len(environments))
+	for _, environment := range environments {
+		names = append(names, environment.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func dryRunQuery(dryRun bool) url.Values {
+	if !dryRun {
+		return nil
+	}
+	return url.Values{"dry_run": {"true"}}
+}
+
+// printDeletion prints the plan of a dry run, the plan is nil when the resource was deleted
+func printDeletion(format string, kind string, id string, plan *apiclient.DeletionPlan) error {
+	if plan == nil {
+		fmt.Fprintf(stdout, "The %s %s was deleted\n", kind, id)
+		return nil
+	}
+	t := &table{headers: []string{"RESOURCE", "NAMES"}}
+	if plan.Application != "" {
+		t.add("application", plan.Application)
+	}
+	t.add("components", strings.Join(plan.Components, ","))
+	t.add("environments", strings.Join(plan.Environments, ","))
+	t.add("namespaces", strings.Join(plan.Namespaces, ","))
+	t.add("variables", strings.Join(plan.Variables, ","))
+	t.add("workloads", strings.Join(plan.Workloads, ","))
+	return printResult(format, plan, t)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/coffeenights/conure/pkg/apiclient"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// prompt asks for a value on stdin when it wasn't given with a flag
+func prompt(label string, value string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func runLogin(config *cliConfig, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	server := fs.String("server", config.Server, "The URL of the api-server")
+	email := fs.String("email", "", "The email of the account")
+	password := fs.String("password", "", "The password, asked when missing")
+	code := fs.String("code", "", "The two-factor code, asked when the account requires it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if *email, err = prompt("Email", *email); err != nil {
+		return err
+	}
+	if *password, err = prompt("Password", *password); err != nil {
+		return err
+	}
+	config.Server = *server
+	client := config.client()
+	ctx := context.Background()
+	response, err := client.Login(ctx, &apiclient.LoginRequest{Email: *email, Password: *password})
+	if err != nil {
+		return err
+	}
+	token := response.Token
+	if response.TwoFactorRequired {
+		if *code, err = prompt("Two-factor code", *code); err != nil {
+			return err
+		}
+		twoFactor, err := client.LoginTwoFactor(ctx, &apiclient.TwoFactorLoginRequest{
+			Challenge: response.Challenge,
+			Code:      *code,
+		})
+		if err != nil {
+			return err
+		}
+		token = twoFactor.Token
+	}
+	if token == "" {
+		return errors.New("the server didn't return a token")
+	}
+
+	config.Token = token
+	if err = config.save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Logged in to %s as %s\n", config.Server, *email)
+	return nil
+}
+
+func runLogout(config *cliConfig, args []string) error {
+	fs := flag.NewFlagSet("logout", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	config.Token = ""
+	if err := config.save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Logged out")
+	return nil
+}
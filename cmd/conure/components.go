@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/coffeenights/conure/pkg/apiclient"
+)
+
+// componentsFile is the file read by "conure component apply"
+type componentsFile struct {
+	Components []apiclient.CreateComponentRequest `json:"components"`
+}
+
+func readComponentsFile(path string) (*componentsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &componentsFile{}
+	if err = yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, component := range file.Components {
+		if component.Name == "" || component.Type == "" {
+			return nil, fmt.Errorf("parsing %s: the component %d needs a name and a type", path, i)
+		}
+	}
+	return file, nil
+}
+
+// findComponent returns the component with the name, or nil when the application doesn't have it
+func findComponent(ctx context.Context, client *apiclient.Client, s *scope, name string) (*apiclient.ComponentResponse, error) {
+	list := &listFlags{name: name}
+	components, err := paginate(list, func(query url.Values) ([]apiclient.ComponentResponse, string, error) {
+		response, err := client.ListComponents(ctx, s.organization, s.application, s.environment, query)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Components, response.NextCursor, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range components {
+		if components[i].Name == name {
+			return &components[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// componentID resolves the ID of the component selected by name
+func componentID(ctx context.Context, client *apiclient.Client, s *scope, name string) (string, error) {
+	component, err := findComponent(ctx, client, s, name)
+	if err != nil {
+		return "", err
+	}
+	if component == nil {
+		return "", fmt.Errorf("component %q not found in the application %s", name, s.application)
+	}
+	return component.ID, nil
+}
+
+func runComponent(config *cliConfig, args []string) error {
+	name, args, err := subcommand(args, "list", "apply", "delete")
+	if err != nil {
+		return err
+	}
+	fs, s := newFlagSet("component "+name, config)
+	var list *listFlags
+	if name == "list" {
+		list = addListFlags(fs)
+	}
+	file := fs.String("f", "", "The YAML file with the components to create or update")
+	dryRun := fs.Bool("dry-run", false, "Only print what would be deleted")
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+	if err = s.require(true, true); err != nil {
+		return err
+	}
+	client := config.client()
+	ctx := context.Background()
+
+	switch name {
+	case "list":
+		components, err := paginate(list, func(query url.Values) ([]apiclient.ComponentResponse, string, error) {
+			response, err := client.ListComponents(ctx, s.organization, s.application, s.environment, query)
+			if err != nil {
+				return nil, "", err
+			}
+			return response.Components, response.NextCursor, nil
+		})
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "TYPE", "UPDATED"}}
+		for _, component := range components {
+			t.add(component.ID, component.Name, component.Type, formatTime(component.UpdatedAt))
+		}
+		return printResult(s.output, components, t)
+	case "apply":
+		if *file == "" {
+			return errors.New("missing -f flag")
+		}
+		components, err := readComponentsFile(*file)
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "ACTION"}}
+		var applied []*apiclient.Component
+		for i := range components.Components {
+			request := &components.Components[i]
+			existing, err := findComponent(ctx, client, s, request.Name)
+			if err != nil {
+				return err
+			}
+			var component *apiclient.Component
+			action := "created"
+			if existing != nil {
+				action = "updated"
+				component, err = client.UpdateComponent(ctx, s.organization, s.application, s.environment, existing.ID, request)
+			} else {
+				component, err = client.CreateComponent(ctx, s.organization, s.application, s.environment, request)
+			}
+			if err != nil {
+				return fmt.Errorf("applying the component %s: %w", request.Name, err)
+			}
+			applied = append(applied, component)
+			t.add(component.ID, component.Name, action)
+		}
+		return printResult(s.output, applied, t)
+	case "delete":
+		componentName, err := positional(fs, "component name")
+		if err != nil {
+			return err
+		}
+		id, err := componentID(ctx, client, s, componentName)
+		if err != nil {
+			return err
+		}
+		plan, err := client.DeleteComponent(ctx, s.organization, s.application, s.environment, id, dryRunQuery(*dryRun))
+		if err != nil {
+			return err
+		}
+		return printDeletion(s.output, "component", componentName, plan)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/coffeenights/conure/pkg/apiclient"
+)
+
+const DefaultServer = "http://localhost:8080"
+
+// cliConfig is stored in the user config directory, CONURE_SERVER and CONURE_TOKEN override it
+type cliConfig struct {
+	Server string `json:"server"`
+	Token  string `json:"token,omitempty"`
+	// Organization is the default organization of the commands, set with "conure org use"
+	Organization string `json:"organization,omitempty"`
+}
+
+func configPath() (string, error) {
+	if path := os.Getenv("CONURE_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "conure", "config.json"), nil
+}
+
+func loadConfig() (*cliConfig, error) {
+	config := &cliConfig{Server: DefaultServer}
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, config); err != nil {
+			return nil, err
+		}
+	}
+	if server := os.Getenv("CONURE_SERVER"); server != "" {
+		config.Server = server
+	}
+	if token := os.Getenv("CONURE_TOKEN"); token != "" {
+		config.Token = token
+	}
+	return config, nil
+}
+
+// save writes the config readable only by the user as it holds the token
+func (c *cliConfig) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func (c *cliConfig) client() *apiclient.Client {
+	client := apiclient.NewClient(c.Server)
+	client.Token = c.Token
+	return client
+}
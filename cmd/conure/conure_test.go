@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func captureOutput(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	previous := stdout
+	stdout = buf
+	t.Cleanup(func() { stdout = previous })
+	return buf
+}
+
+func TestConfigSaveAndLoad(t *testing.T) {
+	t.Setenv("CONURE_CONFIG", filepath.Join(t.TempDir(), "conure", "config.json"))
+	t.Setenv("CONURE_SERVER", "")
+	t.Setenv("CONURE_TOKEN", "")
+
+	config, err := loadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultServer, config.Server)
+
+	config.Token = "token"
+	config.Organization = "org"
+	require.NoError(t, config.save())
+	path, _ := configPath()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	t.Setenv("CONURE_TOKEN", "override")
+	loaded, err := loadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "override", loaded.Token)
+	assert.Equal(t, "org", loaded.Organization)
+}
+
+func TestPrintResult(t *testing.T) {
+	result := []map[string]string{{"name": "web"}}
+	tbl := &table{headers: []string{"NAME", "TYPE"}}
+	tbl.add("web", "service")
+
+	out := captureOutput(t)
+	require.NoError(t, printResult(OutputTable, result, tbl))
+	assert.Equal(t, "NAME  TYPE\nweb   service\n", out.String())
+
+	out.Reset()
+	require.NoError(t, printResult(OutputYAML, result, tbl))
+	assert.Equal(t, "- name: web\n", out.String())
+
+	out.Reset()
+	require.NoError(t, printResult(OutputJSON, result, tbl))
+	var decoded []map[string]string
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, result, decoded)
+
+	assert.Error(t, printResult("xml", result, tbl))
+}
+
+func TestReadComponentsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "components.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+components:
+  - name: web
+    type: service
+    settings:
+      resources_settings:
+        replicas: 2
+`), 0600))
+	file, err := readComponentsFile(path)
+	require.NoError(t, err)
+	require.Len(t, file.Components, 1)
+	assert.Equal(t, "web", file.Components[0].Name)
+	assert.Equal(t, int64(2), file.Components[0].Settings.ResourcesSettings.Replicas)
+
+	require.NoError(t, os.WriteFile(path, []byte("components:\n  - name: web\n"), 0600))
+	_, err = readComponentsFile(path)
+	assert.Error(t, err)
+}
+
+func TestPrintEvents(t *testing.T) {
+	out := &bytes.Buffer{}
+	stream := "event:message\ndata:first line\n\nevent:message\ndata:second line\n\n"
+	require.NoError(t, printEvents(strings.NewReader(stream), out))
+	assert.Equal(t, "first line\nsecond line\n", out.String())
+
+	err := printEvents(strings.NewReader("event:error\ndata:pod not found\n\n"), out)
+	assert.EqualError(t, err, "pod not found")
+}
+
+func TestOrganizationListFollowsCursors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("auth")
+		require.NoError(t, err)
+		assert.Equal(t, "token", cookie.Value)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"organizations":[{"id":"1","name":"first"}],"next_cursor":"next"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"organizations":[{"id":"2","name":"second"}]}`))
+	}))
+	defer server.Close()
+
+	out := captureOutput(t)
+	config := &cliConfig{Server: server.URL, Token: "token", Organization: "2"}
+	require.NoError(t, runOrganization(config, []string{"list"}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "first")
+	assert.Contains(t, lines[2], "second")
+	assert.True(t, strings.HasSuffix(lines[2], "*"))
+}
+
+func TestSubcommand(t *testing.T) {
+	name, args, err := subcommand([]string{"list", "-o", "json"}, "list", "create")
+	require.NoError(t, err)
+	assert.Equal(t, "list", name)
+	assert.Equal(t, []string{"-o", "json"}, args)
+
+	_, _, err = subcommand(nil, "list")
+	assert.ErrorIs(t, err, errMissingSubcommand)
+	_, _, err = subcommand([]string{"remove"}, "list")
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var errMissingSubcommand = errors.New("missing subcommand")
+
+// scope holds the flags selecting the resources a command works on
+type scope struct {
+	organization string
+	application  string
+	environment  string
+	output       string
+}
+
+// newFlagSet returns a flag set with the scope flags, the organization defaults to the one set with "conure org use"
+func newFlagSet(name string, config *cliConfig) (*flag.FlagSet, *scope) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	s := &scope{}
+	fs.StringVar(&s.organization, "org", config.Organization, "The organization ID")
+	fs.StringVar(&s.application, "app", "", "The application ID")
+	fs.StringVar(&s.environment, "env", "", "The environment name")
+	fs.StringVar(&s.output, "o", OutputTable, "The output format: table, json or yaml")
+	return fs, s
+}
+
+// require checks the scope flags needed by the command are set
+func (s *scope) require(application bool, environment bool) error {
+	if s.organization == "" {
+		return errors.New("missing -org flag, or set a default with conure org use")
+	}
+	if application && s.application == "" {
+		return errors.New("missing -app flag")
+	}
+	if environment && s.environment == "" {
+		return errors.New("missing -env flag")
+	}
+	return nil
+}
+
+// listFlags adds the pagination flags of the list commands
+type listFlags struct {
+	limit  int
+	cursor string
+	sort   string
+	name   string
+}
+
+func addListFlags(fs *flag.FlagSet) *listFlags {
+	l := &listFlags{}
+	fs.IntVar(&l.limit, "limit", 0, "Maximum amount of items, all of them when 0")
+	fs.StringVar(&l.cursor, "cursor", "", "Start after this cursor")
+	fs.StringVar(&l.sort, "sort", "", "Field to sort by, prefixed with - for a descending order")
+	fs.StringVar(&l.name, "name", "", "Only the items whose name starts with this prefix")
+	return l
+}
+
+func (l *listFlags) query() url.Values {
+	query := url.Values{}
+	if l.limit > 0 {
+		query.Set("limit", strconv.Itoa(l.limit))
+	}
+	if l.cursor != "" {
+		query.Set("cursor", l.cursor)
+	}
+	if l.sort != "" {
+		query.Set("sort", l.sort)
+	}
+	if l.name != "" {
+		query.Set("name_prefix", l.name)
+	}
+	return query
+}
+
+// paginate calls fetch until the last page, or only once when a limit is set
+func paginate[T any](l *listFlags, fetch func(query url.Values) ([]T, string, error)) ([]T, error) {
+	var items []T
+	query := l.query()
+	for {
+		page, next, err := fetch(query)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if next == "" || l.limit > 0 {
+			return items, nil
+		}
+		query.Set("cursor", next)
+	}
+}
+
+// subcommand splits the subcommand name from its arguments
+func subcommand(args []string, available ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%w, available: %s", errMissingSubcommand, strings.Join(available, ", "))
+	}
+	for _, name := range available {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("unknown subcommand %q, available: %s", args[0], strings.Join(available, ", "))
+}
+
+// positional returns the only positional argument left after the flags
+func positional(fs *flag.FlagSet, name string) (string, error) {
+	if fs.NArg() != 1 {
+		return "", fmt.Errorf("expected the %s as argument", name)
+	}
+	return fs.Arg(0), nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/coffeenights/conure/pkg/apiclient"
+)
+
+func runIntegration(config *cliConfig, args []string) error {
+	name, args, err := subcommand(args, "list", "create", "delete")
+	if err != nil {
+		return err
+	}
+	fs, s := newFlagSet("integration "+name, config)
+	var list *listFlags
+	if name == "list" {
+		list = addListFlags(fs)
+	}
+	integrationName := fs.String("name", "", "The name of the integration")
+	integrationType := fs.String("type", "", "The type of the integration")
+	valueFile := fs.String("value-file", "", "The JSON or YAML file with the value of the integration")
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+	if err = s.require(false, false); err != nil {
+		return err
+	}
+	client := config.client()
+	ctx := context.Background()
+
+	switch name {
+	case "list":
+		integrations, err := paginate(list, func(query url.Values) ([]apiclient.Integration, string, error) {
+			response, err := client.ListIntegrations(ctx, s.organization, query)
+			if err != nil {
+				return nil, "", err
+			}
+			return response.Integrations, response.NextCursor, nil
+		})
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "TYPE", "CREATED"}}
+		for _, integration := range integrations {
+			t.add(integration.ID, integration.Name, integration.IntegrationType, formatTime(integration.CreatedAt))
+		}
+		return printResult(s.output, integrations, t)
+	case "create":
+		if *integrationName == "" || *integrationType == "" || *valueFile == "" {
+			return errors.New("the -name, -type and -value-file flags are required")
+		}
+		data, err := os.ReadFile(*valueFile)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if err = yaml.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("parsing %s: %w", *valueFile, err)
+		}
+		integration, err := client.CreateIntegration(ctx, s.organization, &apiclient.CreateIntegrationRequest{
+			Name:             *integrationName,
+			IntegrationType:  *integrationType,
+			IntegrationValue: value,
+		})
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "TYPE"}}
+		t.add(integration.ID, integration.Name, integration.IntegrationType)
+		return printResult(s.output, integration, t)
+	case "delete":
+		integrationID, err := positional(fs, "integration ID")
+		if err != nil {
+			return err
+		}
+		if err = client.DeleteIntegration(ctx, s.organization, integrationID); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Integration %s deleted\n", integrationID)
+	}
+	return nil
+}
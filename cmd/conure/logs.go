@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+func runLogs(config *cliConfig, args []string) error {
+	fs, s := newFlagSet("logs", config)
+	component := fs.String("component", "", "The name of the component")
+	pods := fs.String("pods", "", "Comma separated pod names, all the pods of the component by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := s.require(true, true); err != nil {
+		return err
+	}
+	if *component == "" {
+		return errors.New("missing -component flag")
+	}
+	client := config.client()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	id, err := componentID(ctx, client, s, *component)
+	if err != nil {
+		return err
+	}
+	if *pods == "" {
+		response, err := client.ComponentPods(ctx, s.organization, s.application, s.environment, id)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(response.Pods))
+		for _, pod := range response.Pods {
+			names = append(names, pod.Name)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("the component %s doesn't have pods", *component)
+		}
+		*pods = strings.Join(names, ",")
+	}
+
+	body, err := client.StreamLogs(ctx, s.organization, s.application, s.environment, id, url.Values{"pods": {*pods}})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return printEvents(body, stdout)
+}
+
+// printEvents writes the data of the server-sent events, the error events end the stream
+func printEvents(body io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(body)
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data := strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+			if event == "error" {
+				return errors.New(data)
+			}
+			fmt.Fprintln(out, data)
+		case line == "":
+			event = ""
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command runs a subcommand with the arguments following its name
+type command func(config *cliConfig, args []string) error
+
+var commands = map[string]command{
+	"login":       runLogin,
+	"logout":      runLogout,
+	"org":         runOrganization,
+	"app":         runApplication,
+	"env":         runEnvironment,
+	"component":   runComponent,
+	"logs":        runLogs,
+	"var":         runVariable,
+	"integration": runIntegration,
+	"apply":       runManifest("apply"),
+	"diff":        runManifest("diff"),
+}
+
+func usage() {
+	fmt.Printf("Usage: \n")
+	fmt.Printf("conure [cmd] [subcmd] [options]\n")
+	fmt.Printf("  Commands available:\n")
+	fmt.Printf("\tlogin                      Log in and store the token\n")
+	fmt.Printf("\tlogout                     Remove the stored token\n")
+	fmt.Printf("\torg list|create|use        Manage the organizations\n")
+	fmt.Printf("\tapp list|create|deploy|status|delete\n")
+	fmt.Printf("\t                           Manage and deploy the applications\n")
+	fmt.Printf("\tenv list|create|update|delete\n")
+	fmt.Printf("\t                           Manage the environments of an application and their domains\n")
+	fmt.Printf("\tcomponent list|apply|delete\n")
+	fmt.Printf("\t                           Manage the components, apply reads them from a YAML file\n")
+	fmt.Printf("\tapply                      Create or update the application of conure.yaml\n")
+	fmt.Printf("\tdiff                       Print the changes apply would make\n")
+	fmt.Printf("\tlogs                       Tail the logs of a component\n")
+	fmt.Printf("\tvar list|set|delete        Manage the variables\n")
+	fmt.Printf("\tintegration list|create|delete\n")
+	fmt.Printf("\t                           Manage the integrations\n")
+	fmt.Printf("  Run conure [cmd] [subcmd] -h for the options of a command.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(1)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading the config: %v\n", err)
+		os.Exit(1)
+	}
+	if err = run(config, os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/coffeenights/conure/pkg/apiclient"
+)
+
+// DefaultManifest is the manifest read by apply and diff without -f
+const DefaultManifest = "conure.yaml"
+
+func readManifest(path string) (*apiclient.Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &apiclient.Manifest{}
+	if err = yaml.UnmarshalStrict(data, manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if manifest.Application.Name == "" {
+		return nil, fmt.Errorf("parsing %s: application.name is required", path)
+	}
+	return manifest, nil
+}
+
+func changesTable(diff *apiclient.ManifestDiff) *table {
+	t := &table{headers: []string{"ACTION", "KIND", "NAME", "SCOPE", "FIELDS"}}
+	for _, change := range diff.Changes {
+		scope := change.Environment
+		if change.Component != "" {
+			scope += "/" + change.Component
+		}
+		t.add(change.Action, change.Kind, change.Name, scope, strings.Join(change.Fields, ","))
+	}
+	return t
+}
+
+func warnMissingSecrets(diff *apiclient.ManifestDiff) {
+	for _, secret := range diff.MissingSecrets {
+		fmt.Fprintf(os.Stderr, "Warning: the secret %s is not set, set it with conure var set -secret\n", secret)
+	}
+}
+
+// runManifest implements apply and diff, apply with -dry-run is the same as diff
+func runManifest(name string) command {
+	return func(config *cliConfig, args []string) error {
+		fs, s := newFlagSet(name, config)
+		file := fs.String("f", DefaultManifest, "The manifest of the application")
+		dryRun := false
+		if name == "apply" {
+			fs.BoolVar(&dryRun, "dry-run", false, "Only print the changes")
+		}
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if err := s.require(false, false); err != nil {
+			return err
+		}
+		manifest, err := readManifest(*file)
+		if err != nil {
+			return err
+		}
+		client := config.client()
+		ctx := context.Background()
+
+		if name == "diff" || dryRun {
+			diff, err := client.DiffManifest(ctx, s.organization, manifest)
+			if err != nil {
+				return err
+			}
+			warnMissingSecrets(diff)
+			if len(diff.Changes) == 0 && s.output == OutputTable {
+				fmt.Fprintf(stdout, "The application %s is up to date\n", diff.Application)
+				return nil
+			}
+			return printResult(s.output, diff, changesTable(diff))
+		}
+		response, err := client.ApplyManifest(ctx, s.organization, manifest)
+		if err != nil {
+			return err
+		}
+		warnMissingSecrets(&response.Diff)
+		if len(response.Diff.Changes) == 0 && s.output == OutputTable {
+			fmt.Fprintf(stdout, "The application %s (%s) is up to date\n", response.Application.Name, response.Application.ID)
+			return nil
+		}
+		return printResult(s.output, response, changesTable(&response.Diff))
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/coffeenights/conure/pkg/apiclient"
+)
+
+func runOrganization(config *cliConfig, args []string) error {
+	name, args, err := subcommand(args, "list", "create", "use")
+	if err != nil {
+		return err
+	}
+	fs, s := newFlagSet("org "+name, config)
+	var list *listFlags
+	if name == "list" {
+		list = addListFlags(fs)
+	}
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+	client := config.client()
+	ctx := context.Background()
+
+	switch name {
+	case "list":
+		organizations, err := paginate(list, func(query url.Values) ([]apiclient.OrganizationResponse, string, error) {
+			response, err := client.ListOrganizations(ctx, query)
+			if err != nil {
+				return nil, "", err
+			}
+			return response.Organizations, response.NextCursor, nil
+		})
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "STATUS", "CREATED", "DEFAULT"}}
+		for _, organization := range organizations {
+			current := ""
+			if organization.ID == config.Organization {
+				current = "*"
+			}
+			t.add(organization.ID, organization.Name, organization.Status, formatTime(organization.CreatedAt), current)
+		}
+		return printResult(s.output, organizations, t)
+	case "create":
+		organizationName, err := positional(fs, "organization name")
+		if err != nil {
+			return err
+		}
+		organization, err := client.CreateOrganization(ctx, &apiclient.CreateOrganizationRequest{Name: organizationName})
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME"}}
+		t.add(organization.ID, organization.Name)
+		return printResult(s.output, organization, t)
+	case "use":
+		organizationID, err := positional(fs, "organization ID")
+		if err != nil {
+			return err
+		}
+		organization, err := client.DetailOrganization(ctx, organizationID)
+		if err != nil {
+			return err
+		}
+		config.Organization = organization.ID
+		if err = config.save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Using the organization %s (%s)\n", organization.Name, organization.ID)
+	}
+	return nil
+}
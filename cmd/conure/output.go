@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+var stdout io.Writer = os.Stdout
+
+// table is the tabular form of a result, the JSON and YAML outputs use the result itself
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(columns ...string) {
+	t.rows = append(t.rows, columns)
+}
+
+func printResult(format string, result interface{}, t *table) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case OutputYAML:
+		data, err := yaml.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(data)
+		return err
+	case OutputTable, "":
+		w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(t.headers, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown output format %q, use table, json or yaml", format)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/coffeenights/conure/pkg/apiclient"
+)
+
+// variableScope holds the IDs of the variables selected by -app, -env and -component, an empty environment ID selects
+// the organization variables and an empty component ID the environment variables
+type variableScope struct {
+	environmentID string
+	componentID   string
+}
+
+func resolveVariableScope(ctx context.Context, client *apiclient.Client, s *scope, component string) (*variableScope, error) {
+	vs := &variableScope{}
+	if s.application == "" && s.environment == "" && component == "" {
+		return vs, s.require(false, false)
+	}
+	if err := s.require(true, true); err != nil {
+		return nil, err
+	}
+	var err error
+	if vs.environmentID, err = environmentID(ctx, client, s); err != nil {
+		return nil, err
+	}
+	if component != "" {
+		if vs.componentID, err = componentID(ctx, client, s, component); err != nil {
+			return nil, err
+		}
+	}
+	return vs, nil
+}
+
+func runVariable(config *cliConfig, args []string) error {
+	name, args, err := subcommand(args, "list", "set", "delete")
+	if err != nil {
+		return err
+	}
+	fs, s := newFlagSet("var "+name, config)
+	var list *listFlags
+	if name == "list" {
+		list = addListFlags(fs)
+	}
+	component := fs.String("component", "", "The name of the component of the variables")
+	secret := fs.Bool("secret", false, "Store the value encrypted")
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+	client := config.client()
+	ctx := context.Background()
+
+	switch name {
+	case "list":
+		vs, err := resolveVariableScope(ctx, client, s, *component)
+		if err != nil {
+			return err
+		}
+		variables, err := paginate(list, func(query url.Values) ([]apiclient.Variable, string, error) {
+			var response *apiclient.VariableListResponse
+			var err error
+			switch {
+			case vs.componentID != "":
+				response, err = client.ListComponentVariables(ctx, s.organization, s.application, vs.environmentID, vs.componentID, query)
+			case vs.environmentID != "":
+				response, err = client.ListEnvironmentVariables(ctx, s.organization, s.application, vs.environmentID, query)
+			default:
+				response, err = client.ListOrganizationVariables(ctx, s.organization, query)
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			return response.Variables, response.NextCursor, nil
+		})
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "VALUE", "TYPE", "UPDATED"}}
+		for _, variable := range variables {
+			value := variable.Value
+			if variable.IsEncrypted {
+				value = "********"
+			}
+			t.add(variable.ID, variable.Name, value, variable.Type, formatTime(variable.UpdatedAt))
+		}
+		return printResult(s.output, variables, t)
+	case "set":
+		assignment, err := positional(fs, "NAME=VALUE assignment")
+		if err != nil {
+			return err
+		}
+		variableName, value, ok := strings.Cut(assignment, "=")
+		if !ok || variableName == "" {
+			return fmt.Errorf("expected NAME=VALUE, got %q", assignment)
+		}
+		vs, err := resolveVariableScope(ctx, client, s, *component)
+		if err != nil {
+			return err
+		}
+		body := &apiclient.Variable{Name: variableName, Value: value, IsEncrypted: *secret}
+		var variable *apiclient.Variable
+		switch {
+		case vs.componentID != "":
+			variable, err = client.CreateComponentVariable(ctx, s.organization, s.application, vs.environmentID, vs.componentID, body)
+		case vs.environmentID != "":
+			variable, err = client.CreateEnvironmentVariable(ctx, s.organization, s.application, vs.environmentID, body)
+		default:
+			variable, err = client.CreateOrganizationVariable(ctx, s.organization, body)
+		}
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"ID", "NAME", "TYPE"}}
+		t.add(variable.ID, variable.Name, variable.Type)
+		return printResult(s.output, variable, t)
+	case "delete":
+		if err = s.require(false, false); err != nil {
+			return err
+		}
+		variableID, err := positional(fs, "variable ID")
+		if err != nil {
+			return err
+		}
+		if err = client.DeleteVariable(ctx, s.organization, variableID); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Variable %s deleted\n", variableID)
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+// Package build renders the jobs of the built-in build actions. A job clones the git repository of the component
+// at the revision of the run, builds its image, pushes it to the registry of the component and reports the digest of
+// the pushed image in the termination message of its report container.
+package build
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+)
+
+// The default images of the build steps
+var (
+	GitImage        = "alpine/git:2.45.2"
+	KanikoImage     = "gcr.io/kaniko-project/executor:v1.23.2"
+	NixpacksImage   = "alpine:3.20"
+	BuildpacksImage = "paketobuildpacks/builder-jammy-base:latest"
+	ReportImage     = "busybox:1.36"
+)
+
+// ReportContainer is the container whose termination message is the digest of the pushed image
+const ReportContainer = "report"
+
+const (
+	workspaceDir = "/workspace"
+	sourceDir    = workspaceDir + "/src"
+	outputDir    = workspaceDir + "/out"
+	layersDir    = "/layers"
+	// dockerConfigDir is where the buildpacks lifecycle finds the registry credentials
+	dockerConfigDir = "/docker"
+	// cnbUser is the user of the buildpacks builders
+	cnbUser = 1000
+)
+
+var digestPattern = regexp.MustCompile(`sha256:[a-f0-9]{64}`)
+
+type Options struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	Type      conurev1alpha1.BuildType
+	Source    conurev1alpha1.Source
+	// Revision is the git commit to build, the head of the branch is built when it's empty
+	Revision string
+	// Image replaces the default image of the builder
+	Image string
+	// CredentialsSecret is the kubernetes.io/dockerconfigjson secret pushing the image, the registry is accessed
+	// anonymously when it's empty
+	CredentialsSecret string
+	// CacheKey is the key of the build cache of the source, its volume is created beforehand for a volume cache
+	CacheKey string
+}
+
+// JobName returns the name of the job of the build action of the component, the names of the jobs label their pods
+// and are at most 63 characters long
+func JobName(component string, action string, suffix string) string {
+	name := component + "-" + action
+	if maxLength := validation.DNS1123LabelMaxLength - len(suffix) - 1; len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-")
+	}
+	return name + "-" + suffix
+}
+
+// TypeOf returns the build of the source: its build tool, else the build of the Dockerfile or the Nixpacks config it
+// sets, else the buildpacks detecting the language of the source
+func TypeOf(source conurev1alpha1.Source) conurev1alpha1.BuildType {
+	switch {
+	case source.BuildTool != "":
+		return conurev1alpha1.BuildType(strings.ToLower(source.BuildTool))
+	case source.DockerfilePath != "":
+		return conurev1alpha1.DockerfileBuild
+	case source.NixpackPath != "":
+		return conurev1alpha1.NixpacksBuild
+	}
+	return conurev1alpha1.BuildpacksBuild
+}
+
+// Repository returns the repository the image of the source is pushed to
+func Repository(source conurev1alpha1.Source) string {
+	return strings.TrimPrefix(source.OCIRepository, "oci://")
+}
+
+// Tag returns the tag of the image of the source
+func Tag(source conurev1alpha1.Source) string {
+	if source.Tag == "" {
+		return "latest"
+	}
+	return source.Tag
+}
+
+// Destinations returns the references the image is pushed to: the tag of the source and, when a commit is built,
+// the commit
+func Destinations(source conurev1alpha1.Source, revision string) []string {
+	destinations := []string{Repository(source) + ":" + Tag(source)}
+	if revision != "" && revision != Tag(source) {
+		destinations = append(destinations, Repository(source)+":"+revision)
+	}
+	return destinations
+}
+
+// ParseDigest returns the digest of the termination message of the report container, which is the digest file of
+// Kaniko or the report of the buildpacks lifecycle
+func ParseDigest(message string) (string, error) {
+	digest := digestPattern.FindString(message)
+	if digest == "" {
+		return "", fmt.Errorf("no image digest in the build report %q", message)
+	}
+	return digest, nil
+}
+
+// Job returns the job building the image of the source
+func Job(opts Options) (*batchv1.Job, error) {
+	if opts.Source.GitRepository == "" {
+		return nil, fmt.Errorf("the source has no git repository to build")
+	}
+	if opts.Source.OCIRepository == "" {
+		return nil, fmt.Errorf("the source has no OCI repository to push the image to")
+	}
+	cache, err := cacheOf(opts)
+	if err != nil {
+		return nil, err
+	}
+	var steps []corev1.Container
+	switch opts.Type {
+	case conurev1alpha1.DockerfileBuild:
+		steps = []corev1.Container{kaniko(opts, dockerfilePath(opts.Source))}
+	case conurev1alpha1.NixpacksBuild:
+		steps = []corev1.Container{nixpacks(opts), kaniko(opts, path.Join(sourceDir, ".nixpacks", "Dockerfile"))}
+	case conurev1alpha1.BuildpacksBuild:
+		steps = []corev1.Container{buildpacks(opts)}
+	default:
+		return nil, fmt.Errorf("unknown build type %s", opts.Type)
+	}
+
+	volumes := []corev1.Volume{
+		{Name: "workspace", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+	var imagePullSecrets []corev1.LocalObjectReference
+	if opts.CredentialsSecret != "" {
+		volumes = append(volumes, corev1.Volume{Name: "registry-credentials", VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: opts.CredentialsSecret,
+				Items:      []corev1.KeyToPath{{Key: corev1.DockerConfigJsonKey, Path: "config.json"}},
+			},
+		}})
+		imagePullSecrets = []corev1.LocalObjectReference{{Name: opts.CredentialsSecret}}
+	}
+	var securityContext *corev1.PodSecurityContext
+	if opts.Type == conurev1alpha1.BuildpacksBuild {
+		volumes = append(volumes, corev1.Volume{Name: "layers", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+		// the builder can write in the cache volume
+		securityContext = &corev1.PodSecurityContext{FSGroup: ptr.To(int64(cnbUser))}
+	}
+	if cache != nil && cache.Type == conurev1alpha1.VolumeBuildCache {
+		volumes = append(volumes, corev1.Volume{Name: "cache", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: CacheVolumeName(opts.CacheKey)},
+		}})
+	}
+
+	job := newJob(opts, corev1.PodSpec{
+		InitContainers:   append([]corev1.Container{clone(opts)}, steps...),
+		Containers:       []corev1.Container{report(opts)},
+		Volumes:          volumes,
+		ImagePullSecrets: imagePullSecrets,
+		SecurityContext:  securityContext,
+	})
+	return job, nil
+}
+
+// ReusedJob returns the job reporting the image of the revision already in the registry, with its digest, instead
+// of building it again
+func ReusedJob(opts Options, digest string) *batchv1.Job {
+	job := newJob(opts, corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name:                     ReportContainer,
+			Image:                    ReportImage,
+			Command:                  []string{"/bin/sh", "-c", "echo \"$DIGEST\" > /dev/termination-log"},
+			Env:                      []corev1.EnvVar{{Name: "DIGEST", Value: digest}},
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		}},
+	})
+	job.Labels[conurev1alpha1.BuildReusedLabel] = "true"
+	job.Spec.Template.Labels[conurev1alpha1.BuildReusedLabel] = "true"
+	return job
+}
+
+func newJob(opts Options, pod corev1.PodSpec) *batchv1.Job {
+	labels := func() map[string]string {
+		labels := map[string]string{}
+		for key, value := range opts.Labels {
+			labels[key] = value
+		}
+		labels[conurev1alpha1.BuildTypeLabel] = string(opts.Type)
+		return labels
+	}
+	pod.RestartPolicy = corev1.RestartPolicyNever
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.Name,
+			Namespace: opts.Namespace,
+			Labels:    labels(),
+		},
+		Spec: batchv1.JobSpec{
+			// a failed build is retried by another run
+			BackoffLimit: ptr.To(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels()},
+				Spec:       pod,
+			},
+		},
+	}
+}
+
+// cacheOf returns the layer cache of the build, nil when the layers aren't cached
+func cacheOf(opts Options) (*conurev1alpha1.BuildCache, error) {
+	cache := opts.Source.Cache
+	if cache == nil || cache.Type == "" {
+		return nil, nil
+	}
+	if cache.Type != conurev1alpha1.RegistryBuildCache && cache.Type != conurev1alpha1.VolumeBuildCache {
+		return nil, fmt.Errorf("unknown build cache type %s", cache.Type)
+	}
+	if opts.CacheKey == "" {
+		return nil, fmt.Errorf("the build cache has no key")
+	}
+	if _, err := CacheTTL(cache); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func dockerfilePath(source conurev1alpha1.Source) string {
+	if source.DockerfilePath == "" {
+		return path.Join(sourceDir, "Dockerfile")
+	}
+	return path.Join(sourceDir, source.DockerfilePath)
+}
+
+func workspaceMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: "workspace", MountPath: workspaceDir}
+}
+
+// clone checks out the revision in the source directory, the builders running as another user can write in the
+// workspace
+func clone(opts Options) corev1.Container {
+	script := fmt.Sprintf(`set -e
+git clone ${GIT_BRANCH:+--branch "$GIT_BRANCH"} "$GIT_REPOSITORY" %[1]s
+if [ -n "$GIT_REVISION" ]; then git -C %[1]s checkout "$GIT_REVISION"; fi
+mkdir -p %[2]s
+chmod -R a+rwX %[3]s`, sourceDir, outputDir, workspaceDir)
+	return corev1.Container{
+		Name:    "clone",
+		Image:   GitImage,
+		Command: []string{"/bin/sh", "-c", script},
+		Env: []corev1.EnvVar{
+			{Name: "GIT_REPOSITORY", Value: opts.Source.GitRepository},
+			{Name: "GIT_BRANCH", Value: opts.Source.GitBranch},
+			{Name: "GIT_REVISION", Value: opts.Revision},
+		},
+		VolumeMounts: []corev1.VolumeMount{workspaceMount()},
+	}
+}
+
+func kaniko(opts Options, dockerfile string) corev1.Container {
+	args := []string{
+		"--context=dir://" + sourceDir,
+		"--dockerfile=" + dockerfile,
+		"--digest-file=" + path.Join(outputDir, "digest"),
+	}
+	for _, destination := range Destinations(opts.Source, opts.Revision) {
+		args = append(args, "--destination="+destination)
+	}
+	mounts := []corev1.VolumeMount{workspaceMount()}
+	if opts.CredentialsSecret != "" {
+		mounts = append(mounts, corev1.VolumeMount{Name: "registry-credentials", MountPath: "/kaniko/.docker", ReadOnly: true})
+	}
+	// the layers are cached in a repository of the registry, or in the OCI layout of the cache volume
+	if cache, _ := cacheOf(opts); cache != nil {
+		ttl, _ := CacheTTL(cache)
+		args = append(args, "--cache=true", "--cache-ttl="+ttl.String())
+		if cache.Type == conurev1alpha1.VolumeBuildCache {
+			args = append(args, "--cache-repo=oci:"+path.Join(cacheDir, "layers"))
+			mounts = append(mounts, corev1.VolumeMount{Name: "cache", MountPath: cacheDir})
+		} else {
+			args = append(args, "--cache-repo="+CacheRepository(opts.Source, opts.CacheKey))
+		}
+	}
+	image := KanikoImage
+	if opts.Image != "" && opts.Type == conurev1alpha1.DockerfileBuild {
+		image = opts.Image
+	}
+	return corev1.Container{
+		Name:         "build",
+		Image:        image,
+		Args:         args,
+		VolumeMounts: mounts,
+	}
+}
+
+// nixpacks writes the Dockerfile of the plan of the source in its .nixpacks directory, without building it
+func nixpacks(opts Options) corev1.Container {
+	command := fmt.Sprintf("nixpacks build %s --out %s", sourceDir, sourceDir)
+	if opts.Source.NixpackPath != "" {
+		command += " --config " + path.Join(sourceDir, opts.Source.NixpackPath)
+	}
+	image := opts.Image
+	if image == "" {
+		// the installer of Nixpacks runs in the default image
+		image = NixpacksImage
+		command = "apk add --no-cache bash curl && curl -sSL https://nixpacks.com/install.sh | bash && " + command
+	}
+	return corev1.Container{
+		Name:         "plan",
+		Image:        image,
+		Command:      []string{"/bin/sh", "-c", "set -e; " + command},
+		VolumeMounts: []corev1.VolumeMount{workspaceMount()},
+	}
+}
+
+func buildpacks(opts Options) corev1.Container {
+	destinations := Destinations(opts.Source, opts.Revision)
+	args := []string{
+		"-app=" + sourceDir,
+		"-layers=" + layersDir,
+		"-report=" + path.Join(outputDir, "report.toml"),
+	}
+	for _, tag := range destinations[1:] {
+		args = append(args, "-tag="+tag)
+	}
+	mounts := []corev1.VolumeMount{workspaceMount(), {Name: "layers", MountPath: layersDir}}
+	if cache, _ := cacheOf(opts); cache != nil {
+		if cache.Type == conurev1alpha1.VolumeBuildCache {
+			args = append(args, "-cache-dir="+cacheDir)
+			mounts = append(mounts, corev1.VolumeMount{Name: "cache", MountPath: cacheDir})
+		} else {
+			args = append(args, "-cache-image="+CacheRepository(opts.Source, opts.CacheKey))
+		}
+	}
+	args = append(args, destinations[0])
+	var env []corev1.EnvVar
+	if opts.CredentialsSecret != "" {
+		mounts = append(mounts, corev1.VolumeMount{Name: "registry-credentials", MountPath: dockerConfigDir, ReadOnly: true})
+		env = append(env, corev1.EnvVar{Name: "DOCKER_CONFIG", Value: dockerConfigDir})
+	}
+	image := BuildpacksImage
+	if opts.Image != "" {
+		image = opts.Image
+	}
+	return corev1.Container{
+		Name:         "build",
+		Image:        image,
+		Command:      []string{"/cnb/lifecycle/creator"},
+		Args:         args,
+		Env:          env,
+		VolumeMounts: mounts,
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:  ptr.To(int64(cnbUser)),
+			RunAsGroup: ptr.To(int64(cnbUser)),
+		},
+	}
+}
+
+// report writes the digest file of the build as the termination message of the pod, which the controller reads
+func report(opts Options) corev1.Container {
+	file := path.Join(outputDir, "digest")
+	if opts.Type == conurev1alpha1.BuildpacksBuild {
+		file = path.Join(outputDir, "report.toml")
+	}
+	return corev1.Container{
+		Name:                     ReportContainer,
+		Image:                    ReportImage,
+		Command:                  []string{"/bin/sh", "-c", fmt.Sprintf("cat %s > /dev/termination-log", file)},
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		VolumeMounts:             []corev1.VolumeMount{workspaceMount()},
+	}
+}
@@ -0,0 +1,255 @@
+package build
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const testDigest = "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+
+func testSource() conurev1alpha1.Source {
+	return conurev1alpha1.Source{
+		SourceType:    "git",
+		GitRepository: "https://github.com/coffeenights/conure.git",
+		GitBranch:     "main",
+		OCIRepository: "ghcr.io/coffeenights/conure",
+		Tag:           "stable",
+	}
+}
+
+func container(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+func TestTypeOf(t *testing.T) {
+	cases := []struct {
+		source   conurev1alpha1.Source
+		expected conurev1alpha1.BuildType
+	}{
+		{conurev1alpha1.Source{BuildTool: "Nixpacks", DockerfilePath: "Dockerfile"}, conurev1alpha1.NixpacksBuild},
+		{conurev1alpha1.Source{DockerfilePath: "cmd/api-server/Dockerfile"}, conurev1alpha1.DockerfileBuild},
+		{conurev1alpha1.Source{NixpackPath: "nixpacks.toml"}, conurev1alpha1.NixpacksBuild},
+		{conurev1alpha1.Source{}, conurev1alpha1.BuildpacksBuild},
+	}
+	for _, tc := range cases {
+		if buildType := TypeOf(tc.source); buildType != tc.expected {
+			t.Errorf("Expected %s for %+v, got: %s", tc.expected, tc.source, buildType)
+		}
+	}
+}
+
+func TestDestinations(t *testing.T) {
+	source := testSource()
+	source.OCIRepository = "oci://ghcr.io/coffeenights/conure"
+	expected := []string{"ghcr.io/coffeenights/conure:stable", "ghcr.io/coffeenights/conure:59b20b8d"}
+	if destinations := Destinations(source, "59b20b8d"); !reflect.DeepEqual(destinations, expected) {
+		t.Errorf("Expected %v, got: %v", expected, destinations)
+	}
+	source.Tag = ""
+	expected = []string{"ghcr.io/coffeenights/conure:latest"}
+	if destinations := Destinations(source, ""); !reflect.DeepEqual(destinations, expected) {
+		t.Errorf("Expected %v, got: %v", expected, destinations)
+	}
+}
+
+func TestJobName(t *testing.T) {
+	if name := JobName("web", "build", "12345678"); name != "web-build-12345678" {
+		t.Errorf("Expected web-build-12345678, got: %s", name)
+	}
+	name := JobName(strings.Repeat("a", 53)+"-b", "build", "12345678")
+	if name != strings.Repeat("a", 53)+"-12345678" {
+		t.Errorf("Expected a truncated name, got: %s", name)
+	}
+}
+
+func TestParseDigest(t *testing.T) {
+	report := `[image]
+  tags = ["ghcr.io/coffeenights/conure:stable"]
+  digest = "` + testDigest + `"
+  manifest-size = 2040
+`
+	for _, message := range []string{testDigest, report} {
+		digest, err := ParseDigest(message)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if digest != testDigest {
+			t.Errorf("Expected %s, got: %s", testDigest, digest)
+		}
+	}
+	if _, err := ParseDigest(""); err == nil {
+		t.Error("Expected an error for an empty report")
+	}
+}
+
+func TestJob_Dockerfile(t *testing.T) {
+	source := testSource()
+	source.DockerfilePath = "cmd/api-server/Dockerfile"
+	job, err := Job(Options{
+		Name:              "web-build-12345678",
+		Namespace:         "development",
+		Labels:            map[string]string{conurev1alpha1.WorkflowActionNamelabel: "build"},
+		Type:              conurev1alpha1.DockerfileBuild,
+		Source:            source,
+		Revision:          "59b20b8d",
+		CredentialsSecret: "conure-registry-credentials",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if job.Labels[conurev1alpha1.BuildTypeLabel] != "dockerfile" || job.Labels[conurev1alpha1.WorkflowActionNamelabel] != "build" {
+		t.Errorf("Unexpected labels: %v", job.Labels)
+	}
+	pod := job.Spec.Template.Spec
+	clone := container(pod.InitContainers, "clone")
+	if clone == nil || !reflect.DeepEqual(clone.Env[2], corev1.EnvVar{Name: "GIT_REVISION", Value: "59b20b8d"}) {
+		t.Fatalf("Expected the clone of the revision, got: %+v", clone)
+	}
+	build := container(pod.InitContainers, "build")
+	if build == nil || build.Image != KanikoImage {
+		t.Fatalf("Expected the kaniko build, got: %+v", build)
+	}
+	expectedArgs := []string{
+		"--context=dir:///workspace/src",
+		"--dockerfile=/workspace/src/cmd/api-server/Dockerfile",
+		"--digest-file=/workspace/out/digest",
+		"--destination=ghcr.io/coffeenights/conure:stable",
+		"--destination=ghcr.io/coffeenights/conure:59b20b8d",
+	}
+	if !reflect.DeepEqual(build.Args, expectedArgs) {
+		t.Errorf("Expected %v, got: %v", expectedArgs, build.Args)
+	}
+	if mount := build.VolumeMounts[1]; mount.Name != "registry-credentials" || mount.MountPath != "/kaniko/.docker" {
+		t.Errorf("Expected the registry credentials in the docker config of kaniko, got: %+v", mount)
+	}
+	if report := container(pod.Containers, ReportContainer); report == nil || !strings.Contains(report.Command[2], "/workspace/out/digest") {
+		t.Errorf("Expected the report of the digest file, got: %+v", report)
+	}
+}
+
+func TestJob_Nixpacks(t *testing.T) {
+	source := testSource()
+	source.NixpackPath = "deploy/nixpacks.toml"
+	job, err := Job(Options{Name: "web-build-12345678", Type: conurev1alpha1.NixpacksBuild, Source: source})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	steps := job.Spec.Template.Spec.InitContainers
+	if len(steps) != 3 || steps[1].Name != "plan" || steps[2].Name != "build" {
+		t.Fatalf("Expected the clone, plan and build steps, got: %+v", steps)
+	}
+	if !strings.Contains(steps[1].Command[2], "nixpacks build /workspace/src --out /workspace/src --config /workspace/src/deploy/nixpacks.toml") {
+		t.Errorf("Unexpected plan command: %s", steps[1].Command[2])
+	}
+	if steps[2].Args[1] != "--dockerfile=/workspace/src/.nixpacks/Dockerfile" {
+		t.Errorf("Expected the build of the generated Dockerfile, got: %v", steps[2].Args)
+	}
+	if len(job.Spec.Template.Spec.ImagePullSecrets) != 0 || len(steps[2].VolumeMounts) != 1 {
+		t.Errorf("Expected no registry credentials")
+	}
+}
+
+func TestJob_Buildpacks(t *testing.T) {
+	job, err := Job(Options{
+		Name:              "web-build-12345678",
+		Type:              conurev1alpha1.BuildpacksBuild,
+		Source:            testSource(),
+		Revision:          "59b20b8d",
+		Image:             "heroku/builder:24",
+		CredentialsSecret: "conure-registry-credentials",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	build := container(job.Spec.Template.Spec.InitContainers, "build")
+	if build == nil || build.Image != "heroku/builder:24" || build.Command[0] != "/cnb/lifecycle/creator" {
+		t.Fatalf("Expected the buildpacks lifecycle of the builder, got: %+v", build)
+	}
+	expectedArgs := []string{
+		"-app=/workspace/src",
+		"-layers=/layers",
+		"-report=/workspace/out/report.toml",
+		"-tag=ghcr.io/coffeenights/conure:59b20b8d",
+		"ghcr.io/coffeenights/conure:stable",
+	}
+	if !reflect.DeepEqual(build.Args, expectedArgs) {
+		t.Errorf("Expected %v, got: %v", expectedArgs, build.Args)
+	}
+	if build.Env[0] != (corev1.EnvVar{Name: "DOCKER_CONFIG", Value: "/docker"}) {
+		t.Errorf("Expected the docker config of the registry credentials, got: %v", build.Env)
+	}
+}
+
+func TestJob_Invalid(t *testing.T) {
+	source := testSource()
+	if _, err := Job(Options{Type: "bazel", Source: source}); err == nil {
+		t.Error("Expected an error for an unknown build type")
+	}
+	source.GitRepository = ""
+	if _, err := Job(Options{Type: conurev1alpha1.DockerfileBuild, Source: source}); err == nil {
+		t.Error("Expected an error for a source without repository")
+	}
+}
+
+func TestJob_Cache(t *testing.T) {
+	source := testSource()
+	source.Cache = &conurev1alpha1.BuildCache{Type: conurev1alpha1.RegistryBuildCache, TTL: "72h"}
+	opts := Options{Name: "web-build-12345678", Type: conurev1alpha1.DockerfileBuild, Source: source, CacheKey: "web-main"}
+	job, err := Job(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	build := container(job.Spec.Template.Spec.InitContainers, "build")
+	expectedArgs := []string{"--cache=true", "--cache-ttl=72h0m0s", "--cache-repo=ghcr.io/coffeenights/conure-cache/web-main"}
+	if args := build.Args[len(build.Args)-3:]; !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected %v, got: %v", expectedArgs, build.Args)
+	}
+
+	source.Cache.Type = conurev1alpha1.VolumeBuildCache
+	opts.Type = conurev1alpha1.BuildpacksBuild
+	opts.Source = source
+	if job, err = Job(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pod := job.Spec.Template.Spec
+	if volume := pod.Volumes[len(pod.Volumes)-1]; volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != "build-cache-web-main" {
+		t.Errorf("Expected the cache volume of the key, got: %+v", volume)
+	}
+	build = container(pod.InitContainers, "build")
+	if build.Args[len(build.Args)-2] != "-cache-dir=/cache" || build.VolumeMounts[len(build.VolumeMounts)-1].MountPath != "/cache" {
+		t.Errorf("Expected the cache directory in the cache volume, got: %v %+v", build.Args, build.VolumeMounts)
+	}
+
+	opts.CacheKey = ""
+	if _, err = Job(opts); err == nil {
+		t.Error("Expected an error for a cache without key")
+	}
+}
+
+func TestReusedJob(t *testing.T) {
+	job := ReusedJob(Options{
+		Name:   "web-build-12345678",
+		Labels: map[string]string{conurev1alpha1.WorkflowActionNamelabel: "build"},
+		Type:   conurev1alpha1.DockerfileBuild,
+		Source: testSource(),
+	}, testDigest)
+	if job.Labels[conurev1alpha1.BuildReusedLabel] != "true" || job.Labels[conurev1alpha1.BuildTypeLabel] != "dockerfile" {
+		t.Errorf("Unexpected labels: %v", job.Labels)
+	}
+	pod := job.Spec.Template.Spec
+	if len(pod.InitContainers) != 0 || len(pod.Containers) != 1 || pod.Containers[0].Name != ReportContainer {
+		t.Fatalf("Expected only the report container, got: %+v", pod)
+	}
+	if env := pod.Containers[0].Env; len(env) != 1 || env[0].Value != testDigest {
+		t.Errorf("Expected the digest of the image, got: %v", env)
+	}
+}
@@ -0,0 +1,118 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// DefaultCacheTTL is how long the cached layers are reused by default, two weeks
+	DefaultCacheTTL = 14 * 24 * time.Hour
+	// DefaultCacheSize is the size of the cache volumes by default
+	DefaultCacheSize  = "10Gi"
+	cacheDir          = "/cache"
+	cacheVolumePrefix = "build-cache-"
+)
+
+// CacheKey returns the key of the cache of the builds of the branch of the component, a DNS label short enough to
+// name the cache volumes
+func CacheKey(component string, branch string) string {
+	if branch == "" {
+		branch = "default"
+	}
+	key := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(component+"-"+branch))
+	if maxLength := validation.DNS1123LabelMaxLength - len(cacheVolumePrefix); len(key) > maxLength {
+		key = key[:maxLength]
+	}
+	return strings.Trim(key, "-")
+}
+
+// CacheVolumeName returns the name of the volume of the cache key
+func CacheVolumeName(key string) string {
+	return cacheVolumePrefix + key
+}
+
+// CacheTTL returns the retention of the cached layers
+func CacheTTL(cache *conurev1alpha1.BuildCache) (time.Duration, error) {
+	if cache == nil || cache.TTL == "" {
+		return DefaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(cache.TTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid build cache ttl %q: %w", cache.TTL, err)
+	}
+	return ttl, nil
+}
+
+// CacheRepository returns the repository of the layers of the registry cache of the key
+func CacheRepository(source conurev1alpha1.Source, key string) string {
+	repository := Repository(source) + "-cache"
+	if source.Cache != nil && source.Cache.Repository != "" {
+		repository = strings.TrimPrefix(source.Cache.Repository, "oci://")
+	}
+	return repository + "/" + key
+}
+
+// CacheVolume returns the claim of the volume of a volume cache, the build using it sets its last use
+func CacheVolume(namespace string, component string, key string, cache *conurev1alpha1.BuildCache, now time.Time) (*corev1.PersistentVolumeClaim, error) {
+	size := DefaultCacheSize
+	if cache.Size != "" {
+		size = cache.Size
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid build cache size %q: %w", size, err)
+	}
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CacheVolumeName(key),
+			Namespace: namespace,
+			Labels: map[string]string{
+				conurev1alpha1.BuildCacheLabel: key,
+				k8sUtils.ComponentNameLabel:    component,
+			},
+			Annotations: map[string]string{
+				conurev1alpha1.BuildCacheLastUsedAnnotation: now.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+			},
+		},
+	}, nil
+}
+
+// StaleCacheVolumes returns the names of the cache volumes which weren't used by a build within the ttl, the volume
+// of the key being built is kept
+func StaleCacheVolumes(volumes []corev1.PersistentVolumeClaim, key string, ttl time.Duration, now time.Time) []string {
+	var stale []string
+	for _, volume := range volumes {
+		if volume.Labels[conurev1alpha1.BuildCacheLabel] == key {
+			continue
+		}
+		lastUsed, err := time.Parse(time.RFC3339, volume.Annotations[conurev1alpha1.BuildCacheLastUsedAnnotation])
+		if err != nil {
+			lastUsed = volume.CreationTimestamp.Time
+		}
+		if now.Sub(lastUsed) > ttl {
+			stale = append(stale, volume.Name)
+		}
+	}
+	return stale
+}
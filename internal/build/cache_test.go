@@ -0,0 +1,91 @@
+package build
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCacheKey(t *testing.T) {
+	if key := CacheKey("web", "feature/Login_Form"); key != "web-feature-login-form" {
+		t.Errorf("Expected web-feature-login-form, got: %s", key)
+	}
+	if key := CacheKey("web", ""); key != "web-default" {
+		t.Errorf("Expected web-default, got: %s", key)
+	}
+	key := CacheKey("web", strings.Repeat("b", 100))
+	if name := CacheVolumeName(key); len(name) != 63 {
+		t.Errorf("Expected a volume name of 63 characters, got: %s", name)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	if ttl, err := CacheTTL(nil); err != nil || ttl != DefaultCacheTTL {
+		t.Errorf("Expected the default ttl, got: %s, %v", ttl, err)
+	}
+	if ttl, err := CacheTTL(&conurev1alpha1.BuildCache{TTL: "72h"}); err != nil || ttl != 72*time.Hour {
+		t.Errorf("Expected 72h, got: %s, %v", ttl, err)
+	}
+	if _, err := CacheTTL(&conurev1alpha1.BuildCache{TTL: "3 days"}); err == nil {
+		t.Error("Expected an error for an invalid ttl")
+	}
+}
+
+func TestCacheRepository(t *testing.T) {
+	source := testSource()
+	source.Cache = &conurev1alpha1.BuildCache{Type: conurev1alpha1.RegistryBuildCache}
+	if repository := CacheRepository(source, "web-main"); repository != "ghcr.io/coffeenights/conure-cache/web-main" {
+		t.Errorf("Expected the cache repository next to the image, got: %s", repository)
+	}
+	source.Cache.Repository = "oci://registry.local/cache"
+	if repository := CacheRepository(source, "web-main"); repository != "registry.local/cache/web-main" {
+		t.Errorf("Expected the cache repository of the source, got: %s", repository)
+	}
+}
+
+func TestCacheVolume(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	volume, err := CacheVolume("development", "web", "web-main", &conurev1alpha1.BuildCache{Size: "5Gi"}, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if volume.Name != "build-cache-web-main" || volume.Labels[conurev1alpha1.BuildCacheLabel] != "web-main" {
+		t.Errorf("Unexpected volume: %+v", volume.ObjectMeta)
+	}
+	if volume.Annotations[conurev1alpha1.BuildCacheLastUsedAnnotation] != "2024-05-01T10:00:00Z" {
+		t.Errorf("Expected the last use of the volume, got: %v", volume.Annotations)
+	}
+	if size := volume.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "5Gi" {
+		t.Errorf("Expected 5Gi, got: %s", size.String())
+	}
+	if _, err = CacheVolume("development", "web", "web-main", &conurev1alpha1.BuildCache{Size: "big"}, now); err == nil {
+		t.Error("Expected an error for an invalid size")
+	}
+}
+
+func TestStaleCacheVolumes(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	volume := func(key string, lastUsed string, created time.Time) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:              CacheVolumeName(key),
+			Labels:            map[string]string{conurev1alpha1.BuildCacheLabel: key},
+			Annotations:       map[string]string{conurev1alpha1.BuildCacheLastUsedAnnotation: lastUsed},
+			CreationTimestamp: metav1.NewTime(created),
+		}}
+	}
+	volumes := []corev1.PersistentVolumeClaim{
+		volume("web-main", "2024-01-01T00:00:00Z", now),
+		volume("web-feature", "2024-05-14T00:00:00Z", now),
+		volume("web-old", "2024-04-01T00:00:00Z", now),
+		volume("web-unknown", "", now.Add(-30*24*time.Hour)),
+	}
+	expected := []string{"build-cache-web-old", "build-cache-web-unknown"}
+	if stale := StaleCacheVolumes(volumes, "web-main", 7*24*time.Hour, now); !reflect.DeepEqual(stale, expected) {
+		t.Errorf("Expected %v, got: %v", expected, stale)
+	}
+}
@@ -0,0 +1,34 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// ImageDigest returns the digest of the image of the reference, empty when the registry doesn't have it. The
+// credentials are the username:password of the registry, it's accessed anonymously when they're empty.
+func ImageDigest(ctx context.Context, reference string, credentials string) (string, error) {
+	ref, err := name.ParseReference(strings.TrimPrefix(reference, "oci://"))
+	if err != nil {
+		return "", err
+	}
+	auth := authn.Anonymous
+	if username, password, ok := strings.Cut(credentials, ":"); ok {
+		auth = &authn.Basic{Username: username, Password: password}
+	}
+	descriptor, err := remote.Head(ref, remote.WithContext(ctx), remote.WithAuth(auth))
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return descriptor.Digest.String(), nil
+}
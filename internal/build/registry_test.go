@@ -0,0 +1,50 @@
+package build
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestImageDigest(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	repository := strings.TrimPrefix(server.URL, "http://") + "/coffeenights/conure"
+
+	image, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ref, err := name.ParseReference(repository + ":59b20b8d")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err = remote.Write(ref, image); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected, err := image.Digest()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	digest, err := ImageDigest(context.Background(), "oci://"+repository+":59b20b8d", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if digest != expected.String() {
+		t.Errorf("Expected %s, got: %s", expected, digest)
+	}
+	digest, err = ImageDigest(context.Background(), repository+":0000000", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if digest != "" {
+		t.Errorf("Expected no digest for a missing image, got: %s", digest)
+	}
+}
@@ -1,49 +1,259 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// FileEnv is the environment variable with the path of the optional YAML config file
+const FileEnv = "CONFIG_FILE"
+
+// The struct tags read by Load:
+//
+//	env:"NAME"            the environment variable of the field
+//	env:"NAME,required"   the field must be set by the file or the environment, an empty value doesn't count
+//	default:"value"       the value when neither the file nor the environment set the field
+//	sep:";"               the separator of the slices in the environment and the defaults, a comma otherwise
+//	oneof:"a b"           the values allowed, separated by spaces
+//	yaml:"key"            the key of the field in the config file, the env name in lower case otherwise
+//	envPrefix:"PREFIX_"   on a nested struct, prepended to the env names of its fields
+//
+// Nested structs are the struct fields without an env tag, in the config file they are a map under the yaml tag or
+// the field name in lower case.
+const (
+	envTag       = "env"
+	defaultTag   = "default"
+	sepTag       = "sep"
+	oneOfTag     = "oneof"
+	yamlTag      = "yaml"
+	envPrefixTag = "envPrefix"
 )
 
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// FieldError is a problem with the value of a field
+type FieldError struct {
+	Env string
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Env, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors lists every problem of the configuration
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	problems := make([]string, len(e))
+	for i, err := range e {
+		problems[i] = err.Error()
+	}
+	return "invalid configuration: " + strings.Join(problems, "; ")
+}
+
+var ErrRequired = errors.New("is required")
+
+// Load fills config, a pointer to a struct, from the defaults of its fields, then the YAML file when file is not
+// empty, then the environment. The problems of all the fields are returned at once as Errors.
+func Load(config interface{}, file string) error {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a pointer to a struct, got %T", config)
+	}
+	values := map[string]interface{}{}
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("reading the config file: %w", err)
+		}
+		if values, err = parseYAML(content); err != nil {
+			return fmt.Errorf("parsing the config file %s: %w", file, err)
+		}
+	}
+	var errs Errors
+	loadStruct(v.Elem(), "", values, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// LoadConfig loads the config from the file in CONFIG_FILE, if any, and the environment. It panics with every
+// problem found, it's meant for the tests, the commands load their config once with Load.
 func LoadConfig[C any](config C) *C {
-	v := reflect.ValueOf(&config).Elem()
-	t := reflect.TypeOf(&config).Elem()
+	if err := Load(&config, os.Getenv(FileEnv)); err != nil {
+		log.Panic(err)
+	}
+	return &config
+}
+
+func parseYAML(content []byte) (map[string]interface{}, error) {
+	content, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	// keeps the numbers as written, an int wouldn't parse from the float decoded otherwise
+	decoder.UseNumber()
+	if err = decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
 
-	for i := 0; i < v.NumField(); i++ {
+func loadStruct(v reflect.Value, prefix string, values map[string]interface{}, errs *Errors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		envName, ok := field.Tag.Lookup("env")
+		if !field.IsExported() {
+			continue
+		}
+		tag, ok := field.Tag.Lookup(envTag)
 		if !ok {
-			continue // Skip fields without an 'env' tag
+			if field.Type.Kind() == reflect.Struct {
+				nested, _ := values[fileKey(field, strings.ToLower(field.Name))].(map[string]interface{})
+				loadStruct(v.Field(i), prefix+field.Tag.Get(envPrefixTag), nested, errs)
+			}
+			continue
 		}
+		name, options, _ := strings.Cut(tag, ",")
+		env := prefix + name
+		if err := loadField(v.Field(i), field, env, values[fileKey(field, strings.ToLower(name))], options == "required"); err != nil {
+			*errs = append(*errs, &FieldError{Env: env, Err: err})
+		}
+	}
+}
+
+func fileKey(field reflect.StructField, fallback string) string {
+	if key, _, _ := strings.Cut(field.Tag.Get(yamlTag), ","); key != "" {
+		return key
+	}
+	return fallback
+}
 
-		env, exist := os.LookupEnv(envName)
-		if !exist {
-			log.Panicf("environment variable not found: %s", envName)
+// loadField sets the field from the value with the highest precedence: the environment, the file, then the default
+func loadField(v reflect.Value, field reflect.StructField, env string, fileValue interface{}, required bool) error {
+	sep := field.Tag.Get(sepTag)
+	if sep == "" {
+		sep = ","
+	}
+	var value interface{}
+	if defaultValue, ok := field.Tag.Lookup(defaultTag); ok {
+		value = defaultValue
+	}
+	if fileValue != nil {
+		value = fileValue
+	}
+	if envValue, ok := os.LookupEnv(env); ok {
+		value = envValue
+	}
+	if value == nil || value == "" {
+		if required {
+			return ErrRequired
 		}
+		if value == "" {
+			// an empty value unsets the field
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
 
-		// Handle different field types appropriately
-		switch v.Field(i).Kind() {
-		case reflect.String:
-			v.Field(i).SetString(env)
-		case reflect.Int, reflect.Int64:
-			intValue, err := strconv.ParseInt(env, 10, 64)
-			if err != nil {
-				log.Panicf("failed to parse int64 for %s: %v", envName, err)
-			}
-			v.Field(i).SetInt(intValue)
-		case reflect.Bool:
-			boolValue, err := strconv.ParseBool(env)
-			if err != nil {
-				log.Panicf("failed to parse bool for %s: %v", envName, err)
+	if v.Kind() == reflect.Slice {
+		var items []interface{}
+		switch typed := value.(type) {
+		case []interface{}:
+			items = typed
+		case string:
+			for _, item := range strings.Split(typed, sep) {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
 			}
-			v.Field(i).SetBool(boolValue)
 		default:
-			// Optionally, handle unsupported field types or return an error
-			log.Panicf("unsupported field type %s for field %s", v.Field(i).Type(), field.Name)
+			return fmt.Errorf("expected a list, got %v", value)
 		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setScalar(slice.Index(i), field, item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
 	}
+	return setScalar(v, field, value)
+}
 
-	return &config
+func setScalar(v reflect.Value, field reflect.StructField, value interface{}) error {
+	var raw string
+	switch typed := value.(type) {
+	case string:
+		raw = typed
+	case json.Number:
+		raw = typed.String()
+	case bool:
+		raw = strconv.FormatBool(typed)
+	default:
+		return fmt.Errorf("expected a single value, got %v", value)
+	}
+
+	if oneOf, ok := field.Tag.Lookup(oneOfTag); ok && raw != "" && !slices.Contains(strings.Fields(oneOf), raw) {
+		return fmt.Errorf("%q is not one of %s", raw, strings.Join(strings.Fields(oneOf), ", "))
+	}
+	if v.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(duration))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		boolValue, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", raw)
+		}
+		v.SetBool(boolValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid int %q", raw)
+		}
+		v.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintValue, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid uint %q", raw)
+		}
+		v.SetUint(uintValue)
+	case reflect.Float32, reflect.Float64:
+		floatValue, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid float %q", raw)
+		}
+		v.SetFloat(floatValue)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
 }
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDatabase struct {
+	URI     string        `env:"URI,required"`
+	Timeout time.Duration `env:"TIMEOUT" default:"10s"`
+}
+
+type testConfig struct {
+	Name     string       `env:"TEST_NAME" default:"conure"`
+	Port     int          `env:"TEST_PORT" default:"8080"`
+	Debug    bool         `env:"TEST_DEBUG"`
+	Ratio    float64      `env:"TEST_RATIO" default:"0.5"`
+	Origins  []string     `env:"TEST_ORIGINS" sep:";"`
+	Ports    []int        `env:"TEST_PORTS"`
+	Mode     string       `env:"TEST_MODE" default:"invite" oneof:"closed invite open"`
+	Secret   string       `env:"TEST_SECRET,required" yaml:"secret"`
+	Database testDatabase `envPrefix:"TEST_DATABASE_" yaml:"database"`
+	ignored  string
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("TEST_SECRET", "s3cr3t")
+	t.Setenv("TEST_DATABASE_URI", "mongodb://localhost:27017")
+	var conf testConfig
+	if err := Load(&conf, ""); err != nil {
+		t.Fatal(err)
+	}
+	expected := testConfig{
+		Name:     "conure",
+		Port:     8080,
+		Ratio:    0.5,
+		Mode:     "invite",
+		Secret:   "s3cr3t",
+		Database: testDatabase{URI: "mongodb://localhost:27017", Timeout: 10 * time.Second},
+	}
+	if !reflect.DeepEqual(conf, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, conf)
+	}
+}
+
+func TestLoad_Environment(t *testing.T) {
+	t.Setenv("TEST_SECRET", "s3cr3t")
+	t.Setenv("TEST_PORT", "9090")
+	t.Setenv("TEST_DEBUG", "true")
+	t.Setenv("TEST_ORIGINS", "http://localhost:5173; https://conure.io")
+	t.Setenv("TEST_PORTS", "80,443")
+	t.Setenv("TEST_DATABASE_URI", "mongodb://mongo:27017")
+	t.Setenv("TEST_DATABASE_TIMEOUT", "1m30s")
+	var conf testConfig
+	if err := Load(&conf, ""); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Port != 9090 || !conf.Debug {
+		t.Errorf("Expected the scalars from the environment, got: %+v", conf)
+	}
+	if !reflect.DeepEqual(conf.Origins, []string{"http://localhost:5173", "https://conure.io"}) {
+		t.Errorf("Expected the origins split on ;, got: %v", conf.Origins)
+	}
+	if !reflect.DeepEqual(conf.Ports, []int{80, 443}) {
+		t.Errorf("Expected the ports split on commas, got: %v", conf.Ports)
+	}
+	if conf.Database.URI != "mongodb://mongo:27017" || conf.Database.Timeout != 90*time.Second {
+		t.Errorf("Expected the nested struct from the prefixed variables, got: %+v", conf.Database)
+	}
+}
+
+func TestLoad_File(t *testing.T) {
+	file := writeFile(t, `
+test_name: from-file
+test_port: 7070
+test_origins:
+  - https://a.conure.io
+  - https://b.conure.io
+secret: from-file
+database:
+  uri: mongodb://file:27017
+  timeout: 5s
+`)
+	// the environment wins over the file
+	t.Setenv("TEST_PORT", "9090")
+	var conf testConfig
+	if err := Load(&conf, file); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Name != "from-file" || conf.Port != 9090 || conf.Secret != "from-file" {
+		t.Errorf("Expected the file overlaid with the environment, got: %+v", conf)
+	}
+	if !reflect.DeepEqual(conf.Origins, []string{"https://a.conure.io", "https://b.conure.io"}) {
+		t.Errorf("Expected the origins from the list, got: %v", conf.Origins)
+	}
+	if conf.Database.URI != "mongodb://file:27017" || conf.Database.Timeout != 5*time.Second {
+		t.Errorf("Expected the nested struct from the file, got: %+v", conf.Database)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Setenv("TEST_PORT", "eighty")
+	t.Setenv("TEST_MODE", "public")
+	t.Setenv("TEST_SECRET", "")
+	t.Setenv("TEST_DATABASE_TIMEOUT", "10")
+	var conf testConfig
+	err := Load(&conf, "")
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected the errors of the config, got: %v", err)
+	}
+	problems := map[string]string{}
+	for _, fieldErr := range errs {
+		problems[fieldErr.Env] = fieldErr.Err.Error()
+	}
+	expected := map[string]string{
+		"TEST_PORT":             `invalid int "eighty"`,
+		"TEST_MODE":             `"public" is not one of closed, invite, open`,
+		"TEST_SECRET":           "is required",
+		"TEST_DATABASE_URI":     "is required",
+		"TEST_DATABASE_TIMEOUT": `invalid duration "10"`,
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Expected every problem at once %v, got: %v", expected, problems)
+	}
+	if !strings.HasPrefix(err.Error(), "invalid configuration: ") {
+		t.Errorf("Unexpected message: %v", err)
+	}
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	if err := Load(&testConfig{}, writeFile(t, "secret: [unclosed")); err == nil {
+		t.Error("Expected an error for an invalid file")
+	}
+	if err := Load(&testConfig{}, filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if err := Load(testConfig{}, ""); err == nil {
+		t.Error("Expected an error for a config which is not a pointer")
+	}
+}
@@ -3,6 +3,7 @@ package application
 import (
 	"context"
 	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	"github.com/coffeenights/conure/internal/controller/core/common"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -26,6 +27,8 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		logger.V(1).Info("Application resource not found.")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	ctx, span := common.StartReconcileSpan(ctx, "Application", &application)
+	defer span.End()
 
 	handler, err := NewApplicationHandler(ctx, &application, r)
 	if err != nil {
@@ -12,6 +12,7 @@ import (
 	"github.com/stefanprodan/timoni/pkg/module"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"maps"
 	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -19,6 +20,13 @@ import (
 	"strings"
 )
 
+const (
+	// GitSourceType is the source type of the components built from a git repository
+	GitSourceType = "git"
+	// BuildActionType is the built-in action building the image of a component
+	BuildActionType = "build"
+)
+
 type ApplicationHandler struct {
 	Application *conurev1alpha1.Application
 	Reconciler  *ApplicationReconciler
@@ -62,7 +70,7 @@ func (a *ApplicationHandler) ReconcileComponent(componentTemp *conurev1alpha1.Co
 	// Build the component object
 	component.ObjectMeta = metav1.ObjectMeta{
 		Name:        componentTemp.Name,
-		Annotations: componentTemp.Annotations,
+		Annotations: common.PropagateTrace(a.Application, maps.Clone(componentTemp.Annotations)),
 		Namespace:   a.Application.Namespace,
 	}
 	component.Spec = componentTemp.Spec
@@ -228,6 +236,7 @@ func (a *ApplicationHandler) updateComponent(component *conurev1alpha1.Component
 			existingComponent.SetLabels(labels)
 		}
 		existingComponent.Spec = *component.Spec.DeepCopy()
+		existingComponent.Annotations = common.PropagateTrace(a.Application, existingComponent.Annotations)
 		if err := a.Reconciler.Update(a.Ctx, existingComponent); err != nil {
 			a.Logger.Error(err, "Unable to update the component for application", "component", component.Name)
 			return err
@@ -249,7 +258,11 @@ func (a *ApplicationHandler) setComponentWorkflow(component *conurev1alpha1.Comp
 	if err = d.Decode(&values); err != nil {
 		return err
 	}
-	componentTemplate, err := module.NewManager(a.Ctx, component.Name, component.Spec.OCIRepository, component.Spec.OCITag, a.Application.Namespace, "", true, values.Get())
+	credentials, err := common.ModuleCredentials(a.Ctx, a.Reconciler, a.Application.Namespace, component.Spec.OCIRepository)
+	if err != nil {
+		return err
+	}
+	componentTemplate, err := module.NewManager(a.Ctx, component.Name, component.Spec.OCIRepository, component.Spec.OCITag, a.Application.Namespace, credentials, true, values.Get())
 	if err != nil {
 		return err
 	}
@@ -258,9 +271,11 @@ func (a *ApplicationHandler) setComponentWorkflow(component *conurev1alpha1.Comp
 		return err
 	}
 	// find the workflow and apply it
+	rendered := false
 	for _, set := range sets {
 		for _, obj := range set.Objects {
 			if obj.GetKind() == "Workflow" {
+				rendered = true
 				_, err = componentTemplate.ApplyObject(obj, false)
 				if err != nil {
 					return err
@@ -268,9 +283,34 @@ func (a *ApplicationHandler) setComponentWorkflow(component *conurev1alpha1.Comp
 			}
 		}
 	}
+	if !rendered && component.Spec.Values.Source.SourceType == GitSourceType {
+		return a.createBuildWorkflow(component)
+	}
 	return nil
 }
 
+// createBuildWorkflow creates the workflow of a component built from git whose module has none, its build action
+// picks the build from the source of the component
+func (a *ApplicationHandler) createBuildWorkflow(component *conurev1alpha1.Component) error {
+	workflow := conurev1alpha1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component.Name,
+			Namespace: a.Application.Namespace,
+		},
+		Spec: conurev1alpha1.WorkflowSpec{
+			Actions: []conurev1alpha1.Action{{Name: BuildActionType, Type: BuildActionType}},
+		},
+	}
+	if err := ctrl.SetControllerReference(a.Application, &workflow, a.Reconciler.Scheme); err != nil {
+		return err
+	}
+	err := a.Reconciler.Create(a.Ctx, &workflow)
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
 func (a *ApplicationHandler) runComponentWorkflow(component *conurev1alpha1.Component) (*conurev1alpha1.WorkflowRun, error) {
 	var wfl conurev1alpha1.Workflow
 	if err := a.Reconciler.Get(a.Ctx, client.ObjectKey{Namespace: a.Application.Namespace, Name: component.Name}, &wfl); err != nil {
@@ -280,6 +320,7 @@ func (a *ApplicationHandler) runComponentWorkflow(component *conurev1alpha1.Comp
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: component.Name + "-",
 			Namespace:    a.Application.Namespace,
+			Annotations:  common.PropagateTrace(a.Application, nil),
 			Labels: map[string]string{
 				k8sUtils.ApplicationNameLabel: a.Application.Name,
 				k8sUtils.ComponentNameLabel:   component.Name,
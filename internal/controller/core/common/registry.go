@@ -0,0 +1,34 @@
+package common
+
+import (
+	"context"
+
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegistryCredentials returns the docker config of the registry credentials secret synced by the API server in the
+// namespace, nil when the organization has no registry integration.
+func RegistryCredentials(ctx context.Context, c client.Reader, namespace string) (*k8sUtils.DockerConfig, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: k8sUtils.RegistryCredentialsSecret}, secret)
+	if k8sErrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return k8sUtils.ParseDockerConfig(secret.Data[corev1.DockerConfigJsonKey])
+}
+
+// ModuleCredentials returns the username:password to pull the module from its OCI repository, empty when the
+// registry of the module has no credentials in the namespace.
+func ModuleCredentials(ctx context.Context, c client.Reader, namespace string, ociRepository string) (string, error) {
+	dockerConfig, err := RegistryCredentials(ctx, c, namespace)
+	if err != nil || dockerConfig == nil {
+		return "", err
+	}
+	credentials, _ := dockerConfig.Credentials(ociRepository)
+	return credentials, nil
+}
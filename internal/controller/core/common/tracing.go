@@ -0,0 +1,38 @@
+package common
+
+import (
+	"context"
+	"github.com/coffeenights/conure/internal/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var tracer = otel.Tracer("github.com/coffeenights/conure/internal/controller")
+
+// StartReconcileSpan continues the trace found in the annotations of the object, the one of the API request that
+// deployed it. The trace id is added to the logger of the returned context.
+func StartReconcileSpan(ctx context.Context, kind string, obj client.Object) (context.Context, trace.Span) {
+	ctx = telemetry.ExtractAnnotations(ctx, obj.GetAnnotations())
+	ctx, span := tracer.Start(ctx, "Reconcile "+kind, trace.WithAttributes(
+		attribute.String("k8s.namespace.name", obj.GetNamespace()),
+		attribute.String("conure.object.kind", kind),
+		attribute.String("conure.object.name", obj.GetName()),
+	))
+	if spanContext := span.SpanContext(); spanContext.HasTraceID() {
+		logger := log.FromContext(ctx).WithValues("trace_id", spanContext.TraceID().String())
+		ctx = log.IntoContext(ctx, logger)
+	}
+	return ctx, span
+}
+
+// PropagateTrace copies the trace annotations of the parent to the annotations, which may be nil
+func PropagateTrace(parent client.Object, annotations map[string]string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	telemetry.CopyAnnotations(parent.GetAnnotations(), annotations)
+	return annotations
+}
@@ -3,6 +3,7 @@ package component
 import (
 	"context"
 	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	"github.com/coffeenights/conure/internal/controller/core/common"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,6 +21,7 @@ type ComponentReconciler struct {
 //+kubebuilder:rbac:groups=core.conure.io,resources=applications,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.conure.io,resources=applications/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.conure.io,resources=applications/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
 func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -28,6 +30,8 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		logger.V(1).Info("Component resource not found.")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	ctx, span := common.StartReconcileSpan(ctx, conurev1alpha1.ComponentKind, &component)
+	defer span.End()
 
 	// Reconcile deployed objects
 	componentHandler := NewComponentHandler(ctx, &component, r)
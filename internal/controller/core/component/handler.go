@@ -7,7 +7,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	"github.com/coffeenights/conure/internal/build"
 	"github.com/coffeenights/conure/internal/controller/core/common"
+	k8sUtils "github.com/coffeenights/conure/internal/k8s"
 	"github.com/coffeenights/conure/internal/timoni"
 	"github.com/go-logr/logr"
 	"github.com/stefanprodan/timoni/pkg/module"
@@ -68,8 +70,25 @@ func NewComponentHandler(ctx context.Context, component *conurev1alpha1.Componen
 }
 
 func (c *ComponentHandler) renderComponent() error {
+	dockerConfig, err := common.RegistryCredentials(c.Ctx, c.Reconciler, c.Component.Namespace)
+	if err != nil {
+		return err
+	}
+	componentValues := c.Component.Spec.Values
+	var credentials string
+	if dockerConfig != nil {
+		credentials, _ = dockerConfig.Credentials(c.Component.Spec.OCIRepository)
+		// the workloads pull their images with the registry integrations of the organization
+		if componentValues.Source.ImagePullSecretsName == "" {
+			componentValues.Source.ImagePullSecretsName = k8sUtils.RegistryCredentialsSecret
+		}
+	}
+	// the workloads run the image of the last build of their source, pinned to its digest
+	if built := c.Component.Status.Build; built != nil && built.OCIRepository == build.Repository(componentValues.Source) && built.Tag == build.Tag(componentValues.Source) {
+		componentValues.Source.Tag = built.Tag + "@" + built.Digest
+	}
 	// Transform the values to a map
-	valuesJSON, err := json.Marshal(c.Component.Spec.Values)
+	valuesJSON, err := json.Marshal(componentValues)
 	if err != nil {
 		return err
 	}
@@ -81,7 +100,7 @@ func (c *ComponentHandler) renderComponent() error {
 	if err = d.Decode(&values); err != nil {
 		return err
 	}
-	c.componentTemplate, err = module.NewManager(c.Ctx, c.Component.Name, c.Component.Spec.OCIRepository, c.Component.Spec.OCITag, c.Component.Namespace, "", true, values.Get())
+	c.componentTemplate, err = module.NewManager(c.Ctx, c.Component.Name, c.Component.Spec.OCIRepository, c.Component.Spec.OCITag, c.Component.Namespace, credentials, true, values.Get())
 	if err != nil {
 		return err
 	}
@@ -221,7 +240,11 @@ func (c *ComponentHandler) ReconcileDeployedObjects() error {
 	// c.updateStatus()
 
 	// Apply the resources
-	manager, err := module.NewManager(c.Ctx, c.Component.Name, c.Component.Spec.OCIRepository, c.Component.Spec.OCITag, c.Component.Namespace, "", true, map[string]interface{}{})
+	credentials, err := common.ModuleCredentials(c.Ctx, c.Reconciler, c.Component.Namespace, c.Component.Spec.OCIRepository)
+	if err != nil {
+		return err
+	}
+	manager, err := module.NewManager(c.Ctx, c.Component.Name, c.Component.Spec.OCIRepository, c.Component.Spec.OCITag, c.Component.Namespace, credentials, true, map[string]interface{}{})
 	if err != nil {
 		return err
 	}
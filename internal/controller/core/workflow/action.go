@@ -2,12 +2,20 @@ package workflow
 
 import (
 	"context"
+	"time"
+
 	coreconureiov1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	"github.com/coffeenights/conure/internal/build"
+	"github.com/coffeenights/conure/internal/controller/core/common"
 	k8sUtils "github.com/coffeenights/conure/internal/k8s"
 	"github.com/coffeenights/conure/internal/timoni"
 	"github.com/stefanprodan/timoni/pkg/module"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -18,25 +26,23 @@ const (
 )
 
 type ActionsHandler struct {
-	Ctx                context.Context
-	Reconciler         *WorkflowReconciler
-	Actions            []coreconureiov1alpha1.Action
-	Workflow           *coreconureiov1alpha1.Workflow
-	WorkflowRun        *coreconureiov1alpha1.WorkflowRun
-	OCIRepoCredentials string
-	Namespace          string
-	ID                 string
+	Ctx         context.Context
+	Reconciler  *WorkflowReconciler
+	Actions     []coreconureiov1alpha1.Action
+	Workflow    *coreconureiov1alpha1.Workflow
+	WorkflowRun *coreconureiov1alpha1.WorkflowRun
+	Namespace   string
+	ID          string
 }
 
 func NewActionsHandler(ctx context.Context, Namespace string, wflw *coreconureiov1alpha1.Workflow, wflr *coreconureiov1alpha1.WorkflowRun, reconciler *WorkflowReconciler) *ActionsHandler {
 	return &ActionsHandler{
-		Ctx:                ctx,
-		OCIRepoCredentials: "", // TODO: Take it from integrations
-		Namespace:          Namespace,
-		ID:                 k8sUtils.Generate8DigitHash(),
-		Workflow:           wflw,
-		WorkflowRun:        wflr,
-		Reconciler:         reconciler,
+		Ctx:         ctx,
+		Namespace:   Namespace,
+		ID:          k8sUtils.Generate8DigitHash(),
+		Workflow:    wflw,
+		WorkflowRun: wflr,
+		Reconciler:  reconciler,
 	}
 }
 
@@ -63,13 +69,32 @@ func (a *ActionsHandler) RunAction(action *coreconureiov1alpha1.Action) error {
 	if err != nil {
 		return err
 	}
+	if actionDefinition.Spec.Build != nil {
+		return a.runBuild(action, actionDefinition.Spec.Build)
+	}
 	logger.V(1).Info("Running action", "action", action.Name)
 	values := timoni.Values{}
 	if err = values.ExtractFromRawExtension(action.Values); err != nil {
 		return err
 	}
 	values["nameSuffix"] = a.ID
-	modManager, err := module.NewManager(a.Ctx, actionDefinition.Name, actionDefinition.Spec.OCIRepository, actionDefinition.Spec.OCITag, a.Namespace, a.OCIRepoCredentials, true, values.Get())
+	if a.WorkflowRun.Spec.Revision != "" {
+		// a run triggered by a push builds the pushed commit
+		values["revision"] = a.WorkflowRun.Spec.Revision
+	}
+	// the registry integrations of the organization pull the module, and pull and push the images of the action
+	dockerConfig, err := common.RegistryCredentials(a.Ctx, a.Reconciler, a.Namespace)
+	if err != nil {
+		return err
+	}
+	var credentials string
+	if dockerConfig != nil {
+		credentials, _ = dockerConfig.Credentials(actionDefinition.Spec.OCIRepository)
+		if _, ok := values["imagePullSecretsName"]; !ok {
+			values["imagePullSecretsName"] = k8sUtils.RegistryCredentialsSecret
+		}
+	}
+	modManager, err := module.NewManager(a.Ctx, actionDefinition.Name, actionDefinition.Spec.OCIRepository, actionDefinition.Spec.OCITag, a.Namespace, credentials, true, values.Get())
 	if err != nil {
 		return err
 	}
@@ -107,3 +132,135 @@ func (a *ActionsHandler) RunAction(action *coreconureiov1alpha1.Action) error {
 	}
 	return nil
 }
+
+// runBuild creates the job building the image of the component of the run, the build type of the definition or,
+// when it has none, the one picked from the source of the component
+func (a *ActionsHandler) runBuild(action *coreconureiov1alpha1.Action, definition *coreconureiov1alpha1.BuildDefinition) error {
+	logger := log.FromContext(a.Ctx)
+	var component coreconureiov1alpha1.Component
+	if err := a.Reconciler.Get(a.Ctx, client.ObjectKey{Namespace: a.Namespace, Name: a.WorkflowRun.Spec.ComponentName}, &component); err != nil {
+		return err
+	}
+	source := component.Spec.Values.Source
+	buildType := definition.Type
+	if buildType == "" {
+		buildType = build.TypeOf(source)
+	}
+	dockerConfig, err := common.RegistryCredentials(a.Ctx, a.Reconciler, a.Namespace)
+	if err != nil {
+		return err
+	}
+	var credentialsSecret string
+	if dockerConfig != nil {
+		credentialsSecret = k8sUtils.RegistryCredentialsSecret
+	}
+	opts := build.Options{
+		Name:      build.JobName(component.Name, action.Name, a.ID),
+		Namespace: a.Namespace,
+		Labels: map[string]string{
+			coreconureiov1alpha1.WorkflowActionNamelabel: action.Name,
+			k8sUtils.ComponentNameLabel:                  component.Name,
+		},
+		Type:              buildType,
+		Source:            source,
+		Revision:          a.WorkflowRun.Spec.Revision,
+		Image:             definition.Image,
+		CredentialsSecret: credentialsSecret,
+		CacheKey:          build.CacheKey(component.Name, source.GitBranch),
+	}
+	job, err := a.reusedBuild(opts, dockerConfig)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		if err = a.prepareBuildCache(&component, opts.CacheKey); err != nil {
+			return err
+		}
+		if job, err = build.Job(opts); err != nil {
+			return err
+		}
+	}
+	if err = ctrl.SetControllerReference(a.WorkflowRun, job, a.Reconciler.Scheme); err != nil {
+		return err
+	}
+	logger.V(1).Info("Running build", "action", action.Name, "type", buildType, "revision", a.WorkflowRun.Spec.Revision)
+	return a.Reconciler.Create(a.Ctx, job)
+}
+
+// reusedBuild returns the job reporting the image of the revision when the source skips the existing images and the
+// registry already has it, nil otherwise
+func (a *ActionsHandler) reusedBuild(opts build.Options, dockerConfig *k8sUtils.DockerConfig) (*batchv1.Job, error) {
+	if opts.Source.Cache == nil || !opts.Source.Cache.SkipExisting || opts.Revision == "" {
+		return nil, nil
+	}
+	reference := build.Repository(opts.Source) + ":" + opts.Revision
+	var credentials string
+	if dockerConfig != nil {
+		credentials, _ = dockerConfig.Credentials(reference)
+	}
+	digest, err := build.ImageDigest(a.Ctx, reference, credentials)
+	if err != nil {
+		// the image is built again when the registry can't tell
+		log.FromContext(a.Ctx).Error(err, "Unable to look up the image of the revision", "image", reference)
+		return nil, nil
+	}
+	if digest == "" {
+		return nil, nil
+	}
+	log.FromContext(a.Ctx).Info("Reusing the image of the revision", "image", reference, "digest", digest)
+	return build.ReusedJob(opts, digest), nil
+}
+
+// prepareBuildCache creates the volume of the cache key of a volume cache, or marks it used, and deletes the cache
+// volumes of the component which weren't used within the ttl of the cache
+func (a *ActionsHandler) prepareBuildCache(component *coreconureiov1alpha1.Component, key string) error {
+	cache := component.Spec.Values.Source.Cache
+	if cache == nil || cache.Type != coreconureiov1alpha1.VolumeBuildCache {
+		return nil
+	}
+	ttl, err := build.CacheTTL(cache)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	volume, err := build.CacheVolume(a.Namespace, component.Name, key, cache, now)
+	if err != nil {
+		return err
+	}
+	var existing corev1.PersistentVolumeClaim
+	err = a.Reconciler.Get(a.Ctx, client.ObjectKeyFromObject(volume), &existing)
+	if apierrors.IsNotFound(err) {
+		// the volume is deleted with the component
+		if err = ctrl.SetControllerReference(component, volume, a.Reconciler.Scheme); err != nil {
+			return err
+		}
+		if err = a.Reconciler.Create(a.Ctx, volume); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		patch := client.MergeFrom(existing.DeepCopy())
+		if existing.Annotations == nil {
+			existing.Annotations = map[string]string{}
+		}
+		existing.Annotations[coreconureiov1alpha1.BuildCacheLastUsedAnnotation] = volume.Annotations[coreconureiov1alpha1.BuildCacheLastUsedAnnotation]
+		if err = a.Reconciler.Patch(a.Ctx, &existing, patch); err != nil {
+			return err
+		}
+	}
+
+	var volumes corev1.PersistentVolumeClaimList
+	if err = a.Reconciler.List(a.Ctx, &volumes, client.InNamespace(a.Namespace), client.HasLabels{coreconureiov1alpha1.BuildCacheLabel},
+		client.MatchingLabels{k8sUtils.ComponentNameLabel: component.Name}); err != nil {
+		return err
+	}
+	for _, name := range build.StaleCacheVolumes(volumes.Items, key, ttl, now) {
+		log.FromContext(a.Ctx).Info("Deleting stale build cache", "volume", name)
+		stale := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: a.Namespace, Name: name}}
+		if err = a.Reconciler.Delete(a.Ctx, &stale); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	conurev1alpha1 "github.com/coffeenights/conure/apis/core/v1alpha1"
+	"github.com/coffeenights/conure/internal/build"
 	"github.com/coffeenights/conure/internal/controller/core/common"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -24,6 +26,11 @@ type WorkflowReconciler struct {
 	Scheme *runtime.Scheme
 }
 
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core.conure.io,resources=components/status,verbs=get;update;patch
+
 func (r *WorkflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
@@ -32,6 +39,9 @@ func (r *WorkflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		logger.V(1).Info("WorkflowRun resource not found.")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	ctx, span := common.StartReconcileSpan(ctx, "WorkflowRun", &wflr)
+	defer span.End()
+	logger = log.FromContext(ctx)
 	var app conurev1alpha1.Application
 	nsn := types.NamespacedName{
 		Namespace: req.Namespace,
@@ -77,6 +87,13 @@ func (r *WorkflowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			return ctrl.Result{}, nil
 		}
 		for _, condition := range job.Status.Conditions {
+			// The digest of a build is written to its component before the run can finish
+			if condition.Type == batchv1.JobComplete && labels[conurev1alpha1.BuildTypeLabel] != "" {
+				if err := r.recordBuild(ctx, &wflr, &job); err != nil {
+					logger.Error(err, "unable to record the build", "job", job.Name)
+					return ctrl.Result{}, err
+				}
+			}
 			// If the job is completed, check if it was the last action and set the condition
 			if condition.Type == batchv1.JobComplete && labels[conurev1alpha1.WorkflowActionNamelabel] == lastAction.Name {
 				if err := r.setCondition(ctx, &wflr, conurev1alpha1.ConditionTypeFinished, metav1.ConditionTrue, conurev1alpha1.FinishedSuccessfullyReason, "Finished"); err != nil {
@@ -117,6 +134,46 @@ func (r *WorkflowReconciler) setCondition(ctx context.Context, wflr *conurev1alp
 	return nil
 }
 
+// recordBuild sets the image pushed by the build job as the build of the component of the run, its digest is the
+// termination message of the report container
+func (r *WorkflowReconciler) recordBuild(ctx context.Context, wflr *conurev1alpha1.WorkflowRun, job *batchv1.Job) error {
+	var component conurev1alpha1.Component
+	if err := r.Get(ctx, types.NamespacedName{Namespace: wflr.Namespace, Name: wflr.Spec.ComponentName}, &component); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if component.Status.Build != nil && component.Status.Build.WorkflowRun == wflr.Name {
+		return nil
+	}
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(job.Namespace), client.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return err
+	}
+	var message string
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == build.ReportContainer && status.State.Terminated != nil && status.State.Terminated.ExitCode == 0 {
+				message = status.State.Terminated.Message
+			}
+		}
+	}
+	digest, err := build.ParseDigest(message)
+	if err != nil {
+		return err
+	}
+	source := component.Spec.Values.Source
+	component.Status.Build = &conurev1alpha1.BuildStatus{
+		OCIRepository: build.Repository(source),
+		Tag:           build.Tag(source),
+		Digest:        digest,
+		Revision:      wflr.Spec.Revision,
+		WorkflowRun:   wflr.Name,
+		BuiltAt:       metav1.Now(),
+		Reused:        job.Labels[conurev1alpha1.BuildReusedLabel] == "true",
+	}
+	log.FromContext(ctx).Info("Recorded build", "component", component.Name, "digest", digest)
+	return common.ApplyStatus(ctx, &component, r.Client)
+}
+
 func (r *WorkflowReconciler) isFinished(wflr *conurev1alpha1.WorkflowRun) bool {
 	_, exists := common.ContainsCondition(wflr.Status.Conditions, conurev1alpha1.ConditionTypeFinished.String())
 	return exists
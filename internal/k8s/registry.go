@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// RegistryCredentialsSecret is the kubernetes.io/dockerconfigjson secret synced by the API server into each
+// environment namespace, with the credentials of the docker_registry integrations of the organization. The workloads
+// pull their images with it, the workflow actions pull their modules and push the images they build with it.
+const RegistryCredentialsSecret = "conure-registry-credentials"
+
+// dockerHubKey is the key of Docker Hub in the docker configs
+const dockerHubKey = "https://index.docker.io/v1/"
+
+// DockerConfig is the content of the .dockerconfigjson key of a kubernetes.io/dockerconfigjson secret
+type DockerConfig struct {
+	Auths map[string]DockerAuth `json:"auths"`
+}
+
+type DockerAuth struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Auth is the base64 of username:password
+	Auth string `json:"auth,omitempty"`
+}
+
+func NewDockerConfig() *DockerConfig {
+	return &DockerConfig{Auths: map[string]DockerAuth{}}
+}
+
+func ParseDockerConfig(data []byte) (*DockerConfig, error) {
+	config := NewDockerConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Add sets the credentials of the registry of the URL, which may have a scheme and a path
+func (c *DockerConfig) Add(registryURL string, username string, password string) {
+	c.Auths[registryKey(RegistryHost(registryURL))] = DockerAuth{
+		Username: username,
+		Password: password,
+		Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	}
+}
+
+// Credentials returns the username:password of the registry of the image or module reference
+func (c *DockerConfig) Credentials(reference string) (string, bool) {
+	host := RegistryHost(reference)
+	for key, auth := range c.Auths {
+		if RegistryHost(key) != host {
+			continue
+		}
+		if auth.Username != "" {
+			return auth.Username + ":" + auth.Password, true
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil || !strings.Contains(string(decoded), ":") {
+			return "", false
+		}
+		return string(decoded), true
+	}
+	return "", false
+}
+
+func (c *DockerConfig) Marshal() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// RegistryHost returns the host of a registry URL or of an image or module reference, the references without a
+// registry are on Docker Hub
+func RegistryHost(reference string) string {
+	for _, scheme := range []string{"oci://", "https://", "http://"} {
+		reference = strings.TrimPrefix(reference, scheme)
+	}
+	host, _, _ := strings.Cut(reference, "/")
+	// an image of Docker Hub starts with its namespace or name, which has no dot nor port
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return "index.docker.io"
+	}
+	switch host {
+	case "docker.io", "registry-1.docker.io", "index.docker.io":
+		return "index.docker.io"
+	}
+	return host
+}
+
+func registryKey(host string) string {
+	if host == "index.docker.io" {
+		return dockerHubKey
+	}
+	return host
+}
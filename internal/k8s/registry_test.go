@@ -0,0 +1,60 @@
+package k8s
+
+import (
+	"testing"
+)
+
+func TestRegistryHost(t *testing.T) {
+	cases := map[string]string{
+		"oci://dev.conure.local:30050/definitions/build-image": "dev.conure.local:30050",
+		"https://ghcr.io":                                         "ghcr.io",
+		"ghcr.io/coffeenights/conure:latest":                      "ghcr.io",
+		"registry-service.conure-system.svc.cluster.local:5000/a": "registry-service.conure-system.svc.cluster.local:5000",
+		"coffeenights/django:latest":                              "index.docker.io",
+		"nginx":                                                   "index.docker.io",
+		"docker.io/library/nginx":                                 "index.docker.io",
+		"https://index.docker.io/v1/":                             "index.docker.io",
+		"localhost/app":                                           "localhost",
+	}
+	for reference, expected := range cases {
+		if host := RegistryHost(reference); host != expected {
+			t.Errorf("Expected %s for %s, got: %s", expected, reference, host)
+		}
+	}
+}
+
+func TestDockerConfig_Credentials(t *testing.T) {
+	config := NewDockerConfig()
+	config.Add("https://ghcr.io", "conure", "s3cr3t")
+	config.Add("docker.io", "hub", "pass")
+	if _, ok := config.Auths[dockerHubKey]; !ok {
+		t.Errorf("Expected Docker Hub under %s, got: %v", dockerHubKey, config.Auths)
+	}
+
+	data, err := config.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseDockerConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials, ok := parsed.Credentials("oci://ghcr.io/coffeenights/modules/build-image"); !ok || credentials != "conure:s3cr3t" {
+		t.Errorf("Expected the credentials of ghcr.io, got: %q", credentials)
+	}
+	if credentials, ok := parsed.Credentials("coffeenights/django:latest"); !ok || credentials != "hub:pass" {
+		t.Errorf("Expected the credentials of Docker Hub, got: %q", credentials)
+	}
+	if _, ok := parsed.Credentials("quay.io/coffeenights/app"); ok {
+		t.Error("Expected no credentials for a registry without integration")
+	}
+
+	// the configs written by docker login only have the auth
+	parsed, err = ParseDockerConfig([]byte(`{"auths": {"quay.io": {"auth": "dXNlcjpwYXNz"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials, ok := parsed.Credentials("quay.io/coffeenights/app"); !ok || credentials != "user:pass" {
+		t.Errorf("Expected the credentials decoded from the auth, got: %q", credentials)
+	}
+}
@@ -10,4 +10,14 @@ const (
 	NamespaceLabel        = "conure.io/namespace"
 	ComponentIDLabel      = "conure.io/component-id"
 	ComponentNameLabel    = "conure.io/component-name"
+	GitCommitLabel        = "conure.io/git-commit"
+)
+
+// The annotations of the workflow runs triggered by a push, the commit statuses are reported with them
+const (
+	GitRepositoryAnnotation = "conure.io/git-repository"
+	GitBranchAnnotation     = "conure.io/git-branch"
+	IntegrationIDAnnotation = "conure.io/integration-id"
+	// CommitStatusAnnotation is the last state reported, so a restart of the API server doesn't report it again
+	CommitStatusAnnotation = "conure.io/commit-status"
 )
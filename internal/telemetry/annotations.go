@@ -0,0 +1,63 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// The trace context travels from the API server to the controllers in the annotations of the objects, so the
+// reconciliations triggered by a request are part of its trace
+const (
+	TraceParentAnnotation = "conure.io/traceparent"
+	TraceStateAnnotation  = "conure.io/tracestate"
+)
+
+const annotationPrefix = "conure.io/"
+
+var traceContext = propagation.TraceContext{}
+
+// annotationCarrier maps the W3C trace context headers to the annotations
+type annotationCarrier map[string]string
+
+func (a annotationCarrier) Get(key string) string {
+	return a[annotationPrefix+key]
+}
+
+func (a annotationCarrier) Set(key string, value string) {
+	a[annotationPrefix+key] = value
+}
+
+func (a annotationCarrier) Keys() []string {
+	var keys []string
+	for key := range a {
+		if name, ok := strings.CutPrefix(key, annotationPrefix); ok {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// InjectAnnotations writes the trace context of ctx to the annotations, nothing is written outside a trace
+func InjectAnnotations(ctx context.Context, annotations map[string]string) {
+	traceContext.Inject(ctx, annotationCarrier(annotations))
+}
+
+// ExtractAnnotations returns ctx with the trace context read from the annotations, as the remote parent of the
+// spans started from it
+func ExtractAnnotations(ctx context.Context, annotations map[string]string) context.Context {
+	return traceContext.Extract(ctx, annotationCarrier(annotations))
+}
+
+// CopyAnnotations copies the trace context annotations from one object to another, so the objects created by a
+// reconciliation stay in the trace that triggered it
+func CopyAnnotations(from map[string]string, to map[string]string) {
+	for _, key := range []string{TraceParentAnnotation, TraceStateAnnotation} {
+		if value, ok := from[key]; ok {
+			to[key] = value
+		} else {
+			delete(to, key)
+		}
+	}
+}
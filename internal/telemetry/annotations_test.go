@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAnnotations(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	annotations := map[string]string{"app.oam.dev/name": "shop"}
+	InjectAnnotations(ctx, annotations)
+	expected := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if annotations[TraceParentAnnotation] != expected {
+		t.Errorf("Expected the traceparent %s, got: %v", expected, annotations)
+	}
+
+	spanContext := trace.SpanContextFromContext(ExtractAnnotations(context.Background(), annotations))
+	if spanContext.TraceID() != traceID || spanContext.SpanID() != spanID || !spanContext.IsRemote() {
+		t.Errorf("Expected the remote span context of the annotations, got: %v", spanContext)
+	}
+
+	copied := map[string]string{TraceStateAnnotation: "stale"}
+	CopyAnnotations(annotations, copied)
+	if copied[TraceParentAnnotation] != expected {
+		t.Errorf("Expected the traceparent to be copied, got: %v", copied)
+	}
+	if _, ok := copied[TraceStateAnnotation]; ok {
+		t.Errorf("Expected the stale tracestate to be removed, got: %v", copied)
+	}
+}
+
+func TestInjectAnnotations_NoTrace(t *testing.T) {
+	annotations := map[string]string{}
+	InjectAnnotations(context.Background(), annotations)
+	if len(annotations) != 0 {
+		t.Errorf("Expected no annotations outside a trace, got: %v", annotations)
+	}
+}
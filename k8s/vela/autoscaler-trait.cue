@@ -0,0 +1,83 @@
+package autoscaler
+
+"conure-autoscaler": {
+	alias: ""
+	annotations: {}
+	attributes: {
+		appliesToWorkloads: ["deployments.apps", "statefulsets.apps"]
+		podDisruptive: false
+	}
+	description: "HorizontalPodAutoscaler of the workload of the component, scaling it to zero on its requests"
+	labels: {}
+	type: "trait"
+}
+
+template: {
+	outputs: autoscaler: {
+		apiVersion: "autoscaling/v2"
+		kind:       "HorizontalPodAutoscaler"
+		metadata: {
+			name:   context.name
+			labels: parameter.labels
+		}
+		spec: {
+			scaleTargetRef: {
+				apiVersion: context.output.apiVersion
+				kind:       context.output.kind
+				name:       context.output.metadata.name
+			}
+			minReplicas: parameter.min
+			maxReplicas: parameter.max
+			metrics: [
+				if parameter.cpu != _|_ {
+					type: "Resource"
+					resource: {
+						name: "cpu"
+						target: {
+							type:               "Utilization"
+							averageUtilization: parameter.cpu
+						}
+					}
+				},
+				if parameter.memory != _|_ {
+					type: "Resource"
+					resource: {
+						name: "memory"
+						target: {
+							type:               "Utilization"
+							averageUtilization: parameter.memory
+						}
+					}
+				},
+				// only an object or external metric scales from zero replicas
+				if parameter.requests != _|_ {
+					type: "Object"
+					object: {
+						describedObject: {
+							apiVersion: "v1"
+							kind:       "Service"
+							name:       context.name
+						}
+						metric: name: parameter.requests.metric
+						target: {
+							type:         "AverageValue"
+							averageValue: "\(parameter.requests.target)"
+						}
+					}
+				},
+			]
+		}
+	}
+	parameter: {
+		min: int & >=0
+		max: int & >=1
+		// average utilization of the pods in percent of their requests
+		cpu?:    int
+		memory?: int
+		requests?: {
+			metric: string
+			target: int
+		}
+		labels: [string]: string
+	}
+}
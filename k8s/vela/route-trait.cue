@@ -0,0 +1,100 @@
+package route
+
+"conure-route": {
+	alias: ""
+	annotations: {}
+	attributes: {
+		appliesToWorkloads: ["deployments.apps", "statefulsets.apps"]
+		podDisruptive: false
+	}
+	description: "HTTP routes to the service of the component, as an Ingress or Gateway API HTTPRoutes"
+	labels: {}
+	type: "trait"
+}
+
+template: {
+	outputs: {
+		if parameter.mode == "ingress" {
+			ingress: {
+				apiVersion: "networking.k8s.io/v1"
+				kind:       "Ingress"
+				metadata: {
+					name:   context.name
+					labels: parameter.labels
+					if len(parameter.tlsHosts) > 0 {
+						annotations: "cert-manager.io/cluster-issuer": parameter.issuer
+					}
+				}
+				spec: {
+					ingressClassName: parameter.class
+					rules: [for route in parameter.routes {
+						host: route.host
+						http: paths: [{
+							path:     route.path
+							pathType: "Prefix"
+							backend: service: {
+								name: context.name
+								port: number: route.port
+							}
+						}]
+					}]
+					if len(parameter.tlsHosts) > 0 {
+						tls: [{
+							hosts:      parameter.tlsHosts
+							secretName: context.name + "-tls"
+						}]
+					}
+				}
+			}
+		}
+		if parameter.mode == "gateway" {
+			for i, route in parameter.routes {
+				"httproute-\(i)": {
+					apiVersion: "gateway.networking.k8s.io/v1"
+					kind:       "HTTPRoute"
+					metadata: {
+						name:   "\(context.name)-\(i)"
+						labels: parameter.labels
+					}
+					spec: {
+						parentRefs: [{
+							name: parameter.gateway.name
+							if parameter.gateway.namespace != _|_ {
+								namespace: parameter.gateway.namespace
+							}
+						}]
+						hostnames: [route.host]
+						rules: [{
+							matches: [{path: {
+								type:  "PathPrefix"
+								value: route.path
+							}}]
+							backendRefs: [{
+								name: context.name
+								port: route.port
+							}]
+						}]
+					}
+				}
+			}
+		}
+	}
+	parameter: {
+		mode:   *"ingress" | "gateway"
+		class?: string
+		// the cluster issuer of cert-manager signing the certificate of the tls hosts
+		issuer?: string
+		gateway?: {
+			name:       string
+			namespace?: string
+		}
+		labels: [string]: string
+		routes: [...{
+			host: string
+			path: *"/" | string
+			port: int
+			tls:  *false | bool
+		}]
+		tlsHosts: *[] | [...string]
+	}
+}
@@ -0,0 +1,72 @@
+package workload
+
+"conure-workload": {
+	alias: ""
+	annotations: {}
+	attributes: {
+		appliesToWorkloads: ["deployments.apps"]
+		podDisruptive: true
+	}
+	description: "Probes, pre-stop hook, termination grace period and rollout strategy of the workload of the component"
+	labels: {}
+	type: "trait"
+}
+
+template: {
+	patch: spec: {
+		if parameter.strategy != _|_ {
+			// the rolling update bounds don't apply to a recreate strategy
+			// +patchStrategy=retainKeys
+			strategy: parameter.strategy
+		}
+		template: spec: {
+			if parameter.terminationGracePeriodSeconds != _|_ {
+				terminationGracePeriodSeconds: parameter.terminationGracePeriodSeconds
+			}
+			// +patchKey=name
+			containers: [{
+				name: context.name
+				if parameter.livenessProbe != _|_ {
+					livenessProbe: parameter.livenessProbe
+				}
+				if parameter.readinessProbe != _|_ {
+					readinessProbe: parameter.readinessProbe
+				}
+				if parameter.startupProbe != _|_ {
+					startupProbe: parameter.startupProbe
+				}
+				if parameter.preStop != _|_ {
+					lifecycle: preStop: exec: command: parameter.preStop
+				}
+			}]
+		}
+	}
+	parameter: {
+		livenessProbe?:  #Probe
+		readinessProbe?: #Probe
+		// holds the other probes back until the container has started
+		startupProbe?: #Probe
+		// runs in the container before it's sent SIGTERM
+		preStop?: [...string]
+		terminationGracePeriodSeconds?: int & >=0
+		strategy?: {
+			type: "RollingUpdate" | "Recreate"
+			rollingUpdate?: {
+				maxSurge?:       int | string
+				maxUnavailable?: int | string
+			}
+		}
+	}
+	#Probe: {
+		httpGet?: {
+			path: string
+			port: int
+		}
+		tcpSocket?: port: int
+		exec?: command: [...string]
+		initialDelaySeconds?: int
+		periodSeconds?:       int
+		timeoutSeconds?:      int
+		failureThreshold?:    int
+	}
+}
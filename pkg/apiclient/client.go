@@ -0,0 +1,132 @@
+// Package apiclient is the Go client of the Conure REST API. The types and the operations are generated from the
+// OpenAPI document of the api-server, run go generate after changing the routes.
+package apiclient
+
+//go:generate go run ../../cmd/api-server/openapi/clientgen -o zz_generated.client.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AuthCookie is the cookie holding the session token
+const AuthCookie = "auth"
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Token is sent in the auth cookie, it's the token returned by Login
+	Token string
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Error is returned for the error responses of the API
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// Fields are the invalid fields of a validation error
+	Fields string
+}
+
+func (e *Error) Error() string {
+	if e.Fields != "" {
+		return fmt.Sprintf("api error %d: %s (code %s): %s", e.StatusCode, e.Message, e.Code, e.Fields)
+	}
+	return fmt.Sprintf("api error %d: %s (code %s)", e.StatusCode, e.Message, e.Code)
+}
+
+func (c *Client) newRequest(ctx context.Context, method string, path string, query url.Values, body interface{}) (*http.Request, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.AddCookie(&http.Cookie{Name: AuthCookie, Value: c.Token})
+	}
+	return req, nil
+}
+
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		apiError := struct {
+			Code    string `json:"code"`
+			Error   string `json:"error"`
+			Message string `json:"message"`
+			Fields  string `json:"fields"`
+		}{}
+		_ = json.NewDecoder(resp.Body).Decode(&apiError)
+		message := apiError.Error
+		if message == "" {
+			message = apiError.Message
+		}
+		return nil, &Error{StatusCode: resp.StatusCode, Code: apiError.Code, Message: message, Fields: apiError.Fields}
+	}
+	return resp, nil
+}
+
+// do sends the request and decodes the JSON response into out, out can be nil to discard the response
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) (int, http.Header, error) {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err = json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+			return resp.StatusCode, resp.Header, err
+		}
+	}
+	return resp.StatusCode, resp.Header, nil
+}
+
+// stream sends the request and returns the body of the response, it must be closed by the caller
+func (c *Client) stream(ctx context.Context, method string, path string, query url.Values) (io.ReadCloser, error) {
+	req, err := c.newRequest(ctx, method, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,54 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(AuthCookie)
+		if err != nil || cookie.Value != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":"1001","error":"unauthorized"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/organizations/org/a/app":
+			if r.URL.Query().Get("dry_run") == "true" {
+				_, _ = w.Write([]byte(`{"application":"app","components":["web"]}`))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case "/variables/org":
+			w.Header().Set("X-Next-Cursor", "next")
+			_, _ = w.Write([]byte(`{"variables":[{"name":"A"}],"next_cursor":"next","limit":20}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL + "/")
+	_, err := client.Me(context.Background())
+	apiError := &Error{}
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusUnauthorized || apiError.Code != "1001" {
+		t.Errorf("Got %v, want an unauthorized error", err)
+	}
+
+	client.Token = "token"
+	plan, err := client.DeleteApplication(context.Background(), "org", "app", url.Values{"dry_run": {"true"}})
+	if err != nil || plan == nil || plan.Components[0] != "web" {
+		t.Errorf("Got %v %v, want the deletion plan", plan, err)
+	}
+	plan, err = client.DeleteApplication(context.Background(), "org", "app", nil)
+	if err != nil || plan != nil {
+		t.Errorf("Got %v %v, want no content", plan, err)
+	}
+	variables, err := client.ListOrganizationVariables(context.Background(), "org", nil)
+	if err != nil || len(variables.Variables) != 1 || variables.NextCursor != "next" {
+		t.Errorf("Got %v %v, want one variable and the next cursor", variables, err)
+	}
+}
@@ -0,0 +1,1209 @@
+// Code generated by clientgen from the OpenAPI document. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Application struct {
+	AccountID      string                `json:"account_id,omitempty"`
+	CreatedAt      time.Time             `json:"created_at,omitempty"`
+	CreatedBy      string                `json:"created_by,omitempty"`
+	Description    string                `json:"description,omitempty"`
+	Environments   []Environment         `json:"environments,omitempty"`
+	ID             string                `json:"id,omitempty"`
+	Name           string                `json:"name,omitempty"`
+	OrganizationID string                `json:"organization_id,omitempty"`
+	Revisions      []ApplicationRevision `json:"revisions,omitempty"`
+}
+
+type ApplicationListResponse struct {
+	Applications []ApplicationResponse `json:"applications,omitempty"`
+	Limit        int64                 `json:"limit,omitempty"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+	Organization OrganizationResponse  `json:"organization,omitempty"`
+}
+
+type ApplicationResponse struct {
+	AccountID       string                `json:"account_id,omitempty"`
+	CreatedAt       time.Time             `json:"created_at,omitempty"`
+	CreatedBy       string                `json:"created_by,omitempty"`
+	Description     string                `json:"description,omitempty"`
+	Environments    []Environment         `json:"environments,omitempty"`
+	ID              string                `json:"id,omitempty"`
+	Name            string                `json:"name,omitempty"`
+	OrganizationID  string                `json:"organization_id,omitempty"`
+	Revisions       []ApplicationRevision `json:"revisions,omitempty"`
+	TotalComponents int64                 `json:"total_components,omitempty"`
+}
+
+type ApplicationRevision struct {
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	RevisionNumber int64     `json:"revision_number,omitempty"`
+}
+
+type AuditEvent struct {
+	Action         string                 `json:"action,omitempty"`
+	ActorEmail     string                 `json:"actor_email,omitempty"`
+	ActorID        string                 `json:"actor_id,omitempty"`
+	After          map[string]interface{} `json:"after,omitempty"`
+	Before         map[string]interface{} `json:"before,omitempty"`
+	CreatedAt      time.Time              `json:"created_at,omitempty"`
+	ID             string                 `json:"id,omitempty"`
+	IP             string                 `json:"ip,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Method         string                 `json:"method,omitempty"`
+	OrganizationID string                 `json:"organization_id,omitempty"`
+	Path           string                 `json:"path,omitempty"`
+	RequestID      string                 `json:"request_id,omitempty"`
+	StatusCode     int64                  `json:"status_code,omitempty"`
+	TargetID       string                 `json:"target_id,omitempty"`
+	TargetType     string                 `json:"target_type,omitempty"`
+}
+
+type AutoscalingProperties struct {
+	CurrentReplicas int32          `json:"current_replicas,omitempty"`
+	DesiredReplicas int32          `json:"desired_replicas,omitempty"`
+	Events          []ScalingEvent `json:"events,omitempty"`
+	LastScaleTime   time.Time      `json:"last_scale_time,omitempty"`
+	MaxReplicas     int32          `json:"max_replicas,omitempty"`
+	MinReplicas     int32          `json:"min_replicas,omitempty"`
+}
+
+type AutoscalingSettings struct {
+	MaxReplicas    int64 `json:"max_replicas,omitempty"`
+	MinReplicas    int64 `json:"min_replicas,omitempty"`
+	ScaleToZero    bool  `json:"scale_to_zero,omitempty"`
+	TargetCpu      int64 `json:"target_cpu,omitempty"`
+	TargetMemory   int64 `json:"target_memory,omitempty"`
+	TargetRequests int64 `json:"target_requests,omitempty"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	Password    string `json:"password"`
+	Password2   string `json:"password2"`
+}
+
+type Component struct {
+	ApplicationID       string                       `json:"application_id,omitempty"`
+	CreatedAt           time.Time                    `json:"created_at,omitempty"`
+	Description         string                       `json:"description,omitempty"`
+	EnvironmentSettings map[string]ComponentSettings `json:"environment_settings,omitempty"`
+	ID                  string                       `json:"id,omitempty"`
+	Name                string                       `json:"name,omitempty"`
+	Settings            ComponentSettings            `json:"settings,omitempty"`
+	Type                string                       `json:"type,omitempty"`
+	UpdatedAt           time.Time                    `json:"updated_at,omitempty"`
+}
+
+type ComponentListResponse struct {
+	Components []ComponentResponse `json:"components,omitempty"`
+	Limit      int64               `json:"limit,omitempty"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+type ComponentPodsResponse struct {
+	Pods []Pod `json:"pods,omitempty"`
+}
+
+type ComponentProperties struct {
+	Health    ComponentStatusHealth `json:"health,omitempty"`
+	Network   NetworkProperties     `json:"network,omitempty"`
+	Resources ResourcesProperties   `json:"resources,omitempty"`
+	Source    SourceProperties      `json:"source,omitempty"`
+	Storage   StorageProperties     `json:"storage,omitempty"`
+}
+
+type ComponentResponse struct {
+	ApplicationID       string                       `json:"application_id,omitempty"`
+	CreatedAt           time.Time                    `json:"created_at,omitempty"`
+	Description         string                       `json:"description,omitempty"`
+	EnvironmentSettings map[string]ComponentSettings `json:"environment_settings,omitempty"`
+	ID                  string                       `json:"id,omitempty"`
+	Name                string                       `json:"name,omitempty"`
+	Settings            ComponentSettings            `json:"settings,omitempty"`
+	Type                string                       `json:"type,omitempty"`
+	UpdatedAt           time.Time                    `json:"updated_at,omitempty"`
+}
+
+type ComponentSettings struct {
+	HealthSettings    HealthSettings    `json:"health_settings,omitempty"`
+	LifecycleSettings LifecycleSettings `json:"lifecycle_settings,omitempty"`
+	NetworkSettings   NetworkSettings   `json:"network_settings,omitempty"`
+	ResourcesSettings ResourcesSettings `json:"resources_settings,omitempty"`
+	RolloutSettings   RolloutSettings   `json:"rollout_settings,omitempty"`
+	SourceSettings    SourceSettings    `json:"source_settings,omitempty"`
+	StorageSettings   []StorageSettings `json:"storage_settings,omitempty"`
+}
+
+type ComponentStatusHealth struct {
+	Healthy bool      `json:"healthy,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Updated time.Time `json:"updated,omitempty"`
+}
+
+type ComponentStatusResponse struct {
+	Component  ComponentResponse   `json:"component,omitempty"`
+	Properties ComponentProperties `json:"properties,omitempty"`
+}
+
+type CreateApplicationRequest struct {
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name"`
+}
+
+type CreateComponentRequest struct {
+	Description string            `json:"description,omitempty"`
+	Name        string            `json:"name"`
+	Settings    ComponentSettings `json:"settings,omitempty"`
+	Type        string            `json:"type"`
+}
+
+type CreateEnvironmentRequest struct {
+	DomainTemplate string `json:"domain_template,omitempty"`
+	Name           string `json:"name"`
+}
+
+type CreateIntegrationRequest struct {
+	IntegrationType  string      `json:"integration_type"`
+	IntegrationValue interface{} `json:"integration_value"`
+	Name             string      `json:"name"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+type DeletionPlan struct {
+	Application  string   `json:"application,omitempty"`
+	Components   []string `json:"components,omitempty"`
+	Environments []string `json:"environments,omitempty"`
+	Namespaces   []string `json:"namespaces,omitempty"`
+	Variables    []string `json:"variables,omitempty"`
+	Workloads    []string `json:"workloads,omitempty"`
+}
+
+type DisableTwoFactorRequest struct {
+	Code         string `json:"code,omitempty"`
+	Password     string `json:"password"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+type EmptyResponse struct {
+}
+
+type Environment struct {
+	DomainTemplate string `json:"domain_template,omitempty"`
+	ID             string `json:"id,omitempty"`
+	Name           string `json:"name,omitempty"`
+}
+
+type EnvironmentResponse struct {
+	DomainTemplate string `json:"domain_template,omitempty"`
+	ID             string `json:"id,omitempty"`
+	Name           string `json:"name,omitempty"`
+}
+
+type ErrorResponse struct {
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Fields  string `json:"fields,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type HealthSettings struct {
+	Liveness  ProbeSettings `json:"liveness,omitempty"`
+	Readiness ProbeSettings `json:"readiness,omitempty"`
+	Startup   ProbeSettings `json:"startup,omitempty"`
+}
+
+type Integration struct {
+	CreatedAt       time.Time              `json:"created_at,omitempty"`
+	Fields          map[string]interface{} `json:"fields,omitempty"`
+	ID              string                 `json:"id,omitempty"`
+	IntegrationType string                 `json:"integration_type,omitempty"`
+	Name            string                 `json:"name,omitempty"`
+	OrganizationID  string                 `json:"organization_id,omitempty"`
+	UpdatedAt       time.Time              `json:"updated_at,omitempty"`
+}
+
+type IntegrationField struct {
+	Name     string `json:"name,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Secret   bool   `json:"secret,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+type IntegrationListResponse struct {
+	Integrations []Integration `json:"integrations,omitempty"`
+	Limit        int64         `json:"limit,omitempty"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+type IntegrationSchema struct {
+	Description string             `json:"description,omitempty"`
+	Fields      []IntegrationField `json:"fields,omitempty"`
+	Name        string             `json:"name,omitempty"`
+}
+
+type LifecycleSettings struct {
+	PreStopCommand                []string `json:"pre_stop_command,omitempty"`
+	TerminationGracePeriodSeconds int64    `json:"termination_grace_period_seconds,omitempty"`
+}
+
+type ListAuditEventsResponse struct {
+	Events     []AuditEvent `json:"events,omitempty"`
+	Limit      int64        `json:"limit,omitempty"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+	Total      int64        `json:"total,omitempty"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	Challenge         string `json:"challenge,omitempty"`
+	Token             string `json:"token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+}
+
+type Manifest struct {
+	Application  ManifestApplication   `json:"application,omitempty"`
+	Components   []ManifestComponent   `json:"components,omitempty"`
+	Environments []ManifestEnvironment `json:"environments,omitempty"`
+}
+
+type ManifestApplication struct {
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name"`
+}
+
+type ManifestApplyResponse struct {
+	Application Application  `json:"application,omitempty"`
+	Diff        ManifestDiff `json:"diff,omitempty"`
+}
+
+type ManifestChange struct {
+	Action      string   `json:"action,omitempty"`
+	Component   string   `json:"component,omitempty"`
+	Environment string   `json:"environment,omitempty"`
+	Fields      []string `json:"fields,omitempty"`
+	Kind        string   `json:"kind,omitempty"`
+	Name        string   `json:"name,omitempty"`
+}
+
+type ManifestComponent struct {
+	Description string            `json:"description,omitempty"`
+	Name        string            `json:"name"`
+	Settings    ComponentSettings `json:"settings,omitempty"`
+	Type        string            `json:"type"`
+}
+
+type ManifestDiff struct {
+	Application    string           `json:"application,omitempty"`
+	Changes        []ManifestChange `json:"changes,omitempty"`
+	MissingSecrets []string         `json:"missing_secrets,omitempty"`
+}
+
+type ManifestEnvironment struct {
+	Components     map[string]ManifestEnvironmentComponent `json:"components,omitempty"`
+	DomainTemplate string                                  `json:"domain_template,omitempty"`
+	Name           string                                  `json:"name"`
+	Variables      []ManifestVariable                      `json:"variables,omitempty"`
+}
+
+type ManifestEnvironmentComponent struct {
+	Settings  interface{}        `json:"settings,omitempty"`
+	Variables []ManifestVariable `json:"variables,omitempty"`
+}
+
+type ManifestVariable struct {
+	Name   string `json:"name"`
+	Secret bool   `json:"secret,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+type NetworkProperties struct {
+	ExternalIP string            `json:"external_ip,omitempty"`
+	Host       string            `json:"host,omitempty"`
+	IP         string            `json:"ip,omitempty"`
+	Port       []int32           `json:"port,omitempty"`
+	Routes     []RouteProperties `json:"routes,omitempty"`
+}
+
+type NetworkSettings struct {
+	Exposed bool            `json:"exposed,omitempty"`
+	Ports   []PortSettings  `json:"ports,omitempty"`
+	Routes  []RouteSettings `json:"routes,omitempty"`
+	Type    string          `json:"type,omitempty"`
+}
+
+type OrganizationListResponse struct {
+	Limit         int64                  `json:"limit,omitempty"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+	Organizations []OrganizationResponse `json:"organizations,omitempty"`
+}
+
+type OrganizationResponse struct {
+	AccountID        string    `json:"account_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at,omitempty"`
+	ID               string    `json:"id,omitempty"`
+	Name             string    `json:"name,omitempty"`
+	RequireTwoFactor bool      `json:"require_two_factor,omitempty"`
+	Status           string    `json:"status,omitempty"`
+}
+
+type OrganizationSecurityRequest struct {
+	RequireTwoFactor bool `json:"require_two_factor"`
+}
+
+type Pod struct {
+	Conditions []PodCondition `json:"conditions,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Phase      string         `json:"phase,omitempty"`
+}
+
+type PodCondition struct {
+	Message string `json:"message,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+type PortSettings struct {
+	HostPort   int64  `json:"host_port,omitempty"`
+	Protocol   string `json:"protocol,omitempty"`
+	TargetPort int64  `json:"target_port,omitempty"`
+}
+
+type ProbeSettings struct {
+	Command             []string `json:"command,omitempty"`
+	FailureThreshold    int64    `json:"failure_threshold,omitempty"`
+	InitialDelaySeconds int64    `json:"initial_delay_seconds,omitempty"`
+	Path                string   `json:"path,omitempty"`
+	PeriodSeconds       int64    `json:"period_seconds,omitempty"`
+	Port                int64    `json:"port,omitempty"`
+	TimeoutSeconds      int64    `json:"timeout_seconds,omitempty"`
+	Type                string   `json:"type,omitempty"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type RegisterRequest struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Password2 string `json:"password2"`
+	Token     string `json:"token,omitempty"`
+}
+
+type Report struct {
+	Checks map[string]string `json:"checks,omitempty"`
+	Status string            `json:"status,omitempty"`
+}
+
+type ResetPasswordRequest struct {
+	Password  string `json:"password"`
+	Password2 string `json:"password2"`
+	Token     string `json:"token"`
+}
+
+type ResourcesProperties struct {
+	Autoscaling AutoscalingProperties `json:"autoscaling,omitempty"`
+	Cpu         string                `json:"cpu,omitempty"`
+	Memory      string                `json:"memory,omitempty"`
+	Replicas    int32                 `json:"replicas,omitempty"`
+}
+
+type ResourcesSettings struct {
+	Autoscaling AutoscalingSettings `json:"autoscaling,omitempty"`
+	Cpu         float64             `json:"cpu,omitempty"`
+	Memory      int64               `json:"memory,omitempty"`
+	Replicas    int64               `json:"replicas,omitempty"`
+}
+
+type RolloutSettings struct {
+	MaxSurge       string `json:"max_surge,omitempty"`
+	MaxUnavailable string `json:"max_unavailable,omitempty"`
+	Strategy       string `json:"strategy,omitempty"`
+}
+
+type RouteProperties struct {
+	Address string `json:"address,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Message string `json:"message,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Port    int32  `json:"port,omitempty"`
+	Ready   bool   `json:"ready,omitempty"`
+	Tls     bool   `json:"tls,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+type RouteSettings struct {
+	Host       string `json:"host,omitempty"`
+	PathPrefix string `json:"path_prefix,omitempty"`
+	TargetPort int64  `json:"target_port,omitempty"`
+	Tls        bool   `json:"tls,omitempty"`
+}
+
+type ScalingEvent struct {
+	Count   int32     `json:"count,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+	Time    time.Time `json:"time,omitempty"`
+	Warning bool      `json:"warning,omitempty"`
+}
+
+type SourceProperties struct {
+	Command        string `json:"command,omitempty"`
+	ContainerImage string `json:"container_image,omitempty"`
+}
+
+type SourceSettings struct {
+	Command       string `json:"command,omitempty"`
+	GitBranch     string `json:"git_branch,omitempty"`
+	GitRepository string `json:"git_repository,omitempty"`
+	Repository    string `json:"repository,omitempty"`
+}
+
+type StatusResponse struct {
+	Status string `json:"status,omitempty"`
+}
+
+type StorageProperties struct {
+	Health  bool               `json:"health,omitempty"`
+	Volumes []VolumeProperties `json:"volumes,omitempty"`
+}
+
+type StorageSettings struct {
+	MountPath string  `json:"mount_path,omitempty"`
+	Name      string  `json:"name,omitempty"`
+	Size      float64 `json:"size,omitempty"`
+}
+
+type TestConnectionResponse struct {
+	Message string `json:"message,omitempty"`
+	Success bool   `json:"success,omitempty"`
+}
+
+type TokenRequest struct {
+	Token string `json:"token"`
+}
+
+type TokenResponse struct {
+	Token string `json:"token,omitempty"`
+}
+
+type TransferOrganizationRequest struct {
+	Email string `json:"email"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorEnrollResponse struct {
+	ProvisioningURI string `json:"provisioning_uri,omitempty"`
+	Secret          string `json:"secret,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+	Challenge    string `json:"challenge"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+type UpdateApplicationRequest struct {
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+type UpdateEnvironmentRequest struct {
+	DomainTemplate string `json:"domain_template,omitempty"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+type User struct {
+	Client              string    `json:"client,omitempty"`
+	CreatedAt           time.Time `json:"created_at,omitempty"`
+	Email               string    `json:"email,omitempty"`
+	ID                  string    `json:"id,omitempty"`
+	IsActive            bool      `json:"is_active,omitempty"`
+	IsAdmin             bool      `json:"is_admin,omitempty"`
+	LastLoginAt         time.Time `json:"last_login_at,omitempty"`
+	PendingVerification bool      `json:"pending_verification,omitempty"`
+	TwoFactorEnabled    bool      `json:"two_factor_enabled,omitempty"`
+	UpdatedAt           time.Time `json:"updated_at,omitempty"`
+}
+
+type UserToken struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	Purpose   string    `json:"purpose,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	UsedAt    time.Time `json:"used_at,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+type Variable struct {
+	ApplicationID  string    `json:"application_id,omitempty"`
+	ComponentID    string    `json:"component_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	EnvironmentID  string    `json:"environment_id,omitempty"`
+	ID             string    `json:"id,omitempty"`
+	IsEncrypted    bool      `json:"is_encrypted,omitempty"`
+	Name           string    `json:"name"`
+	OrganizationID string    `json:"organization_id,omitempty"`
+	Type           string    `json:"type,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+	Value          string    `json:"value"`
+}
+
+type VariableListResponse struct {
+	Limit      int64      `json:"limit,omitempty"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	Variables  []Variable `json:"variables,omitempty"`
+}
+
+type VolumeProperties struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+	Size string `json:"size,omitempty"`
+}
+
+type WebhookResponse struct {
+	WorkflowRuns []string `json:"workflow_runs,omitempty"`
+}
+
+// ApplyManifest calls POST /organizations/{organizationID}/apply
+//
+// Create, update and delete the application of the manifest with its environments, components and variables
+func (c *Client) ApplyManifest(ctx context.Context, organizationID string, body *Manifest) (*ManifestApplyResponse, error) {
+	out := &ManifestApplyResponse{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/"+url.PathEscape(organizationID)+"/apply", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChangePassword calls PATCH /auth/change-password
+func (c *Client) ChangePassword(ctx context.Context, body *ChangePasswordRequest) (*MessageResponse, error) {
+	out := &MessageResponse{}
+	if _, _, err := c.do(ctx, "PATCH", "/auth/change-password", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ComponentPods calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}/c/{componentID}/status/pods
+func (c *Client) ComponentPods(ctx context.Context, organizationID string, applicationID string, environment string, componentID string) (*ComponentPodsResponse, error) {
+	out := &ComponentPodsResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c/"+url.PathEscape(componentID)+"/status/pods", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateApplication calls POST /organizations/{organizationID}/a
+func (c *Client) CreateApplication(ctx context.Context, organizationID string, body *CreateApplicationRequest) (*Application, error) {
+	out := &Application{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/"+url.PathEscape(organizationID)+"/a", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateComponent calls POST /organizations/{organizationID}/a/{applicationID}/e/{environment}/c
+func (c *Client) CreateComponent(ctx context.Context, organizationID string, applicationID string, environment string, body *CreateComponentRequest) (*Component, error) {
+	out := &Component{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateComponentVariable calls POST /variables/{organizationID}/{applicationID}/e/{environmentID}/c/{componentID}
+func (c *Client) CreateComponentVariable(ctx context.Context, organizationID string, applicationID string, environmentID string, componentID string, body *Variable) (*Variable, error) {
+	out := &Variable{}
+	if _, _, err := c.do(ctx, "POST", "/variables/"+url.PathEscape(organizationID)+"/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environmentID)+"/c/"+url.PathEscape(componentID), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateEnvironment calls POST /organizations/{organizationID}/a/{applicationID}/e
+func (c *Client) CreateEnvironment(ctx context.Context, organizationID string, applicationID string, body *CreateEnvironmentRequest) (*EmptyResponse, error) {
+	out := &EmptyResponse{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateEnvironmentVariable calls POST /variables/{organizationID}/{applicationID}/e/{environmentID}
+func (c *Client) CreateEnvironmentVariable(ctx context.Context, organizationID string, applicationID string, environmentID string, body *Variable) (*Variable, error) {
+	out := &Variable{}
+	if _, _, err := c.do(ctx, "POST", "/variables/"+url.PathEscape(organizationID)+"/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environmentID), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateIntegration calls POST /settings/{organizationID}/i
+func (c *Client) CreateIntegration(ctx context.Context, organizationID string, body *CreateIntegrationRequest) (*Integration, error) {
+	out := &Integration{}
+	if _, _, err := c.do(ctx, "POST", "/settings/"+url.PathEscape(organizationID)+"/i", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateInvitation calls POST /auth/invitations
+//
+// Invite a user to register, admins only
+func (c *Client) CreateInvitation(ctx context.Context, body *EmailRequest) (*UserToken, error) {
+	out := &UserToken{}
+	if _, _, err := c.do(ctx, "POST", "/auth/invitations", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateOrganization calls POST /organizations/
+func (c *Client) CreateOrganization(ctx context.Context, body *CreateOrganizationRequest) (*OrganizationResponse, error) {
+	out := &OrganizationResponse{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateOrganizationVariable calls POST /variables/{organizationID}
+func (c *Client) CreateOrganizationVariable(ctx context.Context, organizationID string, body *Variable) (*Variable, error) {
+	out := &Variable{}
+	if _, _, err := c.do(ctx, "POST", "/variables/"+url.PathEscape(organizationID), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteApplication calls DELETE /organizations/{organizationID}/a/{applicationID}
+//
+// Delete the application with its components, variables and environments
+func (c *Client) DeleteApplication(ctx context.Context, organizationID string, applicationID string, query url.Values) (*DeletionPlan, error) {
+	out := &DeletionPlan{}
+	status, _, err := c.do(ctx, "DELETE", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID), query, nil, out)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNoContent {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// DeleteComponent calls DELETE /organizations/{organizationID}/a/{applicationID}/e/{environment}/c/{componentID}
+//
+// Delete the component from every environment along with its variables
+func (c *Client) DeleteComponent(ctx context.Context, organizationID string, applicationID string, environment string, componentID string, query url.Values) (*DeletionPlan, error) {
+	out := &DeletionPlan{}
+	status, _, err := c.do(ctx, "DELETE", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c/"+url.PathEscape(componentID), query, nil, out)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNoContent {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// DeleteEnvironment calls DELETE /organizations/{organizationID}/a/{applicationID}/e/{environment}
+func (c *Client) DeleteEnvironment(ctx context.Context, organizationID string, applicationID string, environment string) (*EmptyResponse, error) {
+	out := &EmptyResponse{}
+	if _, _, err := c.do(ctx, "DELETE", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteIntegration calls DELETE /settings/{organizationID}/i/{integrationID}
+func (c *Client) DeleteIntegration(ctx context.Context, organizationID string, integrationID string) error {
+	_, _, err := c.do(ctx, "DELETE", "/settings/"+url.PathEscape(organizationID)+"/i/"+url.PathEscape(integrationID), nil, nil, nil)
+	return err
+}
+
+// DeleteOrganization calls DELETE /organizations/{organizationID}
+//
+// Delete the organization, its data is purged after the grace period
+func (c *Client) DeleteOrganization(ctx context.Context, organizationID string) (*EmptyResponse, error) {
+	out := &EmptyResponse{}
+	if _, _, err := c.do(ctx, "DELETE", "/organizations/"+url.PathEscape(organizationID), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteVariable calls DELETE /variables/{organizationID}/{variableID}
+func (c *Client) DeleteVariable(ctx context.Context, organizationID string, variableID string) error {
+	_, _, err := c.do(ctx, "DELETE", "/variables/"+url.PathEscape(organizationID)+"/"+url.PathEscape(variableID), nil, nil, nil)
+	return err
+}
+
+// DeployApplication calls PUT /organizations/{organizationID}/a/{applicationID}/e/{environment}
+func (c *Client) DeployApplication(ctx context.Context, organizationID string, applicationID string, environment string) (*MessageResponse, error) {
+	out := &MessageResponse{}
+	if _, _, err := c.do(ctx, "PUT", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DetailApplication calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}
+func (c *Client) DetailApplication(ctx context.Context, organizationID string, applicationID string, environment string) (*ApplicationResponse, error) {
+	out := &ApplicationResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DetailComponent calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}/c/{componentID}
+func (c *Client) DetailComponent(ctx context.Context, organizationID string, applicationID string, environment string, componentID string) (*ComponentResponse, error) {
+	out := &ComponentResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c/"+url.PathEscape(componentID), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DetailOrganization calls GET /organizations/{organizationID}
+func (c *Client) DetailOrganization(ctx context.Context, organizationID string) (*OrganizationResponse, error) {
+	out := &OrganizationResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DiffManifest calls POST /organizations/{organizationID}/diff
+//
+// List the changes applying the manifest would make
+func (c *Client) DiffManifest(ctx context.Context, organizationID string, body *Manifest) (*ManifestDiff, error) {
+	out := &ManifestDiff{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/"+url.PathEscape(organizationID)+"/diff", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DisableOrganization calls POST /organizations/{organizationID}/disable
+func (c *Client) DisableOrganization(ctx context.Context, organizationID string) (*OrganizationResponse, error) {
+	out := &OrganizationResponse{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/"+url.PathEscape(organizationID)+"/disable", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DisableTwoFactor calls POST /auth/2fa/disable
+func (c *Client) DisableTwoFactor(ctx context.Context, body *DisableTwoFactorRequest) (*MessageResponse, error) {
+	out := &MessageResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/2fa/disable", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EnableOrganization calls POST /organizations/{organizationID}/enable
+func (c *Client) EnableOrganization(ctx context.Context, organizationID string) (*OrganizationResponse, error) {
+	out := &OrganizationResponse{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/"+url.PathEscape(organizationID)+"/enable", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EnrollTwoFactor calls POST /auth/2fa/enroll
+func (c *Client) EnrollTwoFactor(ctx context.Context) (*TwoFactorEnrollResponse, error) {
+	out := &TwoFactorEnrollResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/2fa/enroll", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ForgotPassword calls POST /auth/forgot-password
+func (c *Client) ForgotPassword(ctx context.Context, body *EmailRequest) (*MessageResponse, error) {
+	out := &MessageResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/forgot-password", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOpenAPIDocument calls GET /openapi.json
+//
+// This document
+func (c *Client) GetOpenAPIDocument(ctx context.Context) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	if _, _, err := c.do(ctx, "GET", "/openapi.json", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListApplications calls GET /organizations/{organizationID}/a
+func (c *Client) ListApplications(ctx context.Context, organizationID string, query url.Values) (*ApplicationListResponse, error) {
+	out := &ApplicationListResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a", query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAuditEvents calls GET /organizations/{organizationID}/audit
+func (c *Client) ListAuditEvents(ctx context.Context, organizationID string, query url.Values) (*ListAuditEventsResponse, error) {
+	out := &ListAuditEventsResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/audit", query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListComponentVariables calls GET /variables/{organizationID}/{applicationID}/e/{environmentID}/c/{componentID}
+func (c *Client) ListComponentVariables(ctx context.Context, organizationID string, applicationID string, environmentID string, componentID string, query url.Values) (*VariableListResponse, error) {
+	out := &VariableListResponse{}
+	if _, _, err := c.do(ctx, "GET", "/variables/"+url.PathEscape(organizationID)+"/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environmentID)+"/c/"+url.PathEscape(componentID), query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListComponents calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}/c
+func (c *Client) ListComponents(ctx context.Context, organizationID string, applicationID string, environment string, query url.Values) (*ComponentListResponse, error) {
+	out := &ComponentListResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c", query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListEnvironmentVariables calls GET /variables/{organizationID}/{applicationID}/e/{environmentID}
+func (c *Client) ListEnvironmentVariables(ctx context.Context, organizationID string, applicationID string, environmentID string, query url.Values) (*VariableListResponse, error) {
+	out := &VariableListResponse{}
+	if _, _, err := c.do(ctx, "GET", "/variables/"+url.PathEscape(organizationID)+"/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environmentID), query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListIntegrationTypes calls GET /settings/integration-types
+func (c *Client) ListIntegrationTypes(ctx context.Context) ([]IntegrationSchema, error) {
+	var out []IntegrationSchema
+	if _, _, err := c.do(ctx, "GET", "/settings/integration-types", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListIntegrations calls GET /settings/{organizationID}/i
+func (c *Client) ListIntegrations(ctx context.Context, organizationID string, query url.Values) (*IntegrationListResponse, error) {
+	out := &IntegrationListResponse{}
+	if _, _, err := c.do(ctx, "GET", "/settings/"+url.PathEscape(organizationID)+"/i", query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListOrganizationVariables calls GET /variables/{organizationID}
+func (c *Client) ListOrganizationVariables(ctx context.Context, organizationID string, query url.Values) (*VariableListResponse, error) {
+	out := &VariableListResponse{}
+	if _, _, err := c.do(ctx, "GET", "/variables/"+url.PathEscape(organizationID), query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListOrganizations calls GET /organizations/
+func (c *Client) ListOrganizations(ctx context.Context, query url.Values) (*OrganizationListResponse, error) {
+	out := &OrganizationListResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/", query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Liveness calls GET /healthz
+//
+// Liveness probe
+func (c *Client) Liveness(ctx context.Context) (*Report, error) {
+	out := &Report{}
+	if _, _, err := c.do(ctx, "GET", "/healthz", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Login calls POST /auth/login
+//
+// Log in, answers with a two-factor challenge when 2FA is enabled
+func (c *Client) Login(ctx context.Context, body *LoginRequest) (*LoginResponse, error) {
+	out := &LoginResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/login", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LoginTwoFactor calls POST /auth/login/2fa
+//
+// Complete a login with a TOTP or recovery code
+func (c *Client) LoginTwoFactor(ctx context.Context, body *TwoFactorLoginRequest) (*TokenResponse, error) {
+	out := &TokenResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/login/2fa", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Me calls GET /auth/me
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	out := &User{}
+	if _, _, err := c.do(ctx, "GET", "/auth/me", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Readiness calls GET /readyz
+//
+// Readiness probe, fails while a dependency is down or the server is shutting down
+func (c *Client) Readiness(ctx context.Context) (*Report, error) {
+	out := &Report{}
+	if _, _, err := c.do(ctx, "GET", "/readyz", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReceiveWebhook calls POST /webhooks/{integrationID}
+//
+// Push webhook of a GitHub, GitLab or Gitea integration, authenticated by its signature
+func (c *Client) ReceiveWebhook(ctx context.Context, integrationID string, body *map[string]interface{}) (*WebhookResponse, error) {
+	out := &WebhookResponse{}
+	status, _, err := c.do(ctx, "POST", "/webhooks/"+url.PathEscape(integrationID), nil, body, out)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNoContent {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// RegenerateRecoveryCodes calls POST /auth/2fa/recovery-codes
+func (c *Client) RegenerateRecoveryCodes(ctx context.Context, body *TwoFactorCodeRequest) (*RecoveryCodesResponse, error) {
+	out := &RecoveryCodesResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/2fa/recovery-codes", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Register calls POST /auth/register
+func (c *Client) Register(ctx context.Context, body *RegisterRequest) (*User, error) {
+	out := &User{}
+	if _, _, err := c.do(ctx, "POST", "/auth/register", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResendVerification calls POST /auth/resend-verification
+func (c *Client) ResendVerification(ctx context.Context, body *EmailRequest) (*MessageResponse, error) {
+	out := &MessageResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/resend-verification", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResetPassword calls POST /auth/reset-password
+func (c *Client) ResetPassword(ctx context.Context, body *ResetPasswordRequest) (*MessageResponse, error) {
+	out := &MessageResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/reset-password", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StatusApplication calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}/status
+func (c *Client) StatusApplication(ctx context.Context, organizationID string, applicationID string, environment string) (*StatusResponse, error) {
+	out := &StatusResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/status", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StatusComponent calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}/c/{componentID}/status
+func (c *Client) StatusComponent(ctx context.Context, organizationID string, applicationID string, environment string, componentID string) (*ComponentStatusResponse, error) {
+	out := &ComponentStatusResponse{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c/"+url.PathEscape(componentID)+"/status", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StatusComponentHealth calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}/c/{componentID}/status/health
+func (c *Client) StatusComponentHealth(ctx context.Context, organizationID string, applicationID string, environment string, componentID string) (*ComponentStatusHealth, error) {
+	out := &ComponentStatusHealth{}
+	if _, _, err := c.do(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c/"+url.PathEscape(componentID)+"/status/health", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamLogs calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}/c/{componentID}/status/logs
+//
+// Stream the logs of the pods as server-sent events
+func (c *Client) StreamLogs(ctx context.Context, organizationID string, applicationID string, environment string, componentID string, query url.Values) (io.ReadCloser, error) {
+	return c.stream(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c/"+url.PathEscape(componentID)+"/status/logs", query)
+}
+
+// TestIntegration calls POST /settings/{organizationID}/i/{integrationID}/test
+//
+// Check the stored value of the integration against its service
+func (c *Client) TestIntegration(ctx context.Context, organizationID string, integrationID string) (*TestConnectionResponse, error) {
+	out := &TestConnectionResponse{}
+	if _, _, err := c.do(ctx, "POST", "/settings/"+url.PathEscape(organizationID)+"/i/"+url.PathEscape(integrationID)+"/test", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransferOrganization calls POST /organizations/{organizationID}/transfer
+func (c *Client) TransferOrganization(ctx context.Context, organizationID string, body *TransferOrganizationRequest) (*OrganizationResponse, error) {
+	out := &OrganizationResponse{}
+	if _, _, err := c.do(ctx, "POST", "/organizations/"+url.PathEscape(organizationID)+"/transfer", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UnlockAccount calls POST /auth/unlock
+//
+// Unlock a locked account, admins only
+func (c *Client) UnlockAccount(ctx context.Context, body *EmailRequest) (*MessageResponse, error) {
+	out := &MessageResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/unlock", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateApplication calls PATCH /organizations/{organizationID}/a/{applicationID}
+func (c *Client) UpdateApplication(ctx context.Context, organizationID string, applicationID string, body *UpdateApplicationRequest) (*ApplicationResponse, error) {
+	out := &ApplicationResponse{}
+	if _, _, err := c.do(ctx, "PATCH", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateComponent calls PUT /organizations/{organizationID}/a/{applicationID}/e/{environment}/c/{componentID}
+func (c *Client) UpdateComponent(ctx context.Context, organizationID string, applicationID string, environment string, componentID string, body *CreateComponentRequest) (*Component, error) {
+	out := &Component{}
+	if _, _, err := c.do(ctx, "PUT", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/c/"+url.PathEscape(componentID), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateEnvironment calls PATCH /organizations/{organizationID}/a/{applicationID}/e/{environment}
+//
+// Set the domain template naming the hosts of the routes of the environment
+func (c *Client) UpdateEnvironment(ctx context.Context, organizationID string, applicationID string, environment string, body *UpdateEnvironmentRequest) (*EnvironmentResponse, error) {
+	out := &EnvironmentResponse{}
+	if _, _, err := c.do(ctx, "PATCH", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateIntegration calls PUT /settings/{organizationID}/i/{integrationID}
+func (c *Client) UpdateIntegration(ctx context.Context, organizationID string, integrationID string, body *CreateIntegrationRequest) (*Integration, error) {
+	out := &Integration{}
+	if _, _, err := c.do(ctx, "PUT", "/settings/"+url.PathEscape(organizationID)+"/i/"+url.PathEscape(integrationID), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateOrganization calls PATCH /organizations/{organizationID}
+func (c *Client) UpdateOrganization(ctx context.Context, organizationID string, body *UpdateOrganizationRequest) (*OrganizationResponse, error) {
+	out := &OrganizationResponse{}
+	if _, _, err := c.do(ctx, "PATCH", "/organizations/"+url.PathEscape(organizationID), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateOrganizationSecurity calls PUT /organizations/{organizationID}/security
+//
+// Require 2FA to access the organization, organizations have no members yet so it only applies to the owner
+func (c *Client) UpdateOrganizationSecurity(ctx context.Context, organizationID string, body *OrganizationSecurityRequest) (*OrganizationResponse, error) {
+	out := &OrganizationResponse{}
+	if _, _, err := c.do(ctx, "PUT", "/organizations/"+url.PathEscape(organizationID)+"/security", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VerifyEmail calls POST /auth/verify-email
+func (c *Client) VerifyEmail(ctx context.Context, body *TokenRequest) (*MessageResponse, error) {
+	out := &MessageResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/verify-email", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VerifyTwoFactor calls POST /auth/2fa/verify
+func (c *Client) VerifyTwoFactor(ctx context.Context, body *TwoFactorCodeRequest) (*RecoveryCodesResponse, error) {
+	out := &RecoveryCodesResponse{}
+	if _, _, err := c.do(ctx, "POST", "/auth/2fa/verify", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WatchStatus calls GET /organizations/{organizationID}/a/{applicationID}/e/{environment}/status/events
+//
+// Stream the status of the environment as server-sent events, named application, component, pod and workflow
+func (c *Client) WatchStatus(ctx context.Context, organizationID string, applicationID string, environment string) (io.ReadCloser, error) {
+	return c.stream(ctx, "GET", "/organizations/"+url.PathEscape(organizationID)+"/a/"+url.PathEscape(applicationID)+"/e/"+url.PathEscape(environment)+"/status/events", nil)
+}